@@ -0,0 +1,37 @@
+package agent
+
+// ToolsConfig 定义工具的白名单/黑名单
+//
+// 过滤规则：
+//   - Allow 非空时，仅保留 Allow 中列出的工具；
+//   - Deny 中列出的工具总是被移除（优先级高于 Allow）。
+//
+// 被过滤的工具不会注册到 ToolsNode，也不会通过 BindTools 暴露给模型。
+type ToolsConfig struct {
+	// Allow 允许使用的工具名列表（如 list_containers）；为空表示不限制。
+	Allow []string `mapstructure:"allow"`
+	// Deny 禁止使用的工具名列表（如 remove_image、stop_container）。
+	Deny []string `mapstructure:"deny"`
+	// SecretEnvPatterns 为 container_env 工具中需要打码的环境变量名通配符（大小写不敏感，语法同 path.Match）；
+	// 为空时使用 DefaultSecretEnvPatterns。
+	SecretEnvPatterns []string `mapstructure:"secret_env_patterns"`
+}
+
+// DefaultSecretEnvPatterns 为默认视为敏感信息的环境变量名模式
+var DefaultSecretEnvPatterns = []string{"*_KEY", "*_TOKEN", "*_SECRET", "*PASSWORD*"}
+
+// DefaultMaxStep 为 ReAct Agent 默认的最大推理步数
+const DefaultMaxStep = 20
+
+// Config 为 Agent 行为相关的配置（对应配置文件中的 agent 段）
+type Config struct {
+	Tools ToolsConfig `mapstructure:"tools"`
+
+	// MaxStep 为 ReAct Agent 单次对话的最大步数（模型调用与工具调用各计一步），用于限制成本。
+	// 设置过小会在正常的多轮工具调用尚未完成时被截断；<=0 时使用 DefaultMaxStep。
+	MaxStep int `mapstructure:"max_step"`
+
+	// SystemPromptFile 为自定义系统提示词模板文件路径；为空时使用内置的 SystemPromptTemplate。
+	// 模板中仍可使用 {os}、{arch}、{time}、{current_container} 变量。
+	SystemPromptFile string `mapstructure:"system_prompt_file"`
+}
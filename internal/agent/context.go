@@ -18,3 +18,41 @@ func GetTraceID(ctx context.Context) string {
 	}
 	return ""
 }
+
+type dryRunKey struct{}
+
+// WithDryRun 标记当前调用处于 dry-run 模式（变更类工具只返回执行计划）
+func WithDryRun(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, enabled)
+}
+
+// IsDryRun 判断当前调用是否处于 dry-run 模式
+func IsDryRun(ctx context.Context) bool {
+	v, _ := ctx.Value(dryRunKey{}).(bool)
+	return v
+}
+
+// ToolProgress 为长耗时工具（如 pull_image）执行过程中上报的进度
+type ToolProgress struct {
+	// Tool 为工具名
+	Tool string
+	// Message 为简短的进度描述（如 "3/5 layers"）
+	Message string
+	// Percent 为完成百分比（0~100）；无法估算时为 -1
+	Percent float64
+}
+
+type toolProgressKey struct{}
+
+// WithToolProgress 注册进度回调；工具执行期间通过 ReportToolProgress 上报进度。
+// 回调在工具所在的 goroutine 中同步调用，不应阻塞。
+func WithToolProgress(ctx context.Context, fn func(ToolProgress)) context.Context {
+	return context.WithValue(ctx, toolProgressKey{}, fn)
+}
+
+// ReportToolProgress 上报工具进度；未注册回调时为空操作
+func ReportToolProgress(ctx context.Context, p ToolProgress) {
+	if fn, ok := ctx.Value(toolProgressKey{}).(func(ToolProgress)); ok && fn != nil {
+		fn(p)
+	}
+}
@@ -21,13 +21,20 @@ type ArkConfig struct {
 }
 
 // BuildGraph 构建 Agent 的处理流程图
-func BuildGraph(ctx context.Context, arkConfig ArkConfig, store *storage.Storage) (compose.Runnable[AgentState, AgentState], error) {
+func BuildGraph(ctx context.Context, arkConfig ArkConfig, agentConfig Config, store *storage.Storage) (compose.Runnable[AgentState, AgentState], error) {
 	//获取chatModel
 	cm, err := NewChatModel(ctx, arkConfig)
 	if err != nil {
 		return nil, fmt.Errorf("init chat model failed: %w", err)
 	}
 
+	// 加载系统提示词模板（未配置时使用内置模板）
+	systemPrompt, err := LoadSystemPrompt(agentConfig.SystemPromptFile)
+	if err != nil {
+		return nil, fmt.Errorf("load system prompt failed: %w", err)
+	}
+	template := NewChatTemplate(systemPrompt)
+
 	// 初始化 Graph，输入输出都是 AgentState
 	g := compose.NewGraph[AgentState, AgentState]()
 
@@ -36,21 +43,21 @@ func BuildGraph(ctx context.Context, arkConfig ArkConfig, store *storage.Storage
 	g.AddLambdaNode(NodeInput, compose.InvokableLambda(InputNode))
 
 	// ChatModelNode: 核心 LLM 推理节点
-	// 使用闭包注入 chatModel
+	// 使用闭包注入 chatModel 与 ChatTemplate
 	g.AddLambdaNode(NodeChatModel, compose.InvokableLambda(func(ctx context.Context, state AgentState) (AgentState, error) {
-		return ChatModelNode(ctx, state, cm)
+		return ChatModelNode(ctx, state, cm, template)
 	}))
 
 	// ToolsNode: 工具执行节点
 	// 创建 ToolsNode
-	tools := GetTools(store)
+	tools := GetTools(store, agentConfig.Tools)
 	tn, err := NewToolsNode(ctx, &compose.ToolsNodeConfig{Tools: tools})
 	if err != nil {
 		return nil, fmt.Errorf("create tools node failed: %w", err)
 	}
 
 	// 将工具信息添加到chatModel
-	toolsInfo, err := GetToolsInfo(ctx, store)
+	toolsInfo, err := GetToolsInfo(ctx, store, agentConfig.Tools)
 	if err != nil {
 		return nil, fmt.Errorf("get tools info failed: %w", err)
 	}
@@ -67,6 +74,11 @@ func BuildGraph(ctx context.Context, arkConfig ArkConfig, store *storage.Storage
 			return state, err
 		}
 
+		// dry-run 模式下变更类工具只返回执行计划
+		if enabled, ok := state.Context[DryRunEnabledContextKey].(bool); ok && enabled {
+			ctx = WithDryRun(ctx, true)
+		}
+
 		// 调用 ToolsNode
 		outputs, err := tn.Invoke(ctx, inputMsg)
 		if err != nil {
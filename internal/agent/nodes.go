@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/schema"
 )
 
@@ -16,7 +17,7 @@ import (
 // 2. 使用 ChatTemplate 生成 Messages
 // 3. 调用 ChatModel 获取回复
 // 4. 更新 AgentState (追加 AI Message, 填充 ToolCalls)
-func ChatModelNode(ctx context.Context, state AgentState, chatModel model.ToolCallingChatModel) (AgentState, error) {
+func ChatModelNode(ctx context.Context, state AgentState, chatModel model.ToolCallingChatModel, template prompt.ChatTemplate) (AgentState, error) {
 	if state.Context == nil {
 		state.Context = map[string]interface{}{}
 	}
@@ -41,20 +42,22 @@ func ChatModelNode(ctx context.Context, state AgentState, chatModel model.ToolCa
 
 	// 1. 准备模板变量
 	// 这里的 key 必须与 NewChatTemplate 中的 MessagesPlaceholder 和变量名一致
-	// {os}, {arch}, {time} 是 SystemPromptTemplate 中的变量
+	// {os}, {arch}, {time}, {current_container} 是 SystemPromptTemplate 中的变量
 	// {history} 是 NewChatTemplate 中的 MessagesPlaceholder
+	currentContainer := "无"
+	if name, ok := state.Context[CurrentContainerContextKey].(string); ok && strings.TrimSpace(name) != "" {
+		currentContainer = name
+	}
 	inputVars := map[string]any{
-		"os":      runtime.GOOS,
-		"arch":    runtime.GOARCH,
-		"time":    time.Now().Format(time.RFC3339),
-		"history": state.Messages,
+		"os":                runtime.GOOS,
+		"arch":              runtime.GOARCH,
+		"time":              time.Now().Format(time.RFC3339),
+		"current_container": currentContainer,
+		"history":           state.Messages,
 	}
 
 	// 2. 生成消息列表
-	// 使用 NewChatTemplate() 获取模板实例
-	// 注意：为了性能，ChatTemplate 实例最好在 BuildGraph 时创建一次并复用，
-	// 这里为了代码结构清晰，先在函数内创建，后续可优化为闭包传入
-	template := NewChatTemplate()
+	// ChatTemplate 实例在 BuildGraph 时创建一次，通过闭包传入复用
 	messages, err := template.Format(ctx, inputVars)
 	if err != nil {
 		return state, fmt.Errorf("format chat template failed: %w", err)
@@ -70,6 +73,7 @@ func ChatModelNode(ctx context.Context, state AgentState, chatModel model.ToolCa
 
 	// 4. 更新状态
 	// 追加 AI 回复到历史记录
+	StampMessage(aiMsg, time.Now())
 	state.Messages = append(state.Messages, aiMsg)
 
 	// 填充 NextStepToolCalls 供 Graph 分支判断
@@ -128,10 +132,12 @@ func ChatModelNode(ctx context.Context, state AgentState, chatModel model.ToolCa
 		} else {
 			summary = "即将调用工具。"
 		}
-		state.Messages = append(state.Messages, &schema.Message{
+		confirmMsg := &schema.Message{
 			Role:    schema.Assistant,
 			Content: summary + "是否允许？输入 y/yes 继续，其他输入取消。",
-		})
+		}
+		StampMessage(confirmMsg, time.Now())
+		state.Messages = append(state.Messages, confirmMsg)
 	}
 
 	return state, nil
@@ -163,6 +169,8 @@ func InputNode(ctx context.Context, state AgentState) (AgentState, error) {
 			userMsg := schema.UserMessage(state.UserQuery)
 			state.Messages = append(state.Messages, userMsg)
 		}
+		// 记录用户消息时间（调用方已追加的消息也一并补上）
+		StampMessage(state.Messages[len(state.Messages)-1], time.Now())
 	}
 
 	// 3. 清理上一轮的临时状态
@@ -1,12 +1,16 @@
 package agent
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/schema"
 )
 
 // SystemPromptTemplate 定义系统提示词模板
-// 包含动态变量: {time}, {os}, {arch}
+// 包含动态变量: {time}, {os}, {arch}, {current_container}
 const SystemPromptTemplate = `你是一名专业的 Docker 智能助手 CentAgent。
 你的目标是帮助用户管理、监控和诊断 Docker 容器问题。
 
@@ -14,22 +18,45 @@ const SystemPromptTemplate = `你是一名专业的 Docker 智能助手 CentAgen
 - 操作系统: {os}
 - 架构: {arch}
 - 系统时间: {time}
+- 当前关注的容器: {current_container}
 
 你需要遵循以下原则:
 1. 在执行删除、停止等高风险操作前，必须明确告知用户风险。
 2. 如果用户询问日志，优先查看最近的异常日志。
 3. 回答要简洁明了，命令输出如果过长，请进行摘要。
 4. 如果遇到无法解决的问题，建议用户查阅官方文档。
+5. 用户使用“它”“这个容器”等指代时，优先理解为当前关注的容器。
+6. 工具结果为 JSON：ok 表示是否成功，message 为说明，data 为结构化数据；ok 为 false 表示调用失败（参数有误、操作被拒绝或执行出错），请根据 message 修正参数后重试，或向用户说明原因。
 
 你可以使用的工具包括 Docker 容器管理、镜像管理、网络管理等。
 请根据用户的输入，选择合适的工具或直接回答。`
 
+// LoadSystemPrompt 读取自定义系统提示词模板
+// path 为空时返回内置的 SystemPromptTemplate
+// 注意：模板按 FString 格式渲染，除 {os}/{arch}/{time}/{current_container} 外的字面量花括号需写成 {{ 和 }}
+func LoadSystemPrompt(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return SystemPromptTemplate, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read system prompt file %s: %w", path, err)
+	}
+	content := strings.TrimSpace(string(data))
+	if content == "" {
+		return "", fmt.Errorf("system prompt file %s is empty", path)
+	}
+	return content, nil
+}
+
 // NewChatTemplate 创建一个 ChatTemplate 实例
 // 该模板用于将 AgentState 中的数据转换为 ChatModel 可接受的消息列表
-func NewChatTemplate() prompt.ChatTemplate {
+// systemPrompt 为系统提示词模板（见 LoadSystemPrompt）
+func NewChatTemplate(systemPrompt string) prompt.ChatTemplate {
 	return prompt.FromMessages(schema.FString,
 		// 1. 系统消息 (包含动态环境信息)
-		schema.SystemMessage(SystemPromptTemplate),
+		schema.SystemMessage(systemPrompt),
 
 		// 2. 历史消息占位符 (用于注入对话历史)
 		// "history" 是参数名，true 表示该字段是可选的
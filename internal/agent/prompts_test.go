@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestLoadSystemPrompt_CustomFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt.txt")
+	content := "你是 ACME 公司的 Docker 助手。\n绝对不要删除任何数据卷。\n环境: {os}/{arch} 时间: {time}\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write prompt file: %v", err)
+	}
+
+	tpl, err := LoadSystemPrompt(path)
+	if err != nil {
+		t.Fatalf("load system prompt: %v", err)
+	}
+
+	msgs, err := NewChatTemplate(tpl).Format(context.Background(), map[string]any{
+		"os":      runtime.GOOS,
+		"arch":    runtime.GOARCH,
+		"time":    "2025-01-01T00:00:00Z",
+		"history": []*schema.Message{schema.UserMessage("hi")},
+	})
+	if err != nil {
+		t.Fatalf("format template: %v", err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(msgs))
+	}
+
+	sys := msgs[0]
+	if sys.Role != schema.System {
+		t.Fatalf("expected system message first, got %s", sys.Role)
+	}
+	if !strings.Contains(sys.Content, "绝对不要删除任何数据卷") {
+		t.Fatalf("custom policy missing from system prompt: %s", sys.Content)
+	}
+	want := "环境: " + runtime.GOOS + "/" + runtime.GOARCH + " 时间: 2025-01-01T00:00:00Z"
+	if !strings.Contains(sys.Content, want) {
+		t.Fatalf("variables not substituted, got: %s", sys.Content)
+	}
+	if strings.Contains(sys.Content, "Docker 智能助手 CentAgent") {
+		t.Fatalf("built-in template should be replaced by custom prompt")
+	}
+}
+
+func TestLoadSystemPrompt_DefaultWhenUnset(t *testing.T) {
+	tpl, err := LoadSystemPrompt("")
+	if err != nil {
+		t.Fatalf("load system prompt: %v", err)
+	}
+	if tpl != SystemPromptTemplate {
+		t.Fatalf("expected built-in template when path is empty")
+	}
+}
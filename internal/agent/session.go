@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+// sessionTitleRunes 为会话标题的最大长度（字符数）
+const sessionTitleRunes = 80
+
+// SaveSession 将对话消息（含工具调用与工具结果）按会话 ID 持久化，重复保存时覆盖
+func SaveSession(ctx context.Context, store *storage.Storage, id string, messages []*schema.Message) error {
+	if store == nil {
+		return errors.New("storage not initialized")
+	}
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return fmt.Errorf("marshal session messages: %w", err)
+	}
+	return store.SaveChatSession(ctx, &storage.ChatSession{
+		ID:           id,
+		Title:        sessionTitle(messages),
+		MessagesJSON: string(data),
+		MessageCount: len(messages),
+	})
+}
+
+// LoadSession 读取会话 ID 对应的对话消息，用于恢复会话
+func LoadSession(ctx context.Context, store *storage.Storage, id string) ([]*schema.Message, error) {
+	if store == nil {
+		return nil, errors.New("storage not initialized")
+	}
+	sess, err := store.GetChatSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var messages []*schema.Message
+	if err := json.Unmarshal([]byte(sess.MessagesJSON), &messages); err != nil {
+		return nil, fmt.Errorf("decode session %s: %w", id, err)
+	}
+	return messages, nil
+}
+
+// sessionTitle 取首条用户消息的首行作为会话标题
+func sessionTitle(messages []*schema.Message) string {
+	for _, msg := range messages {
+		if msg == nil || msg.Role != schema.User {
+			continue
+		}
+		line, _, _ := strings.Cut(strings.TrimSpace(msg.Content), "\n")
+		if line == "" {
+			continue
+		}
+		if r := []rune(line); len(r) > sessionTitleRunes {
+			line = string(r[:sessionTitleRunes]) + "…"
+		}
+		return line
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+func TestSaveAndLoadSession(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := storage.Open(ctx, storage.Config{Path: filepath.Join(t.TempDir(), "centagent-test.db")})
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	now := time.Now()
+	messages := []*schema.Message{
+		schema.UserMessage("为什么 web 一直重启？\n请帮我看看"),
+		{
+			Role: schema.Assistant,
+			ToolCalls: []schema.ToolCall{{
+				ID:       "call-1",
+				Type:     "function",
+				Function: schema.FunctionCall{Name: "inspect_container", Arguments: `{"container_id":"web"}`},
+			}},
+		},
+		schema.ToolMessage(`{"ok":true,"data":{"name":"/web","status":"restarting"}}`, "call-1"),
+		{Role: schema.Assistant, Content: "web 在不断重启，退出码为 137。"},
+	}
+	for _, msg := range messages {
+		StampMessage(msg, now)
+	}
+
+	if err := SaveSession(ctx, store, "sess-1", messages[:2]); err != nil {
+		t.Fatalf("save session: %v", err)
+	}
+	// 再次保存同一会话时覆盖
+	if err := SaveSession(ctx, store, "sess-1", messages); err != nil {
+		t.Fatalf("save session again: %v", err)
+	}
+
+	got, err := LoadSession(ctx, store, "sess-1")
+	if err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	if len(got) != len(messages) {
+		t.Fatalf("expected %d messages, got %d", len(messages), len(got))
+	}
+	call := got[1].ToolCalls
+	if len(call) != 1 || call[0].ID != "call-1" || call[0].Function.Name != "inspect_container" || call[0].Function.Arguments != `{"container_id":"web"}` {
+		t.Fatalf("tool call not restored: %+v", call)
+	}
+	if got[2].Role != schema.Tool || got[2].ToolCallID != "call-1" || got[2].Content != messages[2].Content {
+		t.Fatalf("tool message not restored: %+v", got[2])
+	}
+	if got[3].Content != messages[3].Content || !MessageTime(got[3]).Equal(MessageTime(messages[3])) {
+		t.Fatalf("assistant message not restored: %+v", got[3])
+	}
+
+	sessions, err := store.ListChatSessions(ctx, 10)
+	if err != nil {
+		t.Fatalf("list sessions: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Title != "为什么 web 一直重启？" || sessions[0].MessageCount != len(messages) {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+
+	if _, err := LoadSession(ctx, store, "missing"); err == nil {
+		t.Fatalf("expected error for unknown session")
+	}
+}
@@ -1,9 +1,22 @@
 package agent
 
 import (
+	"time"
+
 	"github.com/cloudwego/eino/schema"
 )
 
+// CurrentContainerContextKey 为 AgentState.Context 中“当前关注的容器”的 key
+// 由工具节点在 inspect_container/list_containers 调用后更新，并注入提示词的 {current_container} 变量
+const CurrentContainerContextKey = "current_container"
+
+// DryRunEnabledContextKey 为 AgentState.Context 中 dry-run 开关的 key
+// 开启后工具节点以 dry-run 模式执行：变更类工具只返回将要调用的 Docker API，只读工具照常执行
+const DryRunEnabledContextKey = "dryrun.enabled"
+
+// MessageTimeExtraKey 为 schema.Message.Extra 中记录消息产生时间 (RFC3339) 的 key
+const MessageTimeExtraKey = "centagent.time"
+
 // AgentState 定义了在 Graph 中流转的状态
 type AgentState struct {
 	// 历史对话消息 (包含 User, System, AI, Tool 消息)
@@ -19,3 +32,32 @@ type AgentState struct {
 	// 用户最后的指令 (用于重试或澄清)
 	UserQuery string `json:"user_query"`
 }
+
+// StampMessage 为消息记录产生时间（已有时间则保持不变）
+func StampMessage(msg *schema.Message, now time.Time) {
+	if msg == nil {
+		return
+	}
+	if msg.Extra == nil {
+		msg.Extra = map[string]any{}
+	}
+	if _, ok := msg.Extra[MessageTimeExtraKey]; !ok {
+		msg.Extra[MessageTimeExtraKey] = now.Format(time.RFC3339)
+	}
+}
+
+// MessageTime 返回消息的产生时间；未记录时返回零值
+func MessageTime(msg *schema.Message) time.Time {
+	if msg == nil {
+		return time.Time{}
+	}
+	s, ok := msg.Extra[MessageTimeExtraKey].(string)
+	if !ok {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -2,15 +2,20 @@ package agent
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/schema"
+	"github.com/docker/docker/api/types/container"
 	"github.com/wwwzy/CentAgent/internal/docker"
+	"github.com/wwwzy/CentAgent/internal/logging"
 	"github.com/wwwzy/CentAgent/internal/storage"
 )
 
@@ -42,27 +47,35 @@ func (t *ListContainersTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 				Type:     schema.String,
 				Required: false,
 			},
+			"full_id": {
+				Desc:     "Return full container IDs instead of truncated ones (use when passing IDs to other tools)",
+				Type:     schema.Boolean,
+				Required: false,
+			},
+			"labels": {
+				Desc:     "Include container labels in the result",
+				Type:     schema.Boolean,
+				Required: false,
+			},
 		}),
 	}, nil
 }
 
+func (t *ListContainersTool) newArgs() any { return &docker.ListContainersOptions{} }
+
 func (t *ListContainersTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
 	var args docker.ListContainersOptions
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
-	fmt.Printf("[DEBUG] ListContainers args: %+v\n", args)
+	logToolArgs("ListContainers", args)
 
 	containers, err := docker.ListContainers(ctx, args)
 	if err != nil {
 		return "", err
 	}
 
-	data, err := json.Marshal(containers)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
-	}
-	return string(data), nil
+	return marshalToolResult(containers)
 }
 
 // InspectContainerTool 查看容器详情
@@ -78,30 +91,194 @@ func (t *InspectContainerTool) Info(_ context.Context) (*schema.ToolInfo, error)
 				Type:     schema.String,
 				Required: true,
 			},
+			"with_size": {
+				Desc:     "Also compute size_rw (writable layer bytes) and size_root_fs (total rootfs bytes). Expensive; only set when disk usage is asked about",
+				Type:     schema.Boolean,
+				Required: false,
+			},
 		}),
 	}, nil
 }
 
+type inspectContainerArgs struct {
+	ContainerID string `json:"container_id"`
+	WithSize    bool   `json:"with_size"`
+}
+
+func (t *InspectContainerTool) newArgs() any { return &inspectContainerArgs{} }
+
 func (t *InspectContainerTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		ContainerID string `json:"container_id"`
+	var args inspectContainerArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
+	// 调试：debug 级别记录解析后的参数
+	logToolArgs("InspectContainer", args)
+
+	inspect := docker.InspectContainer
+	if args.WithSize {
+		inspect = docker.InspectContainerWithSize
 	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+	info, err := inspect(ctx, args.ContainerID)
+	if err != nil {
+		return "", err
 	}
-	// 调试：打印解析后的参数
-	fmt.Printf("[DEBUG] InspectContainer args: %+v\n", args)
 
-	info, err := docker.InspectContainer(ctx, args.ContainerID)
+	return marshalToolResult(info)
+}
+
+// TailContainerLogsTool 实时跟踪容器的新日志（不依赖日志采集与存储）
+type TailContainerLogsTool struct{}
+
+func (t *TailContainerLogsTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "tail_container_logs",
+		Desc: fmt.Sprintf("Follow a container's NEW log lines live for a bounded time (default %s, max %s) or until max_lines new lines arrive (default %d, max %d), and return the captured lines. Does not include history; use get_container_logs for past logs. Works even when the log collector is disabled.", docker.DefaultTailDuration, docker.MaxTailDuration, docker.DefaultTailLines, docker.MaxTailLines),
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"container_id": {
+				Desc:     "The ID or name of the container",
+				Type:     schema.String,
+				Required: true,
+			},
+			"duration": {
+				Desc:     "How long to follow, e.g. 5s, 30s (default 10s, max 60s)",
+				Type:     schema.String,
+				Required: false,
+			},
+			"max_lines": {
+				Desc:     "Stop after this many new lines (default 100, max 1000)",
+				Type:     schema.Integer,
+				Required: false,
+			},
+		}),
+	}, nil
+}
+
+type tailContainerLogsArgs struct {
+	ContainerID string `json:"container_id"`
+	Duration    string `json:"duration"`
+	MaxLines    int    `json:"max_lines"`
+}
+
+func (t *TailContainerLogsTool) newArgs() any { return &tailContainerLogsArgs{} }
+
+func (t *TailContainerLogsTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	var args tailContainerLogsArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
+	var duration time.Duration
+	if s := strings.TrimSpace(args.Duration); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return toolFailure(fmt.Sprintf("invalid duration %q: %v", s, err))
+		}
+		duration = d
+	}
+
+	start := time.Now()
+	lines, err := docker.TailContainerLogs(ctx, docker.TailContainerLogsOptions{
+		ContainerID: args.ContainerID,
+		Duration:    duration,
+		MaxLines:    args.MaxLines,
+	})
 	if err != nil {
 		return "", err
 	}
+	out := map[string]any{
+		"container_id": args.ContainerID,
+		"followed_for": time.Since(start).Round(time.Millisecond).String(),
+		"count":        len(lines),
+		"lines":        lines,
+	}
+	return marshalToolResult(out)
+}
+
+// maskedEnvValue 为敏感环境变量值的替代显示
+const maskedEnvValue = "****"
+
+// ContainerEnvTool 查看容器环境变量，敏感值会被打码
+type ContainerEnvTool struct {
+	secretPatterns []string
+}
+
+type containerEnvVar struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Masked bool   `json:"masked,omitempty"`
+}
+
+func (t *ContainerEnvTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "container_env",
+		Desc: "List the environment variables a container is running with. Values of secret-looking keys (e.g. *_KEY, *_TOKEN, *_SECRET, *PASSWORD*) are masked as ****; never try to recover them.",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"container_id": {
+				Desc:     "The ID or name of the container",
+				Type:     schema.String,
+				Required: true,
+			},
+		}),
+	}, nil
+}
+
+type containerEnvArgs struct {
+	ContainerID string `json:"container_id"`
+}
+
+func (t *ContainerEnvTool) newArgs() any { return &containerEnvArgs{} }
+
+func (t *ContainerEnvTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	var args containerEnvArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
 
-	data, err := json.Marshal(info)
+	info, err := docker.InspectContainer(ctx, args.ContainerID)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
+		return "", err
+	}
+	var env []string
+	if info.Config != nil {
+		env = info.Config.Env
+	}
+
+	out := map[string]any{
+		"id":   info.ID,
+		"name": strings.TrimPrefix(info.Name, "/"),
+		"env":  maskEnv(env, t.secretPatterns),
+	}
+	return marshalToolResult(out)
+}
+
+// maskEnv 将 KEY=VALUE 形式的环境变量解析为列表，名称匹配 patterns 的值替换为 maskedEnvValue。
+// patterns 为空时使用 DefaultSecretEnvPatterns。
+func maskEnv(env []string, patterns []string) []containerEnvVar {
+	if len(patterns) == 0 {
+		patterns = DefaultSecretEnvPatterns
+	}
+	out := make([]containerEnvVar, 0, len(env))
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		v := containerEnvVar{Name: name, Value: value}
+		if isSecretEnvName(name, patterns) {
+			v.Value = maskedEnvValue
+			v.Masked = true
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func isSecretEnvName(name string, patterns []string) bool {
+	upper := strings.ToUpper(name)
+	for _, p := range patterns {
+		ok, err := path.Match(strings.ToUpper(strings.TrimSpace(p)), upper)
+		if err == nil && ok {
+			return true
+		}
 	}
-	return string(data), nil
+	return false
 }
 
 // GetContainerLogsTool 获取容器日志
@@ -127,28 +304,58 @@ func (t *GetContainerLogsTool) Info(_ context.Context) (*schema.ToolInfo, error)
 				Type:     schema.String,
 				Required: false,
 			},
+			"until": {
+				Desc:     "Show logs before timestamp (e.g. 2013-01-02T13:28:37Z) or relative (e.g. 10m for 10 minutes ago); combine with since for a bounded window",
+				Type:     schema.String,
+				Required: false,
+			},
 			"details": {
 				Desc:     "Show extra details provided to logs",
 				Type:     schema.Boolean,
 				Required: false,
 			},
+			"regex": {
+				Desc:     "Optional Go regular expression; only lines whose message matches are returned (applied after tail), e.g. ' 5\\d\\d ' for HTTP 5xx",
+				Type:     schema.String,
+				Required: false,
+			},
+			"structured": {
+				Desc:     "Return a JSON array of {timestamp, stream, message} lines instead of plain text",
+				Type:     schema.Boolean,
+				Required: false,
+			},
 		}),
 	}, nil
 }
 
+type getContainerLogsArgs struct {
+	docker.GetContainerLogsOptions
+	Structured bool `json:"structured"`
+}
+
+func (t *GetContainerLogsTool) newArgs() any { return &getContainerLogsArgs{} }
+
 func (t *GetContainerLogsTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args docker.GetContainerLogsOptions
+	var args getContainerLogsArgs
 	// 注意：JSON 中的字段名需要匹配 struct tag，如果 struct 没有 json tag，则默认匹配字段名
 	// 这里假设 LLM 会生成 snake_case 的参数，我们需要确保能正确映射
 	// 为了保险起见，我们可以定义一个临时的结构体来接收 JSON
 
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
+	// 调试：debug 级别记录解析后的参数
+	logToolArgs("GetContainerLogs", args)
+
+	if args.Structured {
+		lines, err := docker.GetContainerLogStructured(ctx, args.GetContainerLogsOptions)
+		if err != nil {
+			return "", err
+		}
+		return marshalToolResult(lines)
 	}
-	// 调试：打印解析后的参数
-	fmt.Printf("[DEBUG] GetContainerLogs args: %+v\n", args)
 
-	logs, err := docker.GetContainerLogs(ctx, args)
+	logs, err := docker.GetContainerLogs(ctx, args.GetContainerLogsOptions)
 	if err != nil {
 		return "", err
 	}
@@ -172,18 +379,22 @@ func (t *StartContainerTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 	}, nil
 }
 
+type startContainerArgs struct {
+	ContainerID string `json:"container_id"`
+}
+
+func (t *StartContainerTool) newArgs() any { return &startContainerArgs{} }
+
 func (t *StartContainerTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		ContainerID string `json:"container_id"`
-	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+	var args startContainerArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
 
 	if err := docker.StartContainer(ctx, args.ContainerID); err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("Container %s started successfully", args.ContainerID), nil
+	return toolMessage(fmt.Sprintf("Container %s started successfully", args.ContainerID), map[string]string{"container_id": args.ContainerID})
 }
 
 // StopContainerTool 停止容器
@@ -203,18 +414,22 @@ func (t *StopContainerTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 	}, nil
 }
 
+type stopContainerArgs struct {
+	ContainerID string `json:"container_id"`
+}
+
+func (t *StopContainerTool) newArgs() any { return &stopContainerArgs{} }
+
 func (t *StopContainerTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		ContainerID string `json:"container_id"`
-	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+	var args stopContainerArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
 
 	if err := docker.StopContainer(ctx, args.ContainerID); err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("Container %s stopped successfully", args.ContainerID), nil
+	return toolMessage(fmt.Sprintf("Container %s stopped successfully", args.ContainerID), map[string]string{"container_id": args.ContainerID})
 }
 
 // RestartContainerTool 重启容器
@@ -234,18 +449,74 @@ func (t *RestartContainerTool) Info(_ context.Context) (*schema.ToolInfo, error)
 	}, nil
 }
 
+type restartContainerArgs struct {
+	ContainerID string `json:"container_id"`
+}
+
+func (t *RestartContainerTool) newArgs() any { return &restartContainerArgs{} }
+
 func (t *RestartContainerTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		ContainerID string `json:"container_id"`
-	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+	var args restartContainerArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
 
 	if err := docker.RestartContainer(ctx, args.ContainerID); err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("Container %s restarted successfully", args.ContainerID), nil
+	return toolMessage(fmt.Sprintf("Container %s restarted successfully", args.ContainerID), map[string]string{"container_id": args.ContainerID})
+}
+
+// UpdateRestartPolicyTool 修改容器的重启策略（无需重建容器）
+type UpdateRestartPolicyTool struct{}
+
+func (t *UpdateRestartPolicyTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "update_restart_policy",
+		Desc: "Change a container's restart policy in place, without recreating it. Returns the policy read back from inspect.",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"container_id": {
+				Desc:     "The ID or name of the container",
+				Type:     schema.String,
+				Required: true,
+			},
+			"policy": {
+				Desc:     "Restart policy: no, always, unless-stopped, on-failure or on-failure:N (N = max retries)",
+				Type:     schema.String,
+				Required: true,
+			},
+		}),
+	}, nil
+}
+
+type updateRestartPolicyArgs struct {
+	ContainerID string `json:"container_id"`
+	Policy      string `json:"policy"`
+}
+
+func (t *UpdateRestartPolicyTool) newArgs() any { return &updateRestartPolicyArgs{} }
+
+func (t *UpdateRestartPolicyTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	var args updateRestartPolicyArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
+	logToolArgs("UpdateRestartPolicy", args)
+
+	policy, err := docker.ParseRestartPolicy(args.Policy)
+	if err != nil {
+		return toolFailure(err.Error())
+	}
+	applied, err := docker.UpdateRestartPolicy(ctx, args.ContainerID, policy)
+	if err != nil {
+		return "", err
+	}
+	out := map[string]any{
+		"container_id":        args.ContainerID,
+		"restart_policy":      string(applied.Name),
+		"maximum_retry_count": applied.MaximumRetryCount,
+	}
+	return marshalToolResult(out)
 }
 
 // RunContainerTool 从镜像创建并启动容器
@@ -310,55 +581,114 @@ func (t *RunContainerTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 				ElemInfo: &schema.ParameterInfo{Type: schema.String},
 				Required: false,
 			},
+			"devices": {
+				Desc:     "Host devices to expose (array), like docker --device: host[:container][:perms], e.g. /dev/snd or /dev/sda:/dev/xvdc:r",
+				Type:     schema.Array,
+				ElemInfo: &schema.ParameterInfo{Type: schema.String},
+				Required: false,
+			},
+			"extra_hosts": {
+				Desc:     "Extra /etc/hosts entries (array of host:ip)",
+				Type:     schema.Array,
+				ElemInfo: &schema.ParameterInfo{Type: schema.String},
+				Required: false,
+			},
+			"dns": {
+				Desc:     "Custom DNS servers (array of IP addresses)",
+				Type:     schema.Array,
+				ElemInfo: &schema.ParameterInfo{Type: schema.String},
+				Required: false,
+			},
+			"tmpfs": {
+				Desc:     "tmpfs mounts (array), like docker --tmpfs: /path[:options], e.g. /tmp:rw,size=64m",
+				Type:     schema.Array,
+				ElemInfo: &schema.ParameterInfo{Type: schema.String},
+				Required: false,
+			},
+			"read_only": {
+				Desc:     "Mount the container's root filesystem as read-only",
+				Type:     schema.Boolean,
+				Required: false,
+			},
+			"shm_size": {
+				Desc:     "Size of /dev/shm in bytes (0 uses the Docker default)",
+				Type:     schema.Integer,
+				Required: false,
+			},
 			"pull_if_missing": {
 				Desc:     "Pull the image if it is not available locally",
 				Type:     schema.Boolean,
 				Required: false,
 			},
+			"if_exists": {
+				Desc:     "What to do when a container with the same name exists: fail (default), reuse (return it, starting it if stopped) or replace (remove and recreate)",
+				Type:     schema.String,
+				Enum:     []string{"fail", "reuse", "replace"},
+				Required: false,
+			},
 		}),
 	}, nil
 }
 
+type runContainerArgs struct {
+	Image         string   `json:"image"`
+	Name          string   `json:"name"`
+	Cmd           []string `json:"cmd"`
+	Env           []string `json:"env"`
+	WorkingDir    string   `json:"working_dir"`
+	AutoRemove    bool     `json:"auto_remove"`
+	RestartPolicy string   `json:"restart_policy"`
+	Binds         []string `json:"binds"`
+	Network       string   `json:"network"`
+	Publish       []string `json:"publish"`
+	Devices       []string `json:"devices"`
+	ExtraHosts    []string `json:"extra_hosts"`
+	DNS           []string `json:"dns"`
+	Tmpfs         []string `json:"tmpfs"`
+	ReadOnly      bool     `json:"read_only"`
+	ShmSize       int64    `json:"shm_size"`
+	PullIfMissing bool     `json:"pull_if_missing"`
+	IfExists      string   `json:"if_exists"`
+}
+
+func (t *RunContainerTool) newArgs() any { return &runContainerArgs{} }
+
 func (t *RunContainerTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		Image         string   `json:"image"`
-		Name          string   `json:"name"`
-		Cmd           []string `json:"cmd"`
-		Env           []string `json:"env"`
-		WorkingDir    string   `json:"working_dir"`
-		AutoRemove    bool     `json:"auto_remove"`
-		RestartPolicy string   `json:"restart_policy"`
-		Binds         []string `json:"binds"`
-		Network       string   `json:"network"`
-		Publish       []string `json:"publish"`
-		PullIfMissing bool     `json:"pull_if_missing"`
-	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
-	}
-	fmt.Printf("[DEBUG] RunContainer args: %+v\n", args)
+	var args runContainerArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
+	logToolArgs("RunContainer", args)
+
+	tmpfs, err := docker.ParseTmpfsSpecs(args.Tmpfs)
+	if err != nil {
+		return toolFailure(err.Error())
+	}
 
 	res, err := docker.RunContainerFromImage(ctx, docker.RunContainerFromImageOptions{
-		Image:         args.Image,
-		Name:          args.Name,
-		Cmd:           args.Cmd,
-		Env:           args.Env,
-		WorkingDir:    args.WorkingDir,
-		AutoRemove:    args.AutoRemove,
-		RestartPolicy: args.RestartPolicy,
-		Binds:         args.Binds,
-		Network:       args.Network,
-		Publish:       args.Publish,
-		PullIfMissing: args.PullIfMissing,
+		Image:          args.Image,
+		Name:           args.Name,
+		Cmd:            args.Cmd,
+		Env:            args.Env,
+		WorkingDir:     args.WorkingDir,
+		AutoRemove:     args.AutoRemove,
+		RestartPolicy:  args.RestartPolicy,
+		Binds:          args.Binds,
+		Network:        args.Network,
+		Publish:        args.Publish,
+		Devices:        args.Devices,
+		ExtraHosts:     args.ExtraHosts,
+		DNS:            args.DNS,
+		Tmpfs:          tmpfs,
+		ReadonlyRootfs: args.ReadOnly,
+		ShmSize:        args.ShmSize,
+		PullIfMissing:  args.PullIfMissing,
+		IfExists:       args.IfExists,
 	})
 	if err != nil {
 		return "", err
 	}
-	data, err := json.Marshal(res)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
-	}
-	return string(data), nil
+	return marshalToolResult(res)
 }
 
 type ListImagesTool struct{}
@@ -373,28 +703,40 @@ func (t *ListImagesTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 				Type:     schema.Boolean,
 				Required: false,
 			},
+			"full_id": {
+				Desc:     "Return full image IDs instead of truncated ones",
+				Type:     schema.Boolean,
+				Required: false,
+			},
+			"dangling": {
+				Desc:     "Only list dangling (untagged) images that are candidates for cleanup",
+				Type:     schema.Boolean,
+				Required: false,
+			},
 		}),
 	}, nil
 }
 
+type listImagesArgs struct {
+	All      bool `json:"all"`
+	FullID   bool `json:"full_id"`
+	Dangling bool `json:"dangling"`
+}
+
+func (t *ListImagesTool) newArgs() any { return &listImagesArgs{} }
+
 func (t *ListImagesTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		All bool `json:"all"`
+	var args listImagesArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
-	}
-	fmt.Printf("[DEBUG] ListImages args: %+v\n", args)
+	logToolArgs("ListImages", args)
 
-	images, err := docker.ListImages(ctx, docker.ListImagesOptions{All: args.All})
+	images, err := docker.ListImages(ctx, docker.ListImagesOptions{All: args.All, FullID: args.FullID, Dangling: args.Dangling})
 	if err != nil {
 		return "", err
 	}
-	data, err := json.Marshal(images)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
-	}
-	return string(data), nil
+	return marshalToolResult(images)
 }
 
 type InspectImageTool struct{}
@@ -409,28 +751,38 @@ func (t *InspectImageTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 				Type:     schema.String,
 				Required: true,
 			},
+			"with_layers": {
+				Desc:     "Also list layers with the build instruction that created each one and its size (useful to find what bloats an image)",
+				Type:     schema.Boolean,
+				Required: false,
+			},
 		}),
 	}, nil
 }
 
+type inspectImageArgs struct {
+	Ref        string `json:"ref"`
+	WithLayers bool   `json:"with_layers"`
+}
+
+func (t *InspectImageTool) newArgs() any { return &inspectImageArgs{} }
+
 func (t *InspectImageTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		Ref string `json:"ref"`
-	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+	var args inspectImageArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
-	fmt.Printf("[DEBUG] InspectImage args: %+v\n", args)
+	logToolArgs("InspectImage", args)
 
-	info, err := docker.InspectImage(ctx, args.Ref)
-	if err != nil {
-		return "", err
+	inspect := docker.InspectImage
+	if args.WithLayers {
+		inspect = docker.InspectImageWithLayers
 	}
-	data, err := json.Marshal(info)
+	info, err := inspect(ctx, args.Ref)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
+		return "", err
 	}
-	return string(data), nil
+	return marshalToolResult(info)
 }
 
 type PullImageTool struct{}
@@ -450,25 +802,55 @@ func (t *PullImageTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 				Type:     schema.String,
 				Required: false,
 			},
+			"username": {
+				Desc:     "Optional registry username for private registries. When omitted, credentials from the Docker config file are used",
+				Type:     schema.String,
+				Required: false,
+			},
+			"password": {
+				Desc:     "Optional registry password or access token (used with username)",
+				Type:     schema.String,
+				Required: false,
+			},
 		}),
 	}, nil
 }
 
+type pullImageArgs struct {
+	Ref      string `json:"ref"`
+	Platform string `json:"platform"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (t *PullImageTool) newArgs() any { return &pullImageArgs{} }
+
 func (t *PullImageTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		Ref      string `json:"ref"`
-		Platform string `json:"platform"`
+	var args pullImageArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+	logged := args
+	if logged.Password != "" {
+		logged.Password = maskedEnvValue
 	}
-	fmt.Printf("[DEBUG] PullImage args: %+v\n", args)
+	logToolArgs("PullImage", logged)
 
-	out, err := docker.PullImage(ctx, docker.PullImageOptions{Ref: args.Ref, Platform: args.Platform})
+	opts := docker.PullImageOptions{Ref: args.Ref, Platform: args.Platform, Username: args.Username, Password: args.Password}
+	res, err := docker.PullImageWithProgress(ctx, opts, func(p docker.PullProgress) {
+		msg := p.Status
+		if p.Layers > 0 {
+			msg = fmt.Sprintf("%d/%d layers", p.LayersDone, p.Layers)
+		}
+		ReportToolProgress(ctx, ToolProgress{Tool: "pull_image", Message: msg, Percent: p.Percent})
+	})
+	if errors.Is(err, docker.ErrRegistryUnauthorized) {
+		return "", fmt.Errorf("%w (provide username/password or run docker login for %s)", err, docker.RegistryHost(args.Ref))
+	}
 	if err != nil {
 		return "", err
 	}
-	return out, nil
+	return toolMessage(fmt.Sprintf("Image %s pulled successfully", res.Ref), res)
 }
 
 type RemoveImageTool struct{}
@@ -497,16 +879,20 @@ func (t *RemoveImageTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 	}, nil
 }
 
+type removeImageArgs struct {
+	Ref           string `json:"ref"`
+	Force         bool   `json:"force"`
+	PruneChildren bool   `json:"prune_children"`
+}
+
+func (t *RemoveImageTool) newArgs() any { return &removeImageArgs{} }
+
 func (t *RemoveImageTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		Ref           string `json:"ref"`
-		Force         bool   `json:"force"`
-		PruneChildren bool   `json:"prune_children"`
-	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+	var args removeImageArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
-	fmt.Printf("[DEBUG] RemoveImage args: %+v\n", args)
+	logToolArgs("RemoveImage", args)
 
 	deleted, err := docker.RemoveImage(ctx, args.Ref, docker.RemoveImageOptions{
 		Force:         args.Force,
@@ -515,59 +901,30 @@ func (t *RemoveImageTool) InvokableRun(ctx context.Context, argumentsInJSON stri
 	if err != nil {
 		return "", err
 	}
-	data, err := json.Marshal(deleted)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
-	}
-	return string(data), nil
-}
-
-type ListNetworksTool struct{}
-
-func (t *ListNetworksTool) Info(_ context.Context) (*schema.ToolInfo, error) {
-	return &schema.ToolInfo{
-		Name:        "list_networks",
-		Desc:        "List Docker networks.",
-		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{}),
-	}, nil
-}
-
-func (t *ListNetworksTool) InvokableRun(ctx context.Context, _ string, _ ...tool.Option) (string, error) {
-	networks, err := docker.ListNetworks(ctx, docker.ListNetworksOptions{})
-	if err != nil {
-		return "", err
-	}
-	data, err := json.Marshal(networks)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
-	}
-	return string(data), nil
+	return marshalToolResult(deleted)
 }
 
-type CreateNetworkTool struct{}
+// RemoveImagesTool 批量删除镜像，逐个报告成功或失败
+type RemoveImagesTool struct{}
 
-func (t *CreateNetworkTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+func (t *RemoveImagesTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 	return &schema.ToolInfo{
-		Name: "create_network",
-		Desc: "Create a Docker network.",
+		Name: "remove_images",
+		Desc: "Remove several images in one call. Continues past individual failures (e.g. image in use) and reports which refs were removed and which failed.",
 		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
-			"name": {
-				Desc:     "Network name",
-				Type:     schema.String,
+			"refs": {
+				Desc:     "Image references to remove (name:tag, digest, or ID)",
+				Type:     schema.Array,
+				ElemInfo: &schema.ParameterInfo{Type: schema.String},
 				Required: true,
 			},
-			"driver": {
-				Desc:     "Network driver (e.g. bridge, overlay)",
-				Type:     schema.String,
-				Required: false,
-			},
-			"internal": {
-				Desc:     "Restrict external access to the network",
+			"force": {
+				Desc:     "Force removal of the images",
 				Type:     schema.Boolean,
 				Required: false,
 			},
-			"attachable": {
-				Desc:     "Enable manual container attachment",
+			"prune_children": {
+				Desc:     "Remove untagged parent images",
 				Type:     schema.Boolean,
 				Required: false,
 			},
@@ -575,17 +932,128 @@ func (t *CreateNetworkTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 	}, nil
 }
 
-func (t *CreateNetworkTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		Name       string `json:"name"`
-		Driver     string `json:"driver"`
-		Internal   bool   `json:"internal"`
-		Attachable bool   `json:"attachable"`
-	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+type removeImagesArgs struct {
+	Refs          []string `json:"refs"`
+	Force         bool     `json:"force"`
+	PruneChildren bool     `json:"prune_children"`
+}
+
+func (t *RemoveImagesTool) newArgs() any { return &removeImagesArgs{} }
+
+func (t *RemoveImagesTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	var args removeImagesArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
+	logToolArgs("RemoveImages", args)
+	if len(args.Refs) == 0 {
+		return toolFailure("refs is required")
+	}
+
+	results, err := docker.RemoveImages(ctx, args.Refs, docker.RemoveImageOptions{
+		Force:         args.Force,
+		PruneChildren: args.PruneChildren,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	out := struct {
+		Removed []string                   `json:"removed"`
+		Failed  []string                   `json:"failed"`
+		Results []docker.RemoveImageResult `json:"results"`
+	}{Removed: []string{}, Failed: []string{}, Results: results}
+	for _, r := range results {
+		if r.Error != "" {
+			out.Failed = append(out.Failed, r.Ref)
+		} else {
+			out.Removed = append(out.Removed, r.Ref)
+		}
+	}
+	return marshalToolResult(out)
+}
+
+type ListNetworksTool struct{}
+
+func (t *ListNetworksTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "list_networks",
+		Desc: "List Docker networks.",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"full_id": {
+				Desc:     "Return full network IDs instead of truncated ones",
+				Type:     schema.Boolean,
+				Required: false,
+			},
+		}),
+	}, nil
+}
+
+type listNetworksArgs struct {
+	FullID bool `json:"full_id"`
+}
+
+func (t *ListNetworksTool) newArgs() any { return &listNetworksArgs{} }
+
+func (t *ListNetworksTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	var args listNetworksArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
+
+	networks, err := docker.ListNetworks(ctx, docker.ListNetworksOptions{FullID: args.FullID})
+	if err != nil {
+		return "", err
+	}
+	return marshalToolResult(networks)
+}
+
+type CreateNetworkTool struct{}
+
+func (t *CreateNetworkTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "create_network",
+		Desc: "Create a Docker network.",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"name": {
+				Desc:     "Network name",
+				Type:     schema.String,
+				Required: true,
+			},
+			"driver": {
+				Desc:     "Network driver (e.g. bridge, overlay)",
+				Type:     schema.String,
+				Required: false,
+			},
+			"internal": {
+				Desc:     "Restrict external access to the network",
+				Type:     schema.Boolean,
+				Required: false,
+			},
+			"attachable": {
+				Desc:     "Enable manual container attachment",
+				Type:     schema.Boolean,
+				Required: false,
+			},
+		}),
+	}, nil
+}
+
+type createNetworkArgs struct {
+	Name       string `json:"name"`
+	Driver     string `json:"driver"`
+	Internal   bool   `json:"internal"`
+	Attachable bool   `json:"attachable"`
+}
+
+func (t *CreateNetworkTool) newArgs() any { return &createNetworkArgs{} }
+
+func (t *CreateNetworkTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	var args createNetworkArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
-	fmt.Printf("[DEBUG] CreateNetwork args: %+v\n", args)
+	logToolArgs("CreateNetwork", args)
 
 	resp, err := docker.CreateNetwork(ctx, docker.CreateNetworkOptions{
 		Name:       args.Name,
@@ -596,11 +1064,7 @@ func (t *CreateNetworkTool) InvokableRun(ctx context.Context, argumentsInJSON st
 	if err != nil {
 		return "", err
 	}
-	data, err := json.Marshal(resp)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
-	}
-	return string(data), nil
+	return marshalToolResult(resp)
 }
 
 type InspectNetworkTool struct{}
@@ -608,7 +1072,7 @@ type InspectNetworkTool struct{}
 func (t *InspectNetworkTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 	return &schema.ToolInfo{
 		Name: "inspect_network",
-		Desc: "Get detailed information about a network.",
+		Desc: "Get a summary of a network: driver, scope, subnets/gateways and the attached containers with their IPv4 addresses.",
 		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
 			"network_id": {
 				Desc:     "The network ID or name",
@@ -619,24 +1083,24 @@ func (t *InspectNetworkTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 	}, nil
 }
 
+type inspectNetworkArgs struct {
+	NetworkID string `json:"network_id"`
+}
+
+func (t *InspectNetworkTool) newArgs() any { return &inspectNetworkArgs{} }
+
 func (t *InspectNetworkTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		NetworkID string `json:"network_id"`
-	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+	var args inspectNetworkArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
-	fmt.Printf("[DEBUG] InspectNetwork args: %+v\n", args)
+	logToolArgs("InspectNetwork", args)
 
-	info, err := docker.InspectNetwork(ctx, args.NetworkID)
+	info, err := docker.GetNetworkDetail(ctx, args.NetworkID)
 	if err != nil {
 		return "", err
 	}
-	data, err := json.Marshal(info)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
-	}
-	return string(data), nil
+	return marshalToolResult(info)
 }
 
 type ConnectNetworkTool struct{}
@@ -644,7 +1108,7 @@ type ConnectNetworkTool struct{}
 func (t *ConnectNetworkTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 	return &schema.ToolInfo{
 		Name: "connect_network",
-		Desc: "Connect a container to a network.",
+		Desc: "Connect a container to a network. Returns the assigned IP/MAC address and aliases of the new endpoint.",
 		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
 			"network_id": {
 				Desc:     "The network ID or name",
@@ -660,20 +1124,25 @@ func (t *ConnectNetworkTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 	}, nil
 }
 
+type connectNetworkArgs struct {
+	NetworkID   string `json:"network_id"`
+	ContainerID string `json:"container_id"`
+}
+
+func (t *ConnectNetworkTool) newArgs() any { return &connectNetworkArgs{} }
+
 func (t *ConnectNetworkTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		NetworkID   string `json:"network_id"`
-		ContainerID string `json:"container_id"`
-	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+	var args connectNetworkArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
-	fmt.Printf("[DEBUG] ConnectNetwork args: %+v\n", args)
+	logToolArgs("ConnectNetwork", args)
 
-	if err := docker.ConnectNetwork(ctx, args.NetworkID, docker.ConnectNetworkOptions{ContainerID: args.ContainerID}); err != nil {
+	ep, err := docker.ConnectNetwork(ctx, args.NetworkID, docker.ConnectNetworkOptions{ContainerID: args.ContainerID})
+	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("Connected container %s to network %s", args.ContainerID, args.NetworkID), nil
+	return marshalToolResult(ep)
 }
 
 type DisconnectNetworkTool struct{}
@@ -702,21 +1171,25 @@ func (t *DisconnectNetworkTool) Info(_ context.Context) (*schema.ToolInfo, error
 	}, nil
 }
 
+type disconnectNetworkArgs struct {
+	NetworkID   string `json:"network_id"`
+	ContainerID string `json:"container_id"`
+	Force       bool   `json:"force"`
+}
+
+func (t *DisconnectNetworkTool) newArgs() any { return &disconnectNetworkArgs{} }
+
 func (t *DisconnectNetworkTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		NetworkID   string `json:"network_id"`
-		ContainerID string `json:"container_id"`
-		Force       bool   `json:"force"`
+	var args disconnectNetworkArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
-	}
-	fmt.Printf("[DEBUG] DisconnectNetwork args: %+v\n", args)
+	logToolArgs("DisconnectNetwork", args)
 
 	if err := docker.DisconnectNetwork(ctx, args.NetworkID, docker.DisconnectNetworkOptions{ContainerID: args.ContainerID, Force: args.Force}); err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("Disconnected container %s from network %s", args.ContainerID, args.NetworkID), nil
+	return toolMessage(fmt.Sprintf("Disconnected container %s from network %s", args.ContainerID, args.NetworkID), map[string]string{"container_id": args.ContainerID, "network_id": args.NetworkID})
 }
 
 type RemoveNetworkTool struct{}
@@ -735,19 +1208,23 @@ func (t *RemoveNetworkTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 	}, nil
 }
 
+type removeNetworkArgs struct {
+	NetworkID string `json:"network_id"`
+}
+
+func (t *RemoveNetworkTool) newArgs() any { return &removeNetworkArgs{} }
+
 func (t *RemoveNetworkTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		NetworkID string `json:"network_id"`
+	var args removeNetworkArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
-	}
-	fmt.Printf("[DEBUG] RemoveNetwork args: %+v\n", args)
+	logToolArgs("RemoveNetwork", args)
 
 	if err := docker.RemoveNetwork(ctx, args.NetworkID); err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("Network %s removed successfully", args.NetworkID), nil
+	return toolMessage(fmt.Sprintf("Network %s removed successfully", args.NetworkID), map[string]string{"network_id": args.NetworkID})
 }
 
 type ListVolumesTool struct{}
@@ -765,11 +1242,7 @@ func (t *ListVolumesTool) InvokableRun(ctx context.Context, _ string, _ ...tool.
 	if err != nil {
 		return "", err
 	}
-	data, err := json.Marshal(volumes)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
-	}
-	return string(data), nil
+	return marshalToolResult(volumes)
 }
 
 type CreateVolumeTool struct{}
@@ -793,25 +1266,25 @@ func (t *CreateVolumeTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 	}, nil
 }
 
+type createVolumeArgs struct {
+	Name   string `json:"name"`
+	Driver string `json:"driver"`
+}
+
+func (t *CreateVolumeTool) newArgs() any { return &createVolumeArgs{} }
+
 func (t *CreateVolumeTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		Name   string `json:"name"`
-		Driver string `json:"driver"`
+	var args createVolumeArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
-	}
-	fmt.Printf("[DEBUG] CreateVolume args: %+v\n", args)
+	logToolArgs("CreateVolume", args)
 
 	created, err := docker.CreateVolume(ctx, docker.CreateVolumeOptions{Name: args.Name, Driver: args.Driver})
 	if err != nil {
 		return "", err
 	}
-	data, err := json.Marshal(created)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
-	}
-	return string(data), nil
+	return marshalToolResult(created)
 }
 
 type InspectVolumeTool struct{}
@@ -830,24 +1303,24 @@ func (t *InspectVolumeTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 	}, nil
 }
 
+type inspectVolumeArgs struct {
+	Name string `json:"name"`
+}
+
+func (t *InspectVolumeTool) newArgs() any { return &inspectVolumeArgs{} }
+
 func (t *InspectVolumeTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		Name string `json:"name"`
+	var args inspectVolumeArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
-	}
-	fmt.Printf("[DEBUG] InspectVolume args: %+v\n", args)
+	logToolArgs("InspectVolume", args)
 
 	info, err := docker.InspectVolume(ctx, args.Name)
 	if err != nil {
 		return "", err
 	}
-	data, err := json.Marshal(info)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
-	}
-	return string(data), nil
+	return marshalToolResult(info)
 }
 
 type RemoveVolumeTool struct{}
@@ -871,20 +1344,24 @@ func (t *RemoveVolumeTool) Info(_ context.Context) (*schema.ToolInfo, error) {
 	}, nil
 }
 
+type removeVolumeArgs struct {
+	Name  string `json:"name"`
+	Force bool   `json:"force"`
+}
+
+func (t *RemoveVolumeTool) newArgs() any { return &removeVolumeArgs{} }
+
 func (t *RemoveVolumeTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
-	var args struct {
-		Name  string `json:"name"`
-		Force bool   `json:"force"`
+	var args removeVolumeArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
-	}
-	fmt.Printf("[DEBUG] RemoveVolume args: %+v\n", args)
+	logToolArgs("RemoveVolume", args)
 
 	if err := docker.RemoveVolume(ctx, args.Name, docker.RemoveVolumeOptions{Force: args.Force}); err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("Volume %s removed successfully", args.Name), nil
+	return toolMessage(fmt.Sprintf("Volume %s removed successfully", args.Name), map[string]string{"name": args.Name})
 }
 
 type QueryContainerStatsTool struct {
@@ -926,24 +1403,34 @@ func (t *QueryContainerStatsTool) Info(_ context.Context) (*schema.ToolInfo, err
 				Type:     schema.Boolean,
 				Required: false,
 			},
+			"label": {
+				Desc:     "Optional container label filter, 'key=value' or just 'key'. Only matches samples collected with monitor.stats.capture_labels enabled.",
+				Type:     schema.String,
+				Required: false,
+			},
 		}),
 	}, nil
 }
 
+type queryContainerStatsArgs struct {
+	ContainerID   string `json:"container_id"`
+	ContainerName string `json:"container_name"`
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Limit         int    `json:"limit"`
+	Desc          bool   `json:"desc"`
+	Label         string `json:"label"`
+}
+
+func (t *QueryContainerStatsTool) newArgs() any { return &queryContainerStatsArgs{} }
+
 func (t *QueryContainerStatsTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
 	if t == nil || t.store == nil {
 		return "", fmt.Errorf("storage not initialized")
 	}
-	var args struct {
-		ContainerID   string `json:"container_id"`
-		ContainerName string `json:"container_name"`
-		From          string `json:"from"`
-		To            string `json:"to"`
-		Limit         int    `json:"limit"`
-		Desc          bool   `json:"desc"`
-	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+	var args queryContainerStatsArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
 
 	normalizedContainerID := strings.TrimSpace(args.ContainerID)
@@ -955,20 +1442,21 @@ func (t *QueryContainerStatsTool) InvokableRun(ctx context.Context, argumentsInJ
 	q := storage.StatsQuery{
 		ContainerID:   normalizedContainerID,
 		ContainerName: normalizedContainerName,
+		Label:         strings.TrimSpace(args.Label),
 		Limit:         limit,
 		Desc:          args.Desc,
 	}
 	if s := strings.TrimSpace(args.From); s != "" {
 		tm, err := parseTimeArg(s, time.Now().UTC())
 		if err != nil {
-			return "", err
+			return toolFailure(err.Error())
 		}
 		q.From = &tm
 	}
 	if s := strings.TrimSpace(args.To); s != "" {
 		tm, err := parseTimeArg(s, time.Now().UTC())
 		if err != nil {
-			return "", err
+			return toolFailure(err.Error())
 		}
 		q.To = &tm
 	}
@@ -977,11 +1465,7 @@ func (t *QueryContainerStatsTool) InvokableRun(ctx context.Context, argumentsInJ
 	if err != nil {
 		return "", err
 	}
-	data, err := json.Marshal(stats)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
-	}
-	return string(data), nil
+	return marshalToolResult(stats)
 }
 
 func (t *QueryContainerStatsTool) queryStatsWithFallback(ctx context.Context, q storage.StatsQuery) ([]storage.ContainerStat, error) {
@@ -1065,6 +1549,11 @@ func (t *QueryContainerLogsTool) Info(_ context.Context) (*schema.ToolInfo, erro
 				Type:     schema.String,
 				Required: false,
 			},
+			"regex": {
+				Desc:     "Optional Go regular expression matched against message, e.g. ' 5\\d\\d ' for HTTP 5xx status codes",
+				Type:     schema.String,
+				Required: false,
+			},
 			"limit": {
 				Desc:     "Limit the number of rows returned (default 200, max 200). Use multiple calls with different time ranges for more data.",
 				Type:     schema.Integer,
@@ -1079,23 +1568,28 @@ func (t *QueryContainerLogsTool) Info(_ context.Context) (*schema.ToolInfo, erro
 	}, nil
 }
 
+type queryContainerLogsArgs struct {
+	ContainerID   string `json:"container_id"`
+	ContainerName string `json:"container_name"`
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Level         string `json:"level"`
+	Source        string `json:"source"`
+	Contains      string `json:"contains"`
+	Regex         string `json:"regex"`
+	Limit         int    `json:"limit"`
+	Desc          bool   `json:"desc"`
+}
+
+func (t *QueryContainerLogsTool) newArgs() any { return &queryContainerLogsArgs{} }
+
 func (t *QueryContainerLogsTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
 	if t == nil || t.store == nil {
 		return "", fmt.Errorf("storage not initialized")
 	}
-	var args struct {
-		ContainerID   string `json:"container_id"`
-		ContainerName string `json:"container_name"`
-		From          string `json:"from"`
-		To            string `json:"to"`
-		Level         string `json:"level"`
-		Source        string `json:"source"`
-		Contains      string `json:"contains"`
-		Limit         int    `json:"limit"`
-		Desc          bool   `json:"desc"`
-	}
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+	var args queryContainerLogsArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
 
 	normalizedContainerID := strings.TrimSpace(args.ContainerID)
@@ -1112,20 +1606,21 @@ func (t *QueryContainerLogsTool) InvokableRun(ctx context.Context, argumentsInJS
 		Level:         strings.TrimSpace(args.Level),
 		Source:        strings.TrimSpace(args.Source),
 		Contains:      strings.TrimSpace(args.Contains),
+		Regexp:        args.Regex,
 		Limit:         limit,
 		Desc:          args.Desc,
 	}
 	if s := strings.TrimSpace(args.From); s != "" {
 		tm, err := parseTimeArg(s, time.Now().UTC())
 		if err != nil {
-			return "", err
+			return toolFailure(err.Error())
 		}
 		q.From = &tm
 	}
 	if s := strings.TrimSpace(args.To); s != "" {
 		tm, err := parseTimeArg(s, time.Now().UTC())
 		if err != nil {
-			return "", err
+			return toolFailure(err.Error())
 		}
 		q.To = &tm
 	}
@@ -1134,11 +1629,7 @@ func (t *QueryContainerLogsTool) InvokableRun(ctx context.Context, argumentsInJS
 	if err != nil {
 		return "", err
 	}
-	data, err := json.Marshal(logs)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
-	}
-	return string(data), nil
+	return marshalToolResult(logs)
 }
 
 func (t *QueryContainerLogsTool) queryLogsWithFallback(ctx context.Context, q storage.LogQuery) ([]storage.ContainerLog, error) {
@@ -1178,94 +1669,1115 @@ func (t *QueryContainerLogsTool) queryLogsWithFallback(ctx context.Context, q st
 	return []storage.ContainerLog{}, nil
 }
 
-func parseTimeArg(s string, now time.Time) (time.Time, error) {
-	if s == "" {
-		return time.Time{}, fmt.Errorf("time string is empty")
-	}
-	if d, err := time.ParseDuration(s); err == nil {
-		if d > 0 {
-			d = -d
-		}
-		return now.Add(d).UTC(), nil
+// LogsSinceRestartTool 查询容器最近一次 start/restart 事件之后的日志，用户无需知道具体重启时间
+type LogsSinceRestartTool struct {
+	store *storage.Storage
+}
+
+func (t *LogsSinceRestartTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "logs_since_last_restart",
+		Desc: "Return stored logs of a container since its most recent start/restart (found in the recorded Docker events). Use this for 'show me logs since the last restart'. When no start/restart event is recorded for the container, falls back to all stored logs and says so in 'note'.",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"container": {
+				Desc:     "Container name or ID (a short ID prefix is accepted)",
+				Type:     schema.String,
+				Required: true,
+			},
+			"level": {
+				Desc:     "Optional log level to filter (exact match, e.g. ERROR/WARN/INFO)",
+				Type:     schema.String,
+				Required: false,
+			},
+			"contains": {
+				Desc:     "Optional substring to search within message (SQL LIKE)",
+				Type:     schema.String,
+				Required: false,
+			},
+			"limit": {
+				Desc:     fmt.Sprintf("Limit the number of rows returned (default %d, max %d)", maxLogsRowsPerTool, maxLogsRowsPerTool),
+				Type:     schema.Integer,
+				Required: false,
+			},
+			"desc": {
+				Desc:     "Sort by timestamp descending (latest first); default is oldest first, starting right after the restart",
+				Type:     schema.Boolean,
+				Required: false,
+			},
+		}),
+	}, nil
+}
+
+type logsSinceRestartArgs struct {
+	Container string `json:"container"`
+	Level     string `json:"level"`
+	Contains  string `json:"contains"`
+	Limit     int    `json:"limit"`
+	Desc      bool   `json:"desc"`
+}
+
+func (t *LogsSinceRestartTool) newArgs() any { return &logsSinceRestartArgs{} }
+
+func (t *LogsSinceRestartTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	if t == nil || t.store == nil {
+		return "", fmt.Errorf("storage not initialized")
 	}
-	if tm, err := time.Parse(time.RFC3339Nano, s); err == nil {
-		return tm.UTC(), nil
+	var args logsSinceRestartArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
-	if tm, err := time.Parse(time.RFC3339, s); err == nil {
-		return tm.UTC(), nil
+	container := strings.TrimSpace(args.Container)
+	if container == "" {
+		return toolFailure("container is required")
 	}
-	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
-		return time.Unix(sec, 0).UTC(), nil
+	limit := args.Limit
+	if limit <= 0 || limit > maxLogsRowsPerTool {
+		limit = maxLogsRowsPerTool
 	}
-	return time.Time{}, fmt.Errorf("invalid time format: %s (use RFC3339 or duration like 10m)", s)
-}
 
-func containerNameCandidates(name string) []string {
-	name = strings.TrimSpace(name)
-	if name == "" {
-		return nil
+	// 事件中的容器名不带前导 /
+	ev, err := t.store.LastContainerStart(ctx, container, []string{strings.TrimPrefix(container, "/")})
+	if err != nil {
+		return "", err
 	}
-	base := strings.TrimPrefix(name, "/")
-	out := make([]string, 0, 2)
-	seen := map[string]struct{}{}
 
-	add := func(s string) {
-		s = strings.TrimSpace(s)
-		if s == "" {
-			return
-		}
-		if _, ok := seen[s]; ok {
-			return
+	q := storage.LogQuery{
+		Level:    strings.TrimSpace(args.Level),
+		Contains: strings.TrimSpace(args.Contains),
+		Limit:    limit,
+		Desc:     args.Desc,
+	}
+	out := map[string]any{"container": container}
+	if ev != nil {
+		since := ev.Timestamp.UTC()
+		q.From = &since
+		out["since"] = since.Format(time.RFC3339Nano)
+		out["restart_event"] = map[string]any{
+			"action":     ev.Action,
+			"timestamp":  since.Format(time.RFC3339Nano),
+			"actor_id":   ev.ActorID,
+			"actor_name": ev.ActorName,
 		}
-		seen[s] = struct{}{}
-		out = append(out, s)
+	} else {
+		out["note"] = "no start/restart event recorded for this container; returning all stored logs"
 	}
 
-	add(name)
-	add(base)
-	add("/" + base)
-	return out
+	logs, err := t.queryLogs(ctx, q, container, ev)
+	if err != nil {
+		return "", err
+	}
+	out["count"] = len(logs)
+	out["logs"] = logs
+	return marshalToolResult(out)
 }
 
-func containerIDCandidates(id string) []string {
-	id = strings.TrimSpace(id)
-	if id == "" {
-		return nil
-	}
-	out := make([]string, 0, 2)
-	seen := map[string]struct{}{}
-	add := func(s string) {
-		s = strings.TrimSpace(s)
-		if s == "" {
-			return
+// queryLogs 优先按事件中的完整容器 ID 查询，再依次尝试输入的 ID 候选与名称候选
+func (t *LogsSinceRestartTool) queryLogs(ctx context.Context, q storage.LogQuery, container string, ev *storage.DockerEvent) ([]storage.ContainerLog, error) {
+	var tries []storage.LogQuery
+	if ev != nil && ev.ActorID != "" {
+		try := q
+		try.ContainerID = ev.ActorID
+		tries = append(tries, try)
+	}
+	for _, id := range containerIDCandidates(container) {
+		try := q
+		try.ContainerID = id
+		tries = append(tries, try)
+	}
+	for _, name := range containerNameCandidates(container) {
+		try := q
+		try.ContainerName = name
+		tries = append(tries, try)
+	}
+	for _, try := range tries {
+		logs, err := t.store.QueryContainerLogs(ctx, try)
+		if err != nil {
+			return nil, err
 		}
-		if _, ok := seen[s]; ok {
-			return
+		if len(logs) > 0 {
+			return logs, nil
 		}
-		seen[s] = struct{}{}
-		out = append(out, s)
 	}
-	add(id)
-	if len(id) > 12 {
-		add(id[:12])
+	return []storage.ContainerLog{}, nil
+}
+
+// ResolveContainerTool 将容器名称或 ID 前缀解析为完整 ID 与名称
+type ResolveContainerTool struct{}
+
+func (t *ResolveContainerTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "resolve_container",
+		Desc: "Resolve a container name, short ID or full ID to its canonical full ID and name. Use this before passing a container reference to other tools when unsure which form they need.",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"container": {
+				Desc:     "Container name, ID prefix or full ID",
+				Type:     schema.String,
+				Required: true,
+			},
+		}),
+	}, nil
+}
+
+type resolveContainerArgs struct {
+	Container string `json:"container"`
+}
+
+func (t *ResolveContainerTool) newArgs() any { return &resolveContainerArgs{} }
+
+func (t *ResolveContainerTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	var args resolveContainerArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
 	}
-	return out
+
+	id, name, err := docker.ResolveContainer(ctx, args.Container)
+	if err != nil {
+		return "", err
+	}
+	return marshalToolResult(map[string]string{
+		"id":       id,
+		"short_id": docker.ShortID(id),
+		"name":     name,
+	})
 }
 
-// GetTools 返回所有可用的工具列表
-func GetTools(store *storage.Storage) []tool.BaseTool {
-	tools := []tool.BaseTool{
+// SampleContainerStatsTool 通过 stats 流多次采样得到稳定的实时 CPU 读数
+type SampleContainerStatsTool struct{}
+
+func (t *SampleContainerStatsTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "sample_container_stats",
+		Desc: fmt.Sprintf("Read live stats of a running container by sampling the Docker stats stream several times (about one sample per second) and averaging CPU. Use this instead of a one-shot reading when CPU shows 0%% or you need the current load. Takes roughly `samples` seconds, at most %d.", docker.MaxStatsSamples),
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"container_id": {
+				Desc:     "The ID or name of the container",
+				Type:     schema.String,
+				Required: true,
+			},
+			"samples": {
+				Desc:     fmt.Sprintf("Number of samples to average (default %d, max %d)", docker.DefaultStatsSamples, docker.MaxStatsSamples),
+				Type:     schema.Integer,
+				Required: false,
+			},
+		}),
+	}, nil
+}
+
+type sampleContainerStatsArgs struct {
+	ContainerID string `json:"container_id"`
+	Samples     int    `json:"samples"`
+}
+
+func (t *SampleContainerStatsTool) newArgs() any { return &sampleContainerStatsArgs{} }
+
+func (t *SampleContainerStatsTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	var args sampleContainerStatsArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
+	id := strings.TrimSpace(args.ContainerID)
+	if id == "" {
+		return toolFailure("container_id is required")
+	}
+	samples := args.Samples
+	if samples <= 0 {
+		samples = docker.DefaultStatsSamples
+	}
+	samples = min(samples, docker.MaxStatsSamples)
+
+	// 首帧不计入平均，再留出少量余量；超时后返回已采集的部分结果
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(samples+3)*time.Second)
+	defer cancel()
+
+	out, err := docker.SampleContainerStats(ctx, id, samples)
+	if err != nil {
+		return "", err
+	}
+	return marshalToolResult(out)
+}
+
+// ContainerRestartInfoTool 查看容器重启次数与最近一次退出信息
+type ContainerRestartInfoTool struct{}
+
+func (t *ContainerRestartInfoTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "container_restart_info",
+		Desc: "Get a container's restart count, restart policy and last exit details (exit_code, oom_killed, error, finished_at). Use this to answer 'why does this container keep restarting?'; exit code 137 with oom_killed=true means it ran out of memory.",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"container_id": {
+				Desc:     "The ID or name of the container",
+				Type:     schema.String,
+				Required: true,
+			},
+		}),
+	}, nil
+}
+
+type containerRestartInfoArgs struct {
+	ContainerID string `json:"container_id"`
+}
+
+func (t *ContainerRestartInfoTool) newArgs() any { return &containerRestartInfoArgs{} }
+
+func (t *ContainerRestartInfoTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	var args containerRestartInfoArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
+	logToolArgs("ContainerRestartInfo", args)
+
+	info, err := docker.GetContainerRestartInfo(ctx, args.ContainerID)
+	if err != nil {
+		return "", err
+	}
+	return marshalToolResult(info)
+}
+
+// ContainerDiffTool 查看容器相对镜像写入磁盘的文件变更
+type ContainerDiffTool struct{}
+
+// defaultDiffMaxPaths 为 container_diff 每类变更默认返回的最大路径数
+const defaultDiffMaxPaths = 200
+
+func (t *ContainerDiffTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "container_diff",
+		Desc: "List files and directories a container has added, changed or deleted relative to its image (like docker diff), grouped by kind. Use this to answer 'what has this container written to disk?' when debugging stateful containers or unexpected disk usage.",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"container_id": {
+				Desc:     "The ID or name of the container",
+				Type:     schema.String,
+				Required: true,
+			},
+			"max_paths": {
+				Desc:     "Maximum number of paths returned per kind (default 200)",
+				Type:     schema.Integer,
+				Required: false,
+			},
+		}),
+	}, nil
+}
+
+// containerDiff 为 container_diff 工具的输出；Total 为截断前的变更总数
+type containerDiff struct {
+	ContainerID string   `json:"container_id"`
+	Added       []string `json:"added"`
+	Changed     []string `json:"changed"`
+	Deleted     []string `json:"deleted"`
+	Total       int      `json:"total"`
+	Truncated   bool     `json:"truncated,omitempty"`
+}
+
+type containerDiffArgs struct {
+	ContainerID string `json:"container_id"`
+	MaxPaths    int    `json:"max_paths"`
+}
+
+func (t *ContainerDiffTool) newArgs() any { return &containerDiffArgs{} }
+
+func (t *ContainerDiffTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	var args containerDiffArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
+	logToolArgs("ContainerDiff", args)
+
+	changes, err := docker.ContainerDiff(ctx, args.ContainerID)
+	if err != nil {
+		return "", err
+	}
+	maxPaths := args.MaxPaths
+	if maxPaths <= 0 {
+		maxPaths = defaultDiffMaxPaths
+	}
+	out := groupContainerChanges(changes, maxPaths)
+	out.ContainerID = args.ContainerID
+	return marshalToolResult(out)
+}
+
+// groupContainerChanges 按变更类型分组，每组按路径排序并最多保留 maxPaths 条
+func groupContainerChanges(changes []container.FilesystemChange, maxPaths int) containerDiff {
+	out := containerDiff{Added: []string{}, Changed: []string{}, Deleted: []string{}, Total: len(changes)}
+	for _, c := range changes {
+		switch c.Kind {
+		case container.ChangeAdd:
+			out.Added = append(out.Added, c.Path)
+		case container.ChangeModify:
+			out.Changed = append(out.Changed, c.Path)
+		case container.ChangeDelete:
+			out.Deleted = append(out.Deleted, c.Path)
+		}
+	}
+	for _, paths := range []*[]string{&out.Added, &out.Changed, &out.Deleted} {
+		sort.Strings(*paths)
+		if len(*paths) > maxPaths {
+			*paths = (*paths)[:maxPaths]
+			out.Truncated = true
+		}
+	}
+	return out
+}
+
+// ContainerHealthTool 汇总单个容器的状态、健康检查、最新资源占用与近期错误日志
+type ContainerHealthTool struct {
+	store *storage.Storage
+}
+
+func (t *ContainerHealthTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "container_health",
+		Desc: "Summarize the health of one container in a single call: state and healthcheck status, restart count, latest collected stats, and counts of ERROR/WARN logs in a recent window. Use this to answer questions like 'is nginx okay?'.",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"container_id": {
+				Desc:     "The ID or name of the container",
+				Type:     schema.String,
+				Required: true,
+			},
+			"window": {
+				Desc:     "Optional lookback window for log counts, as a duration like 30m/1h (default 1h)",
+				Type:     schema.String,
+				Required: false,
+			},
+		}),
+	}, nil
+}
+
+// containerHealth 为 container_health 工具的输出
+type containerHealth struct {
+	Container    string `json:"container"`
+	Exists       bool   `json:"exists"`
+	ID           string `json:"id,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Image        string `json:"image,omitempty"`
+	Status       string `json:"status,omitempty"`
+	Health       string `json:"health,omitempty"`
+	FailingCount int    `json:"health_failing_streak,omitempty"`
+	OOMKilled    bool   `json:"oom_killed,omitempty"`
+	ExitCode     int    `json:"exit_code,omitempty"`
+	StartedAt    string `json:"started_at,omitempty"`
+	RestartCount int    `json:"restart_count"`
+	// LatestStats 为监控采集的最新一条 stats；未开启采集或没有数据时为空
+	LatestStats *storage.ContainerStat `json:"latest_stats,omitempty"`
+	LogWindow   string                 `json:"log_window,omitempty"`
+	ErrorLogs   int64                  `json:"error_logs"`
+	WarnLogs    int64                  `json:"warn_logs"`
+	// Notes 记录部分信息获取失败的原因，其余字段仍然有效
+	Notes []string `json:"notes,omitempty"`
+}
+
+type containerHealthArgs struct {
+	ContainerID string `json:"container_id"`
+	Window      string `json:"window"`
+}
+
+func (t *ContainerHealthTool) newArgs() any { return &containerHealthArgs{} }
+
+func (t *ContainerHealthTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	var args containerHealthArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
+	ref := strings.TrimSpace(args.ContainerID)
+	if ref == "" {
+		return toolFailure("container_id is required")
+	}
+	window := time.Hour
+	if s := strings.TrimSpace(args.Window); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return toolFailure(fmt.Sprintf("invalid window %q: use a positive duration like 30m", s))
+		}
+		window = d
+	}
+
+	out := containerHealth{Container: ref}
+	info, err := docker.InspectContainerDeatil(ctx, ref)
+	if err != nil {
+		if !docker.IsNotFound(err) {
+			return "", err
+		}
+		// 容器不存在时返回 exists=false，便于模型直接告知用户
+		out.Notes = append(out.Notes, "container not found")
+		return marshalToolResult(out)
+	}
+
+	out.Exists = true
+	out.ID = info.ID
+	out.Name = strings.TrimPrefix(info.Name, "/")
+	out.RestartCount = info.RestartCount
+	if info.Config != nil {
+		out.Image = info.Config.Image
+	}
+	if st := info.State; st != nil {
+		out.Status = string(st.Status)
+		out.OOMKilled = st.OOMKilled
+		out.ExitCode = st.ExitCode
+		out.StartedAt = st.StartedAt
+		if st.Health != nil {
+			out.Health = string(st.Health.Status)
+			out.FailingCount = st.Health.FailingStreak
+		}
+	}
+
+	if t.store == nil {
+		out.Notes = append(out.Notes, "storage not available: stats and log counts skipped")
+		return marshalToolResult(out)
+	}
+
+	for _, id := range containerIDCandidates(info.ID) {
+		stats, err := t.store.QueryContainerStats(ctx, storage.StatsQuery{ContainerID: id, Limit: 1, Desc: true})
+		if err != nil {
+			out.Notes = append(out.Notes, fmt.Sprintf("query stats: %v", err))
+			break
+		}
+		if len(stats) > 0 {
+			// RawJSON 体积较大且对概览无帮助
+			stats[0].RawJSON = ""
+			out.LatestStats = &stats[0]
+			break
+		}
+	}
+
+	from := time.Now().UTC().Add(-window)
+	out.LogWindow = window.String()
+	for _, id := range containerIDCandidates(info.ID) {
+		counts, err := t.store.LogLevelCounts(ctx, storage.LogQuery{ContainerID: id, From: &from})
+		if err != nil {
+			out.Notes = append(out.Notes, fmt.Sprintf("count logs: %v", err))
+			break
+		}
+		if len(counts) > 0 {
+			out.ErrorLogs = counts["ERROR"] + counts["FATAL"]
+			out.WarnLogs = counts["WARN"]
+			break
+		}
+	}
+
+	return marshalToolResult(out)
+}
+
+const (
+	// defaultAnomalyCPUHigh/defaultAnomalyMemHigh 与 monitor.retention.stats 的默认阈值一致
+	defaultAnomalyCPUHigh = 80
+	defaultAnomalyMemHigh = 80
+	defaultAnomaliesLimit = 20
+	maxAnomaliesLimit     = 200
+)
+
+// RecentAnomaliesTool 查询最近的资源异常采样（CPU 或内存超过阈值）
+type RecentAnomaliesTool struct {
+	store *storage.Storage
+}
+
+func (t *RecentAnomaliesTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "recent_anomalies",
+		Desc: "List the most recent stored stats samples, across all containers, where CPU% or memory% reached the thresholds (newest first). Uses the same anomaly definition as the retention policy, so these are the spikes kept long-term. Use this for 'what were the recent spikes'.",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"cpu_high": {
+				Desc:     fmt.Sprintf("CPU percent threshold (default %d); <=0 disables the CPU check only when mem_high is set", defaultAnomalyCPUHigh),
+				Type:     schema.Number,
+				Required: false,
+			},
+			"mem_high": {
+				Desc:     fmt.Sprintf("Memory percent threshold (default %d)", defaultAnomalyMemHigh),
+				Type:     schema.Number,
+				Required: false,
+			},
+			"limit": {
+				Desc:     fmt.Sprintf("Max samples to return (default %d, max %d)", defaultAnomaliesLimit, maxAnomaliesLimit),
+				Type:     schema.Integer,
+				Required: false,
+			},
+		}),
+	}, nil
+}
+
+type recentAnomaliesArgs struct {
+	CPUHigh *float64 `json:"cpu_high"`
+	MemHigh *float64 `json:"mem_high"`
+	Limit   int      `json:"limit"`
+}
+
+func (t *RecentAnomaliesTool) newArgs() any { return &recentAnomaliesArgs{} }
+
+func (t *RecentAnomaliesTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	if t == nil || t.store == nil {
+		return "", fmt.Errorf("storage not initialized")
+	}
+	var args recentAnomaliesArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
+	cpuHigh, memHigh := float64(defaultAnomalyCPUHigh), float64(defaultAnomalyMemHigh)
+	if args.CPUHigh != nil {
+		cpuHigh = *args.CPUHigh
+	}
+	if args.MemHigh != nil {
+		memHigh = *args.MemHigh
+	}
+	if cpuHigh <= 0 && memHigh <= 0 {
+		return toolFailure("at least one of cpu_high or mem_high must be > 0")
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultAnomaliesLimit
+	}
+	limit = min(limit, maxAnomaliesLimit)
+
+	rows, err := t.store.RecentAnomalies(ctx, cpuHigh, memHigh, limit)
+	if err != nil {
+		return "", err
+	}
+	out := map[string]any{
+		"cpu_high": cpuHigh,
+		"mem_high": memHigh,
+		"count":    len(rows),
+		"samples":  rows,
+	}
+	return marshalToolResult(out)
+}
+
+const (
+	defaultErrorHotspotsLimit = 10
+	maxErrorHotspotsLimit     = 50
+)
+
+// ErrorHotspotsTool 找出时间窗口内错误日志最多的容器
+type ErrorHotspotsTool struct {
+	store *storage.Storage
+}
+
+func (t *ErrorHotspotsTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "error_hotspots",
+		Desc: "Rank containers by the number of ERROR/FATAL logs collected in a time window, with the total log count and error_rate (errors/total) for each. Use this to answer 'which container is logging the most errors right now'.",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"from": {
+				Desc:     "Optional start time (RFC3339) or duration like 10m/1h (means now-10m/now-1h), default 1h",
+				Type:     schema.String,
+				Required: false,
+			},
+			"to": {
+				Desc:     "Optional end time (RFC3339) or duration like 10m/1h (means now-10m/now-1h), default now",
+				Type:     schema.String,
+				Required: false,
+			},
+			"limit": {
+				Desc:     fmt.Sprintf("Max containers to return (default %d, max %d)", defaultErrorHotspotsLimit, maxErrorHotspotsLimit),
+				Type:     schema.Integer,
+				Required: false,
+			},
+		}),
+	}, nil
+}
+
+type errorHotspotsArgs struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Limit int    `json:"limit"`
+}
+
+func (t *ErrorHotspotsTool) newArgs() any { return &errorHotspotsArgs{} }
+
+func (t *ErrorHotspotsTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	if t == nil || t.store == nil {
+		return "", fmt.Errorf("storage not initialized")
+	}
+	var args errorHotspotsArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultErrorHotspotsLimit
+	}
+	limit = min(limit, maxErrorHotspotsLimit)
+
+	now := time.Now().UTC()
+	from := now.Add(-time.Hour)
+	to := now
+	if s := strings.TrimSpace(args.From); s != "" {
+		tm, err := parseTimeArg(s, now)
+		if err != nil {
+			return toolFailure(err.Error())
+		}
+		from = tm
+	}
+	if s := strings.TrimSpace(args.To); s != "" {
+		tm, err := parseTimeArg(s, now)
+		if err != nil {
+			return toolFailure(err.Error())
+		}
+		to = tm
+	}
+	if from.After(to) {
+		return toolFailure(fmt.Sprintf("from (%s) must not be after to (%s)", from.Format(time.RFC3339), to.Format(time.RFC3339)))
+	}
+
+	rates, err := t.store.ErrorRatePerContainer(ctx, from, to)
+	if err != nil {
+		return "", err
+	}
+	out := map[string]any{
+		"from":             from.Format(time.RFC3339),
+		"to":               to.Format(time.RFC3339),
+		"containers_total": len(rates),
+		"containers":       rates[:min(limit, len(rates))],
+	}
+	return marshalToolResult(out)
+}
+
+const (
+	defaultContainersWithStatsLimit = 50
+	maxContainersWithStatsLimit     = 200
+)
+
+// ListContainersWithStatsTool 列出容器并附带每个容器最新一条采集的资源数据
+type ListContainersWithStatsTool struct {
+	store *storage.Storage
+}
+
+func (t *ListContainersWithStatsTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "list_containers_with_stats",
+		Desc: "List containers together with their latest collected stats (CPU%, memory%, last seen) in one call. Use this for overview questions like 'show me everything'. Containers without collected stats are included with null metrics. Results are sorted by name and paginated with limit/offset.",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"all": {
+				Desc:     "Include stopped containers (default shows just running)",
+				Type:     schema.Boolean,
+				Required: false,
+			},
+			"limit": {
+				Desc:     fmt.Sprintf("Max containers to return (default %d, max %d)", defaultContainersWithStatsLimit, maxContainersWithStatsLimit),
+				Type:     schema.Integer,
+				Required: false,
+			},
+			"offset": {
+				Desc:     "Number of containers to skip, for pagination (default 0)",
+				Type:     schema.Integer,
+				Required: false,
+			},
+		}),
+	}, nil
+}
+
+// containerWithStats 为 list_containers_with_stats 输出的一行；没有采集数据时指标为 null
+type containerWithStats struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Image      string     `json:"image"`
+	State      string     `json:"state"`
+	Status     string     `json:"status"`
+	CPUPercent *float64   `json:"cpu_percent"`
+	MemPercent *float64   `json:"mem_percent"`
+	LastSeen   *time.Time `json:"last_seen"`
+}
+
+type listContainersWithStatsArgs struct {
+	All    bool `json:"all"`
+	Limit  int  `json:"limit"`
+	Offset int  `json:"offset"`
+}
+
+func (t *ListContainersWithStatsTool) newArgs() any { return &listContainersWithStatsArgs{} }
+
+func (t *ListContainersWithStatsTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	if t == nil || t.store == nil {
+		return "", fmt.Errorf("storage not initialized")
+	}
+	var args listContainersWithStatsArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
+	if args.Limit <= 0 {
+		args.Limit = defaultContainersWithStatsLimit
+	}
+	if args.Limit > maxContainersWithStatsLimit {
+		args.Limit = maxContainersWithStatsLimit
+	}
+	if args.Offset < 0 {
+		args.Offset = 0
+	}
+
+	containers, err := docker.ListContainers(ctx, docker.ListContainersOptions{All: args.All, FullID: true})
+	if err != nil {
+		return "", err
+	}
+	stats, err := t.store.LatestStatPerContainer(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	rows := joinContainerStats(containers, stats)
+	total := len(rows)
+	start := min(args.Offset, total)
+	end := min(start+args.Limit, total)
+
+	out := map[string]any{
+		"total":      total,
+		"offset":     start,
+		"count":      end - start,
+		"containers": rows[start:end],
+	}
+	if end < total {
+		out["next_offset"] = end
+	}
+	return marshalToolResult(out)
+}
+
+// joinContainerStats 按容器 ID（完整或截断形式）关联最新 stats，结果按名称排序
+func joinContainerStats(containers []docker.ContainerSummary, stats []storage.ContainerStat) []containerWithStats {
+	byID := make(map[string]*storage.ContainerStat, len(stats))
+	for i := range stats {
+		byID[stats[i].ContainerID] = &stats[i]
+	}
+
+	rows := make([]containerWithStats, 0, len(containers))
+	for _, c := range containers {
+		row := containerWithStats{
+			ID:     c.ID,
+			Name:   strings.TrimPrefix(c.Names, "/"),
+			Image:  c.Image,
+			State:  c.State,
+			Status: c.Status,
+		}
+		for _, id := range containerIDCandidates(c.ID) {
+			if st, ok := byID[id]; ok {
+				cpu, mem, seen := st.CPUPercent, st.MemPercent, st.CollectedAt
+				row.CPUPercent, row.MemPercent, row.LastSeen = &cpu, &mem, &seen
+				break
+			}
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+	return rows
+}
+
+// logToolArgs 在 debug 级别记录工具解析后的参数（输出到共享日志器，不写 stdout）
+func logToolArgs(tool string, args any) {
+	logging.L().Debug("tool arguments", "tool", tool, "args", args)
+}
+
+// CompareContainersTool 对比两个容器在同一时间窗口内的资源占用与重启情况
+type CompareContainersTool struct {
+	store *storage.Storage
+	// restartInfo 查询 daemon 记录的重启次数，为空时使用 docker.GetContainerRestartInfo
+	restartInfo func(ctx context.Context, containerID string) (*docker.ContainerRestartInfo, error)
+}
+
+func (t *CompareContainersTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "compare_containers",
+		Desc: "Compare two containers side by side over the same time window using the CentAgent database: avg/max CPU and memory, sample counts, and restart/die counts from recorded Docker events within the window, plus restart_count, the daemon's total number of restarts by the restart policy (omitted when the container cannot be inspected). The delta is container_a minus container_b.",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"container_a": {
+				Desc:     "First container ID or name",
+				Type:     schema.String,
+				Required: true,
+			},
+			"container_b": {
+				Desc:     "Second container ID or name",
+				Type:     schema.String,
+				Required: true,
+			},
+			"from": {
+				Desc:     "Optional start time (RFC3339) or duration like 10m/1h (means now-10m/now-1h), default 1h",
+				Type:     schema.String,
+				Required: false,
+			},
+			"to": {
+				Desc:     "Optional end time (RFC3339) or duration like 10m/1h (means now-10m/now-1h), default now",
+				Type:     schema.String,
+				Required: false,
+			},
+		}),
+	}, nil
+}
+
+// containerComparison 为单个容器在对比窗口内的聚合结果
+type containerComparison struct {
+	Query string `json:"query"`
+	storage.StatsAggregate
+	Restarts int64 `json:"restarts"`
+	Dies     int64 `json:"dies"`
+	// RestartCount 为 daemon 记录的累计重启次数（不限于时间窗口），容器无法 inspect 时为空
+	RestartCount *int `json:"restart_count,omitempty"`
+}
+
+type compareContainersArgs struct {
+	ContainerA string `json:"container_a"`
+	ContainerB string `json:"container_b"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+}
+
+func (t *CompareContainersTool) newArgs() any { return &compareContainersArgs{} }
+
+func (t *CompareContainersTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	if t == nil || t.store == nil {
+		return "", fmt.Errorf("storage not initialized")
+	}
+	var args compareContainersArgs
+	if err := decodeToolArgs(argumentsInJSON, &args); err != nil {
+		return toolFailure(err.Error())
+	}
+	a, b := strings.TrimSpace(args.ContainerA), strings.TrimSpace(args.ContainerB)
+	if a == "" || b == "" {
+		return toolFailure("container_a and container_b are required")
+	}
+
+	now := time.Now().UTC()
+	from := now.Add(-time.Hour)
+	to := now
+	if s := strings.TrimSpace(args.From); s != "" {
+		tm, err := parseTimeArg(s, now)
+		if err != nil {
+			return toolFailure(err.Error())
+		}
+		from = tm
+	}
+	if s := strings.TrimSpace(args.To); s != "" {
+		tm, err := parseTimeArg(s, now)
+		if err != nil {
+			return toolFailure(err.Error())
+		}
+		to = tm
+	}
+	if from.After(to) {
+		return toolFailure(fmt.Sprintf("from (%s) must not be after to (%s)", from.Format(time.RFC3339), to.Format(time.RFC3339)))
+	}
+
+	ca, err := t.compareOne(ctx, a, from, to)
+	if err != nil {
+		return "", err
+	}
+	cb, err := t.compareOne(ctx, b, from, to)
+	if err != nil {
+		return "", err
+	}
+
+	round := func(v float64) float64 { return math.Round(v*100) / 100 }
+	out := map[string]any{
+		"from":        from.Format(time.RFC3339),
+		"to":          to.Format(time.RFC3339),
+		"container_a": ca,
+		"container_b": cb,
+		"delta": map[string]any{
+			"avg_cpu_percent":     round(ca.AvgCPUPercent - cb.AvgCPUPercent),
+			"max_cpu_percent":     round(ca.MaxCPUPercent - cb.MaxCPUPercent),
+			"avg_mem_percent":     round(ca.AvgMemPercent - cb.AvgMemPercent),
+			"max_mem_percent":     round(ca.MaxMemPercent - cb.MaxMemPercent),
+			"max_mem_usage_bytes": int64(ca.MaxMemUsageBytes) - int64(cb.MaxMemUsageBytes),
+			"restarts":            ca.Restarts - cb.Restarts,
+			"dies":                ca.Dies - cb.Dies,
+		},
+	}
+	if ca.RestartCount != nil && cb.RestartCount != nil {
+		out["delta"].(map[string]any)["restart_count"] = *ca.RestartCount - *cb.RestartCount
+	}
+	return marshalToolResult(out)
+}
+
+// compareOne 先按 ID 候选、再按名称候选聚合 stats，并按容器名统计重启/退出事件
+func (t *CompareContainersTool) compareOne(ctx context.Context, container string, from, to time.Time) (containerComparison, error) {
+	out := containerComparison{Query: container}
+
+	var queries []storage.StatsQuery
+	for _, id := range containerIDCandidates(container) {
+		queries = append(queries, storage.StatsQuery{ContainerID: id, From: &from, To: &to})
+	}
+	for _, name := range containerNameCandidates(container) {
+		queries = append(queries, storage.StatsQuery{ContainerName: name, From: &from, To: &to})
+	}
+	for _, q := range queries {
+		agg, err := t.store.AggregateStats(ctx, q)
+		if err != nil {
+			return out, err
+		}
+		if agg.Samples > 0 {
+			out.StatsAggregate = *agg
+			break
+		}
+	}
+
+	// 事件中的容器名不带前导 /；未找到 stats 时按用户输入的名称统计
+	name := strings.TrimPrefix(out.ContainerName, "/")
+	if name == "" {
+		name = strings.TrimPrefix(container, "/")
+	}
+	for action, dst := range map[string]*int64{"restart": &out.Restarts, "die": &out.Dies} {
+		n, err := t.store.CountDockerEvents(ctx, storage.EventQuery{
+			Type:      "container",
+			Action:    action,
+			ActorName: name,
+			From:      &from,
+			To:        &to,
+		})
+		if err != nil {
+			return out, err
+		}
+		*dst = n
+	}
+
+	// 按重启策略自动重启时 daemon 只产生 start 事件，因此另外给出 inspect 中的累计重启次数
+	restartInfo := t.restartInfo
+	if restartInfo == nil {
+		restartInfo = docker.GetContainerRestartInfo
+	}
+	target := out.ContainerID
+	if target == "" {
+		target = container
+	}
+	if info, err := restartInfo(ctx, target); err == nil {
+		out.RestartCount = &info.RestartCount
+	}
+	return out, nil
+}
+
+// naturalTimeLayouts 为无时区的常见时间写法，统一按 UTC 解析
+var naturalTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+func parseTimeArg(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, fmt.Errorf("time string is empty")
+	}
+	// today/yesterday 表示 UTC 当天/前一天的零点
+	switch strings.ToLower(s) {
+	case "now":
+		return now.UTC(), nil
+	case "today":
+		y, m, d := now.UTC().Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), nil
+	case "yesterday":
+		y, m, d := now.UTC().Date()
+		return time.Date(y, m, d-1, 0, 0, 0, 0, time.UTC), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		if d > 0 {
+			d = -d
+		}
+		return now.Add(d).UTC(), nil
+	}
+	if tm, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return tm.UTC(), nil
+	}
+	if tm, err := time.Parse(time.RFC3339, s); err == nil {
+		return tm.UTC(), nil
+	}
+	for _, layout := range naturalTimeLayouts {
+		if tm, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return tm, nil
+		}
+	}
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time format: %s (use RFC3339, 2006-01-02[ 15:04:05], today/yesterday or duration like 10m)", s)
+}
+
+func containerNameCandidates(name string) []string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	base := strings.TrimPrefix(name, "/")
+	out := make([]string, 0, 2)
+	seen := map[string]struct{}{}
+
+	add := func(s string) {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return
+		}
+		if _, ok := seen[s]; ok {
+			return
+		}
+		seen[s] = struct{}{}
+		out = append(out, s)
+	}
+
+	add(name)
+	add(base)
+	add("/" + base)
+	return out
+}
+
+func containerIDCandidates(id string) []string {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil
+	}
+	out := make([]string, 0, 2)
+	seen := map[string]struct{}{}
+	add := func(s string) {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return
+		}
+		if _, ok := seen[s]; ok {
+			return
+		}
+		seen[s] = struct{}{}
+		out = append(out, s)
+	}
+	add(id)
+	add(docker.ShortID(id))
+	return out
+}
+
+// GetTools 返回所有可用的工具列表
+// toolsCfg 用于按白名单/黑名单过滤工具，被过滤的工具不会出现在返回结果中
+func GetTools(store *storage.Storage, toolsCfg ToolsConfig) []tool.BaseTool {
+	tools := filterTools(registeredTools(store, toolsCfg), toolsCfg)
+
+	// 变更类工具支持 dry-run（是否生效由 context 决定）；执行前统一按参数 Schema 校验；
+	// 执行出错时统一返回 ok=false 的结果
+	for i, t := range tools {
+		tools[i] = wrapWithValidation(wrapWithDryRun(wrapWithResult(t)))
+	}
+
+	// 如果有 storage，则对所有工具进行审计包装
+	if store != nil {
+		auditedTools := make([]tool.BaseTool, len(tools))
+		for i, t := range tools {
+			auditedTools[i] = wrapWithAudit(t, store)
+		}
+		return auditedTools
+	}
+
+	return tools
+}
+
+// registeredTools 返回未经过滤与包装的全部工具；依赖存储的工具仅在 store 非空时注册
+func registeredTools(store *storage.Storage, toolsCfg ToolsConfig) []tool.BaseTool {
+	tools := []tool.BaseTool{
 		&ListContainersTool{},
 		&InspectContainerTool{},
+		&ResolveContainerTool{},
+		&ContainerEnvTool{secretPatterns: toolsCfg.SecretEnvPatterns},
 		&GetContainerLogsTool{},
+		&TailContainerLogsTool{},
+		&ContainerHealthTool{store: store},
+		&ContainerRestartInfoTool{},
+		&ContainerDiffTool{},
+		&SampleContainerStatsTool{},
 		&RunContainerTool{},
 		&StartContainerTool{},
 		&StopContainerTool{},
 		&RestartContainerTool{},
+		&UpdateRestartPolicyTool{},
 		&ListImagesTool{},
 		&InspectImageTool{},
 		&PullImageTool{},
 		&RemoveImageTool{},
+		&RemoveImagesTool{},
 		&ListNetworksTool{},
 		&CreateNetworkTool{},
 		&InspectNetworkTool{},
@@ -1278,23 +2790,21 @@ func GetTools(store *storage.Storage) []tool.BaseTool {
 		&RemoveVolumeTool{},
 	}
 	if store != nil {
-		tools = append(tools, &QueryContainerStatsTool{store: store}, &QueryContainerLogsTool{store: store})
+		tools = append(tools,
+			&QueryContainerStatsTool{store: store},
+			&QueryContainerLogsTool{store: store},
+			&LogsSinceRestartTool{store: store},
+			&CompareContainersTool{store: store},
+			&ListContainersWithStatsTool{store: store},
+			&ErrorHotspotsTool{store: store},
+			&RecentAnomaliesTool{store: store},
+		)
 	}
-
-	// 如果有 storage，则对所有工具进行审计包装
-	if store != nil {
-		auditedTools := make([]tool.BaseTool, len(tools))
-		for i, t := range tools {
-			auditedTools[i] = wrapWithAudit(t, store)
-		}
-		return auditedTools
-	}
-
 	return tools
 }
 
-func GetToolsInfo(ctx context.Context, store *storage.Storage) ([]*schema.ToolInfo, error) {
-	tools := GetTools(store)
+func GetToolsInfo(ctx context.Context, store *storage.Storage, toolsCfg ToolsConfig) ([]*schema.ToolInfo, error) {
+	tools := GetTools(store, toolsCfg)
 	toolInfos := make([]*schema.ToolInfo, 0, len(tools))
 	for _, t := range tools {
 		info, err := t.Info(ctx)
@@ -1305,3 +2815,44 @@ func GetToolsInfo(ctx context.Context, store *storage.Storage) ([]*schema.ToolIn
 	}
 	return toolInfos, nil
 }
+
+// filterTools 按 ToolsConfig 过滤工具列表
+// Allow 非空时仅保留其中的工具；Deny 中的工具总是被移除
+func filterTools(tools []tool.BaseTool, cfg ToolsConfig) []tool.BaseTool {
+	if len(cfg.Allow) == 0 && len(cfg.Deny) == 0 {
+		return tools
+	}
+
+	allow := toolNameSet(cfg.Allow)
+	deny := toolNameSet(cfg.Deny)
+
+	out := make([]tool.BaseTool, 0, len(tools))
+	for _, t := range tools {
+		info, err := t.Info(context.Background())
+		if err != nil || info == nil {
+			continue
+		}
+		if len(allow) > 0 {
+			if _, ok := allow[info.Name]; !ok {
+				continue
+			}
+		}
+		if _, ok := deny[info.Name]; ok {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+func toolNameSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		set[name] = struct{}{}
+	}
+	return set
+}
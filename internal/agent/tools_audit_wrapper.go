@@ -2,11 +2,12 @@ package agent
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
 	"time"
 
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/schema"
+	"github.com/wwwzy/CentAgent/internal/logging"
 	"github.com/wwwzy/CentAgent/internal/storage"
 )
 
@@ -51,7 +52,7 @@ func (t *AuditedTool) InvokableRun(ctx context.Context, argumentsInJSON string,
 	record := &storage.AuditRecord{
 		TraceID:    traceID,
 		Action:     action,
-		ParamsJSON: truncate(argumentsInJSON, auditTruncateLimit),
+		ParamsJSON: truncate(redactArgumentsJSON(argumentsInJSON), auditTruncateLimit),
 		Status:     "running",
 		StartedAt:  now,
 	}
@@ -59,7 +60,7 @@ func (t *AuditedTool) InvokableRun(ctx context.Context, argumentsInJSON string,
 	// 3. 插入初始记录（Status=running）
 	// 注意：如果插入失败，我们通常选择打印日志但不阻断工具执行
 	if err := t.store.InsertAuditRecord(ctx, record); err != nil {
-		fmt.Printf("[WARN] Failed to insert audit record: %v\n", err)
+		logging.L().Warn("insert audit record failed", "tool", action, "trace_id", traceID, "err", err)
 	}
 
 	// 4. 执行原始工具逻辑
@@ -80,12 +81,18 @@ func (t *AuditedTool) InvokableRun(ctx context.Context, argumentsInJSON string,
 		status = "failed"
 		e := truncate(runErr.Error(), auditTruncateLimit)
 		errMsg = &e
+	} else if res, err := ParseToolResult(result); err == nil && !res.OK {
+		// ok=false 的结果（参数有误、执行出错等）同样记为失败
+		status = "failed"
+		e := truncate(res.Message, auditTruncateLimit)
+		errMsg = &e
 	} else {
 		r := truncate(result, auditTruncateLimit)
 		resultJSON = &r
 	}
 
 	// 只有在 Insert 成功且有了 ID 后，才能 Update
+	// 调用被取消时仍需落库最终状态，因此使用不随 ctx 取消的 context
 	if record.ID != 0 {
 		update := storage.AuditUpdate{
 			Status:       &status,
@@ -93,14 +100,49 @@ func (t *AuditedTool) InvokableRun(ctx context.Context, argumentsInJSON string,
 			ErrorMessage: errMsg,
 			FinishedAt:   &finishedAt,
 		}
-		if err := t.store.UpdateAuditRecord(ctx, record.ID, update); err != nil {
-			fmt.Printf("[WARN] Failed to update audit record: %v\n", err)
+		if err := t.store.UpdateAuditRecord(context.WithoutCancel(ctx), record.ID, update); err != nil {
+			logging.L().Warn("update audit record failed", "tool", action, "trace_id", traceID, "audit_id", record.ID, "err", err)
 		}
 	}
 
 	return result, runErr
 }
 
+// secretArgKeys 为落库或展示前需要打码的工具参数名
+var secretArgKeys = map[string]bool{"password": true}
+
+// redactSecretArgs 就地将 args 中的敏感参数替换为 maskedEnvValue
+func redactSecretArgs(args map[string]any) {
+	for k, v := range args {
+		if secretArgKeys[k] && v != nil && v != "" {
+			args[k] = maskedEnvValue
+		}
+	}
+}
+
+// redactArgumentsJSON 对 JSON 参数中的敏感字段打码；无法解析或无需打码时原样返回
+func redactArgumentsJSON(argumentsInJSON string) string {
+	args := map[string]any{}
+	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
+		return argumentsInJSON
+	}
+	redacted := false
+	for k := range args {
+		if secretArgKeys[k] {
+			redacted = true
+		}
+	}
+	if !redacted {
+		return argumentsInJSON
+	}
+	redactSecretArgs(args)
+	data, err := json.Marshal(args)
+	if err != nil {
+		return argumentsInJSON
+	}
+	return string(data)
+}
+
 func truncate(s string, limit int) string {
 	if len(s) <= limit {
 		return s
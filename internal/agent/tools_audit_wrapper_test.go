@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+func openTestStorage(t *testing.T) *storage.Storage {
+	t.Helper()
+
+	ctx := context.Background()
+	store, err := storage.Open(ctx, storage.Config{
+		Path:      filepath.Join(t.TempDir(), "centagent.db"),
+		EnableWAL: true,
+	})
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+// TestGraphToolsNode_WritesAuditRecord 验证 Graph 路径下的工具执行会写入审计记录
+// 这里选用 query_container_stats 工具，它只依赖 storage，不需要 Docker 或真实模型
+func TestGraphToolsNode_WritesAuditRecord(t *testing.T) {
+	store := openTestStorage(t)
+	ctx := context.Background()
+
+	tn, err := NewToolsNode(ctx, &compose.ToolsNodeConfig{Tools: GetTools(store, ToolsConfig{})})
+	if err != nil {
+		t.Fatalf("create tools node: %v", err)
+	}
+
+	state := AgentState{
+		NextStepToolCalls: []schema.ToolCall{
+			{
+				ID: "call-1",
+				Function: schema.FunctionCall{
+					Name:      "query_container_stats",
+					Arguments: `{"container_id":"cid-a","limit":5}`,
+				},
+			},
+		},
+	}
+	inputMsg, err := ConvertStateToToolsInput(ctx, state)
+	if err != nil {
+		t.Fatalf("convert state: %v", err)
+	}
+
+	traceID := "trace-graph-audit"
+	outputs, err := tn.Invoke(WithTraceID(ctx, traceID), inputMsg)
+	if err != nil {
+		t.Fatalf("invoke tools node: %v", err)
+	}
+	if len(outputs) != 1 {
+		t.Fatalf("expected 1 tool output, got %d", len(outputs))
+	}
+
+	records, err := store.QueryAuditRecords(ctx, storage.AuditQuery{TraceID: traceID})
+	if err != nil {
+		t.Fatalf("query audit records: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 audit record, got %d", len(records))
+	}
+	rec := records[0]
+	if rec.Action != "query_container_stats" {
+		t.Fatalf("unexpected action: %s", rec.Action)
+	}
+	if rec.Status != "success" {
+		t.Fatalf("unexpected status: %s (error=%s)", rec.Status, rec.ErrorMessage)
+	}
+	if rec.ParamsJSON != `{"container_id":"cid-a","limit":5}` {
+		t.Fatalf("unexpected params: %s", rec.ParamsJSON)
+	}
+	if rec.FinishedAt.IsZero() {
+		t.Fatalf("expected finished_at to be set")
+	}
+}
+
+func TestRedactArgumentsJSON(t *testing.T) {
+	got := redactArgumentsJSON(`{"ref":"ghcr.io/org/app","username":"bob","password":"s3cret"}`)
+	if strings.Contains(got, "s3cret") || !strings.Contains(got, `"password":"****"`) || !strings.Contains(got, `"username":"bob"`) {
+		t.Fatalf("expected password to be masked, got %s", got)
+	}
+
+	plain := `{"container_id":"cid-a"}`
+	if got := redactArgumentsJSON(plain); got != plain {
+		t.Fatalf("expected arguments without secrets unchanged, got %s", got)
+	}
+	if got := redactArgumentsJSON("{"); got != "{" {
+		t.Fatalf("expected invalid JSON unchanged, got %s", got)
+	}
+}
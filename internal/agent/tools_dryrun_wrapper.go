@@ -0,0 +1,146 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// mutatingTools 为会修改 Docker 状态的工具，dry-run 模式下只返回执行计划
+var mutatingTools = map[string]struct{}{
+	"run_container":         {},
+	"start_container":       {},
+	"stop_container":        {},
+	"restart_container":     {},
+	"update_restart_policy": {},
+	"pull_image":            {},
+	"remove_image":          {},
+	"remove_images":         {},
+	"create_network":        {},
+	"connect_network":       {},
+	"disconnect_network":    {},
+	"remove_network":        {},
+	"create_volume":         {},
+	"remove_volume":         {},
+}
+
+// IsMutatingTool 判断工具是否会修改 Docker 状态（启停容器、删除镜像等）
+func IsMutatingTool(name string) bool {
+	_, ok := mutatingTools[name]
+	return ok
+}
+
+// DryRunPlan 为 dry-run 模式下工具返回的执行计划
+type DryRunPlan struct {
+	DryRun    bool           `json:"dry_run"`
+	Tool      string         `json:"tool"`
+	DockerAPI []string       `json:"docker_api"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+}
+
+// DryRunTool 是一个工具包装器：dry-run 模式下不执行变更操作，而是返回将要调用的 Docker API
+type DryRunTool struct {
+	impl tool.InvokableTool
+	name string
+}
+
+// wrapWithDryRun 对变更类工具进行 dry-run 包装，只读工具原样返回
+func wrapWithDryRun(t tool.BaseTool) tool.BaseTool {
+	it, ok := t.(tool.InvokableTool)
+	if !ok {
+		return t
+	}
+	info, err := t.Info(context.Background())
+	if err != nil || info == nil {
+		return t
+	}
+	if _, ok := mutatingTools[info.Name]; !ok {
+		return t
+	}
+	return &DryRunTool{impl: it, name: info.Name}
+}
+
+func (t *DryRunTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return t.impl.Info(ctx)
+}
+
+func (t *DryRunTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	if !IsDryRun(ctx) {
+		return t.impl.InvokableRun(ctx, argumentsInJSON, opts...)
+	}
+
+	args := map[string]any{}
+	if argumentsInJSON != "" {
+		if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
+			return toolFailure(fmt.Sprintf("invalid argument: arguments must be a JSON object: %v", err))
+		}
+	}
+
+	redactSecretArgs(args)
+	plan := DryRunPlan{
+		DryRun:    true,
+		Tool:      t.name,
+		DockerAPI: dockerAPICalls(t.name, args),
+		Arguments: args,
+	}
+	return toolMessage(fmt.Sprintf("Dry run: %s was not executed", t.name), plan)
+}
+
+// dockerAPICalls 将工具调用解析为对应的 Docker Engine API 请求
+func dockerAPICalls(name string, args map[string]any) []string {
+	arg := func(key string) string {
+		if v, ok := args[key].(string); ok {
+			return url.PathEscape(v)
+		}
+		return ""
+	}
+
+	switch name {
+	case "run_container":
+		calls := []string{}
+		if pull, ok := args["pull_if_missing"].(bool); ok && pull {
+			calls = append(calls, "POST /images/create?fromImage="+url.QueryEscape(fmt.Sprint(args["image"]))+" (if missing)")
+		}
+		create := "POST /containers/create"
+		if n := arg("name"); n != "" {
+			create += "?name=" + n
+		}
+		return append(calls, create, "POST /containers/{id}/start")
+	case "start_container":
+		return []string{"POST /containers/" + arg("container_id") + "/start"}
+	case "stop_container":
+		return []string{"POST /containers/" + arg("container_id") + "/stop"}
+	case "restart_container":
+		return []string{"POST /containers/" + arg("container_id") + "/restart"}
+	case "update_restart_policy":
+		return []string{"POST /containers/" + arg("container_id") + "/update"}
+	case "pull_image":
+		return []string{"POST /images/create?fromImage=" + url.QueryEscape(fmt.Sprint(args["ref"]))}
+	case "remove_image":
+		return []string{"DELETE /images/" + arg("ref")}
+	case "remove_images":
+		refs, _ := args["refs"].([]any)
+		calls := make([]string, 0, len(refs))
+		for _, ref := range refs {
+			calls = append(calls, "DELETE /images/"+url.PathEscape(fmt.Sprint(ref)))
+		}
+		return calls
+	case "create_network":
+		return []string{"POST /networks/create"}
+	case "connect_network":
+		return []string{"POST /networks/" + arg("network_id") + "/connect"}
+	case "disconnect_network":
+		return []string{"POST /networks/" + arg("network_id") + "/disconnect"}
+	case "remove_network":
+		return []string{"DELETE /networks/" + arg("network_id")}
+	case "create_volume":
+		return []string{"POST /volumes/create"}
+	case "remove_volume":
+		return []string{"DELETE /volumes/" + arg("name")}
+	}
+	return nil
+}
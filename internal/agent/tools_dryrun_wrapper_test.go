@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// recordingTool 记录是否被真正执行
+type recordingTool struct {
+	name   string
+	called bool
+}
+
+func (t *recordingTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: t.name}, nil
+}
+
+func (t *recordingTool) InvokableRun(_ context.Context, _ string, _ ...tool.Option) (string, error) {
+	t.called = true
+	return "executed", nil
+}
+
+func TestDryRun_StopContainerReturnsPlan(t *testing.T) {
+	ctx := WithDryRun(context.Background(), true)
+
+	inner := &recordingTool{name: "stop_container"}
+	wrapped, ok := wrapWithDryRun(inner).(tool.InvokableTool)
+	if !ok {
+		t.Fatalf("expected wrapped tool to be invokable")
+	}
+
+	out, err := wrapped.InvokableRun(ctx, `{"container_id":"web-1"}`)
+	if err != nil {
+		t.Fatalf("dry-run stop_container: %v", err)
+	}
+	if inner.called {
+		t.Fatalf("stop_container should not be executed in dry-run mode")
+	}
+
+	var plan DryRunPlan
+	decodeToolData(t, out, &plan)
+	if !plan.DryRun || plan.Tool != "stop_container" {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if len(plan.DockerAPI) != 1 || plan.DockerAPI[0] != "POST /containers/web-1/stop" {
+		t.Fatalf("unexpected docker api: %v", plan.DockerAPI)
+	}
+
+	// 关闭 dry-run 时照常执行
+	if _, err := wrapped.InvokableRun(context.Background(), `{"container_id":"web-1"}`); err != nil {
+		t.Fatalf("run stop_container: %v", err)
+	}
+	if !inner.called {
+		t.Fatalf("stop_container should be executed when dry-run is off")
+	}
+}
+
+func TestDryRun_InvalidArgumentsReturnFailedResult(t *testing.T) {
+	ctx := WithDryRun(context.Background(), true)
+
+	inner := &recordingTool{name: "stop_container"}
+	wrapped := wrapWithDryRun(inner).(tool.InvokableTool)
+
+	out, err := wrapped.InvokableRun(ctx, `{"container_id":`)
+	if err != nil {
+		t.Fatalf("expected failed result instead of error, got %v", err)
+	}
+	res, err := ParseToolResult(out)
+	if err != nil || res.OK || !strings.HasPrefix(res.Message, "invalid argument:") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	if inner.called {
+		t.Fatalf("stop_container should not be executed in dry-run mode")
+	}
+}
+
+func TestDryRun_ReadOnlyToolsNotWrapped(t *testing.T) {
+	inner := &recordingTool{name: "list_containers"}
+	if got := wrapWithDryRun(inner); got != tool.BaseTool(inner) {
+		t.Fatalf("read-only tool should not be wrapped")
+	}
+}
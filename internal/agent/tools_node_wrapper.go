@@ -2,6 +2,9 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
+	"time"
 
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
@@ -31,7 +34,19 @@ func ConvertToolsOutputToState(ctx context.Context, state AgentState, outputs []
 	// 1. 更新 LatestToolOutputs
 	state.LatestToolOutputs = outputs
 
+	// 根据本轮工具调用更新“当前关注的容器”，便于后续“重启它”这类指代
+	if name := mostReferencedContainer(state.NextStepToolCalls, outputs); name != "" {
+		if state.Context == nil {
+			state.Context = map[string]interface{}{}
+		}
+		state.Context[CurrentContainerContextKey] = name
+	}
+
 	// 2. 将 ToolOutputs 追加到 Messages
+	now := time.Now()
+	for _, out := range outputs {
+		StampMessage(out, now)
+	}
 	state.Messages = append(state.Messages, outputs...)
 
 	// 3. 清空 NextStepToolCalls
@@ -39,3 +54,63 @@ func ConvertToolsOutputToState(ctx context.Context, state AgentState, outputs []
 
 	return state, nil
 }
+
+// mostReferencedContainer 统计本轮 inspect_container/list_containers 调用中引用到的容器，返回引用次数最多的一个
+// inspect_container 优先使用输出中的容器名；list_containers 仅在结果恰好为一个容器时计入
+func mostReferencedContainer(calls []schema.ToolCall, outputs []*schema.Message) string {
+	outputByID := make(map[string]string, len(outputs))
+	for _, out := range outputs {
+		if out != nil && out.ToolCallID != "" {
+			outputByID[out.ToolCallID] = toolResultData(out.Content)
+		}
+	}
+
+	counts := map[string]int{}
+	order := make([]string, 0)
+	add := func(name string) {
+		name = strings.TrimPrefix(strings.TrimSpace(name), "/")
+		if name == "" {
+			return
+		}
+		if _, ok := counts[name]; !ok {
+			order = append(order, name)
+		}
+		counts[name]++
+	}
+
+	for _, tc := range calls {
+		content := outputByID[tc.ID]
+		switch tc.Function.Name {
+		case "inspect_container":
+			var detail struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal([]byte(content), &detail); err == nil && detail.Name != "" {
+				add(detail.Name)
+				continue
+			}
+			var args struct {
+				ContainerID string `json:"container_id"`
+			}
+			if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err == nil {
+				add(args.ContainerID)
+			}
+		case "list_containers":
+			var items []struct {
+				Names string `json:"names"`
+			}
+			if err := json.Unmarshal([]byte(content), &items); err == nil && len(items) == 1 {
+				name, _, _ := strings.Cut(items[0].Names, ",")
+				add(name)
+			}
+		}
+	}
+
+	best := ""
+	for _, name := range order {
+		if best == "" || counts[name] > counts[best] {
+			best = name
+		}
+	}
+	return best
+}
@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// TestConvertToolsOutputToState_UpdatesCurrentContainer 验证 inspect_container 调用后会更新当前关注的容器
+func TestConvertToolsOutputToState_UpdatesCurrentContainer(t *testing.T) {
+	ctx := context.Background()
+	state := AgentState{
+		NextStepToolCalls: []schema.ToolCall{
+			{
+				ID: "call-1",
+				Function: schema.FunctionCall{
+					Name:      "inspect_container",
+					Arguments: `{"container_id":"abc123"}`,
+				},
+			},
+		},
+	}
+	outputs := []*schema.Message{
+		schema.ToolMessage(`{"ok":true,"data":{"id":"abc123","name":"/nginx-web","status":"running"}}`, "call-1"),
+	}
+
+	next, err := ConvertToolsOutputToState(ctx, state, outputs)
+	if err != nil {
+		t.Fatalf("convert outputs: %v", err)
+	}
+	if got := next.Context[CurrentContainerContextKey]; got != "nginx-web" {
+		t.Fatalf("unexpected current container: %v", got)
+	}
+
+	// 输出无法解析时回退到调用参数中的 container_id
+	state = next
+	state.NextStepToolCalls = []schema.ToolCall{
+		{
+			ID: "call-2",
+			Function: schema.FunctionCall{
+				Name:      "inspect_container",
+				Arguments: `{"container_id":"redis"}`,
+			},
+		},
+	}
+	next, err = ConvertToolsOutputToState(ctx, state, []*schema.Message{schema.ToolMessage("error: not json", "call-2")})
+	if err != nil {
+		t.Fatalf("convert outputs: %v", err)
+	}
+	if got := next.Context[CurrentContainerContextKey]; got != "redis" {
+		t.Fatalf("unexpected current container: %v", got)
+	}
+}
@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ToolResult 为所有工具统一的返回信封，序列化为 JSON 后作为工具输出交给模型与 UI
+// OK 表示调用是否达成目的；Message 为给人看的简要说明；Data 为工具的结构化结果
+type ToolResult struct {
+	OK      bool            `json:"ok"`
+	Message string          `json:"message,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// marshalToolResult 将 v 作为 data 包装为成功结果
+func marshalToolResult(v any) (string, error) {
+	return marshalToolEnvelope(true, "", v)
+}
+
+// toolMessage 返回带提示信息的成功结果，data 为 nil 时省略
+func toolMessage(message string, data any) (string, error) {
+	return marshalToolEnvelope(true, message, data)
+}
+
+// toolFailure 返回 ok=false 的结果，用于参数校验失败等需要模型自行修正的场景
+func toolFailure(message string) (string, error) {
+	return marshalToolEnvelope(false, message, nil)
+}
+
+func marshalToolEnvelope(ok bool, message string, data any) (string, error) {
+	res := ToolResult{OK: ok, Message: message}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal result: %w", err)
+		}
+		res.Data = raw
+	}
+	out, err := json.Marshal(res)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return string(out), nil
+}
+
+// ParseToolResult 将工具输出解析为 ToolResult；输出不是信封格式时返回错误
+func ParseToolResult(content string) (ToolResult, error) {
+	var res struct {
+		OK      *bool           `json:"ok"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "{") {
+		return ToolResult{}, fmt.Errorf("tool result is not a JSON object")
+	}
+	if err := json.Unmarshal([]byte(trimmed), &res); err != nil {
+		return ToolResult{}, fmt.Errorf("invalid tool result: %w", err)
+	}
+	if res.OK == nil {
+		return ToolResult{}, fmt.Errorf("invalid tool result: missing ok field")
+	}
+	return ToolResult{OK: *res.OK, Message: res.Message, Data: res.Data}, nil
+}
+
+// toolResultData 返回信封中的 data；无法解析为信封时原样返回输出，兼容旧格式的历史消息
+func toolResultData(content string) string {
+	res, err := ParseToolResult(content)
+	if err != nil {
+		return content
+	}
+	return string(res.Data)
+}
+
+// ResultTool 是一个工具包装器：将工具返回的 error（如 Docker 不可用、容器不存在）转换为 ok=false 的结果，
+// 使模型总能拿到统一的信封并据此调整；调用被取消时仍返回 error，由调用方结束本轮
+type ResultTool struct {
+	impl tool.InvokableTool
+}
+
+// wrapWithResult 对可执行的工具进行结果包装
+func wrapWithResult(t tool.BaseTool) tool.BaseTool {
+	it, ok := t.(tool.InvokableTool)
+	if !ok {
+		return t
+	}
+	return &ResultTool{impl: it}
+}
+
+func (t *ResultTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return t.impl.Info(ctx)
+}
+
+func (t *ResultTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	out, err := t.impl.InvokableRun(ctx, argumentsInJSON, opts...)
+	if err != nil && ctx.Err() == nil {
+		return toolFailure(err.Error())
+	}
+	return out, err
+}
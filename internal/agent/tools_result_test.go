@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+// decodeToolData 解析工具结果信封，要求 ok=true 并将 data 解码到 v
+func decodeToolData(t *testing.T, out string, v any) {
+	t.Helper()
+	res, err := ParseToolResult(out)
+	if err != nil {
+		t.Fatalf("decode result: %v\n%s", err, out)
+	}
+	if !res.OK {
+		t.Fatalf("expected ok result, got: %s", out)
+	}
+	if err := json.Unmarshal(res.Data, v); err != nil {
+		t.Fatalf("decode result data: %v\n%s", err, out)
+	}
+}
+
+func TestParseToolResult(t *testing.T) {
+	out, err := toolMessage("Container web started successfully", map[string]string{"container_id": "web"})
+	if err != nil {
+		t.Fatalf("marshal result: %v", err)
+	}
+	res, err := ParseToolResult(out)
+	if err != nil {
+		t.Fatalf("parse result: %v", err)
+	}
+	if !res.OK || res.Message != "Container web started successfully" || string(res.Data) != `{"container_id":"web"}` {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	out, err = toolFailure("invalid argument: field name is required")
+	if err != nil {
+		t.Fatalf("marshal failure: %v", err)
+	}
+	if out != `{"ok":false,"message":"invalid argument: field name is required"}` {
+		t.Fatalf("unexpected failure output: %s", out)
+	}
+
+	// 旧格式的输出不是信封
+	for _, in := range []string{"Container web started successfully", `[{"id":"abc"}]`, `{"id":"abc"}`} {
+		if _, err := ParseToolResult(in); err == nil {
+			t.Fatalf("expected %q to be rejected", in)
+		}
+		if got := toolResultData(in); got != in {
+			t.Fatalf("expected %q to be passed through, got %q", in, got)
+		}
+	}
+}
+
+// TestTools_ResultsParseIntoEnvelope 验证每个工具的输出都是 ToolResult 信封
+// 依赖 Docker 的工具在 daemon 不可用时同样返回 ok=false 的信封，而不是 error
+func TestTools_ResultsParseIntoEnvelope(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	store, err := storage.Open(ctx, storage.Config{Path: filepath.Join(t.TempDir(), "centagent-test.db")})
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	now := time.Now().UTC()
+	if err := store.InsertContainerStats(ctx, []storage.ContainerStat{
+		{ContainerID: "aaaaaaaaaaaa", ContainerName: "/api", CPUPercent: 80, MemPercent: 50, CollectedAt: now.Add(-5 * time.Minute)},
+		{ContainerID: "bbbbbbbbbbbb", ContainerName: "/worker", CPUPercent: 20, MemPercent: 10, CollectedAt: now.Add(-5 * time.Minute)},
+	}); err != nil {
+		t.Fatalf("insert stats: %v", err)
+	}
+	if err := store.InsertContainerLogs(ctx, []storage.ContainerLog{
+		{ContainerID: "aaaaaaaaaaaa", ContainerName: "/api", Source: "stderr", Message: "error: boom", Timestamp: now.Add(-time.Minute)},
+	}); err != nil {
+		t.Fatalf("insert logs: %v", err)
+	}
+
+	// 仅依赖存储的工具必须成功返回 ok 信封
+	storageArgs := map[string]string{
+		"query_container_stats":   `{}`,
+		"query_container_logs":    `{}`,
+		"logs_since_last_restart": `{"container":"api"}`,
+		"compare_containers":      `{"container_a":"api","container_b":"worker"}`,
+		"error_hotspots":          `{}`,
+		"recent_anomalies":        `{"cpu_high":50}`,
+	}
+
+	// 变更类工具在 dry-run 下只返回执行计划，不会触达 Docker
+	ctx = WithDryRun(ctx, true)
+	for _, bt := range GetTools(store, ToolsConfig{}) {
+		info, err := bt.Info(ctx)
+		if err != nil {
+			t.Fatalf("tool info: %v", err)
+		}
+		it, ok := bt.(tool.InvokableTool)
+		if !ok {
+			t.Fatalf("%s: expected invokable tool", info.Name)
+		}
+
+		args, storageOnly := storageArgs[info.Name]
+		if !storageOnly {
+			args = `{}`
+		}
+		out, err := it.InvokableRun(ctx, args)
+		if err != nil {
+			t.Fatalf("%s: expected a tool result envelope, got error: %v", info.Name, err)
+		}
+		res, err := ParseToolResult(out)
+		if err != nil {
+			t.Fatalf("%s: output is not a tool result envelope: %v\n%s", info.Name, err, out)
+		}
+		if storageOnly && (!res.OK || len(res.Data) == 0) {
+			t.Fatalf("%s: expected ok result with data, got %s", info.Name, out)
+		}
+		if !res.OK && res.Message == "" {
+			t.Fatalf("%s: failed result should carry a message: %s", info.Name, out)
+		}
+	}
+}
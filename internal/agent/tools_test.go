@@ -0,0 +1,423 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/wwwzy/CentAgent/internal/docker"
+	"github.com/wwwzy/CentAgent/internal/logging"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+func TestGetToolsInfo_DenyList(t *testing.T) {
+	ctx := context.Background()
+
+	infos, err := GetToolsInfo(ctx, nil, ToolsConfig{
+		Deny: []string{"remove_image", "stop_container"},
+	})
+	if err != nil {
+		t.Fatalf("get tools info: %v", err)
+	}
+
+	names := make(map[string]struct{}, len(infos))
+	for _, info := range infos {
+		names[info.Name] = struct{}{}
+	}
+	for _, denied := range []string{"remove_image", "stop_container"} {
+		if _, ok := names[denied]; ok {
+			t.Fatalf("denied tool %s should not be advertised", denied)
+		}
+	}
+	if _, ok := names["list_containers"]; !ok {
+		t.Fatalf("expected list_containers to remain available")
+	}
+}
+
+func TestGetToolsInfo_AllowList(t *testing.T) {
+	ctx := context.Background()
+
+	infos, err := GetToolsInfo(ctx, nil, ToolsConfig{
+		Allow: []string{"list_containers", "inspect_container", "remove_image"},
+		Deny:  []string{"remove_image"},
+	})
+	if err != nil {
+		t.Fatalf("get tools info: %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("expected 2 tools, got %d", len(infos))
+	}
+	for _, info := range infos {
+		if info.Name != "list_containers" && info.Name != "inspect_container" {
+			t.Fatalf("unexpected tool advertised: %s", info.Name)
+		}
+	}
+}
+
+func TestCompareContainersTool(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := storage.Open(ctx, storage.Config{Path: filepath.Join(t.TempDir(), "centagent-test.db")})
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	now := time.Now().UTC()
+	stats := []storage.ContainerStat{
+		{ContainerID: "aaaaaaaaaaaa", ContainerName: "/api", CPUPercent: 80, MemPercent: 50, CollectedAt: now.Add(-10 * time.Minute)},
+		{ContainerID: "aaaaaaaaaaaa", ContainerName: "/api", CPUPercent: 60, MemPercent: 30, CollectedAt: now.Add(-5 * time.Minute)},
+		{ContainerID: "bbbbbbbbbbbb", ContainerName: "/worker", CPUPercent: 20, MemPercent: 10, CollectedAt: now.Add(-5 * time.Minute)},
+	}
+	if err := store.InsertContainerStats(ctx, stats); err != nil {
+		t.Fatalf("insert stats: %v", err)
+	}
+	if err := store.InsertDockerEvent(ctx, &storage.DockerEvent{Type: "container", Action: "restart", ActorName: "api", Timestamp: now.Add(-time.Minute)}); err != nil {
+		t.Fatalf("insert event: %v", err)
+	}
+
+	restartInfo := func(ctx context.Context, id string) (*docker.ContainerRestartInfo, error) {
+		switch id {
+		case "aaaaaaaaaaaa":
+			return &docker.ContainerRestartInfo{ID: id, RestartCount: 5}, nil
+		case "bbbbbbbbbbbb":
+			return &docker.ContainerRestartInfo{ID: id, RestartCount: 2}, nil
+		}
+		return nil, fmt.Errorf("no such container: %s", id)
+	}
+	out, err := (&CompareContainersTool{store: store, restartInfo: restartInfo}).InvokableRun(ctx, `{"container_a":"api","container_b":"bbbbbbbbbbbb","from":"30m"}`)
+	if err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	var got struct {
+		A     containerComparison `json:"container_a"`
+		B     containerComparison `json:"container_b"`
+		Delta map[string]float64  `json:"delta"`
+	}
+	decodeToolData(t, out, &got)
+	if got.A.Samples != 2 || got.A.AvgCPUPercent != 70 || got.A.Restarts != 1 || got.A.RestartCount == nil || *got.A.RestartCount != 5 {
+		t.Fatalf("unexpected container_a: %+v", got.A)
+	}
+	if got.B.Samples != 1 || got.B.ContainerName != "/worker" || got.B.RestartCount == nil || *got.B.RestartCount != 2 {
+		t.Fatalf("unexpected container_b: %+v", got.B)
+	}
+	if got.Delta["avg_cpu_percent"] != 50 || got.Delta["restarts"] != 1 || got.Delta["restart_count"] != 3 {
+		t.Fatalf("unexpected delta: %+v", got.Delta)
+	}
+
+	// 无法 inspect 时省略 restart_count，仍返回事件统计
+	out, err = (&CompareContainersTool{store: store, restartInfo: restartInfo}).InvokableRun(ctx, `{"container_a":"api","container_b":"gone","from":"30m"}`)
+	if err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	got.B = containerComparison{}
+	got.Delta = nil
+	decodeToolData(t, out, &got)
+	if got.B.RestartCount != nil {
+		t.Fatalf("expected no restart_count for uninspectable container, got %d", *got.B.RestartCount)
+	}
+	if _, ok := got.Delta["restart_count"]; ok {
+		t.Fatalf("expected no restart_count delta, got %+v", got.Delta)
+	}
+}
+
+func TestLogsSinceRestartTool(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := storage.Open(ctx, storage.Config{Path: filepath.Join(t.TempDir(), "centagent-test.db")})
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	apiID := strings.Repeat("a", 64)
+	now := time.Now().UTC()
+	restartAt := now.Add(-10 * time.Minute)
+	logs := []storage.ContainerLog{
+		{ContainerID: apiID, ContainerName: "/api", Source: "stdout", Message: "before restart", Timestamp: restartAt.Add(-time.Minute)},
+		{ContainerID: apiID, ContainerName: "/api", Source: "stderr", Message: "after restart", Timestamp: restartAt.Add(time.Minute)},
+		{ContainerID: apiID, ContainerName: "/api", Source: "stdout", Message: "latest", Timestamp: restartAt.Add(2 * time.Minute)},
+		{ContainerID: "bbbbbbbbbbbb", ContainerName: "/worker", Source: "stdout", Message: "worker line", Timestamp: restartAt.Add(-time.Hour)},
+	}
+	if err := store.InsertContainerLogs(ctx, logs); err != nil {
+		t.Fatalf("insert logs: %v", err)
+	}
+	events := []storage.DockerEvent{
+		{Type: "container", Action: "start", ActorID: apiID, ActorName: "api", Timestamp: restartAt.Add(-time.Hour)},
+		{Type: "container", Action: "start", ActorID: apiID, ActorName: "api", Timestamp: restartAt},
+		// 其他动作与其他容器的事件不影响结果
+		{Type: "container", Action: "die", ActorID: apiID, ActorName: "api", Timestamp: restartAt.Add(30 * time.Second)},
+		{Type: "container", Action: "start", ActorID: strings.Repeat("c", 64), ActorName: "other", Timestamp: now},
+	}
+	for i := range events {
+		if err := store.InsertDockerEvent(ctx, &events[i]); err != nil {
+			t.Fatalf("insert event: %v", err)
+		}
+	}
+
+	type result struct {
+		Since        string                 `json:"since"`
+		RestartEvent map[string]any         `json:"restart_event"`
+		Note         string                 `json:"note"`
+		Count        int                    `json:"count"`
+		Logs         []storage.ContainerLog `json:"logs"`
+	}
+	run := func(args string) result {
+		t.Helper()
+		out, err := (&LogsSinceRestartTool{store: store}).InvokableRun(ctx, args)
+		if err != nil {
+			t.Fatalf("run %s: %v", args, err)
+		}
+		var got result
+		decodeToolData(t, out, &got)
+		return got
+	}
+
+	for _, c := range []string{"api", "/api", apiID[:12]} {
+		got := run(`{"container":"` + c + `"}`)
+		if got.Count != 2 || got.Logs[0].Message != "after restart" || got.Logs[1].Message != "latest" {
+			t.Fatalf("%s: expected only logs after the restart, got %+v", c, got.Logs)
+		}
+		if got.RestartEvent == nil || !strings.HasPrefix(got.Since, restartAt.Format("2006-01-02T15:04:05")) {
+			t.Fatalf("%s: unexpected restart info: since=%q event=%v", c, got.Since, got.RestartEvent)
+		}
+	}
+
+	// 没有重启事件时返回全部日志并给出说明
+	got := run(`{"container":"worker"}`)
+	if got.Count != 1 || got.Note == "" || got.RestartEvent != nil {
+		t.Fatalf("expected fallback to all logs for worker, got %+v", got)
+	}
+
+	out, err := (&LogsSinceRestartTool{store: store}).InvokableRun(ctx, `{}`)
+	if res, perr := ParseToolResult(out); err != nil || perr != nil || res.OK {
+		t.Fatalf("expected failed result when container is missing, got %q, %v", out, err)
+	}
+	out, err = (&LogsSinceRestartTool{store: store}).InvokableRun(ctx, `{"container":" "}`)
+	if res, perr := ParseToolResult(out); err != nil || perr != nil || res.OK || res.Message != "container is required" {
+		t.Fatalf("expected failed result when container is blank, got %q, %v", out, err)
+	}
+}
+
+// TestLogsSinceRestartTool_CollectorEvent 使用与日志采集器相同的 storage.NewDockerEvent 写入 start 事件，
+// 确认事件流中不带前导 / 的容器名与日志中的 /api 能对上
+func TestLogsSinceRestartTool_CollectorEvent(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := storage.Open(ctx, storage.Config{Path: filepath.Join(t.TempDir(), "centagent-test.db")})
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	apiID := strings.Repeat("a", 64)
+	restartAt := time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+	if err := store.InsertContainerLogs(ctx, []storage.ContainerLog{
+		{ContainerID: apiID, ContainerName: "/api", Source: "stdout", Message: "before restart", Timestamp: restartAt.Add(-time.Minute)},
+		{ContainerID: apiID, ContainerName: "/api", Source: "stdout", Message: "after restart", Timestamp: restartAt.Add(time.Second)},
+	}); err != nil {
+		t.Fatalf("insert logs: %v", err)
+	}
+	if err := store.InsertDockerEvent(ctx, storage.NewDockerEvent(events.Message{
+		Type:     events.ContainerEventType,
+		Action:   "start",
+		Actor:    events.Actor{ID: apiID, Attributes: map[string]string{"name": "api"}},
+		TimeNano: restartAt.UnixNano(),
+	})); err != nil {
+		t.Fatalf("insert event: %v", err)
+	}
+
+	var restartTool tool.InvokableTool
+	for _, bt := range GetTools(store, ToolsConfig{}) {
+		info, err := bt.Info(ctx)
+		if err != nil {
+			t.Fatalf("tool info: %v", err)
+		}
+		if info.Name == "logs_since_last_restart" {
+			restartTool = bt.(tool.InvokableTool)
+		}
+	}
+	if restartTool == nil {
+		t.Fatalf("logs_since_last_restart tool not registered")
+	}
+
+	out, err := restartTool.InvokableRun(ctx, `{"container":"api"}`)
+	if err != nil {
+		t.Fatalf("run tool: %v", err)
+	}
+	var got struct {
+		RestartEvent map[string]any         `json:"restart_event"`
+		Logs         []storage.ContainerLog `json:"logs"`
+	}
+	decodeToolData(t, out, &got)
+	if got.RestartEvent == nil {
+		t.Fatalf("expected the recorded start event, got %s", out)
+	}
+	if len(got.Logs) != 1 || got.Logs[0].Message != "after restart" {
+		t.Fatalf("expected only logs after the recorded start, got %+v", got.Logs)
+	}
+}
+
+func TestContainerHealthTool_NotFound(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := docker.Ping(ctx); err != nil {
+		t.Skipf("docker daemon unavailable: %v", err)
+	}
+
+	out, err := (&ContainerHealthTool{}).InvokableRun(ctx, `{"container_id":"centagent-no-such-container"}`)
+	if err != nil {
+		t.Fatalf("expected graceful result for missing container, got error: %v", err)
+	}
+	var got containerHealth
+	decodeToolData(t, out, &got)
+	if got.Exists || got.Container != "centagent-no-such-container" {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+}
+
+func TestParseTimeArg(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+	cases := []struct {
+		in   string
+		want time.Time
+	}{
+		{"10m", now.Add(-10 * time.Minute)},
+		{"2024-01-02T15:04:05Z", time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2024-01-02T15:04:05.5+08:00", time.Date(2024, 1, 2, 7, 4, 5, 500000000, time.UTC)},
+		{"1704207845", time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"2024-01-02 15:04:05", time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2024-01-02 15:04", time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC)},
+		{"2024-01-02T15:04:05", time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"today", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"Yesterday", time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{"now", now},
+	}
+	for _, c := range cases {
+		got, err := parseTimeArg(c.in, now)
+		if err != nil {
+			t.Fatalf("parseTimeArg(%q): %v", c.in, err)
+		}
+		if !got.Equal(c.want) {
+			t.Fatalf("parseTimeArg(%q) = %s, want %s", c.in, got, c.want)
+		}
+	}
+
+	if _, err := parseTimeArg("last tuesday", now); err == nil {
+		t.Fatalf("expected error for unsupported format")
+	}
+}
+
+func TestJoinContainerStats(t *testing.T) {
+	fullID := "0123456789abcdef0123456789abcdef"
+	seen := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+	containers := []docker.ContainerSummary{
+		{ID: "fedcba9876543210", Names: "/worker", State: "exited"},
+		{ID: fullID, Names: "/api", State: "running"},
+	}
+	stats := []storage.ContainerStat{
+		{ContainerID: fullID[:12], ContainerName: "/api", CPUPercent: 12.5, MemPercent: 40, CollectedAt: seen},
+	}
+
+	rows := joinContainerStats(containers, stats)
+	if len(rows) != 2 || rows[0].Name != "api" || rows[1].Name != "worker" {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+	if rows[0].CPUPercent == nil || *rows[0].CPUPercent != 12.5 || rows[0].LastSeen == nil || !rows[0].LastSeen.Equal(seen) {
+		t.Fatalf("expected stats joined by short id, got %+v", rows[0])
+	}
+	if rows[1].CPUPercent != nil || rows[1].MemPercent != nil || rows[1].LastSeen != nil {
+		t.Fatalf("expected null metrics for container without stats, got %+v", rows[1])
+	}
+}
+
+func TestMaskEnv(t *testing.T) {
+	env := []string{"DB_PASSWORD=hunter2", "LOG_LEVEL=debug", "api_token=abc", "EMPTY="}
+
+	got := maskEnv(env, nil)
+	if len(got) != len(env) {
+		t.Fatalf("expected %d vars, got %+v", len(env), got)
+	}
+	byName := make(map[string]containerEnvVar, len(got))
+	for _, v := range got {
+		byName[v.Name] = v
+	}
+	if v := byName["DB_PASSWORD"]; v.Value != maskedEnvValue || !v.Masked {
+		t.Fatalf("DB_PASSWORD should be masked, got %+v", v)
+	}
+	if v := byName["api_token"]; v.Value != maskedEnvValue {
+		t.Fatalf("api_token should be masked case-insensitively, got %+v", v)
+	}
+	if v := byName["LOG_LEVEL"]; v.Value != "debug" || v.Masked {
+		t.Fatalf("LOG_LEVEL should be shown, got %+v", v)
+	}
+
+	custom := maskEnv(env, []string{"LOG_*"})
+	for _, v := range custom {
+		switch v.Name {
+		case "LOG_LEVEL":
+			if !v.Masked {
+				t.Fatalf("custom pattern should mask LOG_LEVEL, got %+v", v)
+			}
+		case "DB_PASSWORD":
+			if v.Masked {
+				t.Fatalf("custom patterns replace defaults, got %+v", v)
+			}
+		}
+	}
+}
+
+func TestLogToolArgs_RespectsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	prev := logging.SetDefault(logging.New(&buf, logging.ParseLevel("info")))
+	defer logging.SetDefault(prev)
+
+	logToolArgs("ListContainers", map[string]any{"all": true})
+	if buf.Len() != 0 {
+		t.Fatalf("expected no debug output at info level, got %q", buf.String())
+	}
+
+	logging.SetDefault(logging.New(&buf, logging.ParseLevel("debug")))
+	logToolArgs("ListContainers", map[string]any{"all": true})
+	if out := buf.String(); !strings.Contains(out, "level=DEBUG") || !strings.Contains(out, "tool=ListContainers") {
+		t.Fatalf("expected debug output at debug level, got %q", out)
+	}
+}
+
+func TestGroupContainerChanges(t *testing.T) {
+	changes := []container.FilesystemChange{
+		{Kind: container.ChangeModify, Path: "/etc"},
+		{Kind: container.ChangeAdd, Path: "/data/b.log"},
+		{Kind: container.ChangeAdd, Path: "/data/a.log"},
+		{Kind: container.ChangeAdd, Path: "/data"},
+		{Kind: container.ChangeDelete, Path: "/etc/motd"},
+	}
+
+	got := groupContainerChanges(changes, 10)
+	if strings.Join(got.Added, ",") != "/data,/data/a.log,/data/b.log" ||
+		strings.Join(got.Changed, ",") != "/etc" || strings.Join(got.Deleted, ",") != "/etc/motd" {
+		t.Fatalf("unexpected grouping: %+v", got)
+	}
+	if got.Total != 5 || got.Truncated {
+		t.Fatalf("unexpected totals: %+v", got)
+	}
+
+	got = groupContainerChanges(changes, 2)
+	if len(got.Added) != 2 || !got.Truncated || got.Total != 5 {
+		t.Fatalf("expected truncated added paths: %+v", got)
+	}
+
+	// 没有变更时输出空数组而不是 null
+	got = groupContainerChanges(nil, 10)
+	if got.Added == nil || got.Changed == nil || got.Deleted == nil {
+		t.Fatalf("expected empty groups, got %+v", got)
+	}
+}
@@ -0,0 +1,197 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// paramSchema 为工具参数 JSON Schema 中校验所需的子集
+type paramSchema struct {
+	Type       string                  `json:"type"`
+	Properties map[string]*paramSchema `json:"properties"`
+	Required   []string                `json:"required"`
+	Items      *paramSchema            `json:"items"`
+	Enum       []any                   `json:"enum"`
+}
+
+// ValidatedTool 是一个工具包装器：执行前按 ParamsOneOf 校验参数（必填字段、类型）
+// 校验失败时不执行工具，而是返回 "invalid argument: ..." 文本，便于模型自行修正后重试
+type ValidatedTool struct {
+	impl   tool.InvokableTool
+	schema *paramSchema
+}
+
+// wrapWithValidation 对声明了参数的工具进行校验包装
+func wrapWithValidation(t tool.BaseTool) tool.BaseTool {
+	it, ok := t.(tool.InvokableTool)
+	if !ok {
+		return t
+	}
+	info, err := t.Info(context.Background())
+	if err != nil || info == nil {
+		return t
+	}
+	sc, err := toParamSchema(info.ParamsOneOf)
+	if err != nil || sc == nil {
+		return t
+	}
+	return &ValidatedTool{impl: it, schema: sc}
+}
+
+func toParamSchema(params *schema.ParamsOneOf) (*paramSchema, error) {
+	js, err := params.ToJSONSchema()
+	if err != nil || js == nil {
+		return nil, err
+	}
+	data, err := json.Marshal(js)
+	if err != nil {
+		return nil, fmt.Errorf("marshal params schema: %w", err)
+	}
+	var sc paramSchema
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return nil, fmt.Errorf("unmarshal params schema: %w", err)
+	}
+	return &sc, nil
+}
+
+func (t *ValidatedTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return t.impl.Info(ctx)
+}
+
+func (t *ValidatedTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	if err := validateArguments(t.schema, argumentsInJSON); err != nil {
+		return toolFailure(err.Error())
+	}
+	return t.impl.InvokableRun(ctx, argumentsInJSON, opts...)
+}
+
+// validateArguments 校验参数 JSON 是否满足工具的参数 Schema
+func validateArguments(sc *paramSchema, argumentsInJSON string) error {
+	if strings.TrimSpace(argumentsInJSON) == "" {
+		argumentsInJSON = "{}"
+	}
+	var args map[string]any
+	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
+		return fmt.Errorf("invalid argument: arguments must be a JSON object: %v", err)
+	}
+	return validateObject(sc, args, "")
+}
+
+func validateObject(sc *paramSchema, obj map[string]any, prefix string) error {
+	for _, name := range sc.Required {
+		if v, ok := obj[name]; !ok || v == nil {
+			return fmt.Errorf("invalid argument: field %s is required", prefix+name)
+		}
+	}
+	for name, v := range obj {
+		prop, ok := sc.Properties[name]
+		if !ok || v == nil {
+			continue
+		}
+		if err := validateValue(prop, v, prefix+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateValue(sc *paramSchema, v any, field string) error {
+	if !matchesType(sc.Type, v) {
+		return fmt.Errorf("invalid argument: field %s must be %s, got %s", field, sc.Type, jsonTypeName(v))
+	}
+	if len(sc.Enum) > 0 {
+		found := false
+		for _, e := range sc.Enum {
+			if e == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("invalid argument: field %s must be one of %v", field, sc.Enum)
+		}
+	}
+	switch val := v.(type) {
+	case []any:
+		if sc.Items != nil {
+			for i, item := range val {
+				if err := validateValue(sc.Items, item, fmt.Sprintf("%s[%d]", field, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]any:
+		if len(sc.Properties) > 0 || len(sc.Required) > 0 {
+			return validateObject(sc, val, field+".")
+		}
+	}
+	return nil
+}
+
+// argsTool 由解码参数的工具实现，返回 InvokableRun 解码参数所用的结构体指针，
+// 测试据此核对 Info 中声明的必填参数都有对应的 json 字段
+type argsTool interface {
+	newArgs() any
+}
+
+// decodeToolArgs 将参数 JSON 解码到 args（结构体指针）；参数本身的校验只由 ValidatedTool 负责
+func decodeToolArgs(argumentsInJSON string, args any) error {
+	if strings.TrimSpace(argumentsInJSON) == "" {
+		argumentsInJSON = "{}"
+	}
+	if err := json.Unmarshal([]byte(argumentsInJSON), args); err != nil {
+		return fmt.Errorf("invalid argument: %v", err)
+	}
+	return nil
+}
+
+func matchesType(typ string, v any) bool {
+	switch typ {
+	case "", "null":
+		return true
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	}
+	return true
+}
+
+func jsonTypeName(v any) string {
+	switch val := v.(type) {
+	case string:
+		return "string"
+	case float64:
+		if val == math.Trunc(val) {
+			return "integer"
+		}
+		return "number"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "null"
+}
@@ -0,0 +1,159 @@
+package agent
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+func inspectContainerTool(t *testing.T) tool.InvokableTool {
+	t.Helper()
+	wrapped, ok := wrapWithValidation(&InspectContainerTool{}).(tool.InvokableTool)
+	if !ok {
+		t.Fatalf("expected wrapped tool to be invokable")
+	}
+	return wrapped
+}
+
+func TestValidatedTool_MissingRequired(t *testing.T) {
+	out, err := inspectContainerTool(t).InvokableRun(context.Background(), `{}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res, err := ParseToolResult(out)
+	if err != nil {
+		t.Fatalf("decode result: %v\n%s", err, out)
+	}
+	if res.OK || res.Message != "invalid argument: field container_id is required" {
+		t.Fatalf("unexpected output: %s", out)
+	}
+}
+
+func TestValidatedTool_WrongType(t *testing.T) {
+	out, err := inspectContainerTool(t).InvokableRun(context.Background(), `{"container_id":123}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res, err := ParseToolResult(out)
+	if err != nil {
+		t.Fatalf("decode result: %v\n%s", err, out)
+	}
+	if res.OK || res.Message != "invalid argument: field container_id must be string, got integer" {
+		t.Fatalf("unexpected output: %s", out)
+	}
+}
+
+func TestDecodeToolArgs(t *testing.T) {
+	ctx := context.Background()
+
+	// 必填参数只由 ValidatedTool 校验，缺失时返回描述性的失败结果
+	out, err := wrapWithValidation(&RunContainerTool{}).(tool.InvokableTool).InvokableRun(ctx, `{"name":"web"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res, err := ParseToolResult(out)
+	if err != nil {
+		t.Fatalf("decode result: %v\n%s", err, out)
+	}
+	if res.OK || res.Message != "invalid argument: field image is required" {
+		t.Fatalf("unexpected output: %s", out)
+	}
+
+	var args runContainerArgs
+	if err := decodeToolArgs(`{"image":"nginx:alpine","cmd":["sh"]}`, &args); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if args.Image != "nginx:alpine" || len(args.Cmd) != 1 {
+		t.Fatalf("unexpected args: %+v", args)
+	}
+
+	// 字段来自嵌入结构体
+	var logsArgs getContainerLogsArgs
+	if err := decodeToolArgs(`{"container_id":"web","structured":true}`, &logsArgs); err != nil {
+		t.Fatalf("decode embedded: %v", err)
+	}
+	if logsArgs.ContainerID != "web" || !logsArgs.Structured {
+		t.Fatalf("unexpected args: %+v", logsArgs)
+	}
+
+	// 参数不是合法 JSON
+	err = decodeToolArgs(`{"image":`, &args)
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid argument:") {
+		t.Fatalf("expected invalid argument error, got %v", err)
+	}
+}
+
+// TestRegisteredTools_ArgsMatchInfo 检查每个注册的工具：Info 中声明的必填参数在其参数结构体中都有对应字段，
+// 否则 ValidatedTool 放行的参数会在解码时被静默丢弃
+func TestRegisteredTools_ArgsMatchInfo(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := storage.Open(ctx, storage.Config{Path: filepath.Join(t.TempDir(), "centagent-test.db")})
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	for _, bt := range registeredTools(store, ToolsConfig{}) {
+		info, err := bt.Info(ctx)
+		if err != nil {
+			t.Fatalf("tool info: %v", err)
+		}
+		sc, err := toParamSchema(info.ParamsOneOf)
+		if err != nil {
+			t.Fatalf("%s: %v", info.Name, err)
+		}
+		if sc == nil || len(sc.Required) == 0 {
+			continue
+		}
+		at, ok := bt.(argsTool)
+		if !ok {
+			t.Errorf("%s declares required parameters but does not expose its arguments", info.Name)
+			continue
+		}
+		fields := jsonFieldNames(reflect.TypeOf(at.newArgs()))
+		for _, name := range sc.Required {
+			if !fields[name] {
+				t.Errorf("%s declares required parameter %s but its arguments do not accept it", info.Name, name)
+			}
+		}
+	}
+}
+
+// jsonFieldNames 返回结构体（或其指针）可解码的 json 字段名，包含匿名嵌入结构体的字段
+func jsonFieldNames(rt reflect.Type) map[string]bool {
+	for rt != nil && rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	out := make(map[string]bool)
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return out
+	}
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			for n := range jsonFieldNames(f.Type) {
+				out[n] = true
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		out[name] = true
+	}
+	return out
+}
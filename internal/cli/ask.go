@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/wwwzy/CentAgent/internal/agent"
+	"github.com/wwwzy/CentAgent/internal/docker"
+	"github.com/wwwzy/CentAgent/internal/reactAgent"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+var askYes bool
+
+var askCmd = &cobra.Command{
+	Use:   "ask <query>",
+	Short: "单次提问，输出最终回答后退出",
+	Long: `以非交互方式运行一轮对话，将最终回答输出到 stdout，便于脚本与管道使用。
+只读工具会自动执行；启停容器、删除镜像等变更类操作默认拒绝，需显式传入 --yes。`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		query := strings.TrimSpace(strings.Join(args, " "))
+		if query == "" {
+			return fmt.Errorf("query 不能为空")
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigChan
+			cancel()
+		}()
+
+		if _, err := docker.GetClient(); err != nil {
+			return fmt.Errorf("连接 docker 失败: %w", err)
+		}
+
+		store, err := storage.Open(ctx, cfg.Storage)
+		if err != nil {
+			return fmt.Errorf("打开存储失败: %w", err)
+		}
+		defer store.Close()
+
+		systemPrompt, err := agent.LoadSystemPrompt(cfg.Agent.SystemPromptFile)
+		if err != nil {
+			return err
+		}
+
+		ra, err := reactAgent.BuildAgent(ctx, reactAgent.ArkConfig{
+			APIKey:  cfg.Ark.APIKey,
+			ModelID: cfg.Ark.ModelID,
+			BaseURL: cfg.Ark.BaseURL,
+		}, store, reactAgent.Options{
+			AllowMutating: askYes,
+			MaxStep:       cfg.Agent.MaxStep,
+			Tools:         cfg.Agent.Tools,
+			SystemPrompt:  systemPrompt,
+		})
+		if err != nil {
+			return fmt.Errorf("构建 Agent 失败: %w", err)
+		}
+
+		ctx = agent.WithTraceID(ctx, uuid.New().String())
+		input := reactAgent.NewMessageModifier(systemPrompt)(ctx, []*schema.Message{schema.UserMessage(query)})
+
+		// 内部日志写到 stderr，stdout 只包含最终回答
+		out, err := ra.Generate(ctx, input)
+		if err != nil {
+			return fmt.Errorf("执行失败: %w", err)
+		}
+
+		if outputJSON {
+			return writeJSON(os.Stdout, out)
+		}
+		fmt.Fprintln(os.Stdout, strings.TrimSpace(out.Content))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(askCmd)
+	askCmd.Flags().BoolVarP(&askYes, "yes", "y", false, "允许执行变更类工具（启停容器、删除镜像等）")
+}
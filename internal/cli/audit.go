@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+var (
+	auditAction string
+	auditStatus string
+	auditTrace  string
+	auditSince  string
+	auditLimit  int
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "查看操作审计记录",
+	Long: `列出 Agent 调用工具产生的审计记录（最新的在前），包括动作、状态、耗时与错误信息。
+使用 audit show <id> 查看单条记录的完整参数与结果。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		q := storage.AuditQuery{
+			Action:  strings.TrimSpace(auditAction),
+			Status:  strings.TrimSpace(auditStatus),
+			TraceID: strings.TrimSpace(auditTrace),
+			Limit:   auditLimit,
+			Desc:    true,
+		}
+		if auditSince != "" {
+			from, err := parseTimeFlag(auditSince, time.Now().UTC())
+			if err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+			q.From = &from
+		}
+
+		store, err := storage.Open(ctx, cfg.Storage)
+		if err != nil {
+			return fmt.Errorf("打开存储失败: %w", err)
+		}
+		defer store.Close()
+
+		records, err := store.QueryAuditRecords(ctx, q)
+		if err != nil {
+			return fmt.Errorf("查询审计记录失败: %w", err)
+		}
+
+		if outputJSON {
+			return writeJSON(os.Stdout, records)
+		}
+		return printAuditTable(os.Stdout, records)
+	},
+}
+
+var auditShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "显示单条审计记录的完整参数与结果",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		id, err := strconv.ParseUint(strings.TrimSpace(args[0]), 10, 64)
+		if err != nil {
+			return fmt.Errorf("无效的记录 ID: %s", args[0])
+		}
+
+		store, err := storage.Open(ctx, cfg.Storage)
+		if err != nil {
+			return fmt.Errorf("打开存储失败: %w", err)
+		}
+		defer store.Close()
+
+		rec, err := store.GetAuditRecord(ctx, id)
+		if err != nil {
+			return fmt.Errorf("读取审计记录失败: %w", err)
+		}
+
+		if outputJSON {
+			return writeJSON(os.Stdout, rec)
+		}
+		printAuditRecord(os.Stdout, rec)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditShowCmd)
+	auditCmd.Flags().StringVar(&auditAction, "action", "", "按动作（工具名）过滤，例如 restart_container")
+	auditCmd.Flags().StringVar(&auditStatus, "status", "", "按状态过滤：running/success/failed")
+	auditCmd.Flags().StringVar(&auditTrace, "trace", "", "按 trace ID 过滤")
+	auditCmd.Flags().StringVar(&auditSince, "since", "", "起始时间，RFC3339 或相对时长（如 24h）")
+	auditCmd.Flags().IntVar(&auditLimit, "limit", 50, "最多返回的记录条数")
+}
+
+func printAuditTable(out io.Writer, records []storage.AuditRecord) error {
+	if len(records) == 0 {
+		fmt.Fprintln(out, "没有找到审计记录。")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ID\tTIME\tACTION\tSTATUS\tDURATION\tERROR")
+	for _, r := range records {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			r.ID,
+			auditTime(r).Local().Format("2006-01-02 15:04:05"),
+			r.Action,
+			r.Status,
+			auditDuration(r),
+			truncateLine(r.ErrorMessage, 60),
+		)
+	}
+	return w.Flush()
+}
+
+func printAuditRecord(out io.Writer, r *storage.AuditRecord) {
+	fmt.Fprintf(out, "ID:        %d\n", r.ID)
+	fmt.Fprintf(out, "Trace ID:  %s\n", r.TraceID)
+	fmt.Fprintf(out, "Action:    %s\n", r.Action)
+	fmt.Fprintf(out, "Status:    %s\n", r.Status)
+	fmt.Fprintf(out, "Started:   %s\n", formatAuditTime(r.StartedAt))
+	fmt.Fprintf(out, "Finished:  %s\n", formatAuditTime(r.FinishedAt))
+	fmt.Fprintf(out, "Duration:  %s\n", auditDuration(*r))
+	if r.ErrorMessage != "" {
+		fmt.Fprintf(out, "Error:     %s\n", r.ErrorMessage)
+	}
+	fmt.Fprintf(out, "\nParams:\n%s\n", indentJSON(r.ParamsJSON))
+	fmt.Fprintf(out, "\nResult:\n%s\n", indentJSON(r.ResultJSON))
+}
+
+func auditTime(r storage.AuditRecord) time.Time {
+	if !r.StartedAt.IsZero() {
+		return r.StartedAt
+	}
+	return r.CreatedAt
+}
+
+func auditDuration(r storage.AuditRecord) string {
+	if r.StartedAt.IsZero() || r.FinishedAt.IsZero() {
+		return "-"
+	}
+	return r.FinishedAt.Sub(r.StartedAt).Round(time.Millisecond).String()
+}
+
+func formatAuditTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+// indentJSON 格式化 JSON 字符串；非 JSON 内容原样返回
+func indentJSON(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "(empty)"
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(s), "", "  "); err != nil {
+		return s
+	}
+	return buf.String()
+}
+
+// truncateLine 将内容压缩为单行并截断到 n 个字符
+func truncateLine(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "..."
+}
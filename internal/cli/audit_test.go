@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+func TestPrintAuditTable(t *testing.T) {
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	records := []storage.AuditRecord{
+		{
+			ID:           7,
+			Action:       "restart_container",
+			Status:       "failed",
+			ErrorMessage: "container not found:\n abc",
+			StartedAt:    started,
+			FinishedAt:   started.Add(1500 * time.Millisecond),
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := printAuditTable(&buf, records); err != nil {
+		t.Fatalf("print table: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"ACTION", "restart_container", "failed", "1.5s", "container not found: abc"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
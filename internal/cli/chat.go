@@ -7,9 +7,12 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/cloudwego/eino/compose"
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 	"github.com/wwwzy/CentAgent/internal/agent"
 	"github.com/wwwzy/CentAgent/internal/docker"
+	"github.com/wwwzy/CentAgent/internal/logging"
 	"github.com/wwwzy/CentAgent/internal/storage"
 	"github.com/wwwzy/CentAgent/internal/tui"
 	"github.com/wwwzy/CentAgent/internal/ui"
@@ -17,6 +20,9 @@ import (
 
 var chatConfirmTools bool
 var chatUI string
+var chatDryRun bool
+var chatTranscript string
+var chatResume string
 
 var chatCmd = &cobra.Command{
 	Use:   "chat",
@@ -44,11 +50,26 @@ var chatCmd = &cobra.Command{
 		}
 		defer store.Close()
 
-		runnable, err := agent.BuildGraph(ctx, cfg.Ark, store)
+		runnable, err := agent.BuildGraph(ctx, cfg.Ark, cfg.Agent, store)
 		if err != nil {
 			return fmt.Errorf("构建 Agent Graph 失败: %w", err)
 		}
 
+		initial := ui.DefaultInitialState()
+		sessionID := chatResume
+		if sessionID != "" {
+			messages, err := agent.LoadSession(ctx, store, sessionID)
+			if err != nil {
+				return fmt.Errorf("恢复会话失败: %w", err)
+			}
+			initial.Messages = messages
+			fmt.Fprintf(cmd.ErrOrStderr(), "已恢复会话 %s (%d 条消息)\n", sessionID, len(messages))
+		} else {
+			sessionID = uuid.New().String()
+			fmt.Fprintf(cmd.ErrOrStderr(), "会话 ID: %s (使用 --resume %s 恢复)\n", sessionID, sessionID)
+		}
+		backend := &sessionBackend{backend: runnable, store: store, id: sessionID}
+
 		var uiImpl ui.ChatUI
 		switch chatUI {
 		case "console", "":
@@ -59,8 +80,10 @@ var chatCmd = &cobra.Command{
 			return fmt.Errorf("未知 ui 类型: %s (支持: console, tui)", chatUI)
 		}
 
-		return uiImpl.Run(ctx, runnable, ui.DefaultInitialState(), ui.ChatOptions{
-			ConfirmTools: chatConfirmTools,
+		return uiImpl.Run(ctx, backend, initial, ui.ChatOptions{
+			ConfirmTools:   chatConfirmTools,
+			DryRun:         chatDryRun,
+			TranscriptPath: chatTranscript,
 		})
 	},
 }
@@ -68,5 +91,26 @@ var chatCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(chatCmd)
 	chatCmd.Flags().BoolVar(&chatConfirmTools, "confirm-tools", true, "工具调用前询问确认")
+	chatCmd.Flags().BoolVar(&chatDryRun, "dry-run", false, "变更类工具只返回将要执行的 Docker API 调用，不真正执行")
+	chatCmd.Flags().StringVar(&chatTranscript, "transcript", "", "退出时将会话记录保存到指定文件 (.json 为 JSON，否则为 Markdown)")
+	chatCmd.Flags().StringVar(&chatResume, "resume", "", "恢复指定 ID 的历史会话，并继续保存到该会话")
 	chatCmd.Flags().StringVar(&chatUI, "ui", "console", "交互界面类型: console/tui")
 }
+
+// sessionBackend 在每轮调用后将会话消息保存到存储，供 chat --resume 恢复；保存失败只记录日志
+type sessionBackend struct {
+	backend ui.ChatBackend
+	store   *storage.Storage
+	id      string
+}
+
+func (b *sessionBackend) Invoke(ctx context.Context, state agent.AgentState, opts ...compose.Option) (agent.AgentState, error) {
+	next, err := b.backend.Invoke(ctx, state, opts...)
+	if err != nil {
+		return next, err
+	}
+	if err := agent.SaveSession(context.WithoutCancel(ctx), b.store, b.id, next.Messages); err != nil {
+		logging.L().Warn("save chat session failed", "session", b.id, "err", err)
+	}
+	return next, nil
+}
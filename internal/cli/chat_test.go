@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/wwwzy/CentAgent/internal/agent"
+	"github.com/wwwzy/CentAgent/internal/config"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+// TestChat_StatsToolReachable 验证 chat 命令使用的 storage 能让历史 stats 工具正常工作
+// 与 chat 命令一致：从 cfg.Storage 打开数据库，并按 cfg.Agent.Tools 构建工具列表
+func TestChat_StatsToolReachable(t *testing.T) {
+	ctx := context.Background()
+
+	c := config.DefaultConfig()
+	c.Storage.Path = filepath.Join(t.TempDir(), "centagent.db")
+
+	store, err := storage.Open(ctx, c.Storage)
+	if err != nil {
+		t.Skipf("storage unavailable: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.InsertContainerStat(ctx, &storage.ContainerStat{
+		ContainerID:   "cid-chat",
+		ContainerName: "nginx-chat",
+		CPUPercent:    12.5,
+		CollectedAt:   time.Now().UTC().Add(-time.Minute),
+	}); err != nil {
+		t.Fatalf("insert stat: %v", err)
+	}
+
+	var statsTool tool.InvokableTool
+	for _, bt := range agent.GetTools(store, c.Agent.Tools) {
+		info, err := bt.Info(ctx)
+		if err != nil {
+			t.Fatalf("tool info: %v", err)
+		}
+		if info.Name == "query_container_stats" {
+			statsTool = bt.(tool.InvokableTool)
+		}
+	}
+	if statsTool == nil {
+		t.Fatalf("query_container_stats should be available when storage is opened")
+	}
+
+	out, err := statsTool.InvokableRun(ctx, `{"container_name":"nginx-chat","from":"10m"}`)
+	if err != nil {
+		t.Fatalf("invoke stats tool: %v", err)
+	}
+	res, err := agent.ParseToolResult(out)
+	if err != nil || !res.OK {
+		t.Fatalf("decode tool result: %v (out=%s)", err, out)
+	}
+	var stats []storage.ContainerStat
+	if err := json.Unmarshal(res.Data, &stats); err != nil {
+		t.Fatalf("decode stats: %v", err)
+	}
+	if len(stats) != 1 || !strings.EqualFold(stats[0].ContainerID, "cid-chat") {
+		t.Fatalf("unexpected stats result: %s", out)
+	}
+}
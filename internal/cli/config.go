@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wwwzy/CentAgent/internal/config"
+	"go.yaml.in/yaml/v3"
+)
+
+// skipConfigLoadAnnotation 标记的命令不在启动时加载与校验配置，由命令自行处理
+const skipConfigLoadAnnotation = "centagent/skip-config-load"
+
+var configCmd = &cobra.Command{
+	Use:         "config",
+	Short:       "查看与校验配置",
+	Long:        `显示实际生效的配置（配置文件 + 环境变量 + 默认值），或检查配置中的问题。`,
+	Annotations: map[string]string{skipConfigLoadAnnotation: "true"},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "以 YAML 显示实际生效的配置（api_key 已打码）",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		loaded, err := config.LoadUnvalidated(cfgFile)
+		if err != nil {
+			return err
+		}
+		return writeConfigShow(os.Stdout, loaded)
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "检查配置并报告问题",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		loaded, err := config.LoadUnvalidated(cfgFile)
+		if err != nil {
+			return err
+		}
+		problems := loaded.Config.Problems()
+		writeConfigProblems(os.Stdout, loaded, problems)
+		if len(problems) > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("配置存在 %d 个问题", len(problems))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configValidateCmd)
+}
+
+func writeConfigShow(out io.Writer, loaded *config.Loaded) error {
+	settings := loaded.MaskedSettings()
+	if outputJSON {
+		return writeJSON(out, settings)
+	}
+
+	fmt.Fprintf(out, "# 配置文件: %s\n", configFileLabel(loaded))
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(settings); err != nil {
+		return fmt.Errorf("encode yaml: %w", err)
+	}
+	return enc.Close()
+}
+
+func writeConfigProblems(out io.Writer, loaded *config.Loaded, problems []string) {
+	fmt.Fprintf(out, "配置文件: %s\n", configFileLabel(loaded))
+	if len(problems) == 0 {
+		fmt.Fprintln(out, "配置有效。")
+		return
+	}
+	for _, p := range problems {
+		fmt.Fprintf(out, "  ✗ %s\n", p)
+	}
+}
+
+func configFileLabel(loaded *config.Loaded) string {
+	if loaded.File == "" {
+		return "(未找到，使用默认值与环境变量)"
+	}
+	return loaded.File
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wwwzy/CentAgent/internal/config"
+)
+
+func TestConfigShowAndValidate(t *testing.T) {
+	t.Setenv("ARK_API_KEY", "")
+	t.Setenv("ARK_MODEL_ID", "")
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := []byte(`
+ark:
+  api_key: "sk-secret-value-9876"
+storage:
+  path: "show.db"
+`)
+	if err := os.WriteFile(configFile, content, 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	loaded, err := config.LoadUnvalidated(configFile)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	var show bytes.Buffer
+	if err := writeConfigShow(&show, loaded); err != nil {
+		t.Fatalf("config show: %v", err)
+	}
+	out := show.String()
+	if strings.Contains(out, "sk-secret-value-9876") {
+		t.Fatalf("api_key should be masked:\n%s", out)
+	}
+	for _, want := range []string{configFile, "****9876", "path: show.db"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	var validate bytes.Buffer
+	problems := loaded.Config.Problems()
+	writeConfigProblems(&validate, loaded, problems)
+	if len(problems) != 1 || !strings.Contains(validate.String(), "ark.model_id is required") {
+		t.Fatalf("unexpected validate output:\n%s", validate.String())
+	}
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wwwzy/CentAgent/internal/docker"
+)
+
+// completionTimeout 为补全时查询 Docker 的超时，避免 shell 补全长时间卡住
+const completionTimeout = 2 * time.Second
+
+var (
+	containersAll    bool
+	containersStatus string
+	containersName   string
+)
+
+var containersCmd = &cobra.Command{
+	Use:     "containers",
+	Aliases: []string{"ps"},
+	Short:   "列出 Docker 容器",
+	Long: `直接从 Docker 读取容器列表，输出 ID、名称、镜像、状态等信息，效果类似 docker ps。
+--status 与 --name 由 Docker 服务端过滤；指定 --status 时会包含已停止的容器。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := docker.GetClient(); err != nil {
+			return fmt.Errorf("连接 docker 失败: %w", err)
+		}
+		containers, err := docker.ListContainers(cmd.Context(), docker.ListContainersOptions{
+			All:    containersAll,
+			Status: containersStatus,
+			Name:   containersName,
+		})
+		if err != nil {
+			return err
+		}
+		if outputJSON {
+			if containers == nil {
+				containers = []docker.ContainerSummary{}
+			}
+			return writeJSON(os.Stdout, containers)
+		}
+		return printContainers(os.Stdout, containers)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(containersCmd)
+	containersCmd.Flags().BoolVarP(&containersAll, "all", "a", false, "包含已停止的容器")
+	containersCmd.Flags().StringVar(&containersStatus, "status", "", "按状态过滤：created/running/paused/restarting/exited/dead")
+	containersCmd.Flags().StringVar(&containersName, "name", "", "按名称过滤（子串匹配）")
+	_ = containersCmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions(
+		[]string{"created", "running", "paused", "restarting", "exited", "dead"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = containersCmd.RegisterFlagCompletionFunc("name", completeContainerNames)
+
+	// 以容器为参数的命令补全容器名称
+	logsCmd.ValidArgsFunction = completeContainerNames
+	statsCmd.ValidArgsFunction = completeContainerNames
+}
+
+func printContainers(out io.Writer, containers []docker.ContainerSummary) error {
+	if len(containers) == 0 {
+		fmt.Fprintln(out, "没有找到容器。")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CONTAINER ID\tNAME\tIMAGE\tSTATE\tSTATUS")
+	for _, c := range containers {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			docker.ShortID(c.ID),
+			containerDisplayName(c.Names),
+			truncateLine(c.Image, 40),
+			c.State,
+			c.Status,
+		)
+	}
+	return w.Flush()
+}
+
+// containerDisplayName 将 Docker 返回的 "/a,/b" 形式的名称列表转换为不带前导 / 的名称
+func containerDisplayName(names string) string {
+	parts := strings.Split(names, ",")
+	for i, p := range parts {
+		parts[i] = strings.TrimPrefix(p, "/")
+	}
+	return strings.Join(parts, ",")
+}
+
+// completeContainerNames 为 shell 补全提供容器名称（包含已停止的容器）；只补全第一个位置参数
+func completeContainerNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	containers, err := docker.ListContainers(ctx, docker.ListContainersOptions{All: true})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return matchContainerNames(containers, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// matchContainerNames 返回以 prefix 开头的容器名称，每个容器可能有多个名称
+func matchContainerNames(containers []docker.ContainerSummary, prefix string) []string {
+	var out []string
+	for _, c := range containers {
+		for _, name := range strings.Split(containerDisplayName(c.Names), ",") {
+			if name != "" && strings.HasPrefix(name, prefix) {
+				out = append(out, name)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wwwzy/CentAgent/internal/docker"
+)
+
+func TestPrintContainers(t *testing.T) {
+	var buf bytes.Buffer
+	err := printContainers(&buf, []docker.ContainerSummary{
+		{ID: "0123456789abcdef", Names: "/web", Image: "nginx:alpine", State: "running", Status: "Up 2 hours"},
+		{ID: "fedcba987654", Names: "/db,/db-alias", Image: "postgres:16", State: "exited", Status: "Exited (0) 3 minutes ago"},
+	})
+	if err != nil {
+		t.Fatalf("print containers: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "CONTAINER ID") {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	if !strings.Contains(lines[1], "0123456789ab") || strings.Contains(lines[1], "0123456789abc") || !strings.Contains(lines[1], " web ") {
+		t.Fatalf("unexpected first row: %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "db,db-alias") || !strings.Contains(lines[2], "exited") {
+		t.Fatalf("unexpected second row: %q", lines[2])
+	}
+
+	buf.Reset()
+	if err := printContainers(&buf, nil); err != nil || !strings.Contains(buf.String(), "没有找到容器") {
+		t.Fatalf("unexpected empty output %q (err=%v)", buf.String(), err)
+	}
+}
+
+func TestMatchContainerNames(t *testing.T) {
+	containers := []docker.ContainerSummary{
+		{Names: "/web"},
+		{Names: "/worker,/jobs"},
+		{Names: "/db"},
+	}
+	if got := matchContainerNames(containers, "w"); !reflect.DeepEqual(got, []string{"web", "worker"}) {
+		t.Fatalf("unexpected matches: %v", got)
+	}
+	if got := matchContainerNames(containers, ""); len(got) != 4 {
+		t.Fatalf("expected all names for empty prefix, got %v", got)
+	}
+}
@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/wwwzy/CentAgent/internal/monitor"
+
+	"github.com/spf13/cobra"
+)
+
+// controlTimeout 为控制接口单次请求的超时；prune 可能耗时较长，单独放宽
+const (
+	controlTimeout      = 5 * time.Second
+	controlPruneTimeout = 10 * time.Minute
+)
+
+// controlSocketFlag 为 --control-socket 参数，优先于 daemon.control_socket
+var controlSocketFlag string
+
+// controlSocketPath 返回控制接口的 socket 路径，命令行参数优先于配置
+func controlSocketPath() string {
+	if controlSocketFlag != "" {
+		return controlSocketFlag
+	}
+	return cfg.Daemon.ControlSocket
+}
+
+// controlResult 为 pause/resume/prune 的响应
+type controlResult struct {
+	OK      bool   `json:"ok"`
+	Changed bool   `json:"changed"`
+	Message string `json:"message,omitempty"`
+}
+
+// newControlHandler 返回控制接口的 HTTP 处理器：GET /status、POST /pause、POST /resume、POST /prune
+func newControlHandler(mgr *monitor.Manager) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusOK, mgr.Status())
+	})
+	mux.HandleFunc("POST /pause", func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusOK, controlResult{OK: true, Changed: mgr.Pause()})
+	})
+	mux.HandleFunc("POST /resume", func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusOK, controlResult{OK: true, Changed: mgr.Resume()})
+	})
+	mux.HandleFunc("POST /prune", func(w http.ResponseWriter, r *http.Request) {
+		if err := mgr.Prune(r.Context()); err != nil {
+			writeJSONResponse(w, http.StatusInternalServerError, controlResult{Message: err.Error()})
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, controlResult{OK: true, Changed: true})
+	})
+	return mux
+}
+
+func writeJSONResponse(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// startControlServer 在 unix socket 上启动控制接口，socket 权限为 0600 仅当前用户可访问；
+// 返回的 stop 关闭监听并删除 socket 文件。
+func startControlServer(path string, mgr *monitor.Manager) (stop func(), err error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("监听控制接口失败: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("设置控制接口权限失败: %w", err)
+	}
+
+	srv := &http.Server{Handler: newControlHandler(mgr), ReadHeaderTimeout: controlTimeout}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("控制接口已停止: %v\n", err)
+		}
+	}()
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	}, nil
+}
+
+// removeStaleSocket 删除上次异常退出遗留的 socket 文件；仍有进程在监听时返回错误
+func removeStaleSocket(path string) error {
+	fi, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("检查控制接口 socket 失败: %w", err)
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("控制接口路径已存在且不是 socket: %s", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("控制接口 %s 已被其他 centagent 进程使用", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("删除遗留的控制接口 socket 失败: %w", err)
+	}
+	return nil
+}
+
+// controlRequest 通过 unix socket 调用控制接口，并将 JSON 响应解码到 out
+func controlRequest(ctx context.Context, path, method, endpoint string, out any) error {
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+	req, err := http.NewRequestWithContext(ctx, method, "http://centagent"+endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("连接控制接口 %s 失败（centagent start 是否在运行？）: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取控制接口响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var res controlResult
+		if json.Unmarshal(body, &res) == nil && res.Message != "" {
+			return errors.New(res.Message)
+		}
+		return fmt.Errorf("控制接口返回 %s", resp.Status)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("解析控制接口响应失败: %w", err)
+	}
+	return nil
+}
+
+// runControl 执行 ctl 子命令并输出结果
+func runControl(ctx context.Context, out io.Writer, path, action string) error {
+	if path == "" {
+		return errors.New("未配置控制接口（daemon.control_socket 为空）")
+	}
+	if action == "status" {
+		ctx, cancel := context.WithTimeout(ctx, controlTimeout)
+		defer cancel()
+		var st monitor.Status
+		if err := controlRequest(ctx, path, http.MethodGet, "/status", &st); err != nil {
+			return err
+		}
+		if outputJSON {
+			return writeJSON(out, st)
+		}
+		printControlStatus(out, st)
+		return nil
+	}
+
+	timeout := controlTimeout
+	if action == "prune" {
+		timeout = controlPruneTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var res controlResult
+	if err := controlRequest(ctx, path, http.MethodPost, "/"+action, &res); err != nil {
+		return err
+	}
+	if outputJSON {
+		return writeJSON(out, res)
+	}
+	switch {
+	case action == "pause" && res.Changed:
+		fmt.Fprintln(out, "已暂停采集。")
+	case action == "pause":
+		fmt.Fprintln(out, "采集已处于暂停状态。")
+	case action == "resume" && res.Changed:
+		fmt.Fprintln(out, "已恢复采集。")
+	case action == "resume":
+		fmt.Fprintln(out, "采集未暂停。")
+	case action == "prune":
+		fmt.Fprintln(out, "清理完成。")
+	}
+	return nil
+}
+
+func printControlStatus(out io.Writer, st monitor.Status) {
+	state := "运行中"
+	if st.Paused {
+		state = "已暂停"
+	}
+	fmt.Fprintf(out, "采集状态: %s\n", state)
+	for _, c := range st.Collectors {
+		s := "未启用"
+		switch {
+		case c.Running:
+			s = "运行中"
+		case c.Error != "":
+			s = "已退出: " + c.Error
+		case c.Enabled:
+			s = "已停止"
+		}
+		fmt.Fprintf(out, "  %-10s %s\n", c.Name, s)
+	}
+	fmt.Fprintf(out, "日志队列: %d/%d，跟随容器数: %d\n", st.LogQueue.Len, st.LogQueue.Cap, st.LogTailers)
+	fmt.Fprintf(out, "stats 流连接数: %d\n", st.StatsStreams)
+	if st.LastPrune != nil {
+		fmt.Fprintf(out, "最近清理: %s\n", st.LastPrune.Local().Format("2006-01-02 15:04:05"))
+	}
+}
+
+// ctlCmd 代表 ctl 命令
+var ctlCmd = &cobra.Command{
+	Use:       "ctl <status|pause|resume|prune>",
+	Short:     "与运行中的 CentAgent 监控服务交互",
+	Long:      `通过本地控制接口（daemon.control_socket）查看采集器状态与队列深度、暂停/恢复采集，或立即执行一次保留策略清理，无需重启服务。`,
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"status", "pause", "resume", "prune"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runControl(cmd.Context(), cmd.OutOrStdout(), controlSocketPath(), args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ctlCmd)
+	for _, c := range []*cobra.Command{startCmd, ctlCmd} {
+		c.Flags().StringVar(&controlSocketFlag, "control-socket", "", "控制接口 socket 路径（默认读取 daemon.control_socket）")
+	}
+}
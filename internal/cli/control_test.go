@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wwwzy/CentAgent/internal/config"
+	"github.com/wwwzy/CentAgent/internal/monitor"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+func TestControlSocketPauseResumeAndPrune(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := config.DefaultConfig()
+	c.Storage.InMemory = true
+	store, err := storage.Open(ctx, c.Storage)
+	if err != nil {
+		t.Skipf("storage unavailable: %v", err)
+	}
+	defer store.Close()
+
+	// 不启用任何周期采集，只验证控制接口本身
+	mcfg := c.Monitor
+	mcfg.Stats.Enabled, mcfg.Logs.Enabled, mcfg.Retention.Enabled, mcfg.Host.Enabled, mcfg.Alert.Enabled = false, false, false, false, false
+	mgr, err := monitor.NewManager(mcfg)
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	ret, err := monitor.NewRetentionCollector(store)
+	if err != nil {
+		t.Fatalf("new retention collector: %v", err)
+	}
+	mgr.WithRetention(ret)
+	if err := mgr.Start(ctx); err != nil {
+		t.Fatalf("start manager: %v", err)
+	}
+	defer mgr.Stop()
+
+	// unix socket 路径长度有限，不使用较长的 t.TempDir
+	dir, err := os.MkdirTemp("", "ca-ctl")
+	if err != nil {
+		t.Fatalf("mkdir temp: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ctl.sock")
+
+	stop, err := startControlServer(path, mgr)
+	if err != nil {
+		t.Skipf("unix socket unavailable: %v", err)
+	}
+	defer stop()
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0600 {
+		t.Fatalf("expected socket mode 0600, got %o", perm)
+	}
+	if _, err := startControlServer(path, mgr); err == nil {
+		t.Fatalf("expected second server on the same socket to fail")
+	}
+
+	prev := outputJSON
+	t.Cleanup(func() { outputJSON = prev })
+	outputJSON = false
+
+	var out bytes.Buffer
+	if err := runControl(ctx, &out, path, "pause"); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	if !strings.Contains(out.String(), "已暂停") || !mgr.Status().Paused {
+		t.Fatalf("expected manager to be paused, output: %s", out.String())
+	}
+
+	var st monitor.Status
+	if err := controlRequest(ctx, path, http.MethodGet, "/status", &st); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !st.Paused || len(st.Collectors) != 4 {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+
+	out.Reset()
+	if err := runControl(ctx, &out, path, "resume"); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if mgr.Status().Paused {
+		t.Fatalf("expected manager to be resumed, output: %s", out.String())
+	}
+
+	if err := runControl(ctx, &out, path, "prune"); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	out.Reset()
+	if err := runControl(ctx, &out, path, "status"); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !strings.Contains(out.String(), "运行中") || !strings.Contains(out.String(), "最近清理") {
+		t.Fatalf("unexpected status output:\n%s", out.String())
+	}
+}
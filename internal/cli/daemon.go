@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// daemonChildEnv 标记当前进程为 start --daemon 派生的后台进程
+const daemonChildEnv = "CENTAGENT_DAEMON_CHILD"
+
+// isDaemonChild 判断当前进程是否为后台子进程
+func isDaemonChild() bool {
+	return os.Getenv(daemonChildEnv) == "1"
+}
+
+// startDaemon 以相同参数重新执行当前程序，新进程脱离终端运行，输出重定向到 logFile，并写入 PID 文件
+func startDaemon(pidFile, logFile string) (int, error) {
+	if pid, err := readPIDFile(pidFile); err == nil && processAlive(pid) {
+		return 0, fmt.Errorf("centagent 已在运行 (PID %d，PID 文件 %s)", pid, pidFile)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("获取可执行文件路径失败: %w", err)
+	}
+
+	out, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("打开日志文件失败: %w", err)
+	}
+	defer out.Close()
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), daemonChildEnv+"=1")
+	cmd.Stdin = nil
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.SysProcAttr = daemonSysProcAttr()
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("启动后台进程失败: %w", err)
+	}
+
+	pid := cmd.Process.Pid
+	if err := writePIDFile(pidFile, pid); err != nil {
+		_ = cmd.Process.Kill()
+		return 0, err
+	}
+	// 不等待子进程，释放相关资源
+	_ = cmd.Process.Release()
+	return pid, nil
+}
+
+func writePIDFile(path string, pid int) error {
+	if err := os.WriteFile(path, []byte(strconv.Itoa(pid)+"\n"), 0644); err != nil {
+		return fmt.Errorf("写入 PID 文件失败: %w", err)
+	}
+	return nil
+}
+
+func readPIDFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("读取 PID 文件失败: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("PID 文件内容无效: %s", path)
+	}
+	return pid, nil
+}
+
+// removeOwnPIDFile 删除 PID 文件，仅当其中记录的是当前进程时才删除
+func removeOwnPIDFile(path string) {
+	if pid, err := readPIDFile(path); err == nil && pid == os.Getpid() {
+		_ = os.Remove(path)
+	}
+}
+
+// stopDaemon 向 PID 文件记录的进程发送停止信号，并等待其退出
+func stopDaemon(pidFile string, timeout time.Duration) (int, error) {
+	pid, err := readPIDFile(pidFile)
+	if err != nil {
+		return 0, err
+	}
+	if !processAlive(pid) {
+		_ = os.Remove(pidFile)
+		return pid, fmt.Errorf("进程 %d 未在运行，已清理 PID 文件", pid)
+	}
+
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return pid, fmt.Errorf("查找进程 %d 失败: %w", pid, err)
+	}
+	if err := terminateProcess(proc); err != nil {
+		return pid, fmt.Errorf("向进程 %d 发送停止信号失败: %w", pid, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			return pid, errors.New("等待进程退出超时")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	_ = os.Remove(pidFile)
+	return pid, nil
+}
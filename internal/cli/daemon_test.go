@@ -0,0 +1,76 @@
+//go:build !windows
+
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPIDFileRoundtrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "centagent.pid")
+	if err := writePIDFile(path, 4321); err != nil {
+		t.Fatalf("write pid file: %v", err)
+	}
+	pid, err := readPIDFile(path)
+	if err != nil {
+		t.Fatalf("read pid file: %v", err)
+	}
+	if pid != 4321 {
+		t.Fatalf("expected pid 4321, got %d", pid)
+	}
+
+	if err := os.WriteFile(path, []byte("not-a-pid"), 0644); err != nil {
+		t.Fatalf("write invalid pid file: %v", err)
+	}
+	if _, err := readPIDFile(path); err == nil {
+		t.Fatalf("expected error for invalid pid file")
+	}
+}
+
+func TestStopDaemonSignalsProcess(t *testing.T) {
+	sleepBin, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skipf("sleep unavailable: %v", err)
+	}
+	cmd := exec.Command(sleepBin, "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start dummy process: %v", err)
+	}
+	// 回收子进程，避免僵尸进程让 processAlive 一直返回 true
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	path := filepath.Join(t.TempDir(), "centagent.pid")
+	if err := writePIDFile(path, cmd.Process.Pid); err != nil {
+		t.Fatalf("write pid file: %v", err)
+	}
+
+	pid, err := stopDaemon(path, 5*time.Second)
+	if err != nil {
+		t.Fatalf("stop daemon: %v", err)
+	}
+	if pid != cmd.Process.Pid {
+		t.Fatalf("expected pid %d, got %d", cmd.Process.Pid, pid)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("dummy process did not exit")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected pid file to be removed, stat err=%v", err)
+	}
+
+	// 进程已退出时 stop 应报告错误并清理 PID 文件
+	if err := writePIDFile(path, cmd.Process.Pid); err != nil {
+		t.Fatalf("write pid file: %v", err)
+	}
+	if _, err := stopDaemon(path, time.Second); err == nil {
+		t.Fatalf("expected error for stale pid file")
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wwwzy/CentAgent/internal/storage"
+	"github.com/wwwzy/CentAgent/internal/tui"
+)
+
+var (
+	dashboardInterval time.Duration
+	dashboardWindow   time.Duration
+)
+
+var dashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Short: "实时监控面板（TUI）",
+	Long: `打开全屏监控面板，定时刷新显示已监控容器的 CPU/内存迷你趋势图、窗口内的错误日志数与健康状态。
+数据来自本地存储（需 centagent start 开启 stats 采集），健康状态从 Docker 读取，Docker 不可用时显示 "-"。
+↑/↓ 选择容器，Enter 查看其最近日志，q 退出。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if dashboardInterval <= 0 {
+			return fmt.Errorf("--interval 必须大于 0")
+		}
+		if dashboardWindow <= 0 {
+			return fmt.Errorf("--window 必须大于 0")
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigChan
+			cancel()
+		}()
+
+		store, err := storage.Open(ctx, cfg.Storage)
+		if err != nil {
+			return fmt.Errorf("打开存储失败: %w", err)
+		}
+		defer store.Close()
+
+		return (&tui.DashboardUI{Store: store}).Run(ctx, tui.DashboardOptions{
+			Interval: dashboardInterval,
+			Window:   dashboardWindow,
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dashboardCmd)
+	dashboardCmd.Flags().DurationVar(&dashboardInterval, "interval", 5*time.Second, "刷新间隔")
+	dashboardCmd.Flags().DurationVar(&dashboardWindow, "window", 30*time.Minute, "趋势图与错误计数覆盖的时间窗口")
+}
@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/spf13/cobra"
+	"github.com/wwwzy/CentAgent/internal/docker"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+// eventsReconnectDelay 为事件流断开后的重连间隔
+const eventsReconnectDelay = 2 * time.Second
+
+var (
+	eventsSince   string
+	eventsUntil   string
+	eventsFilters []string
+	eventsPersist bool
+)
+
+var eventTypeStyles = map[events.Type]lipgloss.Style{
+	events.ContainerEventType: lipgloss.NewStyle().Foreground(lipgloss.Color("39")),
+	events.ImageEventType:     lipgloss.NewStyle().Foreground(lipgloss.Color("213")),
+	events.NetworkEventType:   lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+	events.VolumeEventType:    lipgloss.NewStyle().Foreground(lipgloss.Color("42")),
+}
+
+var (
+	eventTimeStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	eventDangerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "实时输出 Docker 事件流",
+	Long: `订阅 Docker 事件并输出容器/镜像/网络/卷的动作，效果类似 docker events。
+--since/--until 支持 RFC3339 时间或相对时长（如 10m），用于回放历史事件；
+--filter 使用 key=value 形式，例如 --filter type=container --filter event=die。
+Docker daemon 重启导致事件流断开时会自动重连。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigChan
+			cancel()
+		}()
+
+		opts, err := buildEventsOptions(eventsSince, eventsUntil, eventsFilters, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		var store *storage.Storage
+		if eventsPersist {
+			store, err = storage.Open(ctx, cfg.Storage)
+			if err != nil {
+				return fmt.Errorf("打开存储失败: %w", err)
+			}
+			defer store.Close()
+		}
+
+		if _, err := docker.GetClient(); err != nil {
+			return fmt.Errorf("连接 docker 失败: %w", err)
+		}
+
+		return streamEvents(ctx, os.Stdout, opts, store)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+	eventsCmd.Flags().StringVar(&eventsSince, "since", "", "起始时间，RFC3339 或相对时长（如 10m）")
+	eventsCmd.Flags().StringVar(&eventsUntil, "until", "", "结束时间，RFC3339 或相对时长；指定后输出完历史事件即退出")
+	eventsCmd.Flags().StringArrayVar(&eventsFilters, "filter", nil, "过滤条件 key=value，可重复指定（如 type=container）")
+	eventsCmd.Flags().BoolVar(&eventsPersist, "persist", false, "将事件写入本地存储")
+}
+
+// buildEventsOptions 将命令行参数转换为 Docker 事件查询参数
+func buildEventsOptions(since, until string, filterArgs []string, now time.Time) (events.ListOptions, error) {
+	opts := events.ListOptions{Filters: filters.NewArgs()}
+	if since != "" {
+		t, err := parseTimeFlag(since, now)
+		if err != nil {
+			return opts, fmt.Errorf("--since: %w", err)
+		}
+		opts.Since = formatEventTime(t)
+	}
+	if until != "" {
+		t, err := parseTimeFlag(until, now)
+		if err != nil {
+			return opts, fmt.Errorf("--until: %w", err)
+		}
+		opts.Until = formatEventTime(t)
+	}
+	for _, f := range filterArgs {
+		key, value, ok := strings.Cut(f, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return opts, fmt.Errorf("无效的过滤条件 %q，应为 key=value", f)
+		}
+		opts.Filters.Add(key, value)
+	}
+	return opts, nil
+}
+
+// formatEventTime 转换为 Docker API 接受的 unix 时间戳（秒.纳秒）
+func formatEventTime(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+}
+
+// streamEvents 持续输出事件；事件流断开时从最后一条事件的时间点重连。指定 Until 时事件流结束即返回
+func streamEvents(ctx context.Context, out io.Writer, opts events.ListOptions, store *storage.Storage) error {
+	for {
+		msgCh, errCh := docker.Events(ctx, opts)
+		err := consumeEvents(ctx, out, msgCh, errCh, store, &opts)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if opts.Until != "" && (err == nil || errors.Is(err, io.EOF)) {
+			return nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			fmt.Fprintf(os.Stderr, "事件流中断: %v，%s 后重连...\n", err, eventsReconnectDelay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(eventsReconnectDelay):
+		}
+	}
+}
+
+func consumeEvents(ctx context.Context, out io.Writer, msgCh <-chan events.Message, errCh <-chan error, store *storage.Storage, opts *events.ListOptions) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err, ok := <-errCh:
+			if !ok {
+				return nil
+			}
+			return err
+		case msg, ok := <-msgCh:
+			if !ok {
+				return nil
+			}
+			printEvent(out, msg)
+			if store != nil {
+				if err := store.InsertDockerEvent(ctx, storage.NewDockerEvent(msg)); err != nil {
+					fmt.Fprintf(os.Stderr, "保存事件失败: %v\n", err)
+				}
+			}
+			// 重连时从下一纳秒开始，避免重复输出
+			opts.Since = formatEventTime(storage.EventTime(msg).Add(time.Nanosecond))
+		}
+	}
+}
+
+func printEvent(out io.Writer, msg events.Message) {
+	if outputJSON {
+		_ = json.NewEncoder(out).Encode(msg)
+		return
+	}
+
+	style, ok := eventTypeStyles[msg.Type]
+	if !ok {
+		style = lipgloss.NewStyle()
+	}
+	action := string(msg.Action)
+	actionText := action
+	switch action {
+	case "die", "kill", "oom", "destroy", "delete":
+		actionText = eventDangerStyle.Render(action)
+	}
+
+	actor := msg.Actor.ID
+	if name := msg.Actor.Attributes["name"]; name != "" && name != actor {
+		actor = fmt.Sprintf("%s (%s)", name, docker.ShortID(msg.Actor.ID))
+	}
+
+	fmt.Fprintf(out, "%s %s %s %s%s\n",
+		eventTimeStyle.Render(storage.EventTime(msg).Local().Format("2006-01-02 15:04:05.000")),
+		style.Render(string(msg.Type)),
+		actionText,
+		actor,
+		formatEventAttributes(msg.Actor.Attributes),
+	)
+}
+
+// formatEventAttributes 将事件属性按 key 排序输出，name 已在对象中展示因此跳过
+func formatEventAttributes(attrs map[string]string) string {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		if k == "name" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+attrs[k])
+	}
+	return " " + strings.Join(parts, " ")
+}
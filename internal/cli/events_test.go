@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+func TestBuildEventsOptions(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	opts, err := buildEventsOptions("10m", "2024-01-02T14:55:00Z", []string{"type=container", "event=die"}, now)
+	if err != nil {
+		t.Fatalf("build options: %v", err)
+	}
+	if opts.Since != formatEventTime(now.Add(-10*time.Minute)) {
+		t.Fatalf("unexpected since: %s", opts.Since)
+	}
+	if opts.Until != "1704207300.000000000" {
+		t.Fatalf("unexpected until: %s", opts.Until)
+	}
+	if got := opts.Filters.Get("type"); len(got) != 1 || got[0] != "container" {
+		t.Fatalf("unexpected type filter: %v", got)
+	}
+	if !opts.Filters.ExactMatch("event", "die") {
+		t.Fatalf("expected event=die filter")
+	}
+
+	if _, err := buildEventsOptions("", "", []string{"type"}, now); err == nil {
+		t.Fatalf("expected error for malformed filter")
+	}
+}
+
+func TestPrintEvent(t *testing.T) {
+	msg := events.Message{
+		Type:   events.ContainerEventType,
+		Action: "start",
+		Actor: events.Actor{
+			ID:         "0123456789abcdef0123",
+			Attributes: map[string]string{"name": "web", "image": "nginx:alpine"},
+		},
+		TimeNano: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).UnixNano(),
+	}
+
+	var buf bytes.Buffer
+	printEvent(&buf, msg)
+	out := buf.String()
+	for _, want := range []string{"container", "start", "web (0123456789ab)", "image=nginx:alpine"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output: %s", want, out)
+		}
+	}
+
+	ev := storage.NewDockerEvent(msg)
+	if ev.ActorName != "web" || ev.Type != "container" || !strings.Contains(ev.AttributesJSON, "nginx:alpine") {
+		t.Fatalf("unexpected stored event: %+v", ev)
+	}
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wwwzy/CentAgent/internal/docker"
+	"github.com/wwwzy/CentAgent/internal/monitor"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+// healthCheckTimeout 为单项健康检查的超时
+const healthCheckTimeout = 2 * time.Second
+
+// healthAddrFlag 为 --health-addr 参数，优先于 daemon.health_addr
+var healthAddrFlag string
+
+// healthAddr 返回健康检查接口的监听地址，命令行参数优先于配置；为空表示不启用
+func healthAddr() string {
+	if healthAddrFlag != "" {
+		return healthAddrFlag
+	}
+	return cfg.Daemon.HealthAddr
+}
+
+// healthResponse 为 /healthz、/readyz 的响应；Checks 中正常的检查项为 "ok"，否则为错误信息
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// healthChecker 汇总数据库、Docker 与采集器的状态
+type healthChecker struct {
+	store      *storage.Storage
+	pingDocker func(ctx context.Context) error
+	mgr        *monitor.Manager
+}
+
+func defaultPingDocker(ctx context.Context) error {
+	_, err := docker.Ping(ctx)
+	return err
+}
+
+// newHealthHandler 返回健康检查处理器：/healthz 检查数据库与采集器（存活探针，Docker 不可用时重启本进程无济于事，不纳入），
+// /readyz 额外检查 Docker（就绪探针）；全部通过返回 200，否则返回 503。
+func newHealthHandler(h healthChecker) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		h.serve(w, r, false)
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		h.serve(w, r, true)
+	})
+	return mux
+}
+
+func (h healthChecker) serve(w http.ResponseWriter, r *http.Request, withDocker bool) {
+	checks := map[string]error{
+		"database":   h.check(r.Context(), h.store.Ping),
+		"collectors": collectorsHealth(h.mgr),
+	}
+	if withDocker {
+		checks["docker"] = h.check(r.Context(), h.pingDocker)
+	}
+
+	resp := healthResponse{Status: "ok", Checks: make(map[string]string, len(checks))}
+	code := http.StatusOK
+	for name, err := range checks {
+		if err != nil {
+			resp.Status, code = "unavailable", http.StatusServiceUnavailable
+			resp.Checks[name] = err.Error()
+			continue
+		}
+		resp.Checks[name] = "ok"
+	}
+	writeJSONResponse(w, code, resp)
+}
+
+func (h healthChecker) check(ctx context.Context, fn func(context.Context) error) error {
+	if fn == nil {
+		return errors.New("not configured")
+	}
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	return fn(ctx)
+}
+
+// collectorsHealth 检查已启用的采集器是否都在运行
+func collectorsHealth(mgr *monitor.Manager) error {
+	if mgr == nil {
+		return errors.New("monitor not started")
+	}
+	var down []string
+	for _, c := range mgr.Status().Collectors {
+		if !c.Enabled || c.Running {
+			continue
+		}
+		if c.Error != "" {
+			down = append(down, fmt.Sprintf("%s (%s)", c.Name, c.Error))
+		} else {
+			down = append(down, c.Name)
+		}
+	}
+	if len(down) > 0 {
+		return fmt.Errorf("collectors not running: %s", strings.Join(down, ", "))
+	}
+	return nil
+}
+
+// startHealthServer 在 addr 上启动健康检查接口，返回的 stop 关闭监听
+func startHealthServer(addr string, h healthChecker) (stop func(), err error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("监听健康检查地址失败: %w", err)
+	}
+	srv := &http.Server{Handler: newHealthHandler(h), ReadHeaderTimeout: controlTimeout}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("健康检查接口已停止: %v\n", err)
+		}
+	}()
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	}, nil
+}
+
+func init() {
+	startCmd.Flags().StringVar(&healthAddrFlag, "health-addr", "", "健康检查接口监听地址，如 127.0.0.1:8081（默认读取 daemon.health_addr，为空不启用）")
+}
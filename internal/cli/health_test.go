@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wwwzy/CentAgent/internal/config"
+	"github.com/wwwzy/CentAgent/internal/monitor"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	ctx := context.Background()
+
+	c := config.DefaultConfig()
+	c.Storage.InMemory = true
+	store, err := storage.Open(ctx, c.Storage)
+	if err != nil {
+		t.Skipf("storage unavailable: %v", err)
+	}
+	defer store.Close()
+
+	mcfg := c.Monitor
+	mcfg.Stats.Enabled, mcfg.Logs.Enabled, mcfg.Retention.Enabled, mcfg.Host.Enabled, mcfg.Alert.Enabled = false, false, false, false, false
+	mgr, err := monitor.NewManager(mcfg)
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+
+	dockerErr := error(nil)
+	srv := httptest.NewServer(newHealthHandler(healthChecker{
+		store:      store,
+		pingDocker: func(context.Context) error { return dockerErr },
+		mgr:        mgr,
+	}))
+	defer srv.Close()
+
+	get := func(path string) (int, healthResponse) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("get %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		var body healthResponse
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+		return resp.StatusCode, body
+	}
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		if code, body := get(path); code != http.StatusOK || body.Status != "ok" {
+			t.Fatalf("expected %s to be healthy, got %d %+v", path, code, body)
+		}
+	}
+
+	// Docker 不可用只影响就绪探针
+	dockerErr = errors.New("docker daemon unreachable")
+	if code, body := get("/readyz"); code != http.StatusServiceUnavailable || body.Checks["docker"] == "ok" {
+		t.Fatalf("expected /readyz to fail when docker is down, got %d %+v", code, body)
+	}
+	if code, _ := get("/healthz"); code != http.StatusOK {
+		t.Fatalf("expected /healthz to ignore docker, got %d", code)
+	}
+	dockerErr = nil
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("close store: %v", err)
+	}
+	for _, path := range []string{"/healthz", "/readyz"} {
+		code, body := get(path)
+		if code != http.StatusServiceUnavailable || body.Status != "unavailable" || body.Checks["database"] == "ok" {
+			t.Fatalf("expected %s to report database down, got %d %+v", path, code, body)
+		}
+	}
+}
+
+func TestCollectorsHealth(t *testing.T) {
+	cfg := monitor.Config{}
+	cfg.Stats.Enabled = true
+	mgr, err := monitor.NewManager(cfg)
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	// 已启用但未运行的采集器视为不健康
+	if err := collectorsHealth(mgr); err == nil {
+		t.Fatalf("expected error for enabled collector that is not running")
+	}
+	if err := collectorsHealth(nil); err == nil {
+		t.Fatalf("expected error for nil manager")
+	}
+}
@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+var (
+	logsLevel    string
+	logsSource   string
+	logsContains string
+	logsSince    string
+	logsUntil    string
+	logsLimit    int
+	logsDesc     bool
+	logsFollow   bool
+	logsInterval time.Duration
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [container]",
+	Short: "查询已采集的容器日志",
+	Long: `从本地存储查询监控采集的容器日志，容器删除后仍可检索历史记录。
+容器可以是名称或 ID；--since/--until 支持 RFC3339 时间或相对时长（如 10m、2h）。`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigChan
+			cancel()
+		}()
+
+		q := storage.LogQuery{
+			Level:    strings.ToUpper(strings.TrimSpace(logsLevel)),
+			Source:   strings.TrimSpace(logsSource),
+			Contains: logsContains,
+			Limit:    logsLimit,
+			Desc:     logsDesc,
+		}
+		now := time.Now().UTC()
+		if logsSince != "" {
+			from, err := parseTimeFlag(logsSince, now)
+			if err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+			q.From = &from
+		}
+		if logsUntil != "" {
+			to, err := parseTimeFlag(logsUntil, now)
+			if err != nil {
+				return fmt.Errorf("--until: %w", err)
+			}
+			q.To = &to
+		}
+		if logsFollow && q.To != nil {
+			return fmt.Errorf("--follow 不能与 --until 同时使用")
+		}
+
+		store, err := storage.Open(ctx, cfg.Storage)
+		if err != nil {
+			return fmt.Errorf("打开存储失败: %w", err)
+		}
+		defer store.Close()
+
+		container := ""
+		if len(args) > 0 {
+			container = strings.TrimSpace(args[0])
+		}
+
+		if logsFollow {
+			// 跟随模式先输出最近的 N 条，再按时间顺序持续输出新日志
+			q.Desc = true
+		}
+		logs, err := queryLogs(ctx, store, container, q)
+		if err != nil {
+			return fmt.Errorf("查询日志失败: %w", err)
+		}
+		if logsFollow {
+			for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
+				logs[i], logs[j] = logs[j], logs[i]
+			}
+		}
+		printLogs(os.Stdout, logs)
+		if !logsFollow {
+			return nil
+		}
+
+		if logsInterval <= 0 {
+			return fmt.Errorf("--interval 必须大于 0")
+		}
+		return followLogs(ctx, os.Stdout, store, container, q, logs)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+	logsCmd.Flags().StringVar(&logsLevel, "level", "", "按日志级别过滤，例如 ERROR、WARN")
+	logsCmd.Flags().StringVar(&logsSource, "source", "", "按来源过滤：stdout 或 stderr")
+	logsCmd.Flags().StringVar(&logsContains, "contains", "", "只显示包含该关键字的日志")
+	logsCmd.Flags().StringVar(&logsSince, "since", "", "起始时间，RFC3339 或相对时长（如 30m）")
+	logsCmd.Flags().StringVar(&logsUntil, "until", "", "结束时间，RFC3339 或相对时长（如 5m）")
+	logsCmd.Flags().IntVar(&logsLimit, "limit", 0, "最多返回的日志条数（默认 200）")
+	logsCmd.Flags().BoolVar(&logsDesc, "desc", false, "按时间倒序输出")
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "持续输出新写入的日志")
+	logsCmd.Flags().DurationVar(&logsInterval, "interval", time.Second, "--follow 模式下的轮询间隔")
+}
+
+// queryLogs 按名称查询日志，未命中时再按容器 ID 查询
+func queryLogs(ctx context.Context, store *storage.Storage, container string, q storage.LogQuery) ([]storage.ContainerLog, error) {
+	if container == "" {
+		return store.QueryContainerLogs(ctx, q)
+	}
+	q.ContainerName = strings.TrimPrefix(container, "/")
+	out, err := store.QueryContainerLogs(ctx, q)
+	if err != nil || len(out) > 0 {
+		return out, err
+	}
+	q.ContainerName = ""
+	q.ContainerID = container
+	return store.QueryContainerLogs(ctx, q)
+}
+
+// followLogs 轮询数据库，输出 ID 大于已输出记录的新日志
+func followLogs(ctx context.Context, out io.Writer, store *storage.Storage, container string, q storage.LogQuery, seen []storage.ContainerLog) error {
+	var lastID uint64
+	lastTime := time.Now().UTC()
+	for _, l := range seen {
+		if l.ID > lastID {
+			lastID = l.ID
+		}
+	}
+	if len(seen) > 0 {
+		lastTime = seen[len(seen)-1].Timestamp
+	}
+
+	q.Desc = false
+	ticker := time.NewTicker(logsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		from := lastTime
+		q.From = &from
+		logs, err := queryLogs(ctx, store, container, q)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("查询日志失败: %w", err)
+		}
+		fresh := logs[:0]
+		for _, l := range logs {
+			if l.ID <= lastID {
+				continue
+			}
+			fresh = append(fresh, l)
+			lastID = l.ID
+			if l.Timestamp.After(lastTime) {
+				lastTime = l.Timestamp
+			}
+		}
+		printLogs(out, fresh)
+	}
+}
+
+// printLogs 逐行输出日志；--json 时每行输出一个 JSON 对象，便于 --follow 时流式处理
+func printLogs(out io.Writer, logs []storage.ContainerLog) {
+	for _, l := range logs {
+		if outputJSON {
+			_ = json.NewEncoder(out).Encode(l)
+			continue
+		}
+		level := ""
+		if l.Level != "" {
+			level = " " + l.Level
+		}
+		fmt.Fprintf(out, "%s %s [%s%s] %s\n",
+			l.Timestamp.Local().Format("2006-01-02 15:04:05.000"),
+			l.ContainerName,
+			l.Source,
+			level,
+			strings.TrimRight(l.Message, "\n"),
+		)
+	}
+}
+
+// parseTimeFlag 解析时间参数：相对时长（如 10m，表示 now 之前）或 RFC3339
+func parseTimeFlag(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if d, err := time.ParseDuration(s); err == nil {
+		if d > 0 {
+			d = -d
+		}
+		return now.Add(d).UTC(), nil
+	}
+	if tm, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return tm.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time format: %s (use RFC3339 or duration like 10m)", s)
+}
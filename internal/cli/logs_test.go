@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeFlag(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	got, err := parseTimeFlag("10m", now)
+	if err != nil {
+		t.Fatalf("parse duration: %v", err)
+	}
+	if !got.Equal(now.Add(-10 * time.Minute)) {
+		t.Fatalf("unexpected relative time: %v", got)
+	}
+
+	got, err = parseTimeFlag("2024-01-02T10:00:00+08:00", now)
+	if err != nil {
+		t.Fatalf("parse rfc3339: %v", err)
+	}
+	if !got.Equal(time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected absolute time: %v", got)
+	}
+
+	if _, err := parseTimeFlag("yesterday-ish", now); err == nil {
+		t.Fatalf("expected error for invalid time")
+	}
+}
@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// outputJSON 对应全局 --json 标志：为 true 时命令输出 JSON 而不是表格
+var outputJSON bool
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&outputJSON, "json", false, "以 JSON 格式输出（适用于 stats/logs/audit/storage info/version 等命令）")
+}
+
+// writeJSON 以缩进格式输出 JSON
+func writeJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
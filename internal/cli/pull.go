@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/wwwzy/CentAgent/internal/docker"
+)
+
+// pullBarWidth 为 pull 命令进度条的宽度
+const pullBarWidth = 30
+
+var pullPlatform string
+
+var pullCmd = &cobra.Command{
+	Use:   "pull <ref>",
+	Short: "拉取镜像并显示实时进度",
+	Long: `从镜像仓库拉取镜像，实时显示各层的完成情况与整体进度，完成后输出镜像 digest。
+按 Ctrl+C 可中途取消拉取。`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		if _, err := docker.GetClient(); err != nil {
+			return fmt.Errorf("连接 docker 失败: %w", err)
+		}
+
+		var onProgress func(docker.PullProgress)
+		r := &pullRenderer{out: os.Stdout}
+		if !outputJSON {
+			fmt.Printf("拉取 %s ...\n", args[0])
+			onProgress = r.update
+		}
+
+		res, err := docker.PullImageWithProgress(ctx, docker.PullImageOptions{Ref: args[0], Platform: pullPlatform}, onProgress)
+		r.finish()
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("已取消拉取 %s", args[0])
+			}
+			return err
+		}
+
+		if outputJSON {
+			return writeJSON(os.Stdout, res)
+		}
+		if res.Digest != "" {
+			fmt.Printf("Digest: %s\n", res.Digest)
+		}
+		if res.Status != "" {
+			fmt.Printf("Status: %s\n", res.Status)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pullCmd)
+	pullCmd.Flags().StringVar(&pullPlatform, "platform", "", "目标平台（如 linux/amd64）")
+}
+
+// pullRenderer 将拉取进度渲染为一行可刷新的进度条；每层完成时单独输出一行
+type pullRenderer struct {
+	out io.Writer
+	// drawn 为 true 表示当前行是尚未换行的进度条
+	drawn bool
+}
+
+func (r *pullRenderer) update(p docker.PullProgress) {
+	if p.LayerID != "" && (p.Status == "Pull complete" || p.Status == "Already exists") {
+		r.clear()
+		fmt.Fprintf(r.out, "%s: %s\n", p.LayerID, p.Status)
+	}
+	r.clear()
+	fmt.Fprint(r.out, formatPullProgress(p))
+	r.drawn = true
+}
+
+// finish 结束进度条所在的行
+func (r *pullRenderer) finish() {
+	if r.drawn {
+		fmt.Fprintln(r.out)
+		r.drawn = false
+	}
+}
+
+func (r *pullRenderer) clear() {
+	if r.drawn {
+		// 回到行首并清除整行
+		fmt.Fprint(r.out, "\r\033[K")
+		r.drawn = false
+	}
+}
+
+// formatPullProgress 渲染整体进度；尚无层信息时只显示状态
+func formatPullProgress(p docker.PullProgress) string {
+	if p.Percent < 0 || p.Layers == 0 {
+		return p.Status
+	}
+	pct := min(100, p.Percent)
+	filled := int(pct / 100 * pullBarWidth)
+	bar := strings.Repeat("=", filled)
+	if filled < pullBarWidth {
+		bar += ">" + strings.Repeat(" ", pullBarWidth-filled-1)
+	}
+	return fmt.Sprintf("[%s] %3.0f%%  %d/%d layers", bar, pct, p.LayersDone, p.Layers)
+}
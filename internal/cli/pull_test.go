@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wwwzy/CentAgent/internal/docker"
+)
+
+func TestFormatPullProgress(t *testing.T) {
+	got := formatPullProgress(docker.PullProgress{Status: "Downloading", Layers: 4, LayersDone: 2, Percent: 50})
+	want := "[" + strings.Repeat("=", 15) + ">" + strings.Repeat(" ", 14) + "]  50%  2/4 layers"
+	if got != want {
+		t.Fatalf("unexpected progress:\n got %q\nwant %q", got, want)
+	}
+
+	if got := formatPullProgress(docker.PullProgress{Status: "Waiting", Percent: -1}); got != "Waiting" {
+		t.Fatalf("expected bare status without layer info, got %q", got)
+	}
+}
+
+func TestPullRendererPrintsCompletedLayers(t *testing.T) {
+	var buf bytes.Buffer
+	r := &pullRenderer{out: &buf}
+	r.update(docker.PullProgress{Status: "Downloading", LayerID: "aaa", Layers: 2, Percent: 20})
+	r.update(docker.PullProgress{Status: "Pull complete", LayerID: "aaa", Layers: 2, LayersDone: 1, Percent: 50})
+	r.finish()
+
+	out := buf.String()
+	if !strings.Contains(out, "\r\033[Kaaa: Pull complete\n") {
+		t.Fatalf("expected completed layer on its own line, got %q", out)
+	}
+	if !strings.HasSuffix(out, "1/2 layers\n") {
+		t.Fatalf("expected final progress line to be terminated, got %q", out)
+	}
+}
@@ -5,13 +5,22 @@ import (
 	"os"
 
 	"github.com/wwwzy/CentAgent/internal/config"
+	"github.com/wwwzy/CentAgent/internal/docker"
+	"github.com/wwwzy/CentAgent/internal/logging"
+	"github.com/wwwzy/CentAgent/internal/storage"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	cfgFile string
-	cfg     *config.Config
+	cfgFile    string
+	dockerHost string
+	logFormat  string
+	inMemory   bool
+	cfg        *config.Config
+
+	// skipConfigLoad 为 true 时 initConfig 不加载配置
+	skipConfigLoad bool
 )
 
 // rootCmd 是没有子命令时调用的基础命令
@@ -27,24 +36,77 @@ var rootCmd = &cobra.Command{
 // Execute 将所有子命令添加到根命令并适当设置标志。
 // 这由 main.main() 调用。它只需要对 rootCmd 调用一次。
 func Execute() error {
+	// config 子命令需要在配置不完整时也能运行，由其自行加载并报告问题
+	if c, _, err := rootCmd.Find(os.Args[1:]); err == nil && skipsConfigLoad(c) {
+		skipConfigLoad = true
+	}
 	return rootCmd.Execute()
 }
 
+// skipsConfigLoad 判断命令或其父命令是否标记了 skipConfigLoadAnnotation
+func skipsConfigLoad(c *cobra.Command) bool {
+	for ; c != nil; c = c.Parent() {
+		if c.Annotations[skipConfigLoadAnnotation] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
 	// 在这里定义您的标志和配置设置。
 	// Cobra 支持持久标志，如果在定义在这里，
 	// 将对您的应用程序全局有效。
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "配置文件（默认按 ./config.yaml、./configs/config.yaml、$HOME/.centagent/config.yaml 搜索）")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "配置文件，支持 yaml/toml/json（默认在 .、./configs、$HOME/.centagent 中搜索 config.{yaml,yml,toml,json}）")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "", "内部日志格式：text 或 json（覆盖配置文件中的 log_format）")
+	rootCmd.PersistentFlags().BoolVar(&inMemory, "in-memory", false, "使用内存数据库（进程退出后数据丢失，适合演示与临时运行），覆盖 storage.in_memory")
+	rootCmd.PersistentFlags().StringVar(&dockerHost, "docker-host", "", "Docker daemon 地址（如 unix:///var/run/docker.sock、tcp://host:2375），覆盖 DOCKER_HOST 与配置文件")
 }
 
 // initConfig 读取配置文件和环境变量（如果已设置）。
 func initConfig() {
+	if skipConfigLoad {
+		return
+	}
+
 	var err error
 	cfg, err = config.Load(cfgFile)
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		os.Exit(1)
 	}
+
+	if inMemory {
+		cfg.Storage.InMemory = true
+	}
+
+	// 按 log_level 设置 GORM 日志级别，debug 时输出 SQL
+	cfg.Storage.Logger = storage.NewLogger(cfg.LogLevel)
+	// 内部日志输出到 stderr，debug 级别才输出工具参数等调试信息；--log-format 优先于配置文件
+	if logFormat != "" {
+		cfg.LogFormat = logFormat
+	}
+	logger, err := logging.NewWithFormat(os.Stderr, logging.ParseLevel(cfg.LogLevel), cfg.LogFormat)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	logging.SetDefault(logger)
+
+	docker.SetShortIDLength(cfg.Docker.ShortIDLength)
+	docker.SetRegistries(cfg.Registries)
+
+	// 命令行 --docker-host 优先于配置文件中的 docker.host
+	host := cfg.Docker.Host
+	if dockerHost != "" {
+		host = dockerHost
+	}
+	if host != "" {
+		if err := docker.SetHost(host); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 }
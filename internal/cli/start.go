@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/wwwzy/CentAgent/internal/config"
 	"github.com/wwwzy/CentAgent/internal/docker"
 	"github.com/wwwzy/CentAgent/internal/monitor"
 	"github.com/wwwzy/CentAgent/internal/storage"
@@ -19,8 +22,22 @@ var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "启动 CentAgent 监控服务",
 	Long: `启动 CentAgent 后台监控服务。
-这将初始化数据库，连接到 Docker，并开始收集统计信息和日志。`,
+这将初始化数据库，连接到 Docker，并开始收集统计信息和日志。
+使用 --daemon 在后台运行，输出写入日志文件，并通过 centagent stop 停止。`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		pidFile, logFile := daemonPaths()
+		if startDaemonMode && !isDaemonChild() {
+			pid, err := startDaemon(pidFile, logFile)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("CentAgent 已在后台启动 (PID %d)\n日志文件: %s\nPID 文件: %s\n", pid, logFile, pidFile)
+			return nil
+		}
+		if isDaemonChild() {
+			defer removeOwnPIDFile(pidFile)
+		}
+
 		// 1. 上下文用于优雅退出
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -40,7 +57,13 @@ var startCmd = &cobra.Command{
 
 		// 4. 初始化监控管理器
 		fmt.Println("正在初始化监控管理器...")
-		mgr, err := monitor.NewManager(cfg.Monitor)
+		monitorCfg := cfg.Monitor
+		if noRawJSON {
+			monitorCfg.Stats.StoreRawJSON = false
+		}
+		monitorCfg.Alert.OnAlert = printAlert
+		monitorCfg.Alert.OnResolve = printAlertResolved
+		mgr, err := monitor.NewManager(monitorCfg)
 		if err != nil {
 			return fmt.Errorf("创建监控管理器失败: %w", err)
 		}
@@ -61,31 +84,69 @@ var startCmd = &cobra.Command{
 			return fmt.Errorf("创建 retention 采集器失败: %w", err)
 		}
 
+		alert := monitor.NewAlertCollector()
+
+		host, err := monitor.NewHostStatsCollector(store)
+		if err != nil {
+			return fmt.Errorf("创建 host 采集器失败: %w", err)
+		}
+
 		// 流式接口挂载采集器
-		mgr.WithStats(stats).WithLogs(logs).WithRetention(ret)
+		mgr.WithStats(stats).WithLogs(logs).WithRetention(ret).WithAlerts(alert).WithHost(host)
+
+		// 6. 启动健康检查接口（可选）；显式配置了地址但无法监听时直接失败，避免探针误判
+		if addr := healthAddr(); addr != "" {
+			stopHealth, err := startHealthServer(addr, healthChecker{store: store, pingDocker: defaultPingDocker, mgr: mgr})
+			if err != nil {
+				return err
+			}
+			defer stopHealth()
+			fmt.Printf("健康检查接口: http://%s/healthz\n", addr)
+		}
 
-		// 6. 启动管理器
+		// 7. 启动管理器
 		fmt.Println("正在启动监控服务...")
 		if err := mgr.Start(ctx); err != nil {
 			return fmt.Errorf("启动管理器失败: %w", err)
 		}
 
-		// 7. 等待信号
+		// 8. 启动本地控制接口；失败不影响采集，仅提示
+		if path := controlSocketPath(); path != "" {
+			stopControl, err := startControlServer(path, mgr)
+			if err != nil {
+				fmt.Printf("控制接口未启动: %v\n", err)
+			} else {
+				defer stopControl()
+				fmt.Printf("控制接口: %s\n", path)
+			}
+		}
+
+		// 9. 等待信号；SIGHUP 重新读取配置文件并热更新日志采集配置
 		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
 		fmt.Println("CentAgent 已启动。按 Ctrl+C 停止。")
 
-		select {
-		case sig := <-sigChan:
-			fmt.Printf("收到信号: %s, 正在关闭...\n", sig)
-		case <-ctx.Done():
-			fmt.Println("上下文已取消, 正在关闭...")
+	wait:
+		for {
+			select {
+			case sig := <-sigChan:
+				if sig == syscall.SIGHUP {
+					reloadMonitorConfig(mgr)
+					continue
+				}
+				fmt.Printf("收到信号: %s, 正在关闭...\n", sig)
+				break wait
+			case <-ctx.Done():
+				fmt.Println("上下文已取消, 正在关闭...")
+				break wait
+			}
 		}
 
-		// 8. 优雅停止
-		mgr.Stop()
-		if err := mgr.Wait(); err != nil {
+		// 10. 优雅停止：等待缓冲中的数据落库，最多等待 shutdownTimeout
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancelShutdown()
+		if err := mgr.Shutdown(shutdownCtx); err != nil {
 			return fmt.Errorf("管理器停止时发生错误: %w", err)
 		}
 
@@ -94,9 +155,101 @@ var startCmd = &cobra.Command{
 	},
 }
 
+// reloadMonitorConfig 重新加载配置文件并将日志采集配置应用到运行中的采集器；加载失败时保留原配置
+func reloadMonitorConfig(mgr *monitor.Manager) {
+	newCfg, err := config.Load(cfgFile)
+	if err != nil {
+		fmt.Printf("重新加载配置失败，继续使用原配置: %v\n", err)
+		return
+	}
+	mgr.ReloadLogs(newCfg.Monitor.Logs)
+	fmt.Println("已重新加载日志采集配置。")
+}
+
+// shutdownTimeout 为停止时等待采集器落库最后一批数据的最长时间
+const shutdownTimeout = 10 * time.Second
+
+var (
+	startDaemonMode bool
+	noRawJSON       bool
+	daemonPIDFile   string
+	daemonLogFile   string
+	stopTimeout     time.Duration
+)
+
+// stopCmd 代表 stop 命令
+var stopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "停止后台运行的 CentAgent 监控服务",
+	Long:  `读取 PID 文件并向 start --daemon 启动的进程发送 SIGTERM，等待其优雅退出。`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pidFile, _ := daemonPaths()
+		pid, err := stopDaemon(pidFile, stopTimeout)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("CentAgent (PID %d) 已停止。\n", pid)
+		return nil
+	},
+}
+
+// daemonPaths 返回 PID 文件与日志文件路径，命令行参数优先于配置
+func daemonPaths() (pidFile, logFile string) {
+	pidFile, logFile = cfg.Daemon.PIDFile, cfg.Daemon.LogFile
+	if daemonPIDFile != "" {
+		pidFile = daemonPIDFile
+	}
+	if daemonLogFile != "" {
+		logFile = daemonLogFile
+	}
+	return pidFile, logFile
+}
+
 func init() {
 	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(stopCmd)
+
+	startCmd.Flags().BoolVarP(&startDaemonMode, "daemon", "d", false, "以守护进程模式在后台运行")
+	startCmd.Flags().StringVar(&daemonLogFile, "log-file", "", "后台模式下的日志文件（默认读取 daemon.log_file）")
+	startCmd.Flags().BoolVar(&noRawJSON, "no-raw-json", false, "不保存 stats 原始 JSON，覆盖 monitor.stats.store_raw_json")
+	for _, c := range []*cobra.Command{startCmd, stopCmd} {
+		c.Flags().StringVar(&daemonPIDFile, "pid-file", "", "PID 文件路径（默认读取 daemon.pid_file）")
+	}
+	stopCmd.Flags().DurationVar(&stopTimeout, "timeout", 30*time.Second, "等待进程退出的最长时间")
+}
+
+// printAlert 输出资源告警；后台模式下写入日志文件
+func printAlert(a monitor.Alert) {
+	if a.Metric == monitor.MetricOOM {
+		fmt.Printf("[ALERT] %s 容器 %s (%s) 被 OOM kill，退出码 %d\n",
+			a.At.Local().Format("2006-01-02 15:04:05"),
+			strings.TrimPrefix(a.ContainerName, "/"),
+			docker.ShortID(a.ContainerID),
+			a.ExitCode,
+		)
+		return
+	}
+	fmt.Printf("[ALERT] %s 容器 %s (%s) %s 使用率 %.2f%% 已持续超过阈值 %.2f%%（自 %s 起）\n",
+		a.At.Local().Format("2006-01-02 15:04:05"),
+		strings.TrimPrefix(a.ContainerName, "/"),
+		docker.ShortID(a.ContainerID),
+		strings.ToUpper(a.Metric),
+		a.Value,
+		a.Threshold,
+		a.Since.Local().Format("15:04:05"),
+	)
+}
 
-	// 这里可以定义 start 命令特有的标志
-	// startCmd.Flags().BoolP("daemon", "d", false, "以守护进程模式运行")
+// printAlertResolved 输出告警恢复
+func printAlertResolved(a monitor.Alert) {
+	fmt.Printf("[RESOLVED] %s 容器 %s (%s) %s 使用率 %.2f%% 已回落到阈值 %.2f%% 以下（告警自 %s 起）\n",
+		a.At.Local().Format("2006-01-02 15:04:05"),
+		strings.TrimPrefix(a.ContainerName, "/"),
+		docker.ShortID(a.ContainerID),
+		strings.ToUpper(a.Metric),
+		a.Value,
+		a.Threshold,
+		a.Since.Local().Format("15:04:05"),
+	)
 }
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wwwzy/CentAgent/internal/docker"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+var (
+	statsSince     time.Duration
+	statsLimit     int
+	statsDesc      bool
+	statsWatch     bool
+	statsInterval  time.Duration
+	statsAnomalies bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [container]",
+	Short: "查看已采集的容器资源统计",
+	Long: `从本地存储读取监控采集的容器资源数据，以类似 docker stats 的表格输出。
+不指定容器时显示每个容器最新的一条采样；指定容器（ID 或名称）时显示其历史采样。
+--anomalies 显示所有容器最近的异常采样（CPU 或内存达到 monitor.retention.stats 的阈值，与保留策略口径一致）。
+该命令不依赖模型，也无需连接 Docker。`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigChan
+			cancel()
+		}()
+
+		store, err := storage.Open(ctx, cfg.Storage)
+		if err != nil {
+			return fmt.Errorf("打开存储失败: %w", err)
+		}
+		defer store.Close()
+
+		container := ""
+		if len(args) > 0 {
+			container = strings.TrimSpace(args[0])
+		}
+		if statsAnomalies && container != "" {
+			return fmt.Errorf("--anomalies 不能与容器参数同时使用")
+		}
+
+		if !statsWatch {
+			return printStats(ctx, os.Stdout, store, container)
+		}
+
+		if statsInterval <= 0 {
+			return fmt.Errorf("--interval 必须大于 0")
+		}
+		ticker := time.NewTicker(statsInterval)
+		defer ticker.Stop()
+		for {
+			if !outputJSON {
+				// 清屏并将光标移到左上角
+				fmt.Print("\033[H\033[2J")
+				fmt.Printf("每 %s 刷新，Ctrl+C 退出  %s\n\n", statsInterval, time.Now().Format("15:04:05"))
+			}
+			if err := printStats(ctx, os.Stdout, store, container); err != nil {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().DurationVar(&statsSince, "since", 0, "只显示最近一段时间内的采样，例如 10m、1h")
+	statsCmd.Flags().IntVar(&statsLimit, "limit", 0, "最多返回的记录条数（默认 200）")
+	statsCmd.Flags().BoolVar(&statsDesc, "desc", false, "按采集时间倒序输出")
+	statsCmd.Flags().BoolVarP(&statsWatch, "watch", "w", false, "持续刷新输出")
+	statsCmd.Flags().DurationVar(&statsInterval, "interval", 2*time.Second, "--watch 模式下的刷新间隔")
+	statsCmd.Flags().BoolVar(&statsAnomalies, "anomalies", false, "显示所有容器最近的异常采样（按采集时间倒序）")
+}
+
+// loadStats 按命令行参数查询统计数据：未指定容器且未指定 --since 时返回每个容器的最新采样
+func loadStats(ctx context.Context, store *storage.Storage, container string) ([]storage.ContainerStat, error) {
+	if statsAnomalies {
+		th := cfg.Monitor.Retention.Stats
+		return store.RecentAnomalies(ctx, th.CPUHigh, th.MemHigh, statsLimit)
+	}
+	if container == "" && statsSince <= 0 {
+		return store.LatestStatPerContainer(ctx)
+	}
+
+	q := storage.StatsQuery{
+		Limit: statsLimit,
+		Desc:  statsDesc,
+	}
+	if statsSince > 0 {
+		from := time.Now().UTC().Add(-statsSince)
+		q.From = &from
+	}
+	if container == "" {
+		return store.QueryContainerStats(ctx, q)
+	}
+
+	// 优先按名称匹配，未命中再按 ID 匹配
+	q.ContainerName = strings.TrimPrefix(container, "/")
+	out, err := store.QueryContainerStats(ctx, q)
+	if err != nil || len(out) > 0 {
+		return out, err
+	}
+	q.ContainerName = ""
+	q.ContainerID = container
+	return store.QueryContainerStats(ctx, q)
+}
+
+func printStats(ctx context.Context, out io.Writer, store *storage.Storage, container string) error {
+	stats, err := loadStats(ctx, store, container)
+	if err != nil {
+		return fmt.Errorf("查询统计数据失败: %w", err)
+	}
+	if outputJSON {
+		return writeJSON(out, stats)
+	}
+	if len(stats) == 0 {
+		fmt.Fprintln(out, "没有找到统计数据，请确认监控已启动（centagent start）。")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CONTAINER ID\tNAME\tCPU %\tMEM USAGE / LIMIT\tMEM %\tNET I/O\tBLOCK I/O\tPIDS\tCOLLECTED AT")
+	for _, st := range stats {
+		fmt.Fprintf(w, "%s\t%s\t%.2f%%\t%s / %s\t%.2f%%\t%s / %s\t%s / %s\t%d\t%s\n",
+			docker.ShortID(st.ContainerID),
+			st.ContainerName,
+			st.CPUPercent,
+			formatBytes(st.MemUsageBytes), formatBytes(st.MemLimitBytes),
+			st.MemPercent,
+			formatBytes(st.NetRxBytes), formatBytes(st.NetTxBytes),
+			formatBytes(st.BlockReadBytes), formatBytes(st.BlockWriteBytes),
+			st.Pids,
+			st.CollectedAt.Local().Format("2006-01-02 15:04:05"),
+		)
+	}
+	return w.Flush()
+}
+
+// formatBytes 以 1024 为基数格式化字节数，例如 1.5MiB
+func formatBytes(v uint64) string {
+	const unit = 1024
+	n := float64(v)
+	if n < unit {
+		return fmt.Sprintf("%dB", v)
+	}
+	units := []string{"KiB", "MiB", "GiB", "TiB"}
+	i := -1
+	for n >= unit && i < len(units)-1 {
+		n /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f%s", n, units[i])
+}
@@ -2,9 +2,12 @@ package cli
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/tabwriter"
 
 	"time"
@@ -33,40 +36,49 @@ func init() {
 	storageCmd.AddCommand(infoCmd)
 	storageCmd.AddCommand(pruneMonitorCmd)
 	storageCmd.AddCommand(pruneAuditCmd)
+	storageCmd.AddCommand(checkpointCmd)
+	storageCmd.AddCommand(migrateCmd)
 }
 
 // pruneAuditCmd represents the prune-audit command
 var pruneAuditCmd = &cobra.Command{
 	Use:   "prune-audit",
 	Short: "清理审计记录",
-	Long:  `根据用户指定的保留条数或天数，清理旧的审计记录。`,
+	Long:  `根据用户指定的保留条数、天数或截止时间清理旧的审计记录；同时指定多个条件时依次全部应用。`,
 	Run:   runPruneAudit,
 }
 
 var (
-	keepAuditCount int
-	keepAuditDays  int
+	keepAuditCount   int
+	keepAuditDays    int
+	pruneAuditBefore string
 )
 
 func init() {
 	pruneAuditCmd.Flags().IntVar(&keepAuditCount, "keep", 0, "保留最近的 N 条记录")
 	pruneAuditCmd.Flags().IntVar(&keepAuditDays, "days", 0, "保留最近 N 天的记录")
-	
-	rootCmd.AddCommand(storageCmd)
-	storageCmd.AddCommand(infoCmd)
-	storageCmd.AddCommand(pruneMonitorCmd)
-	storageCmd.AddCommand(pruneAuditCmd)
+	pruneAuditCmd.Flags().StringVar(&pruneAuditBefore, "before", "", "删除该时间（RFC3339，例如 2024-01-02T15:04:05Z）之前的记录")
 }
 
 func runPruneAudit(cmd *cobra.Command, args []string) {
 	ctx := context.Background()
 
-	if keepAuditCount <= 0 && keepAuditDays <= 0 {
-		fmt.Println("Error: must specify either --keep or --days")
+	if keepAuditCount <= 0 && keepAuditDays <= 0 && pruneAuditBefore == "" {
+		fmt.Println("Error: must specify at least one of --keep, --days or --before")
 		cmd.Usage()
 		os.Exit(1)
 	}
 
+	var beforeCutoff time.Time
+	if pruneAuditBefore != "" {
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(pruneAuditBefore))
+		if err != nil {
+			fmt.Printf("Error: invalid --before %q, expected RFC3339 (e.g. 2024-01-02T15:04:05Z)\n", pruneAuditBefore)
+			os.Exit(1)
+		}
+		beforeCutoff = t.UTC()
+	}
+
 	if cfg == nil {
 		fmt.Println("Config not loaded")
 		os.Exit(1)
@@ -89,6 +101,7 @@ func runPruneAudit(cmd *cobra.Command, args []string) {
 			fmt.Printf("Error pruning by count: %v\n", err)
 			os.Exit(1)
 		}
+		fmt.Printf("  --keep: deleted %d records\n", count)
 		deletedCount += count
 	}
 
@@ -100,11 +113,23 @@ func runPruneAudit(cmd *cobra.Command, args []string) {
 			fmt.Printf("Error pruning by days: %v\n", err)
 			os.Exit(1)
 		}
+		fmt.Printf("  --days: deleted %d records\n", count)
+		deletedCount += count
+	}
+
+	if !beforeCutoff.IsZero() {
+		fmt.Printf("Pruning audit records before %s...\n", beforeCutoff.Format(time.RFC3339))
+		count, err := store.DeleteAuditRecordsBefore(ctx, beforeCutoff)
+		if err != nil {
+			fmt.Printf("Error pruning by timestamp: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("  --before: deleted %d records\n", count)
 		deletedCount += count
 	}
 
 	fmt.Printf("Prune completed. Deleted %d records.\n", deletedCount)
-	
+
 	if count, err := store.CountAuditRecords(ctx); err == nil {
 		fmt.Printf("Remaining Audit Records: %d\n", count)
 	}
@@ -155,6 +180,203 @@ func runPruneMonitor(cmd *cobra.Command, args []string) {
 	}
 }
 
+// checkpointCmd represents the checkpoint command
+var checkpointCmd = &cobra.Command{
+	Use:   "checkpoint",
+	Short: "将 WAL 写回数据库并截断 -wal 文件",
+	Long: `执行 PRAGMA wal_checkpoint，将 WAL 中的内容写回数据库文件。
+默认使用 TRUNCATE 模式，完成后 -wal 文件会被截断为 0，用于回收持续写入导致膨胀的磁盘空间。`,
+	Args: cobra.NoArgs,
+	Run:  runCheckpoint,
+}
+
+var checkpointMode string
+
+func init() {
+	checkpointCmd.Flags().StringVar(&checkpointMode, "mode", "TRUNCATE", "检查点模式：PASSIVE、FULL、RESTART 或 TRUNCATE")
+}
+
+// checkpointOutput 为 storage checkpoint 命令的输出内容
+type checkpointOutput struct {
+	Mode string `json:"mode"`
+	storage.CheckpointResult
+	WALBytesBefore int64 `json:"wal_bytes_before"`
+	WALBytesAfter  int64 `json:"wal_bytes_after"`
+}
+
+func runCheckpoint(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	if cfg == nil {
+		fmt.Println("Config not loaded")
+		os.Exit(1)
+	}
+
+	store, err := storage.Open(ctx, cfg.Storage)
+	if err != nil {
+		fmt.Printf("Error opening database: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	walPath := cfg.Storage.Path + "-wal"
+	out := checkpointOutput{Mode: strings.ToUpper(strings.TrimSpace(checkpointMode))}
+	out.WALBytesBefore = fileSize(walPath)
+	res, err := store.Checkpoint(ctx, checkpointMode)
+	if err != nil {
+		fmt.Printf("Checkpoint failed: %v\n", err)
+		os.Exit(1)
+	}
+	out.CheckpointResult = *res
+	out.WALBytesAfter = fileSize(walPath)
+
+	if outputJSON {
+		if err := writeJSON(os.Stdout, out); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if res.Log < 0 {
+		fmt.Println("Database is not in WAL mode, nothing to checkpoint.")
+		return
+	}
+	fmt.Printf("Checkpoint (%s): %d/%d pages written back", out.Mode, res.Checkpointed, res.Log)
+	if res.Busy != 0 {
+		fmt.Print(" (blocked by another connection, not fully completed)")
+	}
+	fmt.Println()
+	fmt.Printf("WAL size: %s -> %s\n", formatBytes(uint64(out.WALBytesBefore)), formatBytes(uint64(out.WALBytesAfter)))
+}
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "执行数据库迁移",
+	Long: `显式执行未应用的数据库迁移并同步表结构，无需启动完整的 start 流程。
+配合 storage.skip_migrate=true 使用时，可以在升级版本后由运维在受控时间点执行迁移。
+使用 --status 仅列出各迁移版本及其执行时间，不做任何修改。`,
+	Args: cobra.NoArgs,
+	Run:  runMigrate,
+}
+
+var migrateStatus bool
+
+func init() {
+	migrateCmd.Flags().BoolVar(&migrateStatus, "status", false, "仅列出迁移版本及执行状态")
+}
+
+func runMigrate(cmd *cobra.Command, args []string) {
+	if cfg == nil {
+		fmt.Println("Config not loaded")
+		os.Exit(1)
+	}
+	if err := migrateStorage(context.Background(), os.Stdout, cfg.Storage, migrateStatus); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// migrateStorage 执行迁移并输出本次应用的版本；status 为 true 时只输出全部迁移的状态
+func migrateStorage(ctx context.Context, out io.Writer, storageCfg storage.Config, status bool) error {
+	// 由本命令决定是否执行迁移，避免 Open 时已自动应用导致无法报告
+	storageCfg.SkipMigrate = true
+	store, err := storage.Open(ctx, storageCfg)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer store.Close()
+
+	if status {
+		list, err := store.Migrations(ctx)
+		if err != nil {
+			return err
+		}
+		if outputJSON {
+			return writeJSON(out, list)
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, m := range list {
+			state, at := "pending", "-"
+			if m.Applied {
+				state, at = "applied", formatInfoTime(m.AppliedAt)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", m.Version, m.Name, state, at)
+		}
+		return w.Flush()
+	}
+
+	applied, err := store.ApplyMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	if outputJSON {
+		if applied == nil {
+			applied = []storage.MigrationStatus{}
+		}
+		return writeJSON(out, applied)
+	}
+	if len(applied) == 0 {
+		fmt.Fprintln(out, "Database is up to date, no migrations applied.")
+		return nil
+	}
+	for _, m := range applied {
+		fmt.Fprintf(out, "Applied migration %d: %s\n", m.Version, m.Name)
+	}
+	fmt.Fprintf(out, "%d migration(s) applied.\n", len(applied))
+	return nil
+}
+
+// fileSize 返回文件大小，文件不存在或无法读取时为 0
+func fileSize(path string) int64 {
+	st, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return st.Size()
+}
+
+// storageInfo 为 storage info 命令的输出内容
+type storageInfo struct {
+	Path      string `json:"path"`
+	Exists    bool   `json:"exists"`
+	SizeBytes int64  `json:"size_bytes"`
+	// WALBytes/SHMBytes 为 WAL 模式下 -wal/-shm 文件的大小，文件不存在时为 0
+	WALBytes int64                 `json:"wal_bytes"`
+	SHMBytes int64                 `json:"shm_bytes"`
+	Error    string                `json:"error,omitempty"`
+	Database *storage.DatabaseInfo `json:"database,omitempty"`
+	Pool     *poolInfo             `json:"pool,omitempty"`
+}
+
+// poolInfo 为数据库连接池统计，字段与 sql.DBStats 对应
+type poolInfo struct {
+	MaxOpenConnections int           `json:"max_open_connections"`
+	OpenConnections    int           `json:"open_connections"`
+	InUse              int           `json:"in_use"`
+	Idle               int           `json:"idle"`
+	WaitCount          int64         `json:"wait_count"`
+	WaitDuration       time.Duration `json:"wait_duration"`
+	MaxIdleClosed      int64         `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64         `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64         `json:"max_lifetime_closed"`
+}
+
+func newPoolInfo(st sql.DBStats) *poolInfo {
+	return &poolInfo{
+		MaxOpenConnections: st.MaxOpenConnections,
+		OpenConnections:    st.OpenConnections,
+		InUse:              st.InUse,
+		Idle:               st.Idle,
+		WaitCount:          st.WaitCount,
+		WaitDuration:       st.WaitDuration,
+		MaxIdleClosed:      st.MaxIdleClosed,
+		MaxIdleTimeClosed:  st.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  st.MaxLifetimeClosed,
+	}
+}
+
 func runInfo(cmd *cobra.Command, args []string) {
 	ctx := context.Background()
 
@@ -165,8 +387,17 @@ func runInfo(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	info := collectStorageInfo(ctx, cfg.Storage)
+	if err := writeStorageInfo(os.Stdout, info); err != nil {
+		fmt.Printf("Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// collectStorageInfo 收集数据库文件信息、各表大小与时间范围；打开失败时仅返回文件信息
+func collectStorageInfo(ctx context.Context, storageCfg storage.Config) storageInfo {
 	// 1. 获取数据库文件信息
-	dbPath := cfg.Storage.Path
+	dbPath := storageCfg.Path
 	if !filepath.IsAbs(dbPath) {
 		// 尝试转换为绝对路径用于展示（虽然 Open 会处理，但展示绝对路径更友好）
 		if absPath, err := filepath.Abs(dbPath); err == nil {
@@ -174,52 +405,105 @@ func runInfo(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	var dbSizeStr string
-	info, err := os.Stat(dbPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			dbSizeStr = "Not Found (Will be created on first run)"
-		} else {
-			dbSizeStr = fmt.Sprintf("Error: %v", err)
-		}
-	} else {
-		sizeMB := float64(info.Size()) / 1024 / 1024
-		dbSizeStr = fmt.Sprintf("%.2f MB (%s)", sizeMB, dbPath)
+	info := storageInfo{Path: dbPath}
+	if st, err := os.Stat(dbPath); err == nil {
+		info.Exists = true
+		info.SizeBytes = st.Size()
+	} else if !os.IsNotExist(err) {
+		info.Error = err.Error()
 	}
 
 	// 2. 连接数据库
-	store, err := storage.Open(ctx, cfg.Storage)
+	store, err := storage.Open(ctx, storageCfg)
 	if err != nil {
 		// 如果数据库文件不存在，Open 可能会创建它，或者如果目录不存在则报错。
-		// 这里如果报错，可能意味着无法连接，仅打印文件信息。
-		fmt.Printf("Database File: %s\n", dbSizeStr)
-		fmt.Printf("Error opening database: %v\n", err)
-		return
+		// 这里如果报错，可能意味着无法连接，仅返回文件信息。
+		info.Error = fmt.Sprintf("open database: %v", err)
+		return info
 	}
 	defer store.Close()
 
 	// 3. 获取统计信息
-	statsCount, err := store.CountContainerStats(ctx)
+	db, err := store.Info(ctx)
 	if err != nil {
-		fmt.Printf("Error counting stats: %v\n", err)
+		info.Error = fmt.Sprintf("read database info: %v", err)
+	} else {
+		info.Database = db
 	}
-	logsCount, err := store.CountContainerLogs(ctx)
-	if err != nil {
-		fmt.Printf("Error counting logs: %v\n", err)
+	info.Pool = newPoolInfo(store.PoolStats())
+
+	// 4. WAL/SHM 文件在连接打开期间才存在，因此在查询之后读取
+	if st, err := os.Stat(dbPath + "-wal"); err == nil {
+		info.WALBytes = st.Size()
 	}
-	auditCount, err := store.CountAuditRecords(ctx)
-	if err != nil {
-		fmt.Printf("Error counting audit records: %v\n", err)
-	}
-
-	// 4. 格式化输出
-	fmt.Printf("Database File: %s\n\n", dbSizeStr)
-	
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "Table\tCount")
-	fmt.Fprintln(w, "-----\t-----")
-	fmt.Fprintf(w, "ContainerStats\t%d\n", statsCount)
-	fmt.Fprintf(w, "ContainerLogs\t%d\n", logsCount)
-	fmt.Fprintf(w, "AuditRecords\t%d\n", auditCount)
-	w.Flush()
+	if st, err := os.Stat(dbPath + "-shm"); err == nil {
+		info.SHMBytes = st.Size()
+	}
+	return info
+}
+
+// writeStorageInfo 按 --json 输出 JSON，否则输出表格
+func writeStorageInfo(out io.Writer, info storageInfo) error {
+	if outputJSON {
+		return writeJSON(out, info)
+	}
+
+	dbSizeStr := "Not Found (Will be created on first run)"
+	if info.Exists {
+		dbSizeStr = fmt.Sprintf("%.2f MB (%s)", float64(info.SizeBytes)/1024/1024, info.Path)
+	}
+	fmt.Fprintf(out, "Database File: %s\n", dbSizeStr)
+	if info.WALBytes > 0 || info.SHMBytes > 0 {
+		fmt.Fprintf(out, "WAL File:      %s\n", formatBytes(uint64(info.WALBytes)))
+		fmt.Fprintf(out, "SHM File:      %s\n", formatBytes(uint64(info.SHMBytes)))
+	}
+	if info.Error != "" {
+		fmt.Fprintf(out, "Error: %s\n", info.Error)
+	}
+	db := info.Database
+	if db == nil {
+		return nil
+	}
+	fmt.Fprintf(out, "Pages:         %d x %s (%d free)\n", db.PageCount, formatBytes(uint64(db.PageSize)), db.FreePages)
+	if p := info.Pool; p != nil {
+		maxOpen := "unlimited"
+		if p.MaxOpenConnections > 0 {
+			maxOpen = fmt.Sprintf("%d", p.MaxOpenConnections)
+		}
+		fmt.Fprintf(out, "Connections:   %d open (%d in use, %d idle, max %s), %d waits (%s)\n",
+			p.OpenConnections, p.InUse, p.Idle, maxOpen, p.WaitCount, p.WaitDuration.Round(time.Millisecond))
+	}
+	if !db.DBStat {
+		fmt.Fprintln(out, "Note: SQLite was built without dbstat; per-table sizes are unavailable.")
+	}
+	fmt.Fprintln(out)
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tROWS\tTABLE SIZE\tINDEX SIZE\tOLDEST\tNEWEST")
+	for _, t := range db.Tables {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\n",
+			t.Name,
+			t.Rows,
+			formatTableBytes(t.TableBytes),
+			formatTableBytes(t.IndexBytes),
+			formatInfoTime(t.Oldest),
+			formatInfoTime(t.Newest),
+		)
+	}
+	return w.Flush()
+}
+
+// formatTableBytes 格式化表大小，负数表示 dbstat 不可用
+func formatTableBytes(n int64) string {
+	if n < 0 {
+		return "-"
+	}
+	return formatBytes(uint64(n))
+}
+
+func formatInfoTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
 }
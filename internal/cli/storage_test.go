@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wwwzy/CentAgent/internal/config"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+func TestStorageInfoOutput(t *testing.T) {
+	ctx := context.Background()
+
+	c := config.DefaultConfig()
+	c.Storage.Path = filepath.Join(t.TempDir(), "centagent.db")
+
+	store, err := storage.Open(ctx, c.Storage)
+	if err != nil {
+		t.Skipf("storage unavailable: %v", err)
+	}
+	if err := store.InsertAuditRecord(ctx, &storage.AuditRecord{Action: "list_containers", Status: "success"}); err != nil {
+		t.Fatalf("insert audit: %v", err)
+	}
+	_ = store.Close()
+
+	info := collectStorageInfo(ctx, c.Storage)
+	if info.Error != "" {
+		t.Fatalf("unexpected error: %s", info.Error)
+	}
+
+	prev := outputJSON
+	t.Cleanup(func() { outputJSON = prev })
+
+	outputJSON = false
+	var table bytes.Buffer
+	if err := writeStorageInfo(&table, info); err != nil {
+		t.Fatalf("write table: %v", err)
+	}
+	if !strings.Contains(table.String(), "TABLE SIZE") || !strings.Contains(table.String(), "audit_records") {
+		t.Fatalf("unexpected table output:\n%s", table.String())
+	}
+
+	outputJSON = true
+	var out bytes.Buffer
+	if err := writeStorageInfo(&out, info); err != nil {
+		t.Fatalf("write json: %v", err)
+	}
+	var got storageInfo
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not json: %v\n%s", err, out.String())
+	}
+	if !got.Exists || got.Path != info.Path {
+		t.Fatalf("unexpected json info: %+v", got)
+	}
+	if got.Database == nil {
+		t.Fatalf("expected database info in json: %s", out.String())
+	}
+	var audits *storage.TableInfo
+	for i := range got.Database.Tables {
+		if got.Database.Tables[i].Name == "audit_records" {
+			audits = &got.Database.Tables[i]
+		}
+	}
+	if audits == nil || audits.Rows != 1 {
+		t.Fatalf("expected 1 audit record, got %+v", audits)
+	}
+	if audits.Oldest == nil || audits.Newest == nil {
+		t.Fatalf("expected audit time range, got %+v", audits)
+	}
+}
+
+func TestStorageMigrateAndStatus(t *testing.T) {
+	ctx := context.Background()
+
+	c := config.DefaultConfig()
+	c.Storage.Path = filepath.Join(t.TempDir(), "centagent.db")
+	c.Storage.SkipMigrate = true
+
+	prev := outputJSON
+	t.Cleanup(func() { outputJSON = prev })
+	outputJSON = false
+
+	var status bytes.Buffer
+	if err := migrateStorage(ctx, &status, c.Storage, true); err != nil {
+		t.Fatalf("status before migrate: %v", err)
+	}
+	if !strings.Contains(status.String(), "pending") || strings.Contains(status.String(), "applied") {
+		t.Fatalf("expected all migrations pending before migrate:\n%s", status.String())
+	}
+
+	var out bytes.Buffer
+	if err := migrateStorage(ctx, &out, c.Storage, false); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if !strings.Contains(out.String(), "Applied migration 1:") {
+		t.Fatalf("expected applied migrations to be printed:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := migrateStorage(ctx, &out, c.Storage, false); err != nil {
+		t.Fatalf("second migrate: %v", err)
+	}
+	if !strings.Contains(out.String(), "up to date") {
+		t.Fatalf("expected no-op on second migrate:\n%s", out.String())
+	}
+
+	outputJSON = true
+	status.Reset()
+	if err := migrateStorage(ctx, &status, c.Storage, true); err != nil {
+		t.Fatalf("status after migrate: %v", err)
+	}
+	var list []storage.MigrationStatus
+	if err := json.Unmarshal(status.Bytes(), &list); err != nil {
+		t.Fatalf("status is not json: %v\n%s", err, status.String())
+	}
+	if len(list) == 0 {
+		t.Fatalf("expected migrations in status")
+	}
+	for _, m := range list {
+		if !m.Applied || m.AppliedAt == nil {
+			t.Fatalf("expected migration to be applied: %+v", m)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wwwzy/CentAgent/internal/docker"
+)
+
+// topConcurrency 为并发采样容器 stats 的上限
+const topConcurrency = 8
+
+var topInterval time.Duration
+
+// topRow 为 top 命令输出的一行
+type topRow struct {
+	ContainerID   string `json:"container_id"`
+	ContainerName string `json:"container_name"`
+	docker.StatsSummary
+	Error string `json:"error,omitempty"`
+}
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "实时查看运行中容器的资源占用",
+	Long: `直接从 Docker 读取所有运行中容器的实时资源数据并定时刷新，效果类似 docker stats。
+CPU/内存的计算方式与监控采集一致。按 Ctrl+C 退出。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if topInterval <= 0 {
+			return fmt.Errorf("--interval 必须大于 0")
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigChan
+			cancel()
+		}()
+
+		if _, err := docker.GetClient(); err != nil {
+			return fmt.Errorf("连接 docker 失败: %w", err)
+		}
+
+		ticker := time.NewTicker(topInterval)
+		defer ticker.Stop()
+		for {
+			rows, err := collectTopRows(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+			if outputJSON {
+				if err := writeJSON(os.Stdout, rows); err != nil {
+					return err
+				}
+			} else {
+				// 清屏并将光标移到左上角
+				fmt.Print("\033[H\033[2J")
+				fmt.Printf("每 %s 刷新，Ctrl+C 退出  %s\n\n", topInterval, time.Now().Format("15:04:05"))
+				if err := printTop(os.Stdout, rows); err != nil {
+					return err
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+	topCmd.Flags().DurationVar(&topInterval, "interval", 2*time.Second, "刷新间隔")
+}
+
+// collectTopRows 并发采样所有运行中的容器，结果按容器名称排序
+func collectTopRows(ctx context.Context) ([]topRow, error) {
+	containers, err := docker.ListContainers(ctx, docker.ListContainersOptions{All: false})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]topRow, len(containers))
+	sem := make(chan struct{}, topConcurrency)
+	var wg sync.WaitGroup
+	for i, c := range containers {
+		rows[i] = topRow{ContainerID: c.ID, ContainerName: strings.TrimPrefix(c.Names, "/")}
+		wg.Add(1)
+		go func(row *topRow) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			summary, _, err := docker.GetContainerStatsSummary(ctx, row.ContainerID)
+			if err != nil {
+				row.Error = err.Error()
+				return
+			}
+			row.StatsSummary = summary
+		}(&rows[i])
+	}
+	wg.Wait()
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ContainerName < rows[j].ContainerName })
+	return rows, nil
+}
+
+func printTop(out io.Writer, rows []topRow) error {
+	if len(rows) == 0 {
+		fmt.Fprintln(out, "没有运行中的容器。")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CONTAINER ID\tNAME\tCPU %\tMEM USAGE / LIMIT\tMEM %\tNET I/O\tBLOCK I/O\tPIDS")
+	for _, r := range rows {
+		if r.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\t-\t-\t-\t-\t-\t-\t(%s)\n", docker.ShortID(r.ContainerID), r.ContainerName, truncateLine(r.Error, 60))
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.2f%%\t%s / %s\t%.2f%%\t%s / %s\t%s / %s\t%d\n",
+			docker.ShortID(r.ContainerID),
+			r.ContainerName,
+			r.CPUPercent,
+			formatBytes(r.MemUsageBytes), formatBytes(r.MemLimitBytes),
+			r.MemPercent,
+			formatBytes(r.NetRxBytes), formatBytes(r.NetTxBytes),
+			formatBytes(r.BlockReadBytes), formatBytes(r.BlockWriteBytes),
+			r.Pids,
+		)
+	}
+	return w.Flush()
+}
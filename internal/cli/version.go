@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wwwzy/CentAgent/internal/docker"
+	"github.com/wwwzy/CentAgent/internal/version"
+)
+
+// versionOutput 为 version 命令的输出内容
+type versionOutput struct {
+	version.Info
+	DockerAPIVersion string `json:"docker_api_version,omitempty"`
+	DockerError      string `json:"docker_error,omitempty"`
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "显示版本与构建信息",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := versionOutput{Info: version.Get()}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if p, err := docker.Ping(ctx); err != nil {
+			out.DockerError = err.Error()
+		} else {
+			out.DockerAPIVersion = p.APIVersion
+		}
+
+		if outputJSON {
+			return writeJSON(os.Stdout, out)
+		}
+
+		fmt.Printf("Version:     %s\n", out.Version)
+		fmt.Printf("Git Commit:  %s\n", out.Commit)
+		fmt.Printf("Built:       %s\n", out.BuildDate)
+		fmt.Printf("Go Version:  %s\n", out.GoVersion)
+		fmt.Printf("OS/Arch:     %s\n", out.Platform)
+		if out.DockerError != "" {
+			fmt.Printf("Docker API:  unavailable (%s)\n", out.DockerError)
+		} else {
+			fmt.Printf("Docker API:  %s\n", out.DockerAPIVersion)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
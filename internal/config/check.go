@@ -0,0 +1,197 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wwwzy/CentAgent/internal/docker"
+	"github.com/wwwzy/CentAgent/internal/monitor"
+)
+
+// maskedKeys 为展示配置时需要打码的敏感字段
+var maskedKeys = [][]string{
+	{"ark", "api_key"},
+}
+
+// MaskedSettings 返回打码敏感字段后的合并配置项，原始 Settings 不受影响
+func (l *Loaded) MaskedSettings() map[string]any {
+	out := copySettings(l.Settings)
+	for _, path := range maskedKeys {
+		m := out
+		for _, key := range path[:len(path)-1] {
+			next, ok := m[key].(map[string]any)
+			if !ok {
+				m = nil
+				break
+			}
+			m = next
+		}
+		if m == nil {
+			continue
+		}
+		last := path[len(path)-1]
+		if s, ok := m[last].(string); ok && s != "" {
+			m[last] = maskSecret(s)
+		}
+	}
+	maskRegistries(out)
+	return out
+}
+
+// maskRegistries 打码 registries 列表中每一项的 password；列表元素需复制后再修改
+func maskRegistries(settings map[string]any) {
+	list, ok := settings["registries"].([]any)
+	if !ok {
+		return
+	}
+	masked := make([]any, len(list))
+	for i, item := range list {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			masked[i] = item
+			continue
+		}
+		entry = copySettings(entry)
+		if s, ok := entry["password"].(string); ok && s != "" {
+			entry["password"] = maskSecret(s)
+		}
+		masked[i] = entry
+	}
+	settings["registries"] = masked
+}
+
+func copySettings(in map[string]any) map[string]any {
+	out := make(map[string]any, len(in))
+	for k, v := range in {
+		if m, ok := v.(map[string]any); ok {
+			out[k] = copySettings(m)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// maskSecret 只保留末尾 4 个字符，其余替换为 *
+func maskSecret(s string) string {
+	if len(s) <= 8 {
+		return "****"
+	}
+	return "****" + s[len(s)-4:]
+}
+
+// Problems 检查配置并返回发现的全部问题；无问题时返回 nil。Validate 与 config validate 共用这里的规则
+func (c *Config) Problems() []string {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Ark.APIKey == "" {
+		add("ark.api_key is required (or set ARK_API_KEY env var)")
+	}
+	if c.Ark.ModelID == "" {
+		add("ark.model_id is required (or set ARK_MODEL_ID env var)")
+	}
+
+	switch strings.ToLower(c.LogLevel) {
+	case "debug", "info", "warn", "error":
+	default:
+		add("log_level must be one of debug/info/warn/error, got %q", c.LogLevel)
+	}
+	switch strings.ToLower(c.LogFormat) {
+	case "", "text", "json":
+	default:
+		add("log_format must be text or json, got %q", c.LogFormat)
+	}
+
+	if strings.TrimSpace(c.Storage.Path) == "" {
+		add("storage.path is required")
+	}
+	if c.Storage.BusyTimeout < 0 {
+		add("storage.busy_timeout must not be negative, got %s", c.Storage.BusyTimeout)
+	}
+
+	if c.Docker.Host != "" {
+		if err := docker.ValidateHost(c.Docker.Host); err != nil {
+			add("docker.host: %v", err)
+		}
+	}
+
+	if c.Agent.MaxStep < 0 {
+		add("agent.max_step must not be negative, got %d", c.Agent.MaxStep)
+	}
+
+	if c.Docker.ShortIDLength < 0 {
+		add("docker.short_id_length must not be negative, got %d", c.Docker.ShortIDLength)
+	}
+
+	m := c.Monitor
+	if m.Stats.Enabled {
+		if m.Stats.Interval <= 0 {
+			add("monitor.stats.interval must be positive, got %s", m.Stats.Interval)
+		}
+		if m.Stats.FlushInterval <= 0 {
+			add("monitor.stats.flush_interval must be positive, got %s", m.Stats.FlushInterval)
+		}
+		if m.Stats.Workers <= 0 {
+			add("monitor.stats.workers must be positive, got %d", m.Stats.Workers)
+		}
+		if m.Stats.FetchTimeout < 0 {
+			add("monitor.stats.fetch_timeout must not be negative, got %s", m.Stats.FetchTimeout)
+		}
+		switch strings.ToLower(m.Stats.Mode) {
+		case "", monitor.StatsModeOneShot, monitor.StatsModeStream:
+		default:
+			add("monitor.stats.mode must be %s or %s, got %q", monitor.StatsModeOneShot, monitor.StatsModeStream, m.Stats.Mode)
+		}
+		if err := monitor.ValidateStatsCollect(m.Stats.Collect); err != nil {
+			add("monitor.stats.collect: %v", err)
+		}
+	}
+	if m.Logs.Enabled && m.Logs.FlushInterval <= 0 {
+		add("monitor.logs.flush_interval must be positive, got %s", m.Logs.FlushInterval)
+	}
+	if m.Retention.Enabled {
+		if m.Retention.Interval <= 0 {
+			add("monitor.retention.interval must be positive, got %s", m.Retention.Interval)
+		}
+	}
+	// storage prune-monitor 在未启用定时清理时也会使用保留策略，因此始终检查；
+	// 且需满足 keep_all <= 上界，否则 withDefaults 会静默把上界抬高到 keep_all
+	if m.Retention.Stats.KeepAll > m.Retention.Stats.KeepAnomalyUntil {
+		add("monitor.retention.stats.keep_all (%s) must not exceed monitor.retention.stats.keep_anomaly_until (%s)",
+			m.Retention.Stats.KeepAll, m.Retention.Stats.KeepAnomalyUntil)
+	}
+	if m.Retention.Logs.KeepAll > m.Retention.Logs.KeepImportantUntil {
+		add("monitor.retention.logs.keep_all (%s) must not exceed monitor.retention.logs.keep_important_until (%s)",
+			m.Retention.Logs.KeepAll, m.Retention.Logs.KeepImportantUntil)
+	}
+	if m.Retention.Stats.MaxTotalRows < 0 {
+		add("monitor.retention.stats.max_total_rows must not be negative, got %d", m.Retention.Stats.MaxTotalRows)
+	}
+	if m.Retention.Logs.MaxTotalRows < 0 {
+		add("monitor.retention.logs.max_total_rows must not be negative, got %d", m.Retention.Logs.MaxTotalRows)
+	}
+	if m.Alert.CPUHigh < 0 || m.Alert.CPUHigh > 100 {
+		add("monitor.alert.cpu_high must be within 0-100, got %g", m.Alert.CPUHigh)
+	}
+	if m.Alert.MemHigh < 0 || m.Alert.MemHigh > 100 {
+		add("monitor.alert.mem_high must be within 0-100, got %g", m.Alert.MemHigh)
+	}
+	if m.Alert.SustainedFor < 0 {
+		add("monitor.alert.sustained_for must not be negative, got %s", m.Alert.SustainedFor)
+	}
+	if m.Alert.Cooldown < 0 {
+		add("monitor.alert.cooldown must not be negative, got %s", m.Alert.Cooldown)
+	}
+	if m.Alert.ResolveAfter < 0 {
+		add("monitor.alert.resolve_after must not be negative, got %s", m.Alert.ResolveAfter)
+	}
+
+	if err := docker.ValidateRegistries(c.Registries); err != nil {
+		add("%v", err)
+	}
+
+	return problems
+}
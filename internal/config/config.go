@@ -1,12 +1,16 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 	"github.com/wwwzy/CentAgent/internal/agent"
+	"github.com/wwwzy/CentAgent/internal/docker"
 	"github.com/wwwzy/CentAgent/internal/monitor"
 	"github.com/wwwzy/CentAgent/internal/storage"
 )
@@ -15,22 +19,66 @@ type Config struct {
 	Storage  storage.Config  `mapstructure:"storage"`
 	Monitor  monitor.Config  `mapstructure:"monitor"`
 	Ark      agent.ArkConfig `mapstructure:"ark"`
+	Agent    agent.Config    `mapstructure:"agent"`
+	Docker   docker.Config   `mapstructure:"docker"`
+	Daemon   DaemonConfig    `mapstructure:"daemon"`
 	LogLevel string          `mapstructure:"log_level"`
+	// LogFormat 为内部日志的输出格式：text 或 json
+	LogFormat string `mapstructure:"log_format"`
+	// Registries 为私有镜像仓库凭据，按镜像引用中的仓库地址匹配
+	Registries []docker.RegistryConfig `mapstructure:"registries"`
+}
+
+// DaemonConfig 为 start --daemon 后台模式的配置
+type DaemonConfig struct {
+	// PIDFile 为后台进程的 PID 文件路径，stop 命令据此发送停止信号
+	PIDFile string `mapstructure:"pid_file"`
+	// LogFile 为后台模式下 stdout/stderr 的重定向文件
+	LogFile string `mapstructure:"log_file"`
+	// ControlSocket 为 start 启动的本地控制接口（unix socket，仅当前用户可访问），为空表示不启用
+	ControlSocket string `mapstructure:"control_socket"`
+	// HealthAddr 为 /healthz、/readyz 健康检查接口的监听地址（如 127.0.0.1:8081），为空表示不启用
+	HealthAddr string `mapstructure:"health_addr"`
 }
 
 func Load(cfgFile string) (*Config, error) {
+	loaded, err := LoadUnvalidated(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. 验证关键配置
+	if err := loaded.Config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return loaded.Config, nil
+}
+
+// Loaded 为未经校验的加载结果，供 config show/validate 等命令展示实际生效的配置
+type Loaded struct {
+	Config *Config
+	// File 为实际读取的配置文件；未找到配置文件时为空
+	File string
+	// Settings 为合并后的全部配置项（文件 + 环境变量 + 默认值）
+	Settings map[string]any
+}
+
+// LoadUnvalidated 按与 Load 相同的优先级加载配置，但不执行 Validate
+func LoadUnvalidated(cfgFile string) (*Loaded, error) {
 	// 1. 初始化 Viper
 	v := viper.New()
 
+	if cfgFile == "" {
+		// 默认搜索路径，按 configSearchExts 的顺序查找 config.*
+		cfgFile = findConfigFile(configSearchPaths)
+	}
 	if cfgFile != "" {
 		v.SetConfigFile(cfgFile)
-	} else {
-		// 默认搜索路径
-		v.AddConfigPath(".")
-		v.AddConfigPath("./configs")
-		v.AddConfigPath("$HOME/.centagent")
-		v.SetConfigName("config")
-		v.SetConfigType("yaml")
+		// 按扩展名自动识别格式；无扩展名时按 YAML 解析
+		if filepath.Ext(cfgFile) == "" {
+			v.SetConfigType("yaml")
+		}
 	}
 
 	v.SetEnvPrefix("CENTAGENT")
@@ -52,12 +100,11 @@ func Load(cfgFile string) (*Config, error) {
 
 	setDefaults(v)
 
-	// 2. 读取配置文件
-	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+	// 2. 读取配置文件；未找到配置文件时使用默认值
+	if cfgFile != "" {
+		if err := v.ReadInConfig(); err != nil {
 			return nil, fmt.Errorf("读取配置文件失败: %w", err)
 		}
-		// 配置文件未找到，使用默认值
 	}
 
 	// 3. 反序列化 (文件/环境变量 覆盖 默认值)
@@ -66,23 +113,41 @@ func Load(cfgFile string) (*Config, error) {
 		return nil, fmt.Errorf("解析配置失败: %w", err)
 	}
 
-	// 4. 验证关键配置
-	if err := cfg.Validate(); err != nil {
-		return nil, err
-	}
+	return &Loaded{
+		Config:   &cfg,
+		File:     v.ConfigFileUsed(),
+		Settings: v.AllSettings(),
+	}, nil
+}
+
+// configSearchPaths 为未指定 --config 时的默认搜索目录
+var configSearchPaths = []string{".", "./configs", "$HOME/.centagent"}
+
+// configSearchExts 为默认搜索的配置文件扩展名，同一目录下按此顺序优先
+var configSearchExts = []string{"yaml", "yml", "toml", "json"}
 
-	return &cfg, nil
+// findConfigFile 依次在各目录中查找 config.{yaml,yml,toml,json}，未找到时返回空字符串
+func findConfigFile(dirs []string) string {
+	for _, dir := range dirs {
+		dir = os.ExpandEnv(dir)
+		for _, ext := range configSearchExts {
+			path := filepath.Join(dir, "config."+ext)
+			if st, err := os.Stat(path); err == nil && !st.IsDir() {
+				return path
+			}
+		}
+	}
+	return ""
 }
 
+// Validate 校验配置，返回 Problems 发现的全部问题；无问题时返回 nil
 func (c *Config) Validate() error {
-	// Ark 配置验证：必须存在
-	if c.Ark.APIKey == "" {
-		return fmt.Errorf("ark.api_key is required (or set ARK_API_KEY env var)")
-	}
-	if c.Ark.ModelID == "" {
-		return fmt.Errorf("ark.model_id is required (or set ARK_MODEL_ID env var)")
+	problems := c.Problems()
+	errs := make([]error, 0, len(problems))
+	for _, p := range problems {
+		errs = append(errs, errors.New(p))
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 func setDefaults(v *viper.Viper) {
@@ -90,12 +155,33 @@ func setDefaults(v *viper.Viper) {
 	// Global Defaults (全局默认值)
 	// -------------------------------------------------------------------------
 	v.SetDefault("log_level", "info")
+	v.SetDefault("log_format", "text")
+
+	// -------------------------------------------------------------------------
+	// Docker Defaults (Docker 连接默认值)
+	// -------------------------------------------------------------------------
+	v.SetDefault("docker.host", "")
+	v.SetDefault("docker.short_id_length", docker.DefaultShortIDLength)
+
+	// -------------------------------------------------------------------------
+	// Daemon Defaults (后台模式默认值)
+	// -------------------------------------------------------------------------
+	v.SetDefault("daemon.pid_file", "centagent.pid")
+	v.SetDefault("daemon.log_file", "centagent.log")
+	v.SetDefault("daemon.control_socket", "centagent.sock")
+	v.SetDefault("daemon.health_addr", "")
 
 	// -------------------------------------------------------------------------
 	// Storage Defaults (存储默认值)
 	// -------------------------------------------------------------------------
 	v.SetDefault("storage.path", "centagent.db")
+	v.SetDefault("storage.in_memory", false)
+	v.SetDefault("storage.skip_migrate", false)
 	v.SetDefault("storage.busy_timeout", 5*time.Second)
+	// 连接池：0 表示沿用 database/sql 的默认行为（不限制打开数、保留 2 个空闲连接、不回收）
+	v.SetDefault("storage.max_open_conns", 0)
+	v.SetDefault("storage.max_idle_conns", 0)
+	v.SetDefault("storage.conn_max_lifetime", time.Duration(0))
 
 	// -------------------------------------------------------------------------
 	// Monitor Stats Defaults (状态采集默认值)
@@ -107,7 +193,14 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("monitor.stats.queue_size", monitorDefaults.Stats.QueueSize)
 	v.SetDefault("monitor.stats.batch_size", monitorDefaults.Stats.BatchSize)
 	v.SetDefault("monitor.stats.flush_interval", monitorDefaults.Stats.FlushInterval)
+	v.SetDefault("monitor.stats.fetch_timeout", monitorDefaults.Stats.FetchTimeout)
+	v.SetDefault("monitor.stats.transactional", monitorDefaults.Stats.Transactional)
 	v.SetDefault("monitor.stats.max_raw_json_bytes", monitorDefaults.Stats.MaxRawJSONBytes)
+	v.SetDefault("monitor.stats.store_raw_json", monitorDefaults.Stats.StoreRawJSON)
+	v.BindEnv("monitor.stats.store_raw_json", "CENTAGENT_MONITOR_STATS_STORE_RAW_JSON")
+	v.SetDefault("monitor.stats.capture_labels", monitorDefaults.Stats.CaptureLabels)
+	v.SetDefault("monitor.stats.collect", []string{})
+	v.SetDefault("monitor.stats.mode", monitorDefaults.Stats.Mode)
 
 	// -------------------------------------------------------------------------
 	// Monitor Logs Defaults (日志采集默认值)
@@ -116,11 +209,13 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("monitor.logs.queue_size", monitorDefaults.Logs.QueueSize)
 	v.SetDefault("monitor.logs.batch_size", monitorDefaults.Logs.BatchSize)
 	v.SetDefault("monitor.logs.flush_interval", monitorDefaults.Logs.FlushInterval)
+	v.SetDefault("monitor.logs.transactional", monitorDefaults.Logs.Transactional)
 	v.SetDefault("monitor.logs.max_line_bytes", monitorDefaults.Logs.MaxLineBytes)
 	v.SetDefault("monitor.logs.tailer_limit", monitorDefaults.Logs.TailerLimit)
 	v.SetDefault("monitor.logs.since_from_start", monitorDefaults.Logs.SinceFromStart)
 	v.SetDefault("monitor.logs.reconnect_delay", monitorDefaults.Logs.ReconnectDelay)
 	v.SetDefault("monitor.logs.reconnect_jitter", monitorDefaults.Logs.ReconnectJitter)
+	v.SetDefault("monitor.logs.reconcile_interval", monitorDefaults.Logs.ReconcileInterval)
 
 	// -------------------------------------------------------------------------
 	// Monitor Retention Defaults (数据清理默认值)
@@ -130,16 +225,46 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("monitor.retention.workers", monitorDefaults.Retention.Workers)
 	v.SetDefault("monitor.retention.batch_rows", monitorDefaults.Retention.BatchRows)
 	v.SetDefault("monitor.retention.idle_sleep", monitorDefaults.Retention.IdleSleep)
+	v.SetDefault("monitor.retention.checkpoint_after_rows", monitorDefaults.Retention.CheckpointAfterRows)
 
 	// Retention Stats Policy
 	v.SetDefault("monitor.retention.stats.keep_all", monitorDefaults.Retention.Stats.KeepAll)
 	v.SetDefault("monitor.retention.stats.keep_anomaly_until", monitorDefaults.Retention.Stats.KeepAnomalyUntil)
 	v.SetDefault("monitor.retention.stats.cpu_high", monitorDefaults.Retention.Stats.CPUHigh)
 	v.SetDefault("monitor.retention.stats.mem_high", monitorDefaults.Retention.Stats.MemHigh)
+	v.SetDefault("monitor.retention.stats.max_total_rows", monitorDefaults.Retention.Stats.MaxTotalRows)
 
 	// Retention Logs Policy
 	v.SetDefault("monitor.retention.logs.keep_all", monitorDefaults.Retention.Logs.KeepAll)
 	v.SetDefault("monitor.retention.logs.keep_important_until", monitorDefaults.Retention.Logs.KeepImportantUntil)
+	v.SetDefault("monitor.retention.logs.max_total_rows", monitorDefaults.Retention.Logs.MaxTotalRows)
+
+	// Retention Sessions Policy
+	v.SetDefault("monitor.retention.sessions.keep_for", monitorDefaults.Retention.Sessions.KeepFor)
+
+	// -------------------------------------------------------------------------
+	// Monitor Alert Defaults (资源告警默认值)
+	// -------------------------------------------------------------------------
+	v.SetDefault("monitor.alert.enabled", monitorDefaults.Alert.Enabled)
+	v.SetDefault("monitor.alert.cpu_high", monitorDefaults.Alert.CPUHigh)
+	v.SetDefault("monitor.alert.mem_high", monitorDefaults.Alert.MemHigh)
+	v.SetDefault("monitor.alert.sustained_for", monitorDefaults.Alert.SustainedFor)
+	v.SetDefault("monitor.alert.cooldown", monitorDefaults.Alert.Cooldown)
+	v.SetDefault("monitor.alert.resolve_after", monitorDefaults.Alert.ResolveAfter)
+	v.SetDefault("monitor.alert.oom", monitorDefaults.Alert.OOM)
+
+	// -------------------------------------------------------------------------
+	// Monitor Host Defaults (宿主机采样默认值)
+	// -------------------------------------------------------------------------
+	v.SetDefault("monitor.host.enabled", monitorDefaults.Host.Enabled)
+	v.SetDefault("monitor.host.interval", monitorDefaults.Host.Interval)
+	v.SetDefault("monitor.host.fetch_timeout", monitorDefaults.Host.FetchTimeout)
+
+	// -------------------------------------------------------------------------
+	// Agent Defaults (Agent 行为默认值)
+	// -------------------------------------------------------------------------
+	v.SetDefault("agent.system_prompt_file", "")
+	v.SetDefault("agent.max_step", agent.DefaultMaxStep)
 
 	// -------------------------------------------------------------------------
 	// Ark AI Defaults (AI 模型默认值)
@@ -156,11 +281,23 @@ func setDefaults(v *viper.Viper) {
 
 func DefaultConfig() Config {
 	return Config{
-		LogLevel: "info",
+		LogLevel:  "info",
+		LogFormat: "text",
 		Storage: storage.Config{
 			Path:        "centagent.db",
 			BusyTimeout: 5 * time.Second,
 		},
 		Monitor: monitor.DefaultConfig(),
+		Agent: agent.Config{
+			MaxStep: agent.DefaultMaxStep,
+		},
+		Docker: docker.Config{
+			ShortIDLength: docker.DefaultShortIDLength,
+		},
+		Daemon: DaemonConfig{
+			PIDFile:       "centagent.pid",
+			LogFile:       "centagent.log",
+			ControlSocket: "centagent.sock",
+		},
 	}
 }
@@ -3,10 +3,12 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/wwwzy/CentAgent/internal/agent"
 	"github.com/wwwzy/CentAgent/internal/monitor"
 	"github.com/wwwzy/CentAgent/internal/storage"
 )
@@ -64,6 +66,51 @@ monitor:
 	assert.Equal(t, monitor.DefaultConfig().Logs.QueueSize, cfg.Monitor.Logs.QueueSize)
 }
 
+func TestLoad_TOMLConfigFile(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	content := []byte(`
+log_level = "debug"
+
+[ark]
+api_key = "file-key"
+model_id = "file-model"
+
+[storage]
+path = "test.db"
+busy_timeout = "10s"
+
+[monitor.stats]
+enabled = false
+interval = "1m"
+`)
+	assert.NoError(t, os.WriteFile(configFile, content, 0644))
+
+	cfg, err := Load(configFile)
+	assert.NoError(t, err)
+
+	// 与 YAML 配置文件的解析结果一致
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, "test.db", cfg.Storage.Path)
+	assert.Equal(t, 10*time.Second, cfg.Storage.BusyTimeout)
+	assert.False(t, cfg.Monitor.Stats.Enabled)
+	assert.Equal(t, 1*time.Minute, cfg.Monitor.Stats.Interval)
+	assert.Equal(t, monitor.DefaultConfig().Logs.QueueSize, cfg.Monitor.Logs.QueueSize)
+}
+
+func TestFindConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, "", findConfigFile([]string{dir}))
+
+	jsonFile := filepath.Join(dir, "config.json")
+	assert.NoError(t, os.WriteFile(jsonFile, []byte(`{"log_level":"warn"}`), 0644))
+	assert.Equal(t, jsonFile, findConfigFile([]string{dir}))
+
+	// 同一目录下 YAML 优先
+	yamlFile := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, os.WriteFile(yamlFile, []byte("log_level: debug\n"), 0644))
+	assert.Equal(t, yamlFile, findConfigFile([]string{dir}))
+}
+
 func TestLoad_EnvOverride(t *testing.T) {
 	// 设置环境变量
 	t.Setenv("CENTAGENT_LOG_LEVEL", "warn")
@@ -83,6 +130,42 @@ func TestLoad_EnvOverride(t *testing.T) {
 	assert.Equal(t, 5*time.Minute, cfg.Monitor.Stats.Interval)
 }
 
+func TestLoad_AlertConfig(t *testing.T) {
+	t.Setenv("ARK_API_KEY", "dummy-key")
+	t.Setenv("ARK_MODEL_ID", "dummy-model")
+
+	cfg, err := Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, monitor.DefaultConfig().Alert.CPUHigh, cfg.Monitor.Alert.CPUHigh)
+	assert.Equal(t, monitor.DefaultConfig().Alert.MemHigh, cfg.Monitor.Alert.MemHigh)
+	assert.Equal(t, 5*time.Minute, cfg.Monitor.Alert.SustainedFor)
+	assert.False(t, cfg.Monitor.Alert.Enabled)
+
+	t.Setenv("CENTAGENT_MONITOR_ALERT_CPU_HIGH", "75.5")
+	cfg, err = Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, 75.5, cfg.Monitor.Alert.CPUHigh)
+
+	t.Setenv("CENTAGENT_MONITOR_ALERT_CPU_HIGH", "150")
+	_, err = Load("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "monitor.alert.cpu_high")
+}
+
+func TestLoad_AgentMaxStep(t *testing.T) {
+	t.Setenv("ARK_API_KEY", "dummy-key")
+	t.Setenv("ARK_MODEL_ID", "dummy-model")
+
+	cfg, err := Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, agent.DefaultMaxStep, cfg.Agent.MaxStep)
+
+	t.Setenv("CENTAGENT_AGENT_MAX_STEP", "40")
+	cfg, err = Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, 40, cfg.Agent.MaxStep)
+}
+
 func TestDefaultConfig(t *testing.T) {
 	cfg := DefaultConfig()
 	
@@ -101,3 +184,217 @@ func TestLoad_ValidateArk(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "ark.api_key is required")
 }
+
+func TestLoad_ValidateRetentionOrdering(t *testing.T) {
+	t.Setenv("ARK_API_KEY", "dummy-key")
+	t.Setenv("ARK_MODEL_ID", "dummy-model")
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := []byte(`
+monitor:
+  retention:
+    stats:
+      keep_all: "48h"
+      keep_anomaly_until: "24h"
+`)
+	assert.NoError(t, os.WriteFile(configFile, content, 0644))
+
+	_, err := Load(configFile)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "monitor.retention.stats.keep_all (48h0m0s)")
+	assert.Contains(t, err.Error(), "monitor.retention.stats.keep_anomaly_until (24h0m0s)")
+
+	t.Setenv("CENTAGENT_MONITOR_RETENTION_STATS_KEEP_ALL", "12h")
+	t.Setenv("CENTAGENT_MONITOR_RETENTION_LOGS_KEEP_ALL", "240h")
+	_, err = Load(configFile)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "monitor.retention.logs.keep_important_until")
+}
+
+func TestLoadUnvalidated_MaskedSettings(t *testing.T) {
+	t.Setenv("ARK_API_KEY", "")
+	t.Setenv("ARK_MODEL_ID", "")
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := []byte(`
+ark:
+  api_key: "sk-1234567890abcd"
+  model_id: "file-model"
+storage:
+  path: "show.db"
+`)
+	assert.NoError(t, os.WriteFile(configFile, content, 0644))
+
+	loaded, err := LoadUnvalidated(configFile)
+	assert.NoError(t, err)
+	assert.Equal(t, configFile, loaded.File)
+	assert.Equal(t, "show.db", loaded.Config.Storage.Path)
+
+	masked := loaded.MaskedSettings()
+	ark, ok := masked["ark"].(map[string]any)
+	assert.True(t, ok)
+	assert.Equal(t, "****abcd", ark["api_key"])
+	assert.Equal(t, "file-model", ark["model_id"])
+
+	// 原始配置不受打码影响
+	assert.Equal(t, "sk-1234567890abcd", loaded.Config.Ark.APIKey)
+	assert.Equal(t, "sk-1234567890abcd", loaded.Settings["ark"].(map[string]any)["api_key"])
+}
+
+func TestProblems(t *testing.T) {
+	t.Setenv("ARK_API_KEY", "")
+	t.Setenv("ARK_MODEL_ID", "")
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := []byte(`
+log_level: "verbose"
+monitor:
+  stats:
+    interval: "0s"
+  retention:
+    stats:
+      keep_all: "48h"
+      keep_anomaly_until: "24h"
+`)
+	assert.NoError(t, os.WriteFile(configFile, content, 0644))
+
+	loaded, err := LoadUnvalidated(configFile)
+	assert.NoError(t, err)
+
+	problems := strings.Join(loaded.Config.Problems(), "\n")
+	assert.Contains(t, problems, "ark.api_key is required")
+	assert.Contains(t, problems, "ark.model_id is required")
+	assert.Contains(t, problems, "log_level")
+	assert.Contains(t, problems, "monitor.stats.interval must be positive")
+	assert.Contains(t, problems, "monitor.retention.stats.keep_all")
+
+	cfg := DefaultConfig()
+	cfg.Ark.APIKey = "key"
+	cfg.Ark.ModelID = "model"
+	assert.Empty(t, cfg.Problems())
+}
+
+func TestLoad_Registries(t *testing.T) {
+	t.Setenv("ARK_API_KEY", "dummy-key")
+	t.Setenv("ARK_MODEL_ID", "dummy-model")
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := []byte(`
+registries:
+  - host: "ghcr.io"
+    username: "bot"
+    password: "ghp_1234567890abcd"
+  - host: "registry.example.com:5000"
+    helper: "pass"
+`)
+	assert.NoError(t, os.WriteFile(configFile, content, 0644))
+
+	loaded, err := LoadUnvalidated(configFile)
+	assert.NoError(t, err)
+	assert.NoError(t, loaded.Config.Validate())
+	assert.Len(t, loaded.Config.Registries, 2)
+	assert.Equal(t, "ghcr.io", loaded.Config.Registries[0].Host)
+	assert.Equal(t, "pass", loaded.Config.Registries[1].Helper)
+
+	// config show 中 password 被打码，原始配置不受影响
+	masked := loaded.MaskedSettings()
+	list, ok := masked["registries"].([]any)
+	assert.True(t, ok)
+	assert.Equal(t, "****abcd", list[0].(map[string]any)["password"])
+	assert.Equal(t, "ghp_1234567890abcd", loaded.Config.Registries[0].Password)
+	assert.Equal(t, "ghp_1234567890abcd", loaded.Settings["registries"].([]any)[0].(map[string]any)["password"])
+}
+
+func TestLoad_ValidateRegistries(t *testing.T) {
+	t.Setenv("ARK_API_KEY", "dummy-key")
+	t.Setenv("ARK_MODEL_ID", "dummy-model")
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := []byte(`
+registries:
+  - username: "bot"
+    password: "secret"
+`)
+	assert.NoError(t, os.WriteFile(configFile, content, 0644))
+
+	_, err := Load(configFile)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "host is required")
+}
+
+func TestLoad_StoragePool(t *testing.T) {
+	t.Setenv("ARK_API_KEY", "dummy-key")
+	t.Setenv("ARK_MODEL_ID", "dummy-model")
+
+	// 默认为 0，即沿用 database/sql 的连接池行为
+	cfg, err := Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cfg.Storage.MaxOpenConns)
+	assert.Equal(t, 0, cfg.Storage.MaxIdleConns)
+	assert.Equal(t, time.Duration(0), cfg.Storage.ConnMaxLifetime)
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := []byte(`
+storage:
+  busy_timeout: "10s"
+  max_open_conns: 1
+  max_idle_conns: 1
+  conn_max_lifetime: "30m"
+`)
+	assert.NoError(t, os.WriteFile(configFile, content, 0644))
+
+	cfg, err = Load(configFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Second, cfg.Storage.BusyTimeout)
+	assert.Equal(t, 1, cfg.Storage.MaxOpenConns)
+	assert.Equal(t, 1, cfg.Storage.MaxIdleConns)
+	assert.Equal(t, 30*time.Minute, cfg.Storage.ConnMaxLifetime)
+
+	// 环境变量覆盖配置文件
+	t.Setenv("CENTAGENT_STORAGE_MAX_OPEN_CONNS", "4")
+	cfg, err = Load(configFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, cfg.Storage.MaxOpenConns)
+}
+
+func TestLoad_StatsCollect(t *testing.T) {
+	t.Setenv("ARK_API_KEY", "dummy-key")
+	t.Setenv("ARK_MODEL_ID", "dummy-model")
+
+	cfg, err := Load("")
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.Monitor.Stats.Collect)
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(configFile, []byte(`
+monitor:
+  stats:
+    collect: ["cpu", "mem"]
+`), 0644))
+	cfg, err = Load(configFile)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cpu", "mem"}, cfg.Monitor.Stats.Collect)
+
+	assert.NoError(t, os.WriteFile(configFile, []byte(`
+monitor:
+  stats:
+    collect: ["cpu", "disk"]
+`), 0644))
+	_, err = Load(configFile)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "monitor.stats.collect")
+}
+
+func TestLoad_StoreRawJSON(t *testing.T) {
+	t.Setenv("ARK_API_KEY", "dummy-key")
+	t.Setenv("ARK_MODEL_ID", "dummy-model")
+
+	cfg, err := Load("")
+	assert.NoError(t, err)
+	assert.True(t, cfg.Monitor.Stats.StoreRawJSON)
+
+	t.Setenv("CENTAGENT_MONITOR_STATS_STORE_RAW_JSON", "false")
+	cfg, err = Load("")
+	assert.NoError(t, err)
+	assert.False(t, cfg.Monitor.Stats.StoreRawJSON)
+}
@@ -1,28 +1,100 @@
 package docker
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"sync"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 )
 
 var (
 	dockerCli *client.Client
 	once      sync.Once
+
+	// dockerHost 为显式指定的 Docker 端点，非空时覆盖 DOCKER_HOST
+	dockerHost  string
+	initialized bool
+	mu          sync.Mutex
 )
 
+// Config 为 Docker 连接配置
+type Config struct {
+	// Host 为 Docker daemon 地址（如 unix:///var/run/docker.sock、tcp://host:2376），留空时使用 DOCKER_HOST 或默认 socket
+	Host string `mapstructure:"host"`
+	// ShortIDLength 为列表输出中 ID 的截断长度，默认 12
+	ShortIDLength int `mapstructure:"short_id_length"`
+}
+
+// supportedSchemes 为 Docker SDK 原生支持的传输协议
+var supportedSchemes = map[string]struct{}{
+	"unix":  {},
+	"npipe": {},
+	"tcp":   {},
+	"http":  {},
+	"https": {},
+}
+
+// ValidateHost 校验 Docker 端点地址，不支持的传输协议会返回明确的错误
+func ValidateHost(host string) error {
+	host = strings.TrimSpace(host)
+	if host == "" {
+		return errors.New("docker host is empty")
+	}
+	u, err := url.Parse(host)
+	if err != nil || u.Scheme == "" {
+		return fmt.Errorf("invalid docker host %q: expected <scheme>://<address>", host)
+	}
+	if u.Scheme == "ssh" {
+		return fmt.Errorf("unsupported docker host %q: ssh:// is not supported, forward the remote socket (e.g. ssh -L) and use unix:// or tcp:// instead", host)
+	}
+	if _, ok := supportedSchemes[u.Scheme]; !ok {
+		return fmt.Errorf("unsupported docker host %q: scheme must be one of unix, npipe, tcp, http, https", host)
+	}
+	return nil
+}
+
+// SetHost 指定 Docker 端点，必须在第一次调用 GetClient 之前设置
+func SetHost(host string) error {
+	if err := ValidateHost(host); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if initialized {
+		return errors.New("docker client already initialized")
+	}
+	dockerHost = strings.TrimSpace(host)
+	return nil
+}
+
+// clientOptions 返回创建 Client 使用的选项，显式指定的 Host 放在 FromEnv 之后以覆盖 DOCKER_HOST
+func clientOptions() []client.Opt {
+	opts := []client.Opt{
+		client.FromEnv,
+		client.WithAPIVersionNegotiation(),
+	}
+	if dockerHost != "" {
+		opts = append(opts, client.WithHost(dockerHost))
+	}
+	return opts
+}
+
 // GetClient 获取 Docker Client 单例
 // 懒加载模式，第一次调用时初始化
 func GetClient() (*client.Client, error) {
 	var err error
 	once.Do(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		initialized = true
 		// 使用 FromEnv 自动读取环境变量 (DOCKER_HOST, etc.)
 		// 并在 API 版本协商上自动适配
-		dockerCli, err = client.NewClientWithOpts(
-			client.FromEnv,
-			client.WithAPIVersionNegotiation(),
-		)
+		dockerCli, err = client.NewClientWithOpts(clientOptions()...)
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create docker client: %w", err)
@@ -30,6 +102,49 @@ func GetClient() (*client.Client, error) {
 	return dockerCli, nil
 }
 
+// Ping 探测 Docker daemon，返回协商后的 API 版本等信息
+func Ping(ctx context.Context) (types.Ping, error) {
+	cli, err := GetClient()
+	if err != nil {
+		return types.Ping{}, err
+	}
+	p, err := cli.Ping(ctx)
+	if err != nil {
+		return types.Ping{}, fmt.Errorf("failed to ping docker daemon: %w", err)
+	}
+	return p, nil
+}
+
+// HostInfo 为 docker daemon 所在宿主机的概况
+type HostInfo struct {
+	NCPU              int    `json:"ncpu"`
+	MemTotalBytes     uint64 `json:"mem_total_bytes"`
+	ContainersRunning int    `json:"containers_running"`
+	ContainersPaused  int    `json:"containers_paused"`
+	ContainersStopped int    `json:"containers_stopped"`
+	Images            int    `json:"images"`
+}
+
+// GetHostInfo 通过 docker info 获取宿主机核数、内存总量与容器/镜像数量
+func GetHostInfo(ctx context.Context) (HostInfo, error) {
+	cli, err := GetClient()
+	if err != nil {
+		return HostInfo{}, err
+	}
+	info, err := cli.Info(ctx)
+	if err != nil {
+		return HostInfo{}, fmt.Errorf("failed to get docker info: %w", err)
+	}
+	return HostInfo{
+		NCPU:              info.NCPU,
+		MemTotalBytes:     uint64(max(info.MemTotal, 0)),
+		ContainersRunning: info.ContainersRunning,
+		ContainersPaused:  info.ContainersPaused,
+		ContainersStopped: info.ContainersStopped,
+		Images:            info.Images,
+	}, nil
+}
+
 // CloseClient 关闭 Docker Client 连接
 // 建议在程序退出时调用
 func CloseClient() error {
@@ -0,0 +1,66 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/client"
+)
+
+func TestClientOptionsOverrideDockerHost(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "unix:///tmp/from-env.sock")
+
+	prev := dockerHost
+	t.Cleanup(func() { dockerHost = prev })
+
+	dockerHost = ""
+	cli, err := client.NewClientWithOpts(clientOptions()...)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if got := cli.DaemonHost(); got != "unix:///tmp/from-env.sock" {
+		t.Fatalf("expected DOCKER_HOST to be used, got %s", got)
+	}
+
+	dockerHost = "tcp://127.0.0.1:2375"
+	cli, err = client.NewClientWithOpts(clientOptions()...)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if got := cli.DaemonHost(); got != "tcp://127.0.0.1:2375" {
+		t.Fatalf("expected explicit host to override DOCKER_HOST, got %s", got)
+	}
+}
+
+func TestValidateHost(t *testing.T) {
+	for _, host := range []string{"unix:///var/run/docker.sock", "tcp://10.0.0.2:2376", "npipe:////./pipe/docker_engine"} {
+		if err := ValidateHost(host); err != nil {
+			t.Fatalf("expected %s to be valid: %v", host, err)
+		}
+	}
+	for _, host := range []string{"", "ssh://user@host", "ftp://host", "/var/run/docker.sock"} {
+		if err := ValidateHost(host); err == nil {
+			t.Fatalf("expected %q to be rejected", host)
+		}
+	}
+}
+
+func TestDisplayID(t *testing.T) {
+	t.Cleanup(func() { SetShortIDLength(DefaultShortIDLength) })
+
+	id := "0123456789abcdef0123456789abcdef"
+	if got := displayID(id, false); got != "0123456789ab" {
+		t.Fatalf("expected default truncation, got %s", got)
+	}
+	if got := displayID(id, true); got != id {
+		t.Fatalf("expected full id, got %s", got)
+	}
+
+	SetShortIDLength(8)
+	if got := displayID(id, false); got != "01234567" {
+		t.Fatalf("expected 8-char id, got %s", got)
+	}
+	SetShortIDLength(0)
+	if got := displayID(id, false); got != "0123456789ab" {
+		t.Fatalf("expected default after reset, got %s", got)
+	}
+}
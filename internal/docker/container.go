@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/go-connections/nat"
@@ -19,6 +22,12 @@ type ListContainersOptions struct {
 	All    bool
 	Limit  int
 	Status string // running, exited, paused
+	// Name 为可选的容器名称过滤（Docker 服务端按子串匹配）
+	Name string `json:"name"`
+	// FullID 为 true 时返回完整的容器 ID，默认按 ShortIDLength 截断
+	FullID bool `json:"full_id"`
+	// Labels 为 true 时返回容器标签；默认不返回以减小输出
+	Labels bool `json:"labels"`
 }
 
 // ContainerSummary 简化版的容器列表信息
@@ -29,6 +38,8 @@ type ContainerSummary struct {
 	Status  string `json:"status"`
 	State   string `json:"state"`
 	Created int64  `json:"created"`
+	// Labels 仅在 ListContainersOptions.Labels 为 true 时填充
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // ListContainers 列出容器
@@ -39,8 +50,9 @@ func ListContainers(ctx context.Context, opts ListContainersOptions) ([]Containe
 	}
 
 	listOpts := container.ListOptions{
-		All:   opts.All,
-		Limit: opts.Limit,
+		All:     opts.All,
+		Limit:   opts.Limit,
+		Filters: listContainersFilters(opts),
 	}
 
 	containers, err := cli.ContainerList(ctx, listOpts)
@@ -50,24 +62,41 @@ func ListContainers(ctx context.Context, opts ListContainersOptions) ([]Containe
 
 	var result []ContainerSummary
 	for _, c := range containers {
-		// 客户端简单的 Status 过滤
+		// 服务端已按 status 过滤，这里保留客户端校验以兼容不支持该过滤的旧版 daemon
 		if opts.Status != "" && c.State != opts.Status {
 			continue
 		}
 
-		result = append(result, ContainerSummary{
-			ID:      truncateID(c.ID),
+		summary := ContainerSummary{
+			ID:      displayID(c.ID, opts.FullID),
 			Names:   strings.Join(c.Names, ","),
 			Image:   c.Image,
 			Status:  c.Status,
 			State:   c.State,
 			Created: c.Created,
-		})
+		}
+		if opts.Labels {
+			summary.Labels = c.Labels
+		}
+		result = append(result, summary)
 	}
 
 	return result, nil
 }
 
+// listContainersFilters 将 Status/Name 转换为 Docker 服务端过滤条件；
+// 指定 status 时 daemon 会包含已停止的容器，无需同时设置 All。
+func listContainersFilters(opts ListContainersOptions) filters.Args {
+	f := filters.NewArgs()
+	if s := strings.TrimSpace(opts.Status); s != "" {
+		f.Add("status", s)
+	}
+	if n := strings.TrimPrefix(strings.TrimSpace(opts.Name), "/"); n != "" {
+		f.Add("name", n)
+	}
+	return f
+}
+
 // ListContainerDetail 列出详细容器
 func ListContainerDetail(ctx context.Context, opts ListContainersOptions) ([]ContainerSummary, error) {
 	cli, err := GetClient()
@@ -112,6 +141,10 @@ type InspectContainerDetail struct {
 	Image   string                `json:"image"`
 	Created string                `json:"created"`
 	Config  *container.Config     `json:"config"`
+	// SizeRw 为可写层大小（字节），SizeRootFs 为包含镜像在内的根文件系统总大小；
+	// 仅在 InspectContainerWithSize 时填充。
+	SizeRw     *int64 `json:"size_rw,omitempty"`
+	SizeRootFs *int64 `json:"size_root_fs,omitempty"`
 }
 
 // InspectContainer 获取容器详情
@@ -126,14 +159,40 @@ func InspectContainer(ctx context.Context, containerID string) (*InspectContaine
 		return nil, fmt.Errorf("failed to inspect container %s: %w", containerID, err)
 	}
 
-	return &InspectContainerDetail{
-		ID:      jsonRaw.ID,
-		Name:    jsonRaw.Name,
-		State:   jsonRaw.State,
-		Image:   jsonRaw.Config.Image,
-		Created: jsonRaw.Created,
-		Config:  jsonRaw.Config,
-	}, nil
+	return newInspectContainerDetail(jsonRaw), nil
+}
+
+// InspectContainerWithSize 获取容器详情并计算可写层与根文件系统大小。
+// 计算大小需要遍历容器文件系统，开销较大，仅在需要时使用。
+func InspectContainerWithSize(ctx context.Context, containerID string) (*InspectContainerDetail, error) {
+	cli, err := GetClient()
+	if err != nil {
+		return nil, err
+	}
+
+	jsonRaw, _, err := cli.ContainerInspectWithRaw(ctx, containerID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container %s: %w", containerID, err)
+	}
+
+	detail := newInspectContainerDetail(jsonRaw)
+	detail.SizeRw = jsonRaw.SizeRw
+	detail.SizeRootFs = jsonRaw.SizeRootFs
+	return detail, nil
+}
+
+func newInspectContainerDetail(info container.InspectResponse) *InspectContainerDetail {
+	detail := &InspectContainerDetail{
+		ID:      info.ID,
+		Name:    info.Name,
+		State:   info.State,
+		Created: info.Created,
+		Config:  info.Config,
+	}
+	if info.Config != nil {
+		detail.Image = info.Config.Image
+	}
+	return detail
 }
 
 // InspectContainerDeatil 获取容器详细详情
@@ -145,6 +204,143 @@ func InspectContainerDeatil(ctx context.Context, containerID string) (container.
 	return cli.ContainerInspect(ctx, containerID)
 }
 
+// ContainerRestartInfo 汇总容器的重启次数与最近一次退出信息，用于排查容器反复重启的原因。
+type ContainerRestartInfo struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Status        string `json:"status"`
+	Restarting    bool   `json:"restarting"`
+	RestartCount  int    `json:"restart_count"`
+	RestartPolicy string `json:"restart_policy,omitempty"`
+	// ExitCode/OOMKilled/Error 为最近一次退出的退出码、是否被 OOM killer 杀死与 daemon 记录的错误。
+	ExitCode  int    `json:"exit_code"`
+	OOMKilled bool   `json:"oom_killed"`
+	Error     string `json:"error,omitempty"`
+	StartedAt string `json:"started_at,omitempty"`
+	// FinishedAt 为最近一次退出时间；容器从未退出过时为空。
+	FinishedAt string `json:"finished_at,omitempty"`
+}
+
+// GetContainerRestartInfo 获取容器的重启次数与最近一次退出信息。
+func GetContainerRestartInfo(ctx context.Context, containerID string) (*ContainerRestartInfo, error) {
+	cli, err := GetClient()
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container %s: %w", containerID, err)
+	}
+	return newContainerRestartInfo(info), nil
+}
+
+// ContainerDiff 返回容器文件系统相对其镜像的变更（新增/修改/删除的路径），容器停止后仍可查询。
+func ContainerDiff(ctx context.Context, containerID string) ([]container.FilesystemChange, error) {
+	cli, err := GetClient()
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := cli.ContainerDiff(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff container %s: %w", containerID, err)
+	}
+	return changes, nil
+}
+
+// zeroDockerTime 为 daemon 表示“未发生”的时间戳
+const zeroDockerTime = "0001-01-01T00:00:00Z"
+
+func newContainerRestartInfo(info container.InspectResponse) *ContainerRestartInfo {
+	out := &ContainerRestartInfo{
+		ID:           info.ID,
+		Name:         strings.TrimPrefix(info.Name, "/"),
+		RestartCount: info.RestartCount,
+	}
+	if st := info.State; st != nil {
+		out.Status = st.Status
+		out.Restarting = st.Restarting
+		out.ExitCode = st.ExitCode
+		out.OOMKilled = st.OOMKilled
+		out.Error = st.Error
+		if st.StartedAt != zeroDockerTime {
+			out.StartedAt = st.StartedAt
+		}
+		if st.FinishedAt != zeroDockerTime {
+			out.FinishedAt = st.FinishedAt
+		}
+	}
+	if hc := info.HostConfig; hc != nil && hc.RestartPolicy.Name != "" {
+		out.RestartPolicy = string(hc.RestartPolicy.Name)
+		if hc.RestartPolicy.MaximumRetryCount > 0 {
+			out.RestartPolicy += ":" + strconv.Itoa(hc.RestartPolicy.MaximumRetryCount)
+		}
+	}
+	return out
+}
+
+// ResolveContainer 将容器名称、完整 ID 或 ID 前缀解析为完整 ID 与名称（不含前导 /）。
+// 前缀匹配到多个容器时返回 "multiple containers match" 错误。
+func ResolveContainer(ctx context.Context, ref string) (id string, name string, err error) {
+	ref = strings.TrimPrefix(strings.TrimSpace(ref), "/")
+	if ref == "" {
+		return "", "", fmt.Errorf("container reference is empty")
+	}
+
+	cli, err := GetClient()
+	if err != nil {
+		return "", "", err
+	}
+
+	info, inspectErr := cli.ContainerInspect(ctx, ref)
+	if inspectErr == nil {
+		return info.ID, strings.TrimPrefix(info.Name, "/"), nil
+	}
+
+	// inspect 失败（不存在或前缀有歧义）时列出全部容器自行匹配，给出更明确的错误
+	containers, err := cli.ContainerList(ctx, container.ListOptions{All: true})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve container %s: %w", ref, inspectErr)
+	}
+	matches := matchContainerRef(ref, containers)
+	switch len(matches) {
+	case 0:
+		return "", "", fmt.Errorf("failed to resolve container %s: %w", ref, inspectErr)
+	case 1:
+		return matches[0].ID, primaryName(matches[0].Names), nil
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, c := range matches {
+			ids = append(ids, fmt.Sprintf("%s (%s)", displayID(c.ID, false), primaryName(c.Names)))
+		}
+		return "", "", fmt.Errorf("multiple containers match %q: %s", ref, strings.Join(ids, ", "))
+	}
+}
+
+// matchContainerRef 返回名称完全匹配或 ID 以 ref 为前缀的容器；名称匹配优先
+func matchContainerRef(ref string, containers []container.Summary) []container.Summary {
+	var byID []container.Summary
+	for _, c := range containers {
+		for _, n := range c.Names {
+			if strings.TrimPrefix(n, "/") == ref {
+				return []container.Summary{c}
+			}
+		}
+		if strings.HasPrefix(c.ID, ref) {
+			byID = append(byID, c)
+		}
+	}
+	return byID
+}
+
+func primaryName(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(names[0], "/")
+}
+
 // StartContainer 启动容器
 func StartContainer(ctx context.Context, containerID string) error {
 	cli, err := GetClient()
@@ -173,6 +369,56 @@ func RestartContainer(ctx context.Context, containerID string) error {
 	return cli.ContainerRestart(ctx, containerID, container.StopOptions{})
 }
 
+// ParseRestartPolicy 解析 no/always/unless-stopped/on-failure[:n] 形式的重启策略；
+// 重试次数仅适用于 on-failure，且不能为负数。
+func ParseRestartPolicy(s string) (container.RestartPolicy, error) {
+	name, count, hasCount := strings.Cut(strings.ToLower(strings.TrimSpace(s)), ":")
+	policy := container.RestartPolicy{Name: container.RestartPolicyMode(name)}
+	switch policy.Name {
+	case container.RestartPolicyDisabled, container.RestartPolicyAlways, container.RestartPolicyUnlessStopped:
+		if hasCount {
+			return container.RestartPolicy{}, fmt.Errorf("restart policy %q does not accept a retry count", name)
+		}
+	case container.RestartPolicyOnFailure:
+		if hasCount {
+			n, err := strconv.Atoi(count)
+			if err != nil || n < 0 {
+				return container.RestartPolicy{}, fmt.Errorf("invalid retry count %q for on-failure: must be a non-negative integer", count)
+			}
+			policy.MaximumRetryCount = n
+		}
+	default:
+		return container.RestartPolicy{}, fmt.Errorf("invalid restart policy %q: want no, always, unless-stopped or on-failure[:n]", s)
+	}
+	return policy, nil
+}
+
+// UpdateRestartPolicy 在不重建容器的情况下修改重启策略，并重新 inspect 返回生效后的策略。
+func UpdateRestartPolicy(ctx context.Context, containerID string, policy container.RestartPolicy) (container.RestartPolicy, error) {
+	cli, err := GetClient()
+	if err != nil {
+		return container.RestartPolicy{}, err
+	}
+
+	if _, err := cli.ContainerUpdate(ctx, containerID, container.UpdateConfig{RestartPolicy: policy}); err != nil {
+		return container.RestartPolicy{}, fmt.Errorf("failed to update restart policy of %s: %w", containerID, err)
+	}
+
+	info, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return container.RestartPolicy{}, fmt.Errorf("failed to inspect container %s: %w", containerID, err)
+	}
+	if info.HostConfig == nil {
+		return container.RestartPolicy{}, fmt.Errorf("container %s has no host config", containerID)
+	}
+	applied := info.HostConfig.RestartPolicy
+	if applied.Name != policy.Name || applied.MaximumRetryCount != policy.MaximumRetryCount {
+		return applied, fmt.Errorf("restart policy of %s is %s:%d after update, want %s:%d",
+			containerID, applied.Name, applied.MaximumRetryCount, policy.Name, policy.MaximumRetryCount)
+	}
+	return applied, nil
+}
+
 // Events 获取容器事件流
 func Events(ctx context.Context, opts events.ListOptions) (<-chan events.Message, <-chan error) {
 	cli, err := GetClient()
@@ -232,8 +478,43 @@ type RunContainerFromImageOptions struct {
 	// 支持：hostPort:containerPort、hostIP:hostPort:containerPort，
 	// 并可在 containerPort 上附带协议：containerPort/tcp 或 containerPort/udp。
 	Publish []string
+	// Devices 设备映射，语法与 docker CLI --device 一致：host[:container][:perms]，
+	// perms 为 rwm 的子集，默认 rwm。例如：/dev/snd 或 /dev/sda:/dev/xvdc:r
+	Devices []string
+	// ExtraHosts 额外的 /etc/hosts 条目（host:ip）。
+	ExtraHosts []string
+	// DNS 自定义 DNS 服务器地址。
+	DNS []string
+	// Tmpfs tmpfs 挂载点到挂载选项的映射，例如 {"/tmp": "rw,size=64m"}，选项可为空。
+	Tmpfs map[string]string
+	// ReadonlyRootfs 是否以只读方式挂载容器根文件系统。
+	ReadonlyRootfs bool
+	// ShmSize /dev/shm 大小（字节），0 表示使用 Docker 默认值。
+	ShmSize int64
 	// PullIfMissing 若本地不存在镜像，是否尝试拉取。
 	PullIfMissing bool
+	// IfExists 同名容器已存在时的处理方式：fail（默认）、reuse（复用并在停止时启动）、
+	// replace（强制删除后重新创建）。仅在指定 Name 时生效。
+	IfExists string
+}
+
+// IfExists 可选值。
+const (
+	IfExistsFail    = "fail"
+	IfExistsReuse   = "reuse"
+	IfExistsReplace = "replace"
+)
+
+// parseIfExists 校验 IfExists，空值视为 fail。
+func parseIfExists(s string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(s)); mode {
+	case "", IfExistsFail:
+		return IfExistsFail, nil
+	case IfExistsReuse, IfExistsReplace:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid if_exists %q: want fail, reuse or replace", s)
+	}
 }
 
 // RunContainerResult 启动容器的结果（用于对外输出）。
@@ -242,8 +523,22 @@ type RunContainerResult struct {
 	ContainerID string `json:"container_id"`
 	// Name 容器名（含前导 /）。
 	Name string `json:"name"`
+	// PortMappings 启动后实际绑定的端口（含 Docker 分配的临时端口）。
+	PortMappings []PortMapping `json:"port_mappings,omitempty"`
 	// Warnings Docker 可能返回的警告信息。
 	Warnings []string `json:"warnings,omitempty"`
+	// Reused 为 true 表示返回的是已存在的同名容器（IfExists=reuse）。
+	Reused bool `json:"reused,omitempty"`
+	// Replaced 为 true 表示已删除同名旧容器后重新创建（IfExists=replace）。
+	Replaced bool `json:"replaced,omitempty"`
+}
+
+// PortMapping 容器端口到宿主机地址的映射。
+type PortMapping struct {
+	// ContainerPort 容器端口及协议，例如 80/tcp。
+	ContainerPort string `json:"container_port"`
+	HostIP        string `json:"host_ip"`
+	HostPort      string `json:"host_port"`
 }
 
 // RunContainerFromImage 从镜像创建并启动一个容器。
@@ -257,6 +552,37 @@ func RunContainerFromImage(ctx context.Context, opts RunContainerFromImageOption
 	if imageRef == "" {
 		return nil, fmt.Errorf("image is required")
 	}
+	ifExists, err := parseIfExists(opts.IfExists)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(opts.Name)
+	replaced := false
+	if name != "" && ifExists != IfExistsFail {
+		existing, err := cli.ContainerInspect(ctx, name)
+		switch {
+		case err == nil && ifExists == IfExistsReuse:
+			if existing.State == nil || !existing.State.Running {
+				if err := cli.ContainerStart(ctx, existing.ID, container.StartOptions{}); err != nil {
+					return nil, fmt.Errorf("failed to start existing container %s: %w", name, err)
+				}
+			}
+			res, err := inspectRunResult(ctx, existing.ID, nil)
+			if err != nil {
+				return nil, err
+			}
+			res.Reused = true
+			return res, nil
+		case err == nil && ifExists == IfExistsReplace:
+			if err := cli.ContainerRemove(ctx, existing.ID, container.RemoveOptions{Force: true}); err != nil {
+				return nil, fmt.Errorf("failed to remove existing container %s: %w", name, err)
+			}
+			replaced = true
+		case err != nil && !IsNotFound(err):
+			return nil, fmt.Errorf("failed to inspect container %s: %w", name, err)
+		}
+	}
 
 	if opts.PullIfMissing {
 		if _, _, err := cli.ImageInspectWithRaw(ctx, imageRef); err != nil {
@@ -269,6 +595,28 @@ func RunContainerFromImage(ctx context.Context, opts RunContainerFromImageOption
 		}
 	}
 
+	var devices []container.DeviceMapping
+	for _, spec := range opts.Devices {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		d, err := ParseDeviceSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, d)
+	}
+
+	for target, mountOpts := range opts.Tmpfs {
+		if err := validateTmpfs(target, mountOpts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.ShmSize < 0 {
+		return nil, fmt.Errorf("invalid shm size %d: must not be negative", opts.ShmSize)
+	}
+
 	exposed := nat.PortSet{}
 	portBindings := nat.PortMap{}
 	for _, spec := range opts.Publish {
@@ -304,9 +652,15 @@ func RunContainerFromImage(ctx context.Context, opts RunContainerFromImageOption
 	}
 
 	hostCfg := &container.HostConfig{
-		AutoRemove:   opts.AutoRemove,
-		Binds:        opts.Binds,
-		PortBindings: portBindings,
+		AutoRemove:     opts.AutoRemove,
+		Binds:          opts.Binds,
+		PortBindings:   portBindings,
+		ExtraHosts:     opts.ExtraHosts,
+		DNS:            opts.DNS,
+		Resources:      container.Resources{Devices: devices},
+		Tmpfs:          opts.Tmpfs,
+		ShmSize:        opts.ShmSize,
+		ReadonlyRootfs: opts.ReadonlyRootfs,
 	}
 	if strings.TrimSpace(opts.RestartPolicy) != "" {
 		hostCfg.RestartPolicy = container.RestartPolicy{Name: container.RestartPolicyMode(strings.TrimSpace(opts.RestartPolicy))}
@@ -319,7 +673,7 @@ func RunContainerFromImage(ctx context.Context, opts RunContainerFromImageOption
 		}
 	}
 
-	resp, err := cli.ContainerCreate(ctx, cfg, hostCfg, netCfg, nil, strings.TrimSpace(opts.Name))
+	resp, err := cli.ContainerCreate(ctx, cfg, hostCfg, netCfg, nil, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create container from image %s: %w", imageRef, err)
 	}
@@ -328,19 +682,157 @@ func RunContainerFromImage(ctx context.Context, opts RunContainerFromImageOption
 		return nil, fmt.Errorf("failed to start container %s: %w", resp.ID, err)
 	}
 
-	inspected, err := cli.ContainerInspect(ctx, resp.ID)
+	res, err := inspectRunResult(ctx, resp.ID, resp.Warnings)
 	if err != nil {
-		return &RunContainerResult{
-			ContainerID: resp.ID,
-			Warnings:    resp.Warnings,
-		}, nil
+		res = &RunContainerResult{ContainerID: resp.ID, Warnings: resp.Warnings}
 	}
+	res.Replaced = replaced
+	return res, nil
+}
 
-	return &RunContainerResult{
-		ContainerID: resp.ID,
+// inspectRunResult 通过 inspect 填充容器名与实际端口绑定。
+func inspectRunResult(ctx context.Context, containerID string, warnings []string) (*RunContainerResult, error) {
+	cli, err := GetClient()
+	if err != nil {
+		return nil, err
+	}
+	inspected, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container %s: %w", containerID, err)
+	}
+	res := &RunContainerResult{
+		ContainerID: inspected.ID,
 		Name:        inspected.Name,
-		Warnings:    resp.Warnings,
-	}, nil
+		Warnings:    warnings,
+	}
+	if inspected.NetworkSettings != nil {
+		res.PortMappings = portMappings(inspected.NetworkSettings.Ports)
+	}
+	return res, nil
+}
+
+// portMappings 将 inspect 得到的端口绑定展开为按容器端口排序的列表，忽略未发布的端口。
+func portMappings(ports nat.PortMap) []PortMapping {
+	var out []PortMapping
+	for port, bindings := range ports {
+		for _, b := range bindings {
+			out = append(out, PortMapping{
+				ContainerPort: string(port),
+				HostIP:        b.HostIP,
+				HostPort:      b.HostPort,
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ContainerPort != out[j].ContainerPort {
+			return out[i].ContainerPort < out[j].ContainerPort
+		}
+		return out[i].HostIP < out[j].HostIP
+	})
+	return out
+}
+
+// ParseDeviceSpec 解析 host[:container][:perms] 形式的设备映射。
+// 省略 container 时与 host 路径相同，省略 perms 时为 rwm。
+func ParseDeviceSpec(spec string) (container.DeviceMapping, error) {
+	parts := strings.Split(strings.TrimSpace(spec), ":")
+	d := container.DeviceMapping{CgroupPermissions: "rwm"}
+	switch len(parts) {
+	case 1:
+		d.PathOnHost = parts[0]
+	case 2:
+		d.PathOnHost = parts[0]
+		if isDevicePerms(parts[1]) {
+			d.CgroupPermissions = parts[1]
+		} else {
+			d.PathInContainer = parts[1]
+		}
+	case 3:
+		if !isDevicePerms(parts[2]) {
+			return container.DeviceMapping{}, fmt.Errorf("invalid device spec %q: permissions must be a combination of r, w and m", spec)
+		}
+		d.PathOnHost, d.PathInContainer, d.CgroupPermissions = parts[0], parts[1], parts[2]
+	default:
+		return container.DeviceMapping{}, fmt.Errorf("invalid device spec %q", spec)
+	}
+	if d.PathInContainer == "" {
+		d.PathInContainer = d.PathOnHost
+	}
+	if !strings.HasPrefix(d.PathOnHost, "/") || !strings.HasPrefix(d.PathInContainer, "/") {
+		return container.DeviceMapping{}, fmt.Errorf("invalid device spec %q: device paths must be absolute", spec)
+	}
+	return d, nil
+}
+
+func isDevicePerms(s string) bool {
+	if s == "" || len(s) > 3 {
+		return false
+	}
+	seen := map[rune]bool{}
+	for _, c := range s {
+		if !strings.ContainsRune("rwm", c) || seen[c] {
+			return false
+		}
+		seen[c] = true
+	}
+	return true
+}
+
+// ParseTmpfsSpecs 将 docker CLI --tmpfs 语法（/path[:options]）转换为 HostConfig.Tmpfs 映射。
+func ParseTmpfsSpecs(specs []string) (map[string]string, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		target, mountOpts, _ := strings.Cut(spec, ":")
+		if err := validateTmpfs(target, mountOpts); err != nil {
+			return nil, err
+		}
+		out[target] = mountOpts
+	}
+	return out, nil
+}
+
+// tmpfsFlags 为 tmpfs 允许的无值挂载选项。
+var tmpfsFlags = map[string]bool{
+	"rw": true, "ro": true,
+	"exec": true, "noexec": true,
+	"suid": true, "nosuid": true,
+	"dev": true, "nodev": true,
+	"atime": true, "noatime": true,
+	"diratime": true, "nodiratime": true,
+	"relatime": true, "norelatime": true,
+	"strictatime": true, "nostrictatime": true,
+}
+
+// tmpfsValueOpts 为 tmpfs 允许的 key=value 挂载选项。
+var tmpfsValueOpts = map[string]bool{
+	"size": true, "mode": true, "uid": true, "gid": true,
+	"nr_inodes": true, "nr_blocks": true, "mpol": true,
+}
+
+func validateTmpfs(target, mountOpts string) error {
+	if !strings.HasPrefix(target, "/") {
+		return fmt.Errorf("invalid tmpfs mount %q: target must be an absolute path", target)
+	}
+	if strings.TrimSpace(mountOpts) == "" {
+		return nil
+	}
+	for _, o := range strings.Split(mountOpts, ",") {
+		key, val, hasVal := strings.Cut(strings.TrimSpace(o), "=")
+		switch {
+		case !hasVal && tmpfsFlags[key]:
+		case hasVal && tmpfsValueOpts[key] && val != "":
+		default:
+			return fmt.Errorf("invalid tmpfs option %q for %s", o, target)
+		}
+	}
+	return nil
 }
 
 func parsePublishSpec(spec string) (hostIP string, hostPort string, contPart string, err error) {
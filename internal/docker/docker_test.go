@@ -12,6 +12,7 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -29,6 +30,37 @@ func requireDocker(t *testing.T) {
 	}
 }
 
+// localShellImage 返回本地带 sh 的 alpine/busybox 镜像标签，未找到时返回空串
+func localShellImage(ctx context.Context) string {
+	cli, err := GetClient()
+	if err != nil {
+		return ""
+	}
+	images, err := cli.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		return ""
+	}
+	for _, img := range images {
+		for _, tag := range img.RepoTags {
+			if strings.Contains(tag, "alpine") || strings.Contains(tag, "busybox") {
+				return tag
+			}
+		}
+	}
+	return ""
+}
+
+// requireShellImage 返回本地可用于运行 shell 命令的镜像，未找到时跳过测试
+func requireShellImage(t *testing.T) string {
+	t.Helper()
+
+	imageName := localShellImage(context.Background())
+	if imageName == "" {
+		t.Skip("no local alpine/busybox image to run a container")
+	}
+	return imageName
+}
+
 // setupTestContainer 启动一个测试用的容器 (nginx:alpine)，如果本地没有镜像会自动拉取
 // 返回容器ID和清理函数
 func setupTestContainer(t *testing.T, ctx context.Context) (string, func()) {
@@ -40,23 +72,10 @@ func setupTestContainer(t *testing.T, ctx context.Context) (string, func()) {
 	}
 
 	// 尝试优先使用本地镜像
-	var imageName string
-	images, err := cli.ImageList(ctx, image.ListOptions{All: false})
-	if err == nil && len(images) > 0 {
-		for _, img := range images {
-			if len(img.RepoTags) > 0 && img.RepoTags[0] != "<none>:<none>" {
-				tag := img.RepoTags[0]
-				if strings.Contains(tag, "alpine") || strings.Contains(tag, "busybox") {
-					imageName = tag
-					t.Logf("Using local image: %s", imageName)
-					break
-				}
-				continue
-			}
-		}
-	}
-
-	if imageName == "" {
+	imageName := localShellImage(ctx)
+	if imageName != "" {
+		t.Logf("Using local image: %s", imageName)
+	} else {
 		imageName = "nginx:alpine"
 		t.Logf("No local image found, trying to pull %s...", imageName)
 		// 1. 检查并拉取镜像
@@ -174,6 +193,39 @@ func TestListContainers(t *testing.T) {
 	}
 }
 
+func TestListContainersFullID(t *testing.T) {
+	requireDocker(t)
+
+	ctx := context.Background()
+	id, cleanup := setupTestContainer(t, ctx)
+	defer cleanup()
+
+	short, err := ListContainers(ctx, ListContainersOptions{All: true})
+	if err != nil {
+		t.Fatalf("ListContainers failed: %v", err)
+	}
+	full, err := ListContainers(ctx, ListContainersOptions{All: true, FullID: true})
+	if err != nil {
+		t.Fatalf("ListContainers(FullID) failed: %v", err)
+	}
+
+	if !containsID(short, id[:DefaultShortIDLength]) {
+		t.Fatalf("expected truncated id %s in list", id[:DefaultShortIDLength])
+	}
+	if !containsID(full, id) {
+		t.Fatalf("expected full id %s in list", id)
+	}
+}
+
+func containsID(containers []ContainerSummary, id string) bool {
+	for _, c := range containers {
+		if c.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
 func TestInspectContainer(t *testing.T) {
 	requireDocker(t)
 
@@ -190,6 +242,73 @@ func TestInspectContainer(t *testing.T) {
 		t.Errorf("Expected ID %s, got %s", containerID, info.ID)
 	}
 	t.Log("Inspected container image", info)
+	if info.SizeRw != nil || info.SizeRootFs != nil {
+		t.Errorf("size fields should be empty without with_size, got %v/%v", info.SizeRw, info.SizeRootFs)
+	}
+}
+
+func TestInspectContainerWithSize(t *testing.T) {
+	requireDocker(t)
+
+	ctx := context.Background()
+	containerID, cleanup := setupTestContainer(t, ctx)
+	defer cleanup()
+
+	info, err := InspectContainerWithSize(ctx, containerID)
+	if err != nil {
+		t.Fatalf("InspectContainerWithSize failed: %v", err)
+	}
+	if info.SizeRw == nil || info.SizeRootFs == nil {
+		t.Fatalf("expected size fields to be populated, got %v/%v", info.SizeRw, info.SizeRootFs)
+	}
+	if *info.SizeRootFs <= 0 || *info.SizeRw < 0 {
+		t.Fatalf("unexpected sizes: rw=%d rootfs=%d", *info.SizeRw, *info.SizeRootFs)
+	}
+}
+
+func TestResolveContainer(t *testing.T) {
+	requireDocker(t)
+
+	ctx := context.Background()
+	containerID, cleanup := setupTestContainer(t, ctx)
+	defer cleanup()
+
+	info, err := InspectContainer(ctx, containerID)
+	if err != nil {
+		t.Fatalf("InspectContainer failed: %v", err)
+	}
+	wantName := strings.TrimPrefix(info.Name, "/")
+
+	for _, ref := range []string{wantName, "/" + wantName, containerID[:DefaultShortIDLength], containerID} {
+		id, name, err := ResolveContainer(ctx, ref)
+		if err != nil {
+			t.Fatalf("ResolveContainer(%q) failed: %v", ref, err)
+		}
+		if id != containerID || name != wantName {
+			t.Errorf("ResolveContainer(%q) = (%s, %s), want (%s, %s)", ref, id, name, containerID, wantName)
+		}
+	}
+}
+
+func TestMatchContainerRef(t *testing.T) {
+	containers := []container.Summary{
+		{ID: "abc111", Names: []string{"/web"}},
+		{ID: "abc222", Names: []string{"/db"}},
+		{ID: "def333", Names: []string{"/abc"}},
+	}
+
+	if got := matchContainerRef("abc", containers); len(got) != 1 || got[0].ID != "def333" {
+		t.Fatalf("expected exact name match to win, got %+v", got)
+	}
+	if got := matchContainerRef("abc1", containers); len(got) != 1 || got[0].ID != "abc111" {
+		t.Fatalf("expected single prefix match, got %+v", got)
+	}
+	if got := matchContainerRef("ab", containers); len(got) != 2 {
+		t.Fatalf("expected ambiguous prefix to match 2 containers, got %d", len(got))
+	}
+	if got := matchContainerRef("zzz", containers); len(got) != 0 {
+		t.Fatalf("expected no match, got %+v", got)
+	}
 }
 
 func TestContainerLifecycle(t *testing.T) {
@@ -257,6 +376,52 @@ func TestGetContainerLogs(t *testing.T) {
 	}
 }
 
+func TestGetContainerLogsUntil(t *testing.T) {
+	requireDocker(t)
+
+	ctx := context.Background()
+	containerID, cleanup := setupTestContainer(t, ctx)
+	defer cleanup()
+
+	// nginx 启动时会输出多行日志，时间戳分布在一个小窗口内
+	time.Sleep(2 * time.Second)
+
+	all, err := GetContainerLogStructured(ctx, GetContainerLogsOptions{ContainerID: containerID, Tail: "all"})
+	if err != nil {
+		t.Fatalf("GetContainerLogStructured failed: %v", err)
+	}
+	if len(all) < 2 {
+		t.Skipf("need at least 2 log lines, got %d", len(all))
+	}
+
+	cutoff := all[len(all)/2-1].Timestamp
+	window, err := GetContainerLogStructured(ctx, GetContainerLogsOptions{
+		ContainerID: containerID,
+		Tail:        "all",
+		Until:       cutoff.Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		t.Fatalf("GetContainerLogStructured(until) failed: %v", err)
+	}
+	if len(window) == 0 {
+		t.Fatalf("expected lines before %s", cutoff)
+	}
+	for _, l := range window {
+		if l.Timestamp.After(cutoff) {
+			t.Fatalf("line after until cutoff %s: %+v", cutoff, l)
+		}
+	}
+
+	// 相对时长：until=1h 表示一小时前，新容器没有更早的日志
+	old, err := GetContainerLogStructured(ctx, GetContainerLogsOptions{ContainerID: containerID, Tail: "all", Until: "1h"})
+	if err != nil {
+		t.Fatalf("GetContainerLogStructured(relative until) failed: %v", err)
+	}
+	if len(old) != 0 {
+		t.Fatalf("expected no logs older than 1h, got %d", len(old))
+	}
+}
+
 func TestListImages(t *testing.T) {
 	requireDocker(t)
 
@@ -304,6 +469,41 @@ func TestInspectImage(t *testing.T) {
 		t.Fatalf("InspectImage returned empty ID for %s", ref)
 	}
 	t.Logf("Inspected image %s: id=%s size=%d", ref, info.ID, info.Size)
+
+	withLayers, err := InspectImageWithLayers(ctx, ref)
+	if err != nil {
+		t.Fatalf("InspectImageWithLayers failed: %v", err)
+	}
+	if len(withLayers.Layers) == 0 {
+		t.Fatalf("expected layers for %s", ref)
+	}
+	for _, l := range withLayers.Layers {
+		if l.Size <= 0 || l.CreatedBy == "" {
+			t.Fatalf("unexpected layer: %+v", l)
+		}
+	}
+}
+
+func TestImageLayers(t *testing.T) {
+	history := []image.HistoryResponseItem{
+		{CreatedBy: `CMD ["nginx"]`, Size: 0},
+		{CreatedBy: "RUN apk add curl", Size: 2048},
+		{CreatedBy: "ENV A=b", Size: 0},
+		{CreatedBy: "ADD rootfs.tar /", Size: 4096},
+	}
+	layers := imageLayers([]string{"sha256:base", "sha256:curl"}, history)
+	if len(layers) != 2 {
+		t.Fatalf("expected 2 layers, got %+v", layers)
+	}
+	if layers[0].Digest != "sha256:base" || layers[0].Size != 4096 || layers[1].CreatedBy != "RUN apk add curl" {
+		t.Fatalf("unexpected layers: %+v", layers)
+	}
+
+	// 数量无法对齐时不猜测 digest
+	layers = imageLayers([]string{"sha256:only"}, history)
+	if len(layers) != 2 || layers[0].Digest != "" {
+		t.Fatalf("expected layers without digests, got %+v", layers)
+	}
 }
 
 func TestVolumeLifecycle(t *testing.T) {
@@ -379,35 +579,12 @@ func TestRunContainerFromImage(t *testing.T) {
 	requireDocker(t)
 
 	ctx := context.Background()
+	imageName := requireShellImage(t)
 	cli, err := GetClient()
 	if err != nil {
 		t.Skipf("Failed to get docker client: %v", err)
 	}
 
-	images, err := cli.ImageList(ctx, image.ListOptions{All: false})
-	if err != nil || len(images) == 0 {
-		t.Skip("no local images to run a container")
-	}
-
-	var imageName string
-	for _, img := range images {
-		for _, tag := range img.RepoTags {
-			if tag == "" || tag == "<none>:<none>" {
-				continue
-			}
-			if strings.Contains(tag, "alpine") || strings.Contains(tag, "busybox") {
-				imageName = tag
-				break
-			}
-		}
-		if imageName != "" && strings.Contains(imageName, "nginx") {
-			break
-		}
-	}
-	if imageName == "" {
-		t.Skip("no suitable tagged image found to run a container")
-	}
-
 	name := fmt.Sprintf("centagent-run-%d", time.Now().UnixNano())
 	runOpts := RunContainerFromImageOptions{
 		Image:         imageName,
@@ -441,3 +618,625 @@ func TestRunContainerFromImage(t *testing.T) {
 		t.Fatalf("expected container running, got state=%v", info.State)
 	}
 }
+func TestTrackPull(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"status":"Pulling from library/nginx","id":"alpine"}`,
+		`{"status":"Already exists","id":"aaa"}`,
+		`{"status":"Pulling fs layer","id":"bbb"}`,
+		`{"status":"Downloading","progressDetail":{"current":50,"total":100},"id":"bbb"}`,
+		`{"status":"Pull complete","id":"bbb"}`,
+		`{"status":"Digest: sha256:abc123"}`,
+		`{"status":"Status: Downloaded newer image for nginx:alpine"}`,
+	}, "\n")
+
+	var got []PullProgress
+	res, err := trackPull(strings.NewReader(stream), func(p PullProgress) { got = append(got, p) })
+	if err != nil {
+		t.Fatalf("track pull: %v", err)
+	}
+	if res.Digest != "sha256:abc123" || res.Status != "Downloaded newer image for nginx:alpine" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if len(got) != 4 {
+		t.Fatalf("expected 4 progress events, got %d: %+v", len(got), got)
+	}
+	if p := got[2]; p.Layers != 2 || p.LayersDone != 1 || p.Percent < 72 || p.Percent > 73 {
+		t.Fatalf("unexpected downloading progress: %+v", p)
+	}
+	if p := got[3]; p.LayersDone != 2 || p.Percent != 100 {
+		t.Fatalf("unexpected final progress: %+v", p)
+	}
+
+	_, err = trackPull(strings.NewReader(`{"errorDetail":{"message":"manifest unknown"},"error":"manifest unknown"}`), nil)
+	if err == nil || !strings.Contains(err.Error(), "manifest unknown") {
+		t.Fatalf("expected pull error, got %v", err)
+	}
+}
+
+func TestParseRestartPolicy(t *testing.T) {
+	cases := map[string]container.RestartPolicy{
+		"no":              {Name: container.RestartPolicyDisabled},
+		"always":          {Name: container.RestartPolicyAlways},
+		" Unless-Stopped": {Name: container.RestartPolicyUnlessStopped},
+		"on-failure":      {Name: container.RestartPolicyOnFailure},
+		"on-failure:5":    {Name: container.RestartPolicyOnFailure, MaximumRetryCount: 5},
+	}
+	for in, want := range cases {
+		got, err := ParseRestartPolicy(in)
+		if err != nil {
+			t.Fatalf("parse %q: %v", in, err)
+		}
+		if got != want {
+			t.Fatalf("parse %q: got %+v, want %+v", in, got, want)
+		}
+	}
+
+	for _, in := range []string{"", "sometimes", "always:3", "on-failure:-1", "on-failure:x"} {
+		if _, err := ParseRestartPolicy(in); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
+
+func TestUpdateRestartPolicy(t *testing.T) {
+	requireDocker(t)
+
+	ctx := context.Background()
+	containerID, cleanup := setupTestContainer(t, ctx)
+	defer cleanup()
+
+	for _, s := range []string{"on-failure:3", "unless-stopped", "no"} {
+		policy, err := ParseRestartPolicy(s)
+		if err != nil {
+			t.Fatalf("parse %q: %v", s, err)
+		}
+		applied, err := UpdateRestartPolicy(ctx, containerID, policy)
+		if err != nil {
+			t.Fatalf("update restart policy to %s: %v", s, err)
+		}
+		if applied != policy {
+			t.Fatalf("expected %+v after update, got %+v", policy, applied)
+		}
+	}
+}
+
+func TestParseDeviceSpec(t *testing.T) {
+	cases := map[string]container.DeviceMapping{
+		"/dev/snd":              {PathOnHost: "/dev/snd", PathInContainer: "/dev/snd", CgroupPermissions: "rwm"},
+		"/dev/snd:r":            {PathOnHost: "/dev/snd", PathInContainer: "/dev/snd", CgroupPermissions: "r"},
+		"/dev/sda:/dev/xvdc":    {PathOnHost: "/dev/sda", PathInContainer: "/dev/xvdc", CgroupPermissions: "rwm"},
+		"/dev/sda:/dev/xvdc:rw": {PathOnHost: "/dev/sda", PathInContainer: "/dev/xvdc", CgroupPermissions: "rw"},
+	}
+	for in, want := range cases {
+		got, err := ParseDeviceSpec(in)
+		if err != nil {
+			t.Fatalf("parse %q: %v", in, err)
+		}
+		if got != want {
+			t.Fatalf("parse %q: got %+v, want %+v", in, got, want)
+		}
+	}
+
+	for _, in := range []string{"", "dev/snd", "/dev/sda:xvdc", "/dev/sda:/dev/xvdc:rx", "/dev/sda:/dev/xvdc:rr", "/a:/b:r:w"} {
+		if _, err := ParseDeviceSpec(in); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
+
+func TestParseTmpfsSpecs(t *testing.T) {
+	got, err := ParseTmpfsSpecs([]string{"/tmp:rw,noexec,size=64m", "/run", " "})
+	if err != nil {
+		t.Fatalf("parse tmpfs: %v", err)
+	}
+	if len(got) != 2 || got["/tmp"] != "rw,noexec,size=64m" || got["/run"] != "" {
+		t.Fatalf("unexpected tmpfs map: %+v", got)
+	}
+
+	for _, in := range []string{"tmp", "/tmp:bogus", "/tmp:size=", "/tmp:rw,,noexec"} {
+		if _, err := ParseTmpfsSpecs([]string{in}); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
+
+func TestRunContainerReadonlyRootfs(t *testing.T) {
+	requireDocker(t)
+
+	ctx := context.Background()
+	imageName := requireShellImage(t)
+	cli, err := GetClient()
+	if err != nil {
+		t.Skipf("Failed to get docker client: %v", err)
+	}
+
+	res, err := RunContainerFromImage(ctx, RunContainerFromImageOptions{
+		Image:          imageName,
+		Name:           fmt.Sprintf("centagent-ro-%d", time.Now().UnixNano()),
+		Cmd:            []string{"sh", "-c", "touch /scratch/ok && touch /probe"},
+		Tmpfs:          map[string]string{"/scratch": "rw,size=1m"},
+		ReadonlyRootfs: true,
+	})
+	if err != nil {
+		t.Fatalf("RunContainerFromImage failed: %v", err)
+	}
+	defer func() {
+		_ = cli.ContainerRemove(ctx, res.ContainerID, container.RemoveOptions{Force: true})
+	}()
+
+	waitCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	statusCh, errCh := cli.ContainerWait(waitCtx, res.ContainerID, container.WaitConditionNotRunning)
+	select {
+	case st := <-statusCh:
+		// tmpfs 可写，根文件系统只读：touch /probe 应失败
+		if st.StatusCode == 0 {
+			t.Fatalf("expected write to / to fail on read-only rootfs")
+		}
+	case err := <-errCh:
+		t.Fatalf("wait container: %v", err)
+	}
+
+	logs, err := GetContainerLogs(ctx, GetContainerLogsOptions{ContainerID: res.ContainerID, Tail: "10"})
+	if err == nil && !strings.Contains(strings.ToLower(logs), "read-only") {
+		t.Fatalf("expected read-only file system error, got logs: %q", logs)
+	}
+}
+
+func TestPortMappings(t *testing.T) {
+	got := portMappings(nat.PortMap{
+		"443/tcp":  {{HostIP: "0.0.0.0", HostPort: "32769"}},
+		"80/tcp":   {{HostIP: "::", HostPort: "32768"}, {HostIP: "0.0.0.0", HostPort: "32768"}},
+		"9000/tcp": nil,
+	})
+	want := []PortMapping{
+		{ContainerPort: "443/tcp", HostIP: "0.0.0.0", HostPort: "32769"},
+		{ContainerPort: "80/tcp", HostIP: "0.0.0.0", HostPort: "32768"},
+		{ContainerPort: "80/tcp", HostIP: "::", HostPort: "32768"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d mappings, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("mapping %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRunContainerEphemeralPort(t *testing.T) {
+	requireDocker(t)
+
+	ctx := context.Background()
+	imageName := requireShellImage(t)
+	cli, err := GetClient()
+	if err != nil {
+		t.Skipf("Failed to get docker client: %v", err)
+	}
+
+	res, err := RunContainerFromImage(ctx, RunContainerFromImageOptions{
+		Image:   imageName,
+		Name:    fmt.Sprintf("centagent-port-%d", time.Now().UnixNano()),
+		Cmd:     []string{"sh", "-c", "sleep 600"},
+		Publish: []string{"0:80"},
+	})
+	if err != nil {
+		t.Fatalf("RunContainerFromImage failed: %v", err)
+	}
+	defer func() {
+		_ = cli.ContainerRemove(ctx, res.ContainerID, container.RemoveOptions{Force: true})
+	}()
+
+	if len(res.PortMappings) == 0 {
+		t.Fatalf("expected port mappings for 0:80, got none")
+	}
+	for _, m := range res.PortMappings {
+		if m.ContainerPort != "80/tcp" {
+			t.Fatalf("unexpected container port: %+v", m)
+		}
+		if m.HostPort == "" || m.HostPort == "0" {
+			t.Fatalf("expected a concrete host port, got %+v", m)
+		}
+	}
+}
+
+func TestParseIfExists(t *testing.T) {
+	cases := map[string]string{"": IfExistsFail, "fail": IfExistsFail, "Reuse": IfExistsReuse, " replace ": IfExistsReplace}
+	for in, want := range cases {
+		got, err := parseIfExists(in)
+		if err != nil || got != want {
+			t.Fatalf("parse %q: got %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := parseIfExists("overwrite"); err == nil {
+		t.Fatalf("expected error for unknown mode")
+	}
+}
+
+func TestRunContainerIfExists(t *testing.T) {
+	requireDocker(t)
+
+	ctx := context.Background()
+	imageName := requireShellImage(t)
+	cli, err := GetClient()
+	if err != nil {
+		t.Skipf("Failed to get docker client: %v", err)
+	}
+
+	name := fmt.Sprintf("centagent-ifexists-%d", time.Now().UnixNano())
+	run := func(mode string) (*RunContainerResult, error) {
+		return RunContainerFromImage(ctx, RunContainerFromImageOptions{
+			Image:    imageName,
+			Name:     name,
+			Cmd:      []string{"sh", "-c", "sleep 600"},
+			IfExists: mode,
+		})
+	}
+
+	first, err := run("")
+	if err != nil {
+		t.Fatalf("initial run failed: %v", err)
+	}
+	defer func() {
+		_ = cli.ContainerRemove(ctx, name, container.RemoveOptions{Force: true})
+	}()
+
+	t.Run("fail", func(t *testing.T) {
+		if _, err := run(IfExistsFail); err == nil {
+			t.Fatalf("expected name conflict error")
+		}
+	})
+
+	t.Run("reuse", func(t *testing.T) {
+		if err := cli.ContainerStop(ctx, first.ContainerID, container.StopOptions{}); err != nil {
+			t.Fatalf("stop container: %v", err)
+		}
+		res, err := run(IfExistsReuse)
+		if err != nil {
+			t.Fatalf("reuse run failed: %v", err)
+		}
+		if !res.Reused || res.ContainerID != first.ContainerID {
+			t.Fatalf("expected existing container %s to be reused, got %+v", first.ContainerID, res)
+		}
+		info, err := cli.ContainerInspect(ctx, res.ContainerID)
+		if err != nil {
+			t.Fatalf("inspect container: %v", err)
+		}
+		if info.State == nil || !info.State.Running {
+			t.Fatalf("expected reused container to be running, got %+v", info.State)
+		}
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		res, err := run(IfExistsReplace)
+		if err != nil {
+			t.Fatalf("replace run failed: %v", err)
+		}
+		if !res.Replaced || res.ContainerID == first.ContainerID {
+			t.Fatalf("expected a new container replacing %s, got %+v", first.ContainerID, res)
+		}
+		if _, err := cli.ContainerInspect(ctx, first.ContainerID); !IsNotFound(err) {
+			t.Fatalf("expected old container to be removed, got err=%v", err)
+		}
+	})
+}
+
+func TestConnectNetworkReturnsEndpoint(t *testing.T) {
+	requireDocker(t)
+
+	ctx := context.Background()
+	containerID, cleanup := setupTestContainer(t, ctx)
+	defer cleanup()
+
+	name := fmt.Sprintf("centagent-connect-%d", time.Now().UnixNano())
+	created, err := CreateNetwork(ctx, CreateNetworkOptions{Name: name, Driver: "bridge"})
+	if err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	defer func() { _ = RemoveNetwork(ctx, created.ID) }()
+
+	ep, err := ConnectNetwork(ctx, name, ConnectNetworkOptions{ContainerID: containerID})
+	if err != nil {
+		t.Fatalf("ConnectNetwork failed: %v", err)
+	}
+	defer func() {
+		_ = DisconnectNetwork(ctx, name, DisconnectNetworkOptions{ContainerID: containerID, Force: true})
+	}()
+
+	if ep.NetworkName != name {
+		t.Fatalf("expected network name %s, got %+v", name, ep)
+	}
+	if ep.IPv4Address == "" || strings.Contains(ep.IPv4Address, "/") {
+		t.Fatalf("expected a bare IPv4 address, got %q", ep.IPv4Address)
+	}
+}
+
+func TestNewInspectNetworkDetail(t *testing.T) {
+	detail := newInspectNetworkDetail(network.Inspect{
+		ID:     "net123",
+		Name:   "app-net",
+		Driver: "bridge",
+		Scope:  "local",
+		IPAM:   network.IPAM{Config: []network.IPAMConfig{{Subnet: "172.18.0.0/16", Gateway: "172.18.0.1"}}},
+		Containers: map[string]network.EndpointResource{
+			"bbbbbbbbbbbbbbbbbbbb": {Name: "web", IPv4Address: "172.18.0.5/16"},
+			"aaaaaaaaaaaaaaaaaaaa": {Name: "db", IPv4Address: "172.18.0.4/16"},
+		},
+	})
+	if len(detail.Subnets) != 1 || detail.Subnets[0].Gateway != "172.18.0.1" {
+		t.Fatalf("unexpected subnets: %+v", detail.Subnets)
+	}
+	if len(detail.Containers) != 2 || detail.Containers[0].ContainerName != "db" || detail.Containers[1].IPv4 != "172.18.0.5" {
+		t.Fatalf("unexpected containers: %+v", detail.Containers)
+	}
+}
+
+func TestGetNetworkDetailListsAttachedContainer(t *testing.T) {
+	requireDocker(t)
+
+	ctx := context.Background()
+	containerID, cleanup := setupTestContainer(t, ctx)
+	defer cleanup()
+
+	name := fmt.Sprintf("centagent-detail-%d", time.Now().UnixNano())
+	created, err := CreateNetwork(ctx, CreateNetworkOptions{Name: name, Driver: "bridge"})
+	if err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	defer func() { _ = RemoveNetwork(ctx, created.ID) }()
+
+	ep, err := ConnectNetwork(ctx, name, ConnectNetworkOptions{ContainerID: containerID})
+	if err != nil {
+		t.Fatalf("ConnectNetwork failed: %v", err)
+	}
+	defer func() {
+		_ = DisconnectNetwork(ctx, name, DisconnectNetworkOptions{ContainerID: containerID, Force: true})
+	}()
+
+	detail, err := GetNetworkDetail(ctx, name)
+	if err != nil {
+		t.Fatalf("GetNetworkDetail failed: %v", err)
+	}
+	if detail.Name != name || detail.Driver != "bridge" {
+		t.Fatalf("unexpected network detail: %+v", detail)
+	}
+	found := false
+	for _, c := range detail.Containers {
+		if c.ContainerName == ep.ContainerName {
+			found = c.IPv4 != ""
+		}
+	}
+	if !found {
+		t.Fatalf("expected %s with an IPv4 address in %+v", ep.ContainerName, detail.Containers)
+	}
+}
+
+func TestImageListFiltersDangling(t *testing.T) {
+	f := imageListFilters(ListImagesOptions{Dangling: true, Filters: map[string][]string{"reference": {"nginx*"}}})
+	if !f.ExactMatch("dangling", "true") || !f.ExactMatch("reference", "nginx*") {
+		t.Fatalf("unexpected filters: %v", f)
+	}
+	if f := imageListFilters(ListImagesOptions{}); f.Len() != 0 {
+		t.Fatalf("expected no filters, got %v", f)
+	}
+}
+
+func TestListImagesDangling(t *testing.T) {
+	requireDocker(t)
+
+	ctx := context.Background()
+	containerID, cleanup := setupTestContainer(t, ctx)
+	defer cleanup()
+
+	cli, err := GetClient()
+	if err != nil {
+		t.Skipf("Failed to get docker client: %v", err)
+	}
+
+	// 对同一标签连续 commit 两次，第一次生成的镜像失去标签成为 dangling
+	ref := fmt.Sprintf("centagent-dangling:%d", time.Now().UnixNano())
+	first, err := cli.ContainerCommit(ctx, containerID, container.CommitOptions{Reference: ref, Config: &container.Config{Labels: map[string]string{"centagent_test": "1"}}})
+	if err != nil {
+		t.Fatalf("commit container: %v", err)
+	}
+	second, err := cli.ContainerCommit(ctx, containerID, container.CommitOptions{Reference: ref, Config: &container.Config{Labels: map[string]string{"centagent_test": "2"}}})
+	if err != nil {
+		t.Fatalf("commit container: %v", err)
+	}
+	defer func() {
+		_, _ = cli.ImageRemove(ctx, second.ID, image.RemoveOptions{Force: true})
+		_, _ = cli.ImageRemove(ctx, first.ID, image.RemoveOptions{Force: true})
+	}()
+	if first.ID == second.ID {
+		t.Skip("commits produced the same image id")
+	}
+
+	images, err := ListImages(ctx, ListImagesOptions{Dangling: true, FullID: true})
+	if err != nil {
+		t.Fatalf("ListImages failed: %v", err)
+	}
+	var found bool
+	for _, img := range images {
+		if img.ID == second.ID {
+			t.Fatalf("tagged image %s listed as dangling", second.ID)
+		}
+		if img.ID == first.ID {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected dangling image %s in %+v", first.ID, images)
+	}
+}
+
+func TestRemoveImagesContinuesPastFailures(t *testing.T) {
+	requireDocker(t)
+
+	ctx := context.Background()
+	containerID, cleanup := setupTestContainer(t, ctx)
+	defer cleanup()
+
+	cli, err := GetClient()
+	if err != nil {
+		t.Skipf("Failed to get docker client: %v", err)
+	}
+
+	suffix := time.Now().UnixNano()
+	unused := fmt.Sprintf("centagent-rmi-unused:%d", suffix)
+	inUse := fmt.Sprintf("centagent-rmi-inuse:%d", suffix)
+	for i, ref := range []string{unused, inUse} {
+		cfg := &container.Config{Labels: map[string]string{"centagent_test": fmt.Sprint(i)}}
+		if _, err := cli.ContainerCommit(ctx, containerID, container.CommitOptions{Reference: ref, Config: cfg}); err != nil {
+			t.Fatalf("commit %s: %v", ref, err)
+		}
+	}
+	defer func() {
+		_, _ = cli.ImageRemove(ctx, inUse, image.RemoveOptions{Force: true})
+		_, _ = cli.ImageRemove(ctx, unused, image.RemoveOptions{Force: true})
+	}()
+
+	// 用 inUse 镜像创建一个容器（不启动），使其无法被非强制删除
+	user, err := cli.ContainerCreate(ctx, &container.Config{Image: inUse, Cmd: []string{"true"}}, nil, nil, nil, "")
+	if err != nil {
+		t.Fatalf("create container from %s: %v", inUse, err)
+	}
+	defer func() { _ = cli.ContainerRemove(ctx, user.ID, container.RemoveOptions{Force: true}) }()
+
+	results, err := RemoveImages(ctx, []string{unused, inUse}, RemoveImageOptions{})
+	if err != nil {
+		t.Fatalf("RemoveImages failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", results)
+	}
+	if results[0].Ref != unused || results[0].Error != "" || len(results[0].Deleted) == 0 {
+		t.Fatalf("expected %s to be removed, got %+v", unused, results[0])
+	}
+	if results[1].Ref != inUse || results[1].Error == "" {
+		t.Fatalf("expected %s removal to fail while in use, got %+v", inUse, results[1])
+	}
+}
+
+func TestNewContainerRestartInfo(t *testing.T) {
+	info := container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{
+			ID:           "abc123",
+			Name:         "/web",
+			RestartCount: 4,
+			State: &container.State{
+				Status:     "restarting",
+				Restarting: true,
+				ExitCode:   137,
+				OOMKilled:  true,
+				StartedAt:  zeroDockerTime,
+				FinishedAt: "2026-10-16T08:00:00Z",
+			},
+			HostConfig: &container.HostConfig{
+				RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: 5},
+			},
+		},
+	}
+	got := newContainerRestartInfo(info)
+	want := ContainerRestartInfo{
+		ID:            "abc123",
+		Name:          "web",
+		Status:        "restarting",
+		Restarting:    true,
+		RestartCount:  4,
+		RestartPolicy: "on-failure:5",
+		ExitCode:      137,
+		OOMKilled:     true,
+		FinishedAt:    "2026-10-16T08:00:00Z",
+	}
+	if *got != want {
+		t.Fatalf("unexpected restart info:\n got %+v\nwant %+v", *got, want)
+	}
+}
+
+func TestGetContainerRestartInfo(t *testing.T) {
+	requireDocker(t)
+
+	ctx := context.Background()
+	imageName := requireShellImage(t)
+	cli, err := GetClient()
+	if err != nil {
+		t.Skipf("Failed to get docker client: %v", err)
+	}
+
+	res, err := RunContainerFromImage(ctx, RunContainerFromImageOptions{
+		Image:         imageName,
+		Name:          fmt.Sprintf("centagent-restart-%d", time.Now().UnixNano()),
+		Cmd:           []string{"sh", "-c", "exit 3"},
+		RestartPolicy: "on-failure:2",
+	})
+	if err != nil {
+		t.Fatalf("RunContainerFromImage failed: %v", err)
+	}
+	defer func() {
+		_ = cli.ContainerRemove(ctx, res.ContainerID, container.RemoveOptions{Force: true})
+	}()
+
+	// 等待 daemon 按 on-failure 策略至少重启一次
+	var info *ContainerRestartInfo
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		info, err = GetContainerRestartInfo(ctx, res.ContainerID)
+		if err != nil {
+			t.Fatalf("GetContainerRestartInfo failed: %v", err)
+		}
+		if info.RestartCount >= 1 && info.FinishedAt != "" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("container did not restart in time: %+v", info)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	if info.ExitCode != 3 || info.OOMKilled || info.RestartPolicy != "on-failure:2" {
+		t.Fatalf("unexpected restart info: %+v", info)
+	}
+}
+
+func TestContainerDiff(t *testing.T) {
+	requireDocker(t)
+
+	ctx := context.Background()
+	imageName := requireShellImage(t)
+	cli, err := GetClient()
+	if err != nil {
+		t.Skipf("Failed to get docker client: %v", err)
+	}
+
+	res, err := RunContainerFromImage(ctx, RunContainerFromImageOptions{
+		Image: imageName,
+		Name:  fmt.Sprintf("centagent-diff-%d", time.Now().UnixNano()),
+		Cmd:   []string{"sh", "-c", "echo hello > /centagent-diff.txt && sleep 30"},
+	})
+	if err != nil {
+		t.Fatalf("RunContainerFromImage failed: %v", err)
+	}
+	defer func() {
+		_ = cli.ContainerRemove(ctx, res.ContainerID, container.RemoveOptions{Force: true})
+	}()
+
+	// 等待容器内命令写入文件
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		changes, err := ContainerDiff(ctx, res.ContainerID)
+		if err != nil {
+			t.Fatalf("ContainerDiff failed: %v", err)
+		}
+		for _, c := range changes {
+			if c.Path == "/centagent-diff.txt" {
+				if c.Kind != container.ChangeAdd {
+					t.Fatalf("expected file to be added, got %+v", c)
+				}
+				return
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("created file not reported in diff: %+v", changes)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
@@ -2,6 +2,8 @@ package docker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -9,6 +11,7 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/pkg/jsonmessage"
 	v1 "github.com/moby/docker-image-spec/specs-go/v1"
 )
 
@@ -17,6 +20,10 @@ type ListImagesOptions struct {
 	All bool
 	// Filters 列表过滤条件，key/value 语义与 Docker Engine API 一致。
 	Filters map[string][]string
+	// FullID 为 true 时返回完整的镜像 ID，默认按 ShortIDLength 截断。
+	FullID bool
+	// Dangling 为 true 时只列出无标签（dangling）镜像，对应 dangling=true 过滤条件。
+	Dangling bool
 }
 
 // ImageSummary 镜像列表的简化信息（用于 list 输出）。
@@ -41,12 +48,7 @@ func ListImages(ctx context.Context, opts ListImagesOptions) ([]ImageSummary, er
 
 	listOpts := image.ListOptions{
 		All:     opts.All,
-		Filters: filters.NewArgs(),
-	}
-	for k, vs := range opts.Filters {
-		for _, v := range vs {
-			listOpts.Filters.Add(k, v)
-		}
+		Filters: imageListFilters(opts),
 	}
 
 	images, err := cli.ImageList(ctx, listOpts)
@@ -57,7 +59,7 @@ func ListImages(ctx context.Context, opts ListImagesOptions) ([]ImageSummary, er
 	result := make([]ImageSummary, 0, len(images))
 	for _, img := range images {
 		result = append(result, ImageSummary{
-			ID:          truncateID(img.ID),
+			ID:          displayID(img.ID, opts.FullID),
 			RepoTags:    img.RepoTags,
 			RepoDigests: img.RepoDigests,
 			Created:     img.Created,
@@ -67,6 +69,19 @@ func ListImages(ctx context.Context, opts ListImagesOptions) ([]ImageSummary, er
 	return result, nil
 }
 
+func imageListFilters(opts ListImagesOptions) filters.Args {
+	f := filters.NewArgs()
+	for k, vs := range opts.Filters {
+		for _, v := range vs {
+			f.Add(k, v)
+		}
+	}
+	if opts.Dangling && !f.Contains("dangling") {
+		f.Add("dangling", "true")
+	}
+	return f
+}
+
 // InspectImageDetail 镜像 inspect 的简化信息（用于给 Agent/CLI 输出）。
 type InspectImageDetail struct {
 	// ID 镜像 content-addressable ID。
@@ -89,6 +104,18 @@ type InspectImageDetail struct {
 	RootFS types.RootFS `json:"root_fs"`
 	// GraphDriver 本地存储驱动信息。
 	GraphDriver types.GraphDriverData `json:"graph_driver"`
+	// Layers 按构建顺序（从底层到顶层）列出的分层，仅 InspectImageWithLayers 填充。
+	Layers []LayerInfo `json:"layers,omitempty"`
+}
+
+// LayerInfo 单个镜像分层：diff ID、创建它的构建指令与大小。
+type LayerInfo struct {
+	// Digest 分层的 diff ID；无法与 RootFS 对齐时为空。
+	Digest string `json:"digest,omitempty"`
+	// CreatedBy 生成该分层的构建指令。
+	CreatedBy string `json:"created_by"`
+	// Size 分层大小（字节）。
+	Size int64 `json:"size"`
 }
 
 func InspectImage(ctx context.Context, ref string) (*InspectImageDetail, error) {
@@ -101,7 +128,48 @@ func InspectImage(ctx context.Context, ref string) (*InspectImageDetail, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to inspect image %s: %w", ref, err)
 	}
+	return newInspectImageDetail(inspect), nil
+}
+
+// InspectImageWithLayers 在 InspectImage 的基础上结合 ImageHistory 填充 Layers。
+func InspectImageWithLayers(ctx context.Context, ref string) (*InspectImageDetail, error) {
+	detail, err := InspectImage(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	cli, err := GetClient()
+	if err != nil {
+		return nil, err
+	}
+	history, err := cli.ImageHistory(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history of image %s: %w", ref, err)
+	}
+	detail.Layers = imageLayers(detail.RootFS.Layers, history)
+	return detail, nil
+}
+
+// imageLayers 将 history（最新在前）与 RootFS diff ID（最底层在前）对齐。
+// 大小为 0 的历史记录（ENV/CMD 等元数据指令）不产生分层，会被跳过；
+// 若剩余记录数与 diff ID 数不一致，则只返回指令与大小，不填 Digest。
+func imageLayers(diffIDs []string, history []image.HistoryResponseItem) []LayerInfo {
+	var layers []LayerInfo
+	for i := len(history) - 1; i >= 0; i-- {
+		h := history[i]
+		if h.Size == 0 {
+			continue
+		}
+		layers = append(layers, LayerInfo{CreatedBy: strings.TrimSpace(h.CreatedBy), Size: h.Size})
+	}
+	if len(layers) == len(diffIDs) {
+		for i := range layers {
+			layers[i].Digest = diffIDs[i]
+		}
+	}
+	return layers
+}
 
+func newInspectImageDetail(inspect image.InspectResponse) *InspectImageDetail {
 	return &InspectImageDetail{
 		ID:           inspect.ID,
 		RepoTags:     inspect.RepoTags,
@@ -113,7 +181,7 @@ func InspectImage(ctx context.Context, ref string) (*InspectImageDetail, error)
 		Config:       inspect.Config,
 		RootFS:       inspect.RootFS,
 		GraphDriver:  inspect.GraphDriver,
-	}, nil
+	}
 }
 
 type PullImageOptions struct {
@@ -121,36 +189,184 @@ type PullImageOptions struct {
 	Ref string
 	// Platform 可选平台（如 linux/amd64）。
 	Platform string
+	// Username/Password 可选的仓库凭据；均为空时依次从配置的 registries 与
+	// docker CLI 配置文件（~/.docker/config.json）中按仓库地址查找。
+	Username string
+	Password string
 }
 
 func PullImage(ctx context.Context, opts PullImageOptions) (string, error) {
-	cli, err := GetClient()
+	res, err := PullImageWithProgress(ctx, opts, nil)
 	if err != nil {
 		return "", err
 	}
+	return res.Output, nil
+}
+
+// PullProgress 为镜像拉取的整体进度
+type PullProgress struct {
+	// Status 为最近一条状态信息（如 "Downloading"）。
+	Status string `json:"status"`
+	// LayerID 为本次状态变化所属的层（短 ID）。
+	LayerID string `json:"layer_id,omitempty"`
+	// Layers 为已知的层数；LayersDone 为已完成（下载并解压或本地已存在）的层数。
+	Layers     int `json:"layers"`
+	LayersDone int `json:"layers_done"`
+	// Percent 为按层估算的完成百分比（0~100）；尚无层信息时为 -1。
+	Percent float64 `json:"percent"`
+}
+
+// PullResult 为镜像拉取的结果
+type PullResult struct {
+	Ref string `json:"ref"`
+	// Digest 为拉取到的 manifest digest（如 sha256:...），输出中没有时为空。
+	Digest string `json:"digest,omitempty"`
+	// Status 为最终状态（如 "Downloaded newer image for nginx:alpine"）。
+	Status string `json:"status,omitempty"`
+	// Output 为原始输出（截断到末尾 2000 字节）。
+	Output string `json:"-"`
+}
+
+// PullImageWithProgress 拉取镜像并解析 jsonmessage 流，每次进度变化时回调 onProgress（可为 nil）。
+func PullImageWithProgress(ctx context.Context, opts PullImageOptions, onProgress func(PullProgress)) (*PullResult, error) {
+	cli, err := GetClient()
+	if err != nil {
+		return nil, err
+	}
 
 	ref := strings.TrimSpace(opts.Ref)
 	if ref == "" {
-		return "", fmt.Errorf("image ref is required")
+		return nil, fmt.Errorf("image ref is required")
 	}
 
 	pullOpts := image.PullOptions{}
 	if strings.TrimSpace(opts.Platform) != "" {
 		pullOpts.Platform = strings.TrimSpace(opts.Platform)
 	}
+	auth, err := pullRegistryAuth(ref, opts)
+	if err != nil {
+		return nil, err
+	}
+	pullOpts.RegistryAuth = auth
 
 	reader, err := cli.ImagePull(ctx, ref, pullOpts)
 	if err != nil {
-		return "", fmt.Errorf("failed to pull image %s: %w", ref, err)
+		return nil, fmt.Errorf("failed to pull image %s: %w", ref, classifyPullError(err))
 	}
 	defer reader.Close()
 
-	var b strings.Builder
-	if _, err := io.Copy(&b, reader); err != nil {
-		return "", fmt.Errorf("failed to read image pull output: %w", err)
+	res, err := trackPull(reader, onProgress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull image %s: %w", ref, classifyPullError(err))
+	}
+	res.Ref = ref
+	return res, nil
+}
+
+// pullRegistryAuth 按 显式凭据 > registries 配置 > docker CLI 配置文件 的顺序解析 ref 所属仓库的认证信息
+func pullRegistryAuth(ref string, opts PullImageOptions) (string, error) {
+	host := RegistryHost(ref)
+	cred := RegistryCredential{Username: opts.Username, Password: opts.Password}
+	if cred.Username == "" && cred.Password == "" {
+		resolved, ok, err := ResolveRegistryCredential(host)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			cred = resolved
+		}
 	}
+	return encodeRegistryAuth(host, cred)
+}
 
-	return truncateTail(b.String(), 2000), nil
+// pullLayer 为单个镜像层的拉取状态
+type pullLayer struct {
+	current int64
+	total   int64
+	done    bool
+}
+
+// trackPull 逐条解析拉取输出的 jsonmessage，汇总各层进度并提取 digest 与最终状态
+func trackPull(r io.Reader, onProgress func(PullProgress)) (*PullResult, error) {
+	var raw strings.Builder
+	dec := json.NewDecoder(io.TeeReader(r, &raw))
+
+	res := &PullResult{}
+	layers := map[string]*pullLayer{}
+	var order []string
+
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to read image pull output: %w", err)
+		}
+		if msg.Error != nil {
+			return nil, errors.New(msg.Error.Message)
+		}
+
+		switch {
+		case strings.HasPrefix(msg.Status, "Digest: "):
+			res.Digest = strings.TrimSpace(strings.TrimPrefix(msg.Status, "Digest: "))
+			continue
+		case strings.HasPrefix(msg.Status, "Status: "):
+			res.Status = strings.TrimSpace(strings.TrimPrefix(msg.Status, "Status: "))
+			continue
+		case msg.ID == "" || strings.HasPrefix(msg.Status, "Pulling from "):
+			continue
+		}
+
+		l, ok := layers[msg.ID]
+		if !ok {
+			l = &pullLayer{}
+			layers[msg.ID] = l
+			order = append(order, msg.ID)
+		}
+		switch msg.Status {
+		case "Downloading":
+			if msg.Progress != nil && msg.Progress.Total > 0 {
+				l.current, l.total = msg.Progress.Current, msg.Progress.Total
+			}
+		case "Download complete", "Verifying Checksum", "Extracting":
+			l.current = l.total
+		case "Pull complete", "Already exists":
+			l.current = l.total
+			l.done = true
+		}
+
+		if onProgress != nil {
+			p := summarizePull(msg.Status, layers, order)
+			p.LayerID = msg.ID
+			onProgress(p)
+		}
+	}
+
+	res.Output = truncateTail(raw.String(), 2000)
+	return res, nil
+}
+
+// summarizePull 按层估算整体进度：已完成的层计 1，下载中的层按已下载字节比例计
+func summarizePull(status string, layers map[string]*pullLayer, order []string) PullProgress {
+	p := PullProgress{Status: status, Layers: len(order), Percent: -1}
+	if len(order) == 0 {
+		return p
+	}
+	var sum float64
+	for _, id := range order {
+		l := layers[id]
+		switch {
+		case l.done:
+			p.LayersDone++
+			sum++
+		case l.total > 0:
+			// 下载完成后仍需解压，未完成的层最多计 0.9
+			sum += 0.9 * float64(l.current) / float64(l.total)
+		}
+	}
+	p.Percent = sum / float64(len(order)) * 100
+	return p
 }
 
 type RemoveImageOptions struct {
@@ -176,6 +392,41 @@ func RemoveImage(ctx context.Context, ref string, opts RemoveImageOptions) ([]im
 	return deleted, nil
 }
 
+// RemoveImageResult 批量删除中单个镜像引用的结果。
+type RemoveImageResult struct {
+	Ref     string                 `json:"ref"`
+	Deleted []image.DeleteResponse `json:"deleted,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+}
+
+// RemoveImages 依次删除多个镜像，单个失败不会中断后续删除；
+// 结果顺序与 refs 一致，失败项记录在 Error 中。
+func RemoveImages(ctx context.Context, refs []string, opts RemoveImageOptions) ([]RemoveImageResult, error) {
+	if _, err := GetClient(); err != nil {
+		return nil, err
+	}
+
+	results := make([]RemoveImageResult, 0, len(refs))
+	for _, ref := range refs {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		res := RemoveImageResult{Ref: ref}
+		deleted, err := RemoveImage(ctx, ref, opts)
+		if err != nil {
+			res.Error = err.Error()
+		} else {
+			res.Deleted = deleted
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
 func PruneImages(ctx context.Context, filterMap map[string][]string) (image.PruneReport, error) {
 	cli, err := GetClient()
 	if err != nil {
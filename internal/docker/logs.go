@@ -1,10 +1,15 @@
 package docker
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/pkg/stdcopy"
@@ -15,34 +20,78 @@ type GetContainerLogsOptions struct {
 	ContainerID string `json:"container_id"`
 	Tail        string `json:"tail"`
 	Since       string `json:"since"`
-	Details     bool   `json:"details"`
+	// Until 与 Since 格式相同（RFC3339 或相对时长），用于限定历史窗口的结束时间
+	Until   string `json:"until"`
+	Details bool   `json:"details"`
+	// Regex 为可选的 Go 正则表达式，在取回日志（Tail 生效）之后逐行过滤消息
+	Regex string `json:"regex"`
 }
 
-// GetContainerLogs 获取容器日志 (stdout + stderr)
-func GetContainerLogs(ctx context.Context, opts GetContainerLogsOptions) (string, error) {
-	tty := false
-	if info, err := InspectContainer(ctx, opts.ContainerID); err == nil && info != nil && info.Config != nil {
-		tty = info.Config.Tty
+// compileLogRegex 编译日志过滤正则；pattern 为空时返回 nil
+func compileLogRegex(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
 	}
-
-	cli, err := GetClient()
+	re, err := regexp.Compile(pattern)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("invalid regex %q: %w", pattern, err)
 	}
+	return re, nil
+}
+
+// filterLines 保留消息匹配 re 的行；re 为 nil 时原样返回
+func filterLines(text string, re *regexp.Regexp) string {
+	if re == nil || text == "" {
+		return text
+	}
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line == "" {
+			continue
+		}
+		// 跳过 Timestamps 添加的时间戳前缀，只匹配消息本身
+		if re.MatchString(newLogLine("", strings.TrimRight(line, "\r\n")).Message) {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
 
+// logsOptions 转换为 Docker API 的日志参数，Tail 默认 50
+func logsOptions(opts GetContainerLogsOptions) container.LogsOptions {
 	logOpts := container.LogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
 		Timestamps: true,
 		Tail:       opts.Tail,
 		Since:      opts.Since,
+		Until:      opts.Until,
 		Details:    opts.Details,
 	}
 	if logOpts.Tail == "" {
 		logOpts.Tail = "50"
 	}
+	return logOpts
+}
+
+// GetContainerLogs 获取容器日志 (stdout + stderr)
+func GetContainerLogs(ctx context.Context, opts GetContainerLogsOptions) (string, error) {
+	re, err := compileLogRegex(opts.Regex)
+	if err != nil {
+		return "", err
+	}
+
+	tty := false
+	if info, err := InspectContainer(ctx, opts.ContainerID); err == nil && info != nil && info.Config != nil {
+		tty = info.Config.Tty
+	}
+
+	cli, err := GetClient()
+	if err != nil {
+		return "", err
+	}
 
-	reader, err := cli.ContainerLogs(ctx, opts.ContainerID, logOpts)
+	reader, err := cli.ContainerLogs(ctx, opts.ContainerID, logsOptions(opts))
 	if err != nil {
 		return "", fmt.Errorf("failed to get logs for %s: %w", opts.ContainerID, err)
 	}
@@ -54,7 +103,7 @@ func GetContainerLogs(ctx context.Context, opts GetContainerLogsOptions) (string
 		if err != nil {
 			return "", fmt.Errorf("failed to read logs for %s: %w", opts.ContainerID, err)
 		}
-		result = fmt.Sprintf("=== LOGS ===\n%s", string(body))
+		result = fmt.Sprintf("=== LOGS ===\n%s", filterLines(string(body), re))
 	} else {
 		var outBuf, errBuf strings.Builder
 
@@ -62,7 +111,7 @@ func GetContainerLogs(ctx context.Context, opts GetContainerLogsOptions) (string
 		if _, err := stdcopy.StdCopy(&outBuf, &errBuf, reader); err != nil {
 			return "", fmt.Errorf("stdcopy failed for %s: %w", opts.ContainerID, err)
 		}
-		result = fmt.Sprintf("=== STDOUT ===\n%s\n=== STDERR ===\n%s", outBuf.String(), errBuf.String())
+		result = fmt.Sprintf("=== STDOUT ===\n%s\n=== STDERR ===\n%s", filterLines(outBuf.String(), re), filterLines(errBuf.String(), re))
 	}
 
 	// 简单的截断保护
@@ -74,6 +123,218 @@ func GetContainerLogs(ctx context.Context, opts GetContainerLogsOptions) (string
 	return result, nil
 }
 
+// LogLine 为一行结构化的容器日志
+type LogLine struct {
+	// Timestamp 为 Docker 记录的日志时间；无法解析时为零值
+	Timestamp time.Time `json:"timestamp"`
+	// Stream 为日志来源：stdout 或 stderr（TTY 容器统一为 stdout）
+	Stream  string `json:"stream"`
+	Message string `json:"message"`
+}
+
+// GetContainerLogStructured 获取容器日志并按行解析出时间戳与来源，保留 stdout/stderr 的原始交错顺序
+func GetContainerLogStructured(ctx context.Context, opts GetContainerLogsOptions) ([]LogLine, error) {
+	re, err := compileLogRegex(opts.Regex)
+	if err != nil {
+		return nil, err
+	}
+
+	tty := false
+	if info, err := InspectContainer(ctx, opts.ContainerID); err == nil && info != nil && info.Config != nil {
+		tty = info.Config.Tty
+	}
+
+	cli, err := GetClient()
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := cli.ContainerLogs(ctx, opts.ContainerID, logsOptions(opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs for %s: %w", opts.ContainerID, err)
+	}
+	defer reader.Close()
+
+	lines, err := readLogLines(reader, tty, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read logs for %s: %w", opts.ContainerID, err)
+	}
+	if re != nil {
+		matched := lines[:0]
+		for _, l := range lines {
+			if re.MatchString(l.Message) {
+				matched = append(matched, l)
+			}
+		}
+		lines = matched
+	}
+	return lines, nil
+}
+
+// newLogLine 拆分 Timestamps 选项添加的 RFC3339Nano 前缀
+func newLogLine(stream, line string) LogLine {
+	out := LogLine{Stream: stream, Message: line}
+	if i := strings.IndexByte(line, ' '); i > 0 {
+		if ts, err := time.Parse(time.RFC3339Nano, line[:i]); err == nil {
+			out.Timestamp = ts
+			out.Message = line[i+1:]
+		}
+	}
+	return out
+}
+
+const (
+	// DefaultTailDuration/MaxTailDuration 为实时跟踪日志的默认/最大时长
+	DefaultTailDuration = 10 * time.Second
+	MaxTailDuration     = 60 * time.Second
+	// DefaultTailLines/MaxTailLines 为实时跟踪日志最多收集的默认/最大行数
+	DefaultTailLines = 100
+	MaxTailLines     = 1000
+)
+
+// TailContainerLogsOptions 定义实时跟踪日志的参数
+type TailContainerLogsOptions struct {
+	ContainerID string
+	// Duration 为最长跟踪时长，<=0 时使用 DefaultTailDuration，超过 MaxTailDuration 时截断
+	Duration time.Duration
+	// MaxLines 为收集到多少行新日志后提前结束，<=0 时使用 DefaultTailLines，超过 MaxTailLines 时截断
+	MaxLines int
+}
+
+// errTailLimit 表示已收集到足够行数，用于中断日志流的复制
+var errTailLimit = errors.New("tail line limit reached")
+
+// TailContainerLogs 以 Follow 方式跟踪容器的新日志（不含历史日志），
+// 在达到时长或行数上限时结束并返回已收集的行；不依赖 monitor 的日志采集。
+// ctx 被取消时返回已收集的行与 ctx.Err()。
+func TailContainerLogs(ctx context.Context, opts TailContainerLogsOptions) ([]LogLine, error) {
+	duration := opts.Duration
+	if duration <= 0 {
+		duration = DefaultTailDuration
+	}
+	duration = min(duration, MaxTailDuration)
+	maxLines := opts.MaxLines
+	if maxLines <= 0 {
+		maxLines = DefaultTailLines
+	}
+	maxLines = min(maxLines, MaxTailLines)
+
+	tty := false
+	if info, err := InspectContainer(ctx, opts.ContainerID); err == nil && info != nil && info.Config != nil {
+		tty = info.Config.Tty
+	}
+
+	cli, err := GetClient()
+	if err != nil {
+		return nil, err
+	}
+
+	tailCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	reader, err := cli.ContainerLogs(tailCtx, opts.ContainerID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+		Follow:     true,
+		Tail:       "0",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to follow logs for %s: %w", opts.ContainerID, err)
+	}
+	defer reader.Close()
+
+	lines, err := readLogLines(reader, tty, maxLines)
+	if ctx.Err() != nil {
+		return lines, ctx.Err()
+	}
+	// 达到时长上限时连接被取消，读取错误属于正常结束
+	if err != nil && tailCtx.Err() == nil {
+		return lines, fmt.Errorf("failed to read logs for %s: %w", opts.ContainerID, err)
+	}
+	return lines, nil
+}
+
+// readLogLines 将 docker logs 的输出按行解析；非 TTY 时使用 stdcopy 解析多路复用流，TTY 时为原始文本。
+// maxLines > 0 时收集到该行数即返回，不视为错误；否则读到流结束为止
+func readLogLines(r io.Reader, tty bool, maxLines int) ([]LogLine, error) {
+	c := &lineCollector{max: maxLines, pending: map[string]*bytes.Buffer{}}
+	stdout := &streamWriter{c: c, stream: "stdout"}
+
+	var err error
+	if tty {
+		_, err = io.Copy(stdout, r)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, &streamWriter{c: c, stream: "stderr"}, r)
+	}
+	if errors.Is(err, errTailLimit) {
+		err = nil
+	}
+	return c.result(), err
+}
+
+// lineCollector 将 stdout/stderr 的写入按行切分并收集
+type lineCollector struct {
+	mu sync.Mutex
+	// max 为最多收集的行数，<= 0 表示不限
+	max     int
+	lines   []LogLine
+	pending map[string]*bytes.Buffer
+}
+
+func (c *lineCollector) write(stream string, p []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	buf, ok := c.pending[stream]
+	if !ok {
+		buf = &bytes.Buffer{}
+		c.pending[stream] = buf
+	}
+	buf.Write(p)
+	for !c.full() {
+		i := bytes.IndexByte(buf.Bytes(), '\n')
+		if i < 0 {
+			return nil
+		}
+		line := string(buf.Next(i + 1))
+		c.lines = append(c.lines, newLogLine(stream, strings.TrimRight(line, "\r\n")))
+	}
+	return errTailLimit
+}
+
+// result 返回已收集的行；未达上限时附带末尾没有换行的残留内容
+func (c *lineCollector) result() []LogLine {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, stream := range []string{"stdout", "stderr"} {
+		if c.full() {
+			break
+		}
+		if buf, ok := c.pending[stream]; ok && buf.Len() > 0 {
+			c.lines = append(c.lines, newLogLine(stream, strings.TrimRight(buf.String(), "\r\n")))
+			buf.Reset()
+		}
+	}
+	return c.lines
+}
+
+func (c *lineCollector) full() bool {
+	return c.max > 0 && len(c.lines) >= c.max
+}
+
+type streamWriter struct {
+	c      *lineCollector
+	stream string
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	if err := w.c.write(w.stream, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
 // ContainerLogs 获取容器日志流
 func ContainerLogs(ctx context.Context, containerID string, opts container.LogsOptions) (io.ReadCloser, error) {
 	cli, err := GetClient()
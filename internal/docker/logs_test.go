@@ -0,0 +1,110 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+)
+
+func muxFrame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func TestReadLogLinesMultiplexed(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(muxFrame(1, "2024-01-02T15:04:05.123456789Z server started\n"))
+	buf.Write(muxFrame(2, "2024-01-02T15:04:06Z GET /x 502\n"))
+	// 一行日志被拆成两个帧
+	buf.Write(muxFrame(1, "2024-01-02T15:04:07Z part"))
+	buf.Write(muxFrame(1, "ial line\n"))
+
+	lines, err := readLogLines(&buf, false, 0)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %+v", len(lines), lines)
+	}
+
+	want := time.Date(2024, 1, 2, 15, 4, 5, 123456789, time.UTC)
+	if !lines[0].Timestamp.Equal(want) || lines[0].Stream != "stdout" || lines[0].Message != "server started" {
+		t.Fatalf("unexpected first line: %+v", lines[0])
+	}
+	if lines[1].Stream != "stderr" || lines[1].Message != "GET /x 502" {
+		t.Fatalf("unexpected second line: %+v", lines[1])
+	}
+	if lines[2].Message != "partial line" {
+		t.Fatalf("unexpected third line: %+v", lines[2])
+	}
+}
+
+func TestReadLogLinesTTY(t *testing.T) {
+	r := strings.NewReader("2024-01-02T15:04:05Z hello\r\nno timestamp")
+	lines, err := readLogLines(r, true, 0)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if lines[0].Stream != "stdout" || lines[0].Message != "hello" || lines[0].Timestamp.IsZero() {
+		t.Fatalf("unexpected first line: %+v", lines[0])
+	}
+	if !lines[1].Timestamp.IsZero() || lines[1].Message != "no timestamp" {
+		t.Fatalf("unexpected second line: %+v", lines[1])
+	}
+}
+
+func TestFilterLines(t *testing.T) {
+	re, err := compileLogRegex(`\s5\d\d$`)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	text := "2024-01-02T15:04:05Z GET /a 200\n2024-01-02T15:04:06Z GET /b 503\n2024-01-02T15:04:07Z 500 at start\n"
+	got := filterLines(text, re)
+	if got != "2024-01-02T15:04:06Z GET /b 503\n" {
+		t.Fatalf("unexpected filtered output: %q", got)
+	}
+
+	// 时间戳前缀不参与匹配
+	re, _ = compileLogRegex(`^500`)
+	if got := filterLines(text, re); got != "2024-01-02T15:04:07Z 500 at start\n" {
+		t.Fatalf("expected match against message only, got %q", got)
+	}
+
+	if _, err := compileLogRegex("(["); err == nil || !strings.Contains(err.Error(), "invalid regex") {
+		t.Fatalf("expected invalid regex error, got %v", err)
+	}
+}
+
+func TestReadLogLinesMaxLines(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(muxFrame(1, "2024-01-02T15:04:05Z one\n2024-01-02T15:04:06Z two\n"))
+	buf.Write(muxFrame(2, "2024-01-02T15:04:07Z three\n"))
+	buf.Write(muxFrame(1, "2024-01-02T15:04:08Z four\n"))
+
+	lines, err := readLogLines(bytes.NewReader(buf.Bytes()), false, 3)
+	if err != nil {
+		t.Fatalf("tail: %v", err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines (max), got %d: %+v", len(lines), lines)
+	}
+	if lines[2].Stream != "stderr" || lines[2].Message != "three" {
+		t.Fatalf("unexpected third line: %+v", lines[2])
+	}
+
+	// 未达上限时读到 EOF 结束，并保留末尾残留内容
+	lines, err = readLogLines(strings.NewReader("2024-01-02T15:04:05Z hello\r\npartial"), true, 10)
+	if err != nil {
+		t.Fatalf("tail tty: %v", err)
+	}
+	if len(lines) != 2 || lines[0].Message != "hello" || lines[1].Message != "partial" {
+		t.Fatalf("unexpected tty lines: %+v", lines)
+	}
+}
@@ -3,6 +3,8 @@ package docker
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/network"
@@ -11,6 +13,8 @@ import (
 type ListNetworksOptions struct {
 	// Filters 列表过滤条件，key/value 语义与 Docker Engine API 一致。
 	Filters map[string][]string
+	// FullID 为 true 时返回完整的网络 ID，默认按 ShortIDLength 截断。
+	FullID bool
 }
 
 // NetworkSummary 网络列表的简化信息（用于 list 输出）。
@@ -48,7 +52,7 @@ func ListNetworks(ctx context.Context, opts ListNetworksOptions) ([]NetworkSumma
 	result := make([]NetworkSummary, 0, len(networks))
 	for _, n := range networks {
 		result = append(result, NetworkSummary{
-			ID:     truncateID(n.ID),
+			ID:     displayID(n.ID, opts.FullID),
 			Name:   n.Name,
 			Driver: n.Driver,
 			Scope:  n.Scope,
@@ -106,6 +110,64 @@ func InspectNetwork(ctx context.Context, networkID string) (network.Inspect, err
 	return inspected, nil
 }
 
+// InspectNetworkDetail 简化版的网络详情，重点列出已连接的容器。
+type InspectNetworkDetail struct {
+	ID         string              `json:"id"`
+	Name       string              `json:"name"`
+	Driver     string              `json:"driver"`
+	Scope      string              `json:"scope"`
+	Internal   bool                `json:"internal,omitempty"`
+	Subnets    []NetworkSubnet     `json:"subnets,omitempty"`
+	Containers []AttachedContainer `json:"containers"`
+}
+
+// NetworkSubnet IPAM 子网与网关。
+type NetworkSubnet struct {
+	Subnet  string `json:"subnet"`
+	Gateway string `json:"gateway,omitempty"`
+}
+
+// AttachedContainer 连接到网络的容器（按名称排序）。
+type AttachedContainer struct {
+	ContainerID   string `json:"container_id"`
+	ContainerName string `json:"container_name"`
+	IPv4          string `json:"ipv4,omitempty"`
+}
+
+// GetNetworkDetail 返回网络的简化详情（子网、网关与已连接容器）。
+func GetNetworkDetail(ctx context.Context, networkID string) (*InspectNetworkDetail, error) {
+	inspected, err := InspectNetwork(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+	return newInspectNetworkDetail(inspected), nil
+}
+
+func newInspectNetworkDetail(info network.Inspect) *InspectNetworkDetail {
+	detail := &InspectNetworkDetail{
+		ID:         info.ID,
+		Name:       info.Name,
+		Driver:     info.Driver,
+		Scope:      info.Scope,
+		Internal:   info.Internal,
+		Containers: make([]AttachedContainer, 0, len(info.Containers)),
+	}
+	for _, cfg := range info.IPAM.Config {
+		detail.Subnets = append(detail.Subnets, NetworkSubnet{Subnet: cfg.Subnet, Gateway: cfg.Gateway})
+	}
+	for id, res := range info.Containers {
+		detail.Containers = append(detail.Containers, AttachedContainer{
+			ContainerID:   ShortID(id),
+			ContainerName: res.Name,
+			IPv4:          stripCIDR(res.IPv4Address),
+		})
+	}
+	sort.Slice(detail.Containers, func(i, j int) bool {
+		return detail.Containers[i].ContainerName < detail.Containers[j].ContainerName
+	})
+	return detail
+}
+
 type ConnectNetworkOptions struct {
 	// ContainerID 要连接到网络的容器 ID 或名称。
 	ContainerID string
@@ -113,15 +175,72 @@ type ConnectNetworkOptions struct {
 	EndpointConfig *network.EndpointSettings
 }
 
-func ConnectNetwork(ctx context.Context, networkID string, opts ConnectNetworkOptions) error {
+// NetworkEndpoint 容器在某个网络上的 endpoint 信息。
+type NetworkEndpoint struct {
+	NetworkID     string   `json:"network_id"`
+	NetworkName   string   `json:"network_name"`
+	ContainerID   string   `json:"container_id"`
+	ContainerName string   `json:"container_name"`
+	EndpointID    string   `json:"endpoint_id"`
+	IPv4Address   string   `json:"ipv4_address,omitempty"`
+	IPv6Address   string   `json:"ipv6_address,omitempty"`
+	MacAddress    string   `json:"mac_address,omitempty"`
+	Aliases       []string `json:"aliases,omitempty"`
+}
+
+// ConnectNetwork 将容器连接到网络，并通过 inspect 返回分配到的 IP/MAC/别名。
+func ConnectNetwork(ctx context.Context, networkID string, opts ConnectNetworkOptions) (*NetworkEndpoint, error) {
 	cli, err := GetClient()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if err := cli.NetworkConnect(ctx, networkID, opts.ContainerID, opts.EndpointConfig); err != nil {
-		return fmt.Errorf("failed to connect container %s to network %s: %w", opts.ContainerID, networkID, err)
+		return nil, fmt.Errorf("failed to connect container %s to network %s: %w", opts.ContainerID, networkID, err)
 	}
-	return nil
+
+	ctr, err := cli.ContainerInspect(ctx, opts.ContainerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container %s: %w", opts.ContainerID, err)
+	}
+	netInfo, err := cli.NetworkInspect(ctx, networkID, network.InspectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect network %s: %w", networkID, err)
+	}
+
+	ep := &NetworkEndpoint{
+		NetworkID:     netInfo.ID,
+		NetworkName:   netInfo.Name,
+		ContainerID:   ctr.ID,
+		ContainerName: strings.TrimPrefix(ctr.Name, "/"),
+	}
+	if res, ok := netInfo.Containers[ctr.ID]; ok {
+		ep.EndpointID = res.EndpointID
+		ep.IPv4Address = stripCIDR(res.IPv4Address)
+		ep.IPv6Address = stripCIDR(res.IPv6Address)
+		ep.MacAddress = res.MacAddress
+	}
+	if ctr.NetworkSettings != nil {
+		if settings, ok := ctr.NetworkSettings.Networks[netInfo.Name]; ok && settings != nil {
+			ep.Aliases = settings.Aliases
+			// 网络 inspect 偶尔尚未同步新 endpoint，回退到容器侧的记录
+			if ep.IPv4Address == "" {
+				ep.IPv4Address = settings.IPAddress
+			}
+			if ep.MacAddress == "" {
+				ep.MacAddress = settings.MacAddress
+			}
+			if ep.EndpointID == "" {
+				ep.EndpointID = settings.EndpointID
+			}
+		}
+	}
+	return ep, nil
+}
+
+// stripCIDR 去掉 172.18.0.5/16 形式地址中的前缀长度。
+func stripCIDR(addr string) string {
+	ip, _, _ := strings.Cut(addr, "/")
+	return ip
 }
 
 type DisconnectNetworkOptions struct {
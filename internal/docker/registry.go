@@ -0,0 +1,258 @@
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/containerd/containerd/errdefs"
+	"github.com/docker/docker/api/types/registry"
+)
+
+// DefaultRegistryHost 为未显式指定仓库地址时的默认仓库（Docker Hub）
+const DefaultRegistryHost = "docker.io"
+
+var (
+	// ErrRegistryUnauthorized 表示仓库拒绝了认证（凭据缺失或错误）
+	ErrRegistryUnauthorized = errors.New("registry authentication failed")
+	// ErrImageNotFound 表示仓库中不存在该镜像或标签
+	ErrImageNotFound = errors.New("image not found in registry")
+)
+
+// RegistryCredential 为访问镜像仓库的凭据
+type RegistryCredential struct {
+	Username string
+	Password string
+}
+
+// RegistryConfig 为配置文件中单个镜像仓库的凭据
+type RegistryConfig struct {
+	// Host 为仓库地址（如 ghcr.io、registry.example.com:5000），Docker Hub 写作 docker.io
+	Host string `mapstructure:"host"`
+	// Username/Password 为仓库凭据，Password 也可以是访问令牌
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// Helper 为 docker credential helper 名称（如 ecr-login，对应 docker-credential-ecr-login），
+	// 设置后凭据由 helper 提供，忽略 Username/Password
+	Helper string `mapstructure:"helper"`
+}
+
+var (
+	registriesMu sync.RWMutex
+	registries   []RegistryConfig
+)
+
+// SetRegistries 设置配置文件中的仓库凭据，拉取镜像时优先于 docker CLI 配置文件使用
+func SetRegistries(list []RegistryConfig) {
+	registriesMu.Lock()
+	defer registriesMu.Unlock()
+	registries = append([]RegistryConfig(nil), list...)
+}
+
+// ValidateRegistries 校验仓库凭据配置：host 必填且不能重复，helper 与 username/password 不能同时设置
+func ValidateRegistries(list []RegistryConfig) error {
+	seen := make(map[string]bool, len(list))
+	for i, r := range list {
+		host := normalizeRegistryHost(r.Host)
+		if host == "" {
+			return fmt.Errorf("registries[%d]: host is required", i)
+		}
+		if seen[host] {
+			return fmt.Errorf("registries[%d]: duplicate host %q", i, r.Host)
+		}
+		seen[host] = true
+		if r.Helper != "" && (r.Username != "" || r.Password != "") {
+			return fmt.Errorf("registries[%d] (%s): helper and username/password are mutually exclusive", i, r.Host)
+		}
+		if r.Helper == "" && r.Username == "" {
+			return fmt.Errorf("registries[%d] (%s): username or helper is required", i, r.Host)
+		}
+	}
+	return nil
+}
+
+// ResolveRegistryCredential 按 配置文件 registries > docker CLI 配置文件 的顺序查找 host 的凭据
+func ResolveRegistryCredential(host string) (RegistryCredential, bool, error) {
+	host = normalizeRegistryHost(host)
+
+	registriesMu.RLock()
+	var matched *RegistryConfig
+	for i := range registries {
+		if normalizeRegistryHost(registries[i].Host) == host {
+			r := registries[i]
+			matched = &r
+			break
+		}
+	}
+	registriesMu.RUnlock()
+
+	if matched != nil {
+		if matched.Helper != "" {
+			cred, err := helperCredential(matched.Helper, host)
+			if err != nil {
+				return RegistryCredential{}, false, err
+			}
+			return cred, true, nil
+		}
+		return RegistryCredential{Username: matched.Username, Password: matched.Password}, true, nil
+	}
+	return dockerConfigCredential(host)
+}
+
+// helperCredential 调用 docker-credential-<helper> get 获取凭据，协议与 docker CLI 相同
+func helperCredential(helper, host string) (RegistryCredential, error) {
+	serverURL := host
+	if host == DefaultRegistryHost {
+		serverURL = "https://index.docker.io/v1/"
+	}
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+	out, err := cmd.Output()
+	if err != nil {
+		return RegistryCredential{}, fmt.Errorf("credential helper %s failed for %s: %w", helper, host, err)
+	}
+	var resp struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return RegistryCredential{}, fmt.Errorf("invalid output from credential helper %s: %w", helper, err)
+	}
+	return RegistryCredential{Username: resp.Username, Password: resp.Secret}, nil
+}
+
+// RegistryHost 返回镜像引用所属的仓库地址，规则与 docker CLI 一致：
+// 第一段包含 "." 或 ":"，或为 localhost 时视为仓库地址，否则为 Docker Hub。
+func RegistryHost(ref string) string {
+	ref = strings.TrimSpace(ref)
+	first, _, found := strings.Cut(ref, "/")
+	if !found {
+		return DefaultRegistryHost
+	}
+	if strings.ContainsAny(first, ".:") || first == "localhost" {
+		if first == "index.docker.io" || first == "registry-1.docker.io" {
+			return DefaultRegistryHost
+		}
+		return first
+	}
+	return DefaultRegistryHost
+}
+
+// encodeRegistryAuth 生成 ImagePull 所需的 X-Registry-Auth 头；凭据为空时返回空串。
+func encodeRegistryAuth(host string, cred RegistryCredential) (string, error) {
+	if cred.Username == "" && cred.Password == "" {
+		return "", nil
+	}
+	auth, err := registry.EncodeAuthConfig(registry.AuthConfig{
+		Username:      cred.Username,
+		Password:      cred.Password,
+		ServerAddress: host,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode registry auth for %s: %w", host, err)
+	}
+	return auth, nil
+}
+
+// dockerConfigPath 返回 docker CLI 配置文件路径（优先 $DOCKER_CONFIG）
+func dockerConfigPath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+// dockerConfigCredential 从 docker CLI 配置文件的 auths 中读取仓库凭据。
+// 文件不存在或没有匹配项时返回 ok=false；凭据存储在 credential helper 中的情况不做处理。
+func dockerConfigCredential(host string) (RegistryCredential, bool, error) {
+	path := dockerConfigPath()
+	if path == "" {
+		return RegistryCredential{}, false, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return RegistryCredential{}, false, nil
+	}
+	if err != nil {
+		return RegistryCredential{}, false, fmt.Errorf("failed to read docker config %s: %w", path, err)
+	}
+
+	var cfg struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return RegistryCredential{}, false, fmt.Errorf("failed to parse docker config %s: %w", path, err)
+	}
+
+	for key, entry := range cfg.Auths {
+		if normalizeRegistryHost(key) != host {
+			continue
+		}
+		if entry.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return RegistryCredential{}, false, fmt.Errorf("invalid auth for %s in docker config: %w", key, err)
+			}
+			user, pass, _ := strings.Cut(string(decoded), ":")
+			return RegistryCredential{Username: user, Password: pass}, true, nil
+		}
+		if entry.Username != "" {
+			return RegistryCredential{Username: entry.Username, Password: entry.Password}, true, nil
+		}
+	}
+	return RegistryCredential{}, false, nil
+}
+
+// normalizeRegistryHost 去掉 scheme 与路径，并将 Docker Hub 的各种写法统一为 docker.io
+func normalizeRegistryHost(s string) string {
+	s = strings.TrimSpace(strings.ToLower(s))
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "https://"), "http://")
+	s, _, _ = strings.Cut(s, "/")
+	switch s {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return DefaultRegistryHost
+	}
+	return s
+}
+
+// classifyPullError 区分认证失败与镜像不存在，便于调用方给出准确提示
+func classifyPullError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errdefs.IsUnauthorized(err) || errdefs.IsPermissionDenied(err) {
+		return fmt.Errorf("%w: %w", ErrRegistryUnauthorized, err)
+	}
+	if errdefs.IsNotFound(err) {
+		return fmt.Errorf("%w: %w", ErrImageNotFound, err)
+	}
+
+	// 拉取流中的错误只有文本，按 registry 常见报错归类
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "manifest unknown"),
+		strings.Contains(msg, "not found"),
+		strings.Contains(msg, "does not exist"):
+		return fmt.Errorf("%w: %w", ErrImageNotFound, err)
+	case strings.Contains(msg, "unauthorized"),
+		strings.Contains(msg, "authentication required"),
+		strings.Contains(msg, "no basic auth credentials"),
+		strings.Contains(msg, "denied"):
+		return fmt.Errorf("%w: %w", ErrRegistryUnauthorized, err)
+	}
+	return err
+}
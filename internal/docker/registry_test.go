@@ -0,0 +1,157 @@
+package docker
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRegistryHost(t *testing.T) {
+	cases := map[string]string{
+		"nginx":                                "docker.io",
+		"nginx:alpine":                         "docker.io",
+		"library/nginx:alpine":                 "docker.io",
+		"index.docker.io/library/nginx":        "docker.io",
+		"ghcr.io/org/app:1.0":                  "ghcr.io",
+		"registry.example.com:5000/team/app":   "registry.example.com:5000",
+		"localhost/app":                        "localhost",
+		"localhost:5000/app@sha256:abcdef0123": "localhost:5000",
+	}
+	for ref, want := range cases {
+		if got := RegistryHost(ref); got != want {
+			t.Fatalf("RegistryHost(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestDockerConfigCredential(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+
+	if _, ok, err := dockerConfigCredential("ghcr.io"); err != nil || ok {
+		t.Fatalf("expected no credential without config file, got ok=%v err=%v", ok, err)
+	}
+
+	auth := base64.StdEncoding.EncodeToString([]byte("bob:s3cret"))
+	cfg := fmt.Sprintf(`{"auths":{"https://index.docker.io/v1/":{"auth":%q},"ghcr.io":{"username":"alice","password":"tok"}}}`, auth)
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(cfg), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cred, ok, err := dockerConfigCredential("docker.io")
+	if err != nil || !ok || cred.Username != "bob" || cred.Password != "s3cret" {
+		t.Fatalf("unexpected docker hub credential: %+v ok=%v err=%v", cred, ok, err)
+	}
+	cred, ok, err = dockerConfigCredential("ghcr.io")
+	if err != nil || !ok || cred.Username != "alice" || cred.Password != "tok" {
+		t.Fatalf("unexpected ghcr credential: %+v ok=%v err=%v", cred, ok, err)
+	}
+	if _, ok, _ := dockerConfigCredential("quay.io"); ok {
+		t.Fatalf("expected no credential for quay.io")
+	}
+
+	// 显式凭据优先于配置文件
+	auth1, err := pullRegistryAuth("ghcr.io/org/app", PullImageOptions{Username: "carol", Password: "pw"})
+	if err != nil || auth1 == "" {
+		t.Fatalf("encode explicit auth: %q %v", auth1, err)
+	}
+	auth2, err := pullRegistryAuth("ghcr.io/org/app", PullImageOptions{})
+	if err != nil || auth2 == "" || auth2 == auth1 {
+		t.Fatalf("expected config-file auth to differ from explicit auth: %q %v", auth2, err)
+	}
+	if auth, err := pullRegistryAuth("quay.io/org/app", PullImageOptions{}); err != nil || auth != "" {
+		t.Fatalf("expected empty auth for unknown registry, got %q %v", auth, err)
+	}
+}
+
+func TestClassifyPullError(t *testing.T) {
+	cases := map[string]error{
+		"manifest for nginx:nope not found: manifest unknown":                                 ErrImageNotFound,
+		"pull access denied for foo, repository does not exist or may require 'docker login'": ErrImageNotFound,
+		"Head \"https://ghcr.io/v2/org/app/manifests/1\": unauthorized":                       ErrRegistryUnauthorized,
+		"no basic auth credentials":                                                           ErrRegistryUnauthorized,
+	}
+	for msg, want := range cases {
+		if err := classifyPullError(errors.New(msg)); !errors.Is(err, want) {
+			t.Fatalf("classify %q: got %v, want %v", msg, err, want)
+		}
+	}
+	other := errors.New("connection reset by peer")
+	if err := classifyPullError(other); err != other {
+		t.Fatalf("expected unrelated error unchanged, got %v", err)
+	}
+}
+
+// TestPullImagePrivateRegistry 演示私有仓库拉取的认证路径：
+// 设置 CENTAGENT_TEST_REGISTRY_IMAGE/USER/PASSWORD 后运行，否则跳过。
+func TestPullImagePrivateRegistry(t *testing.T) {
+	ref := os.Getenv("CENTAGENT_TEST_REGISTRY_IMAGE")
+	if ref == "" {
+		t.Skip("CENTAGENT_TEST_REGISTRY_IMAGE not set; skipping private registry pull")
+	}
+	requireDocker(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	// 错误的密码应被识别为认证失败，而不是镜像不存在
+	_, err := PullImageWithProgress(ctx, PullImageOptions{Ref: ref, Username: "centagent-invalid", Password: "invalid"}, nil)
+	if !errors.Is(err, ErrRegistryUnauthorized) {
+		t.Fatalf("expected auth failure with bad credentials, got %v", err)
+	}
+
+	res, err := PullImageWithProgress(ctx, PullImageOptions{
+		Ref:      ref,
+		Username: os.Getenv("CENTAGENT_TEST_REGISTRY_USER"),
+		Password: os.Getenv("CENTAGENT_TEST_REGISTRY_PASSWORD"),
+	}, nil)
+	if err != nil {
+		t.Fatalf("pull %s: %v", ref, err)
+	}
+	t.Logf("pulled %s digest=%s", ref, res.Digest)
+}
+
+func TestResolveRegistryCredentialFromConfig(t *testing.T) {
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	SetRegistries([]RegistryConfig{
+		{Host: "ghcr.io", Username: "bot", Password: "tok"},
+		{Host: "https://index.docker.io/v1/", Username: "hub", Password: "pw"},
+	})
+	t.Cleanup(func() { SetRegistries(nil) })
+
+	cred, ok, err := ResolveRegistryCredential(RegistryHost("ghcr.io/org/app:1.0"))
+	if err != nil || !ok || cred.Username != "bot" || cred.Password != "tok" {
+		t.Fatalf("unexpected ghcr credential: %+v ok=%v err=%v", cred, ok, err)
+	}
+	cred, ok, err = ResolveRegistryCredential(RegistryHost("library/nginx:alpine"))
+	if err != nil || !ok || cred.Username != "hub" {
+		t.Fatalf("unexpected docker hub credential: %+v ok=%v err=%v", cred, ok, err)
+	}
+	if _, ok, _ := ResolveRegistryCredential(RegistryHost("quay.io/org/app")); ok {
+		t.Fatalf("expected no credential for quay.io")
+	}
+}
+
+func TestValidateRegistries(t *testing.T) {
+	valid := []RegistryConfig{{Host: "ghcr.io", Username: "bot"}, {Host: "ecr.example.com", Helper: "ecr-login"}}
+	if err := ValidateRegistries(valid); err != nil {
+		t.Fatalf("expected valid registries, got %v", err)
+	}
+
+	invalid := [][]RegistryConfig{
+		{{Username: "bot"}},
+		{{Host: "ghcr.io", Username: "a"}, {Host: "GHCR.io", Username: "b"}},
+		{{Host: "ghcr.io", Helper: "pass", Username: "bot"}},
+		{{Host: "ghcr.io"}},
+	}
+	for _, list := range invalid {
+		if err := ValidateRegistries(list); err == nil {
+			t.Fatalf("expected error for %+v", list)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// StatsSummary 为从 Docker stats 响应中提取的常用指标，计算口径与 docker stats 一致
+type StatsSummary struct {
+	CPUPercent      float64 `json:"cpu_percent"`
+	MemUsageBytes   uint64  `json:"mem_usage_bytes"`
+	MemLimitBytes   uint64  `json:"mem_limit_bytes"`
+	MemPercent      float64 `json:"mem_percent"`
+	NetRxBytes      uint64  `json:"net_rx_bytes"`
+	NetTxBytes      uint64  `json:"net_tx_bytes"`
+	BlockReadBytes  uint64  `json:"block_read_bytes"`
+	BlockWriteBytes uint64  `json:"block_write_bytes"`
+	Pids            uint64  `json:"pids"`
+	// ReadAt 为 daemon 采样时间；响应中缺失时为当前时间
+	ReadAt time.Time `json:"read_at"`
+}
+
+// ParseStats 从 stats 响应计算 CPU/内存百分比并汇总网络与块设备 I/O
+func ParseStats(stats container.StatsResponse) StatsSummary {
+	out := StatsSummary{
+		CPUPercent:    CalculateCPUPercent(stats),
+		MemUsageBytes: stats.MemoryStats.Usage,
+		MemLimitBytes: stats.MemoryStats.Limit,
+		Pids:          stats.PidsStats.Current,
+		ReadAt:        time.Now(),
+	}
+	if out.MemLimitBytes > 0 {
+		out.MemPercent = (float64(out.MemUsageBytes) / float64(out.MemLimitBytes)) * 100.0
+	}
+
+	out.NetRxBytes, out.NetTxBytes = NetworkIO(stats)
+	out.BlockReadBytes, out.BlockWriteBytes = BlockIO(stats)
+
+	if !stats.Read.IsZero() {
+		out.ReadAt = stats.Read
+	}
+	return out
+}
+
+// NetworkIO 汇总所有网卡的累计收发字节数
+func NetworkIO(stats container.StatsResponse) (rx, tx uint64) {
+	for _, nw := range stats.Networks {
+		rx += nw.RxBytes
+		tx += nw.TxBytes
+	}
+	return rx, tx
+}
+
+// BlockIO 汇总块设备的累计读写字节数
+func BlockIO(stats container.StatsResponse) (read, write uint64) {
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			read += entry.Value
+		case "write":
+			write += entry.Value
+		}
+	}
+	return read, write
+}
+
+// CalculateCPUPercent 按 docker stats 的方式计算 CPU 使用率：(容器 CPU 增量 / 系统 CPU 增量) * 核数 * 100
+func CalculateCPUPercent(stats container.StatsResponse) float64 {
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+	onlineCPUs := float64(stats.CPUStats.OnlineCPUs)
+	if onlineCPUs <= 0 {
+		if n := len(stats.CPUStats.CPUUsage.PercpuUsage); n > 0 {
+			onlineCPUs = float64(n)
+		} else {
+			onlineCPUs = 1
+		}
+	}
+	return (cpuDelta / systemDelta) * onlineCPUs * 100.0
+}
+
+// GetContainerStatsSummary 获取容器一次采样并解析为 StatsSummary
+func GetContainerStatsSummary(ctx context.Context, containerID string) (StatsSummary, container.StatsResponse, error) {
+	resp, err := GetContainerStatsOneShot(ctx, containerID)
+	if err != nil {
+		return StatsSummary{}, container.StatsResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	var stats container.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return StatsSummary{}, container.StatsResponse{}, fmt.Errorf("failed to decode stats: %w", err)
+	}
+	return ParseStats(stats), stats, nil
+}
+
+const (
+	// DefaultStatsSamples 为 SampleContainerStats 默认采集的有效帧数
+	DefaultStatsSamples = 3
+	// MaxStatsSamples 为单次采样的帧数上限（Docker 约每秒推送一帧）
+	MaxStatsSamples = 10
+)
+
+// SampledStats 为流式多次采样的结果：CPUPercent 为有效帧的平均值，其余指标取最后一帧
+type SampledStats struct {
+	StatsSummary
+	// Samples 为参与 CPU 平均的有效帧数；超时或流提前结束时可能少于请求值
+	Samples int `json:"samples"`
+}
+
+// SampleContainerStats 以流式方式读取容器 stats，采集 samples 个有效帧后取 CPU 平均值。
+// 单次读取的首帧没有 PreCPUStats，CPU 恒为 0，这里不计入平均；总时长由 ctx 控制。
+func SampleContainerStats(ctx context.Context, containerID string, samples int) (SampledStats, error) {
+	if samples <= 0 {
+		samples = DefaultStatsSamples
+	}
+	if samples > MaxStatsSamples {
+		samples = MaxStatsSamples
+	}
+
+	resp, err := GetContainerStats(ctx, containerID, true)
+	if err != nil {
+		return SampledStats{}, err
+	}
+	defer resp.Body.Close()
+	return sampleStatsStream(ctx, resp.Body, samples)
+}
+
+// sampleStatsStream 从 stats 流中解码帧直到得到 samples 个有效帧、流结束或 ctx 结束；已有数据时返回部分结果
+func sampleStatsStream(ctx context.Context, r io.Reader, samples int) (SampledStats, error) {
+	dec := json.NewDecoder(r)
+
+	var (
+		out    SampledStats
+		last   *StatsSummary
+		cpuSum float64
+	)
+	for out.Samples < samples && ctx.Err() == nil {
+		var stats container.StatsResponse
+		if err := dec.Decode(&stats); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				break
+			}
+			return SampledStats{}, fmt.Errorf("failed to decode stats: %w", err)
+		}
+		summary := ParseStats(stats)
+		last = &summary
+		if stats.PreCPUStats.SystemUsage == 0 {
+			continue
+		}
+		cpuSum += summary.CPUPercent
+		out.Samples++
+	}
+
+	if last == nil {
+		if err := ctx.Err(); err != nil {
+			return SampledStats{}, fmt.Errorf("no stats received: %w", err)
+		}
+		return SampledStats{}, errors.New("no stats received")
+	}
+	out.StatsSummary = *last
+	if out.Samples > 0 {
+		out.CPUPercent = cpuSum / float64(out.Samples)
+	}
+	return out, nil
+}
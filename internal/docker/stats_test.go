@@ -0,0 +1,115 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestParseStats(t *testing.T) {
+	read := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var stats container.StatsResponse
+	stats.Read = read
+	stats.CPUStats.CPUUsage.TotalUsage = 300
+	stats.CPUStats.SystemUsage = 2000
+	stats.CPUStats.OnlineCPUs = 2
+	stats.PreCPUStats.CPUUsage.TotalUsage = 100
+	stats.PreCPUStats.SystemUsage = 1000
+	stats.MemoryStats.Usage = 256
+	stats.MemoryStats.Limit = 1024
+	stats.PidsStats.Current = 7
+	stats.Networks = map[string]container.NetworkStats{
+		"eth0": {RxBytes: 10, TxBytes: 20},
+		"eth1": {RxBytes: 1, TxBytes: 2},
+	}
+	stats.BlkioStats.IoServiceBytesRecursive = []container.BlkioStatEntry{
+		{Op: "Read", Value: 100},
+		{Op: "Write", Value: 50},
+		{Op: "read", Value: 5},
+	}
+
+	got := ParseStats(stats)
+	// (200 / 1000) * 2 * 100
+	if math.Abs(got.CPUPercent-40) > 1e-9 {
+		t.Fatalf("unexpected cpu percent: %v", got.CPUPercent)
+	}
+	if got.MemPercent != 25 {
+		t.Fatalf("unexpected mem percent: %v", got.MemPercent)
+	}
+	if got.NetRxBytes != 11 || got.NetTxBytes != 22 {
+		t.Fatalf("unexpected net io: %d/%d", got.NetRxBytes, got.NetTxBytes)
+	}
+	if got.BlockReadBytes != 105 || got.BlockWriteBytes != 50 {
+		t.Fatalf("unexpected block io: %d/%d", got.BlockReadBytes, got.BlockWriteBytes)
+	}
+	if got.Pids != 7 || !got.ReadAt.Equal(read) {
+		t.Fatalf("unexpected pids/read: %d %v", got.Pids, got.ReadAt)
+	}
+
+	// 首次采样没有 PreCPUStats 时 CPU 为 0
+	if p := CalculateCPUPercent(container.StatsResponse{}); p != 0 {
+		t.Fatalf("expected 0 cpu percent, got %v", p)
+	}
+}
+
+func statsFrame(pre, cur uint64, preSys, curSys uint64, mem uint64) container.StatsResponse {
+	var s container.StatsResponse
+	s.CPUStats.CPUUsage.TotalUsage = cur
+	s.CPUStats.SystemUsage = curSys
+	s.CPUStats.OnlineCPUs = 1
+	s.PreCPUStats.CPUUsage.TotalUsage = pre
+	s.PreCPUStats.SystemUsage = preSys
+	s.MemoryStats.Usage = mem
+	s.MemoryStats.Limit = 1000
+	return s
+}
+
+func TestSampleStatsStream(t *testing.T) {
+	frames := []container.StatsResponse{
+		// 首帧没有 PreCPUStats，不计入平均
+		statsFrame(0, 100, 0, 1000, 100),
+		statsFrame(100, 200, 1000, 2000, 200), // 10%
+		statsFrame(200, 400, 2000, 3000, 300), // 20%
+		statsFrame(400, 700, 3000, 4000, 400), // 30%
+		statsFrame(700, 1100, 4000, 5000, 500),
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, f := range frames {
+		if err := enc.Encode(f); err != nil {
+			t.Fatalf("encode frame: %v", err)
+		}
+	}
+	stream := buf.Bytes()
+
+	got, err := sampleStatsStream(context.Background(), bytes.NewReader(stream), 3)
+	if err != nil {
+		t.Fatalf("sample: %v", err)
+	}
+	if got.Samples != 3 || math.Abs(got.CPUPercent-20) > 1e-9 {
+		t.Fatalf("expected 3 samples averaging 20%%, got %d / %v", got.Samples, got.CPUPercent)
+	}
+	if got.MemUsageBytes != 400 {
+		t.Fatalf("expected memory from last sampled frame, got %d", got.MemUsageBytes)
+	}
+
+	// 流提前结束时返回部分结果
+	got, err = sampleStatsStream(context.Background(), bytes.NewReader(stream), 10)
+	if err != nil {
+		t.Fatalf("sample partial: %v", err)
+	}
+	if got.Samples != 4 || math.Abs(got.CPUPercent-25) > 1e-9 {
+		t.Fatalf("expected 4 samples averaging 25%%, got %d / %v", got.Samples, got.CPUPercent)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := sampleStatsStream(ctx, bytes.NewReader(stream), 3); err == nil {
+		t.Fatalf("expected error when cancelled before any frame")
+	}
+}
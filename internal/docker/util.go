@@ -1,13 +1,62 @@
 package docker
 
-import "strings"
+import (
+	"strings"
+	"sync/atomic"
 
-func truncateID(id string) string {
+	"github.com/containerd/containerd/errdefs"
+)
+
+// DefaultShortIDLength 为列表展示时截断 ID 的默认长度，与 docker CLI 一致
+const DefaultShortIDLength = 12
+
+var shortIDLength atomic.Int64
+
+func init() {
+	shortIDLength.Store(DefaultShortIDLength)
+}
+
+// SetShortIDLength 设置列表中 ID 的截断长度；n <= 0 时恢复默认值
+func SetShortIDLength(n int) {
+	if n <= 0 {
+		n = DefaultShortIDLength
+	}
+	shortIDLength.Store(int64(n))
+}
+
+// ShortIDLength 返回当前配置的 ID 截断长度
+func ShortIDLength() int {
+	return int(shortIDLength.Load())
+}
+
+// ShortID 按配置的长度截断 ID，与列表输出保持一致
+func ShortID(id string) string {
 	id = strings.TrimSpace(id)
-	if len(id) <= 12 {
+	n := ShortIDLength()
+	if len(id) <= n {
 		return id
 	}
-	return id[:12]
+	return id[:n]
+}
+
+// displayID 按 full 返回完整 ID 或截断后的 ID
+func displayID(id string, full bool) string {
+	if full {
+		return strings.TrimSpace(id)
+	}
+	return ShortID(id)
+}
+
+// IsNotFound 判断 Docker API 返回的错误是否为对象不存在（容器、镜像、网络等）
+func IsNotFound(err error) bool {
+	return errdefs.IsNotFound(err)
+}
+
+// IsUnsupported 判断错误是否表示接口不被支持或无权访问（未实现、不存在、无权限、未认证），
+// 这类错误由 daemon 或代理的能力/权限决定，重试无意义
+func IsUnsupported(err error) bool {
+	return errdefs.IsNotImplemented(err) || errdefs.IsNotFound(err) ||
+		errdefs.IsPermissionDenied(err) || errdefs.IsUnauthorized(err)
 }
 
 func truncateTail(s string, maxLen int) string {
@@ -0,0 +1,68 @@
+// Package logging 提供进程内共享的 slog 日志器，由 CLI 按 log_level 配置一次。
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+var current atomic.Pointer[slog.Logger]
+
+func init() {
+	current.Store(New(os.Stderr, slog.LevelInfo))
+}
+
+// 日志输出格式
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// New 创建输出到 w 的文本日志器，低于 level 的日志被丢弃。
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// NewWithFormat 按 format（text/json，空串视为 text）创建输出到 w 的日志器。
+func NewWithFormat(w io.Writer, level slog.Level, format string) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unsupported log format %q (want text or json)", format)
+	}
+}
+
+// ParseLevel 将配置中的 log_level（debug/info/warn/error）映射为 slog 级别，无法识别时为 info。
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// L 返回当前共享的日志器；默认输出到 stderr，级别为 info。
+func L() *slog.Logger {
+	return current.Load()
+}
+
+// SetDefault 替换共享的日志器并返回之前的日志器，便于临时替换后恢复；l 为 nil 时丢弃所有日志。
+func SetDefault(l *slog.Logger) *slog.Logger {
+	if l == nil {
+		l = slog.New(slog.DiscardHandler)
+	}
+	return current.Swap(l)
+}
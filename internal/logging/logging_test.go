@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	cases := []struct {
+		level string
+		want  []string
+		skip  []string
+	}{
+		{level: "debug", want: []string{"dbg", "inf", "wrn", "err"}},
+		{level: "info", want: []string{"inf", "wrn", "err"}, skip: []string{"dbg"}},
+		{level: "WARN", want: []string{"wrn", "err"}, skip: []string{"dbg", "inf"}},
+		{level: "error", want: []string{"err"}, skip: []string{"dbg", "inf", "wrn"}},
+		{level: "bogus", want: []string{"inf"}, skip: []string{"dbg"}},
+	}
+	for _, tc := range cases {
+		var buf bytes.Buffer
+		l := New(&buf, ParseLevel(tc.level))
+		l.Debug("dbg")
+		l.Info("inf")
+		l.Warn("wrn")
+		l.Error("err")
+
+		out := buf.String()
+		for _, msg := range tc.want {
+			if !strings.Contains(out, "msg="+msg) {
+				t.Fatalf("level %s: expected %q in output %q", tc.level, msg, out)
+			}
+		}
+		for _, msg := range tc.skip {
+			if strings.Contains(out, "msg="+msg) {
+				t.Fatalf("level %s: unexpected %q in output %q", tc.level, msg, out)
+			}
+		}
+	}
+}
+
+func TestNewWithFormat(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := NewWithFormat(&buf, slog.LevelInfo, "json")
+	if err != nil {
+		t.Fatalf("new json logger: %v", err)
+	}
+	l.Warn("monitor error", "container_id", "abc")
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", buf.String(), err)
+	}
+	if rec["level"] != "WARN" || rec["msg"] != "monitor error" || rec["container_id"] != "abc" {
+		t.Fatalf("unexpected record: %v", rec)
+	}
+
+	if _, err := NewWithFormat(&buf, slog.LevelInfo, "xml"); err == nil {
+		t.Fatalf("expected error for unsupported format")
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	var buf bytes.Buffer
+	prev := SetDefault(New(&buf, slog.LevelInfo))
+	defer SetDefault(prev)
+
+	L().Info("hello")
+	if !strings.Contains(buf.String(), "msg=hello") {
+		t.Fatalf("expected output from shared logger, got %q", buf.String())
+	}
+
+	buf.Reset()
+	SetDefault(nil)
+	L().Error("dropped")
+	if buf.Len() != 0 {
+		t.Fatalf("expected nil logger to discard output, got %q", buf.String())
+	}
+}
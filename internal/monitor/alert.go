@@ -0,0 +1,241 @@
+package monitor
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+// MetricOOM 为 OOM kill 告警的 Metric；OOM kill 后 stats 采样只是中断，不会触发资源告警，因此单独检测。
+const MetricOOM = "oom"
+
+// oomDieWindow 为 oom 事件与随后 die 事件的最大间隔，超过该间隔的 die 不再视为 OOM kill。
+const oomDieWindow = 30 * time.Second
+
+// AlertHandler 为告警回调；在容器持续超过阈值时触发一次。
+type AlertHandler func(alert Alert)
+
+// AlertConfig 为资源告警的配置。
+type AlertConfig struct {
+	// Enabled 控制是否根据采集到的 stats 触发告警。
+	Enabled bool `mapstructure:"enabled"`
+
+	// CPUHigh/MemHigh 为告警阈值（百分比）；CPUPercent>=CPUHigh 或 MemPercent>=MemHigh 视为超限，0 表示不检查该项。
+	CPUHigh float64 `mapstructure:"cpu_high"`
+	MemHigh float64 `mapstructure:"mem_high"`
+	// SustainedFor 为持续超限的时长；超限持续达到该时长才触发告警，0 表示首次超限即告警。
+	SustainedFor time.Duration `mapstructure:"sustained_for"`
+	// Cooldown 为同一容器同一指标两次告警的最小间隔；冷却期内再次持续超限不会重复告警，0 表示不限制。
+	Cooldown time.Duration `mapstructure:"cooldown"`
+	// ResolveAfter 为已告警的指标持续回落到阈值以下多久后视为恢复并触发 OnResolve，0 表示首次回落即恢复。
+	ResolveAfter time.Duration `mapstructure:"resolve_after"`
+	// OOM 控制是否对 OOM kill（oom 事件后以非零退出码 die）告警；依赖日志流水线订阅的容器事件，与 Cooldown 共用冷却。
+	OOM bool `mapstructure:"oom"`
+
+	// OnAlert 为告警回调；默认丢弃。
+	OnAlert AlertHandler `mapstructure:"-"`
+	// OnResolve 为恢复回调，仅对已通知过 OnAlert 的超限触发；默认丢弃。
+	OnResolve AlertHandler `mapstructure:"-"`
+}
+
+// Alert 为一次告警的内容。
+type Alert struct {
+	ContainerID   string
+	ContainerName string
+	// Metric 为超限的指标：cpu、mem 或 oom（MetricOOM）。
+	Metric    string
+	Value     float64
+	Threshold float64
+	// ExitCode 为 OOM kill 后容器的退出码，仅 Metric 为 oom 时有效。
+	ExitCode int
+	// Since 为本轮持续超限的起始时间，At 为触发告警（或恢复）的采样时间；
+	// OOM kill 时分别为 oom 事件与 die 事件的时间。
+	Since time.Time
+	At    time.Time
+}
+
+func (c AlertConfig) withDefaults() AlertConfig {
+	if c.SustainedFor < 0 {
+		c.SustainedFor = 0
+	}
+	if c.Cooldown < 0 {
+		c.Cooldown = 0
+	}
+	if c.ResolveAfter < 0 {
+		c.ResolveAfter = 0
+	}
+	if c.OnAlert == nil {
+		c.OnAlert = func(Alert) {}
+	}
+	if c.OnResolve == nil {
+		c.OnResolve = func(Alert) {}
+	}
+	return c
+}
+
+type alertKey struct {
+	containerID string
+	metric      string
+}
+
+// alertState 记录某容器某指标本轮超限的起始时间与是否已告警。
+// notified 为 false 表示本轮因冷却被抑制；below 为已告警后开始回落的时间，零值表示仍在超限。
+type alertState struct {
+	since    time.Time
+	fired    bool
+	notified bool
+	below    time.Time
+}
+
+// AlertCollector 根据 stats 采样判断容器是否持续超过阈值；每轮持续超限只告警一次，
+// 冷却期内不重复告警，持续回落后触发恢复并重新计时。
+type AlertCollector struct {
+	cfg AlertConfig
+
+	mu     sync.Mutex
+	states map[alertKey]*alertState
+	// lastFired 为每个 (容器, 指标) 最近一次通知告警的采样时间，用于冷却判断
+	lastFired map[alertKey]time.Time
+	// ooms 为每个容器尚未匹配到 die 的 oom 事件时间
+	ooms map[string]time.Time
+}
+
+func NewAlertCollector() *AlertCollector {
+	return &AlertCollector{
+		states:    make(map[alertKey]*alertState),
+		lastFired: make(map[alertKey]time.Time),
+		ooms:      make(map[string]time.Time),
+	}
+}
+
+// Observe 处理一条 stats 采样，满足条件时调用 OnAlert。
+func (a *AlertCollector) Observe(stat storage.ContainerStat) {
+	if a == nil || !a.cfg.Enabled {
+		return
+	}
+	a.observe(stat, "cpu", stat.CPUPercent, a.cfg.CPUHigh)
+	a.observe(stat, "mem", stat.MemPercent, a.cfg.MemHigh)
+}
+
+func (a *AlertCollector) observe(stat storage.ContainerStat, metric string, value, threshold float64) {
+	if threshold <= 0 {
+		return
+	}
+	key := alertKey{containerID: stat.ContainerID, metric: metric}
+
+	a.mu.Lock()
+	st, ok := a.states[key]
+	if value < threshold {
+		if !ok {
+			a.mu.Unlock()
+			return
+		}
+		if st.fired {
+			// 已告警：持续回落达到 ResolveAfter 才视为恢复
+			if st.below.IsZero() {
+				st.below = stat.CollectedAt
+			}
+			if stat.CollectedAt.Sub(st.below) < a.cfg.ResolveAfter {
+				a.mu.Unlock()
+				return
+			}
+		}
+		delete(a.states, key)
+		a.mu.Unlock()
+
+		if st.notified {
+			a.cfg.OnResolve(Alert{
+				ContainerID:   stat.ContainerID,
+				ContainerName: stat.ContainerName,
+				Metric:        metric,
+				Value:         value,
+				Threshold:     threshold,
+				Since:         st.since,
+				At:            stat.CollectedAt,
+			})
+		}
+		return
+	}
+	if !ok {
+		st = &alertState{since: stat.CollectedAt}
+		a.states[key] = st
+	}
+	st.below = time.Time{}
+	if st.fired || stat.CollectedAt.Sub(st.since) < a.cfg.SustainedFor {
+		a.mu.Unlock()
+		return
+	}
+	st.fired = true
+	if last, ok := a.lastFired[key]; ok && a.cfg.Cooldown > 0 && stat.CollectedAt.Sub(last) < a.cfg.Cooldown {
+		a.mu.Unlock()
+		return
+	}
+	st.notified = true
+	a.lastFired[key] = stat.CollectedAt
+	since := st.since
+	a.mu.Unlock()
+
+	a.cfg.OnAlert(Alert{
+		ContainerID:   stat.ContainerID,
+		ContainerName: stat.ContainerName,
+		Metric:        metric,
+		Value:         value,
+		Threshold:     threshold,
+		Since:         since,
+		At:            stat.CollectedAt,
+	})
+}
+
+// ObserveEvent 处理一条容器事件：记录 oom 事件，同一容器随后以非零退出码 die 时视为 OOM kill 并调用 OnAlert。
+// 容器内子进程被 OOM kill 而容器仍存活时不告警（事件仍由日志流水线落库）。
+func (a *AlertCollector) ObserveEvent(msg events.Message) {
+	if a == nil || !a.cfg.Enabled || !a.cfg.OOM || msg.Type != events.ContainerEventType || msg.Actor.ID == "" {
+		return
+	}
+	id := msg.Actor.ID
+	at := storage.EventTime(msg)
+
+	a.mu.Lock()
+	switch msg.Action {
+	case "oom":
+		a.ooms[id] = at
+		a.mu.Unlock()
+		return
+	case "destroy":
+		delete(a.ooms, id)
+		a.mu.Unlock()
+		return
+	case "die":
+	default:
+		a.mu.Unlock()
+		return
+	}
+
+	since, ok := a.ooms[id]
+	delete(a.ooms, id)
+	exitCode, _ := strconv.Atoi(msg.Actor.Attributes["exitCode"])
+	if !ok || exitCode == 0 || at.Sub(since) > oomDieWindow {
+		a.mu.Unlock()
+		return
+	}
+	key := alertKey{containerID: id, metric: MetricOOM}
+	if last, ok := a.lastFired[key]; ok && a.cfg.Cooldown > 0 && at.Sub(last) < a.cfg.Cooldown {
+		a.mu.Unlock()
+		return
+	}
+	a.lastFired[key] = at
+	a.mu.Unlock()
+
+	a.cfg.OnAlert(Alert{
+		ContainerID:   id,
+		ContainerName: msg.Actor.Attributes["name"],
+		Metric:        MetricOOM,
+		ExitCode:      exitCode,
+		Since:         since,
+		At:            at,
+	})
+}
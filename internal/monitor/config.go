@@ -1,7 +1,10 @@
 package monitor
 
 import (
+	"fmt"
 	"runtime"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -21,14 +24,71 @@ type StatsConfig struct {
 	BatchSize int `mapstructure:"batch_size"`
 	// FlushInterval 为写入端的最大等待时间；即使未达到 BatchSize，也会按该间隔定时落库。
 	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	// FetchTimeout 为单个容器单次采样（以及每轮列出容器）的超时；超时的容器本轮被跳过并回调 OnError。
+	FetchTimeout time.Duration `mapstructure:"fetch_timeout"`
+	// Transactional 为 true 时每次落库使用一个显式事务写入整批数据（全部成功或全部回滚），突发写入时吞吐更高。
+	Transactional bool `mapstructure:"transactional"`
 
 	// MaxRawJSONBytes 限制落库时 RawJSON 的最大长度（字节）；超过则写入 {"_truncated":true}。
 	MaxRawJSONBytes int `mapstructure:"max_raw_json_bytes"`
+	// StoreRawJSON 为 false 时完全不保存 RawJSON（不序列化、不落库），与 MaxRawJSONBytes 的截断不同；
+	// RawJSON 通常占单条采样的大部分体积，容器较多时关闭可显著减小数据库。
+	StoreRawJSON bool `mapstructure:"store_raw_json"`
+	// CaptureLabels 为 true 时将容器标签以 JSON 写入每条采样，便于按标签分组统计；默认关闭以减小行体积。
+	CaptureLabels bool `mapstructure:"capture_labels"`
+	// Mode 为采样方式：oneshot（默认）每个周期对每个容器发起一次独立请求；
+	// stream 为每个容器保持一条常驻的流式连接，每个周期读取最新一帧，容器较多时显著减少 HTTP 往返。
+	Mode string `mapstructure:"mode"`
+	// Collect 为需要采集的指标（cpu/mem/net/block/pids/raw_json），为空表示全部采集；
+	// 未列出的指标不做计算，落库为 0（raw_json 为空串）。告警与异常保留依赖 cpu/mem。
+	Collect []string `mapstructure:"collect"`
 
-	// OnError 为异步错误回调（例如采样失败、落库失败、列容器失败）；默认丢弃。
+	// OnError 为异步错误回调（例如采样失败、落库失败、列容器失败）；默认以 warn 级别写入共享日志。
 	OnError ErrorHandler `mapstructure:"-"`
 }
 
+// StatsConfig.Mode 可选的采样方式
+const (
+	StatsModeOneShot = "oneshot"
+	StatsModeStream  = "stream"
+)
+
+// StatsConfig.Collect 可选的指标名
+const (
+	StatsFieldCPU     = "cpu"
+	StatsFieldMem     = "mem"
+	StatsFieldNet     = "net"
+	StatsFieldBlock   = "block"
+	StatsFieldPids    = "pids"
+	StatsFieldRawJSON = "raw_json"
+)
+
+// StatsFields 为全部可采集的指标名
+var StatsFields = []string{StatsFieldCPU, StatsFieldMem, StatsFieldNet, StatsFieldBlock, StatsFieldPids, StatsFieldRawJSON}
+
+// ValidateStatsCollect 校验 Collect 中的指标名
+func ValidateStatsCollect(fields []string) error {
+	for _, f := range fields {
+		if !slices.Contains(StatsFields, strings.ToLower(strings.TrimSpace(f))) {
+			return fmt.Errorf("unknown stats field %q (valid: %s)", f, strings.Join(StatsFields, ", "))
+		}
+	}
+	return nil
+}
+
+// collects 返回是否采集指定指标；Collect 为空时采集全部
+func (c StatsConfig) collects(field string) bool {
+	if len(c.Collect) == 0 {
+		return true
+	}
+	for _, f := range c.Collect {
+		if strings.EqualFold(strings.TrimSpace(f), field) {
+			return true
+		}
+	}
+	return false
+}
+
 type LogConfig struct {
 	// Enabled 控制日志收集流水线是否启用（Events + Follow + 落库）。
 	Enabled bool `mapstructure:"enabled"`
@@ -39,6 +99,8 @@ type LogConfig struct {
 	BatchSize int `mapstructure:"batch_size"`
 	// FlushInterval 为写入端的最大等待时间；即使未达到 BatchSize，也会按该间隔定时落库。
 	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	// Transactional 为 true 时每次落库使用一个显式事务写入整批日志（全部成功或全部回滚）。
+	Transactional bool `mapstructure:"transactional"`
 
 	// MaxLineBytes 为单行日志的最大长度（字节）；超过会导致扫描报错并结束该容器 tailer。
 	MaxLineBytes int `mapstructure:"max_line_bytes"`
@@ -50,8 +112,25 @@ type LogConfig struct {
 	ReconnectDelay time.Duration `mapstructure:"reconnect_delay"`
 	// ReconnectJitter 为重连抖动区间（±jitter），用于降低重连风暴风险。
 	ReconnectJitter time.Duration `mapstructure:"reconnect_jitter"`
+	// ReconcileInterval 为周期性重新列出运行中容器、补启缺失 tailer 的间隔，用于事件丢失（daemon 重启、事件流中断）后自愈；
+	// 为 0 时使用默认值 1m，负数关闭。
+	ReconcileInterval time.Duration `mapstructure:"reconcile_interval"`
+
+	// OnError 为异步错误回调（例如 events 断开、tailer 启动失败、队列满等）；默认以 warn 级别写入共享日志。
+	OnError ErrorHandler `mapstructure:"-"`
+}
+
+// HostStatsConfig 为宿主机整体采样（CPU/内存与 docker 容器数量）的配置。
+type HostStatsConfig struct {
+	// Enabled 控制宿主机采样是否启用；默认关闭。
+	Enabled bool `mapstructure:"enabled"`
+
+	// Interval 为采样周期；CPU 使用率按相邻两次采样之间的增量计算。
+	Interval time.Duration `mapstructure:"interval"`
+	// FetchTimeout 为单次采样（docker info）的超时；超时本轮跳过并回调 OnError。
+	FetchTimeout time.Duration `mapstructure:"fetch_timeout"`
 
-	// OnError 为异步错误回调（例如 events 断开、tailer 启动失败、队列满等）；默认丢弃。
+	// OnError 为异步错误回调（例如 docker info 失败、/proc 不可读）；默认以 warn 级别写入共享日志。
 	OnError ErrorHandler `mapstructure:"-"`
 }
 
@@ -65,6 +144,8 @@ type StatsRetentionPolicy struct {
 	// CPUHigh/MemHigh 为异常阈值（百分比）；满足 CPUPercent>=CPUHigh 或 MemPercent>=MemHigh 视为异常。
 	CPUHigh float64 `mapstructure:"cpu_high"`
 	MemHigh float64 `mapstructure:"mem_high"`
+	// MaxTotalRows 为 stats 表的总行数上限；按策略清理后仍超出时不论策略删除最旧的行，0 表示不限制。
+	MaxTotalRows int64 `mapstructure:"max_total_rows"`
 }
 
 // LogsRetentionPolicy 定义 logs（容器日志）数据的分层保留策略。
@@ -78,6 +159,14 @@ type LogsRetentionPolicy struct {
 	KeepLevels []string `mapstructure:"keep_levels"`
 	// KeepSources 为重要来源白名单（例如 stderr）；为空表示不按来源做保留。
 	KeepSources []string `mapstructure:"keep_sources"`
+	// MaxTotalRows 为日志表的总行数上限；按策略清理后仍超出时不论策略删除最旧的行，防止日志量暴增撑满磁盘，0 表示不限制。
+	MaxTotalRows int64 `mapstructure:"max_total_rows"`
+}
+
+// SessionsRetentionPolicy 定义对话会话（chat --resume 使用的持久化会话）的保留策略。
+type SessionsRetentionPolicy struct {
+	// KeepFor 为会话保留窗口；最近一次保存早于该窗口的会话会被清除。
+	KeepFor time.Duration `mapstructure:"keep_for"`
 }
 
 // RetentionConfig 为自动清理（分层删除）流水线的配置。
@@ -93,12 +182,17 @@ type RetentionConfig struct {
 	BatchRows int `mapstructure:"batch_rows"`
 	// IdleSleep 为每批删除后的短暂等待；用于降低持续写锁对采集写入的影响。
 	IdleSleep time.Duration `mapstructure:"idle_sleep"`
+	// CheckpointAfterRows 为一轮清理删除的行数达到该值时执行 WAL TRUNCATE 检查点，避免 -wal 文件持续膨胀；
+	// 0 使用默认值，负数表示不执行。
+	CheckpointAfterRows int `mapstructure:"checkpoint_after_rows"`
 
 	// Stats/Logs 分别定义状态采样与日志的分层保留策略。
 	Stats StatsRetentionPolicy `mapstructure:"stats"`
 	Logs  LogsRetentionPolicy  `mapstructure:"logs"`
+	// Sessions 定义对话会话的保留策略。
+	Sessions SessionsRetentionPolicy `mapstructure:"sessions"`
 
-	// OnError 为异步错误回调（例如删除失败、配置非法等）；默认丢弃。
+	// OnError 为异步错误回调（例如删除失败、配置非法等）；默认以 warn 级别写入共享日志。
 	OnError ErrorHandler `mapstructure:"-"`
 }
 
@@ -106,6 +200,8 @@ type Config struct {
 	Stats     StatsConfig     `mapstructure:"stats"`
 	Logs      LogConfig       `mapstructure:"logs"`
 	Retention RetentionConfig `mapstructure:"retention"`
+	Alert     AlertConfig     `mapstructure:"alert"`
+	Host      HostStatsConfig `mapstructure:"host"`
 }
 
 func DefaultConfig() Config {
@@ -117,18 +213,22 @@ func DefaultConfig() Config {
 			QueueSize:       256,
 			BatchSize:       100,
 			FlushInterval:   2 * time.Second,
+			FetchTimeout:    5 * time.Second,
 			MaxRawJSONBytes: 1024,
+			StoreRawJSON:    true,
+			Mode:            StatsModeOneShot,
 		},
 		Logs: LogConfig{
-			Enabled:         false,
-			QueueSize:       1024,
-			BatchSize:       200,
-			FlushInterval:   2 * time.Second,
-			MaxLineBytes:    64 * 1024,
-			TailerLimit:     128,
-			SinceFromStart:  true,
-			ReconnectDelay:  2 * time.Second,
-			ReconnectJitter: 500 * time.Millisecond,
+			Enabled:           false,
+			QueueSize:         1024,
+			BatchSize:         200,
+			FlushInterval:     2 * time.Second,
+			MaxLineBytes:      64 * 1024,
+			TailerLimit:       128,
+			SinceFromStart:    true,
+			ReconnectDelay:    2 * time.Second,
+			ReconnectJitter:   500 * time.Millisecond,
+			ReconcileInterval: time.Minute,
 		},
 		Retention: RetentionConfig{
 			Enabled:   true,
@@ -136,6 +236,8 @@ func DefaultConfig() Config {
 			Workers:   2,
 			BatchRows: 500,
 			IdleSleep: 25 * time.Millisecond,
+
+			CheckpointAfterRows: 10000,
 			Stats: StatsRetentionPolicy{
 				KeepAll:          12 * time.Hour,
 				KeepAnomalyUntil: 5 * 24 * time.Hour,
@@ -148,6 +250,23 @@ func DefaultConfig() Config {
 				KeepLevels:         []string{"ERROR", "WARN"},
 				KeepSources:        []string{"stderr"},
 			},
+			Sessions: SessionsRetentionPolicy{
+				KeepFor: 30 * 24 * time.Hour,
+			},
+		},
+		Alert: AlertConfig{
+			Enabled:      false,
+			CPUHigh:      90,
+			MemHigh:      90,
+			SustainedFor: 5 * time.Minute,
+			Cooldown:     30 * time.Minute,
+			ResolveAfter: 2 * time.Minute,
+			OOM:          true,
+		},
+		Host: HostStatsConfig{
+			Enabled:      false,
+			Interval:     30 * time.Second,
+			FetchTimeout: 5 * time.Second,
 		},
 	}
 }
@@ -168,11 +287,31 @@ func (c StatsConfig) withDefaults() StatsConfig {
 	if c.FlushInterval <= 0 {
 		c.FlushInterval = 2 * time.Second
 	}
+	if c.FetchTimeout <= 0 {
+		c.FetchTimeout = 5 * time.Second
+	}
 	if c.MaxRawJSONBytes <= 0 {
 		c.MaxRawJSONBytes = 128 * 1024
 	}
+	c.Mode = strings.ToLower(strings.TrimSpace(c.Mode))
+	if c.Mode == "" {
+		c.Mode = StatsModeOneShot
+	}
 	if c.OnError == nil {
-		c.OnError = func(error) {}
+		c.OnError = logErrorHandler("stats")
+	}
+	return c
+}
+
+func (c HostStatsConfig) withDefaults() HostStatsConfig {
+	if c.Interval <= 0 {
+		c.Interval = 30 * time.Second
+	}
+	if c.FetchTimeout <= 0 {
+		c.FetchTimeout = 5 * time.Second
+	}
+	if c.OnError == nil {
+		c.OnError = logErrorHandler("host")
 	}
 	return c
 }
@@ -199,8 +338,11 @@ func (c LogConfig) withDefaults() LogConfig {
 	if c.ReconnectJitter < 0 {
 		c.ReconnectJitter = 0
 	}
+	if c.ReconcileInterval == 0 {
+		c.ReconcileInterval = time.Minute
+	}
 	if c.OnError == nil {
-		c.OnError = func(error) {}
+		c.OnError = logErrorHandler("logs")
 	}
 	return c
 }
@@ -221,6 +363,9 @@ func (c RetentionConfig) withDefaults() RetentionConfig {
 	if c.IdleSleep < 0 {
 		c.IdleSleep = 0
 	}
+	if c.CheckpointAfterRows == 0 {
+		c.CheckpointAfterRows = 10000
+	}
 	if c.Stats.KeepAll <= 0 {
 		c.Stats.KeepAll = 12 * time.Hour
 	}
@@ -236,6 +381,9 @@ func (c RetentionConfig) withDefaults() RetentionConfig {
 	if c.Stats.MemHigh < 0 {
 		c.Stats.MemHigh = 0
 	}
+	if c.Stats.MaxTotalRows < 0 {
+		c.Stats.MaxTotalRows = 0
+	}
 
 	if c.Logs.KeepAll <= 0 {
 		c.Logs.KeepAll = 12 * time.Hour
@@ -246,8 +394,14 @@ func (c RetentionConfig) withDefaults() RetentionConfig {
 	if c.Logs.KeepImportantUntil < c.Logs.KeepAll {
 		c.Logs.KeepImportantUntil = c.Logs.KeepAll
 	}
+	if c.Logs.MaxTotalRows < 0 {
+		c.Logs.MaxTotalRows = 0
+	}
+	if c.Sessions.KeepFor <= 0 {
+		c.Sessions.KeepFor = 30 * 24 * time.Hour
+	}
 	if c.OnError == nil {
-		c.OnError = func(error) {}
+		c.OnError = logErrorHandler("retention")
 	}
 	return c
 }
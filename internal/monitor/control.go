@@ -0,0 +1,181 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// pauser 为采集器共享的暂停开关：stats/host 暂停时跳过采样周期，日志 tailer 暂停时阻塞在入队前，
+// 由 docker 的 follow 连接暂存未读取的日志，恢复后继续读取，不丢日志。
+type pauser struct {
+	mu     sync.Mutex
+	paused bool
+	// resumed 在未暂停时处于关闭状态，暂停时替换为新的未关闭 channel
+	resumed chan struct{}
+}
+
+func newPauser() *pauser {
+	ch := make(chan struct{})
+	close(ch)
+	return &pauser{resumed: ch}
+}
+
+// pause 进入暂停状态，已暂停时返回 false
+func (p *pauser) pause() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.paused {
+		return false
+	}
+	p.paused = true
+	p.resumed = make(chan struct{})
+	return true
+}
+
+// resume 解除暂停，未暂停时返回 false
+func (p *pauser) resume() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.paused {
+		return false
+	}
+	p.paused = false
+	close(p.resumed)
+	return true
+}
+
+func (p *pauser) isPaused() bool {
+	if p == nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
+
+// wait 阻塞直到未暂停或 ctx 结束
+func (p *pauser) wait(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	ch := p.resumed
+	p.mu.Unlock()
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CollectorStatus 为单个采集器的运行状态
+type CollectorStatus struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Running bool   `json:"running"`
+	// Error 为采集器异常退出时的错误
+	Error string `json:"error,omitempty"`
+}
+
+// QueueStatus 为内部队列的当前长度与容量
+type QueueStatus struct {
+	Len int `json:"len"`
+	Cap int `json:"cap"`
+}
+
+// Status 为 Manager 运行期状态快照，供控制接口查询
+type Status struct {
+	Paused     bool              `json:"paused"`
+	Collectors []CollectorStatus `json:"collectors"`
+	// LogQueue 为等待落库的日志队列，LogTailers 为正在跟随的容器数
+	LogQueue   QueueStatus `json:"log_queue"`
+	LogTailers int         `json:"log_tailers"`
+	// StatsStreams 为 stream 模式下打开的 stats 连接数
+	StatsStreams int `json:"stats_streams"`
+	// LastPrune 为最近一次保留策略清理完成的时间
+	LastPrune *time.Time `json:"last_prune,omitempty"`
+}
+
+// collectorState 记录采集器协程的运行情况
+type collectorState struct {
+	running bool
+	err     error
+}
+
+func (m *Manager) setState(name string, running bool, err error) {
+	m.statesMu.Lock()
+	defer m.statesMu.Unlock()
+	if m.states == nil {
+		m.states = make(map[string]collectorState)
+	}
+	m.states[name] = collectorState{running: running, err: err}
+}
+
+// Status 返回各采集器状态、队列深度与暂停状态
+func (m *Manager) Status() Status {
+	if m == nil {
+		return Status{}
+	}
+	st := Status{Paused: m.pause.isPaused()}
+
+	m.statesMu.Lock()
+	for _, c := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"stats", m.cfg.Stats.Enabled},
+		{"logs", m.cfg.Logs.Enabled},
+		{"retention", m.cfg.Retention.Enabled},
+		{"host", m.cfg.Host.Enabled},
+	} {
+		cs := CollectorStatus{Name: c.name, Enabled: c.enabled}
+		if s, ok := m.states[c.name]; ok {
+			cs.Running = s.running
+			if s.err != nil {
+				cs.Error = s.err.Error()
+			}
+		}
+		st.Collectors = append(st.Collectors, cs)
+	}
+	m.statesMu.Unlock()
+
+	if m.logs != nil {
+		st.LogQueue, st.LogTailers = m.logs.queueStatus()
+	}
+	if m.stats != nil {
+		st.StatsStreams = m.stats.streamCount()
+	}
+	if m.ret != nil {
+		if at := m.ret.lastRunAt(); !at.IsZero() {
+			st.LastPrune = &at
+		}
+	}
+	return st
+}
+
+// Pause 暂停 stats/host 采样与日志入库，已暂停时返回 false
+func (m *Manager) Pause() bool {
+	if m == nil {
+		return false
+	}
+	return m.pause.pause()
+}
+
+// Resume 恢复采集，未暂停时返回 false
+func (m *Manager) Resume() bool {
+	if m == nil {
+		return false
+	}
+	return m.pause.resume()
+}
+
+// Prune 立即执行一次保留策略清理，不等待下一个周期
+func (m *Manager) Prune(ctx context.Context) error {
+	if m == nil || m.ret == nil {
+		return errors.New("retention collector is not configured")
+	}
+	return m.ret.RunNow(ctx)
+}
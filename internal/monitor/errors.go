@@ -0,0 +1,35 @@
+package monitor
+
+import (
+	"errors"
+
+	"github.com/wwwzy/CentAgent/internal/logging"
+)
+
+// ContainerError 为与某个容器相关的采集错误，便于 OnError 按容器记录或过滤。
+type ContainerError struct {
+	ContainerID string
+	// Op 为出错的操作，如 "fetch stats"、"tail logs"。
+	Op  string
+	Err error
+}
+
+func (e *ContainerError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ContainerError) Unwrap() error {
+	return e.Err
+}
+
+// logErrorHandler 返回默认的 OnError：通过共享日志器以 warn 级别记录，附带采集器与容器字段。
+func logErrorHandler(component string) ErrorHandler {
+	return func(err error) {
+		attrs := []any{"component", component, "err", err}
+		var ce *ContainerError
+		if errors.As(err, &ce) {
+			attrs = append(attrs, "container_id", ce.ContainerID, "op", ce.Op)
+		}
+		logging.L().Warn("monitor error", attrs...)
+	}
+}
@@ -0,0 +1,235 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wwwzy/CentAgent/internal/docker"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+type sampleHostFunc func(ctx context.Context) (storage.HostStat, error)
+
+// HostStatsCollector 周期性记录宿主机整体 CPU/内存与 docker 容器数量，
+// 用于回答“宿主机本身是否有压力”，这是单容器 stats 无法体现的。
+type HostStatsCollector struct {
+	cfg HostStatsConfig
+
+	store *storage.Storage
+
+	sample sampleHostFunc
+
+	// paused 为 Manager 注入的暂停开关，暂停期间跳过采样周期
+	paused *pauser
+
+	// prevCPU 为上一次读取的 /proc/stat 计数，用于计算两次采样之间的 CPU 使用率
+	prevCPU  cpuTimes
+	procOnce sync.Once
+}
+
+func NewHostStatsCollector(store *storage.Storage) (*HostStatsCollector, error) {
+	if store == nil {
+		return nil, errors.New("storage is required")
+	}
+	return &HostStatsCollector{store: store}, nil
+}
+
+func (c *HostStatsCollector) WithSampler(fn sampleHostFunc) *HostStatsCollector {
+	c.sample = fn
+	return c
+}
+
+func (c *HostStatsCollector) Run(ctx context.Context) error {
+	if c == nil || c.store == nil {
+		return errors.New("host stats collector not initialized")
+	}
+	c.cfg = c.cfg.withDefaults()
+
+	sampleFn := c.sample
+	if sampleFn == nil {
+		// 先记录一次 CPU 计数作为基准，第一个周期即可得到有效的使用率
+		c.prevCPU, _ = readCPUTimes()
+		sampleFn = c.defaultSample
+	}
+
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if c.paused.isPaused() {
+				continue
+			}
+			if err := c.runOnce(ctx, sampleFn); err != nil && !errors.Is(err, context.Canceled) {
+				return err
+			}
+		}
+	}
+}
+
+// runOnce 采样一次并落库；采样失败只回调 OnError 并跳过本轮，落库失败则返回错误。
+func (c *HostStatsCollector) runOnce(ctx context.Context, sampleFn sampleHostFunc) error {
+	sampleCtx, cancel := context.WithTimeout(ctx, c.cfg.FetchTimeout)
+	stat, err := sampleFn(sampleCtx)
+	cancel()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.cfg.OnError(fmt.Errorf("sample host stats: %w", err))
+		return nil
+	}
+	if stat.CollectedAt.IsZero() {
+		stat.CollectedAt = time.Now().UTC()
+	}
+	return c.store.InsertHostStat(ctx, &stat)
+}
+
+func (c *HostStatsCollector) defaultSample(ctx context.Context) (storage.HostStat, error) {
+	info, err := docker.GetHostInfo(ctx)
+	if err != nil {
+		return storage.HostStat{}, err
+	}
+	stat := storage.HostStat{
+		NCPU:              info.NCPU,
+		MemTotalBytes:     info.MemTotalBytes,
+		ContainersRunning: info.ContainersRunning,
+		ContainersPaused:  info.ContainersPaused,
+		ContainersStopped: info.ContainersStopped,
+		Images:            info.Images,
+		CollectedAt:       time.Now().UTC(),
+	}
+
+	// /proc 仅在 Linux 上可用；读取失败时只记录 docker 层面的数据，错误只上报一次
+	cur, cpuErr := readCPUTimes()
+	if cpuErr == nil {
+		stat.CPUPercent = cpuPercent(c.prevCPU, cur)
+		c.prevCPU = cur
+	}
+	total, used, memErr := readMemInfo()
+	if memErr == nil {
+		stat.MemTotalBytes, stat.MemUsedBytes = total, used
+		if total > 0 {
+			stat.MemPercent = float64(used) / float64(total) * 100.0
+		}
+	}
+	if err := errors.Join(cpuErr, memErr); err != nil {
+		c.procOnce.Do(func() {
+			c.cfg.OnError(fmt.Errorf("read host cpu/memory from /proc, recording docker totals only: %w", err))
+		})
+	}
+	return stat, nil
+}
+
+// cpuTimes 为 /proc/stat 中汇总 cpu 行的累计计数（单位 jiffies）
+type cpuTimes struct {
+	Total uint64
+	Idle  uint64
+}
+
+// readCPUTimes 读取 /proc/stat 的 cpu 行；idle 包含 iowait，total 不含已计入 user 的 guest 时间
+func readCPUTimes() (cpuTimes, error) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return cpuTimes{}, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || fields[0] != "cpu" {
+			continue
+		}
+		return parseCPUTimes(fields[1:])
+	}
+	if err := sc.Err(); err != nil {
+		return cpuTimes{}, err
+	}
+	return cpuTimes{}, errors.New("cpu line not found in /proc/stat")
+}
+
+func parseCPUTimes(fields []string) (cpuTimes, error) {
+	if len(fields) < 4 {
+		return cpuTimes{}, fmt.Errorf("unexpected cpu line: %v", fields)
+	}
+	var out cpuTimes
+	// user nice system idle iowait irq softirq steal
+	for i := 0; i < len(fields) && i < 8; i++ {
+		v, err := strconv.ParseUint(fields[i], 10, 64)
+		if err != nil {
+			return cpuTimes{}, fmt.Errorf("parse cpu field %q: %w", fields[i], err)
+		}
+		out.Total += v
+		if i == 3 || i == 4 {
+			out.Idle += v
+		}
+	}
+	return out, nil
+}
+
+// cpuPercent 计算两次读数之间的 CPU 使用率；没有有效基准时返回 0
+func cpuPercent(prev, cur cpuTimes) float64 {
+	if prev.Total == 0 || cur.Total <= prev.Total || cur.Idle < prev.Idle {
+		return 0
+	}
+	total := float64(cur.Total - prev.Total)
+	idle := float64(cur.Idle - prev.Idle)
+	if idle > total {
+		return 0
+	}
+	return (total - idle) / total * 100.0
+}
+
+// readMemInfo 读取 /proc/meminfo，已用量按 MemTotal - MemAvailable 计算（与 free 的 used 口径一致）
+func readMemInfo() (total uint64, used uint64, err error) {
+	data, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return 0, 0, err
+	}
+	return parseMemInfo(string(data))
+}
+
+func parseMemInfo(data string) (total uint64, used uint64, err error) {
+	var available uint64
+	var haveTotal, haveAvailable bool
+	for _, line := range strings.Split(data, "\n") {
+		key, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		switch key {
+		case "MemTotal", "MemAvailable":
+			kb, err := strconv.ParseUint(fields[0], 10, 64)
+			if err != nil {
+				return 0, 0, fmt.Errorf("parse %s: %w", key, err)
+			}
+			if key == "MemTotal" {
+				total, haveTotal = kb*1024, true
+			} else {
+				available, haveAvailable = kb*1024, true
+			}
+		}
+	}
+	if !haveTotal || !haveAvailable {
+		return 0, 0, errors.New("MemTotal/MemAvailable not found in /proc/meminfo")
+	}
+	if available > total {
+		available = total
+	}
+	return total, total - available, nil
+}
@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+// logDedupWindow 为去重记录的保留时长；日志驱动轮转/截断后 follow 流重放的旧行通常在数秒内到达
+const logDedupWindow = 30 * time.Second
+
+// logDedupMax 为去重表的条目上限，超过时提前清理，避免日志量很大时占用过多内存
+const logDedupMax = 100000
+
+type logDedupKey struct {
+	containerID string
+	ts          int64
+	msgHash     uint64
+}
+
+// logDedup 按 (container_id, timestamp, message) 过滤短时间窗口内重复的日志行。
+// 过期按接收时间而不是日志时间计算：轮转后重放的行保留原始时间戳，可能早于窗口。
+type logDedup struct {
+	window time.Duration
+	seen   map[logDedupKey]time.Time
+}
+
+func newLogDedup(window time.Duration) *logDedup {
+	return &logDedup{window: window, seen: make(map[logDedupKey]time.Time)}
+}
+
+// duplicate 返回该行是否已在窗口内出现过；未出现过时记录下来
+func (d *logDedup) duplicate(rec storage.ContainerLog, now time.Time) bool {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(rec.Message))
+	key := logDedupKey{containerID: rec.ContainerID, ts: rec.Timestamp.UnixNano(), msgHash: h.Sum64()}
+	if at, ok := d.seen[key]; ok && now.Sub(at) < d.window {
+		return true
+	}
+	if len(d.seen) >= logDedupMax {
+		d.prune(now)
+	}
+	d.seen[key] = now
+	return false
+}
+
+// prune 清理过期条目；仍超过上限时清空，宁可漏掉少量重复也不无限增长
+func (d *logDedup) prune(now time.Time) {
+	for k, at := range d.seen {
+		if now.Sub(at) >= d.window {
+			delete(d.seen, k)
+		}
+	}
+	if len(d.seen) >= logDedupMax {
+		clear(d.seen)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"math/rand/v2"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
@@ -30,9 +31,56 @@ type LogCollector struct {
 	// logCh 为“解析完成 -> 等待批量落库”的内部队列。
 	logCh chan storage.ContainerLog
 
-	// tailers 保存当前正在 Follow 的容器 tailer 取消函数；key 为 containerID。
+	// cfgMu 保护 cfg 中可热更新的 tailer 配置（MaxLineBytes/SinceFromStart/TailerLimit）。
+	cfgMu sync.RWMutex
+
+	// runCtx/startedAt 为 Run 的上下文与启动时间，用于 Reload 时重启 tailer。
+	runCtx    context.Context
+	startedAt time.Time
+
+	// tailers 保存当前正在 Follow 的容器 tailer；key 为 containerID。
 	tailersMu sync.Mutex
-	tailers   map[string]context.CancelFunc
+	tailers   map[string]*logTailer
+
+	// paused 为 Manager 注入的暂停开关，暂停期间 tailer 阻塞在入队前
+	paused *pauser
+
+	// alert 为 Manager 注入的告警采集器，用于根据容器事件检测 OOM kill
+	alert *AlertCollector
+
+	// follow/inspect/watchEvents/listRunning 可在测试中替换，默认调用 docker
+	follow      followLogsFunc
+	inspect     inspectContainerFunc
+	watchEvents watchEventsFunc
+	listRunning listContainersFunc
+
+	// pollInterval 为事件接口不可用时轮询运行中容器的间隔
+	pollInterval time.Duration
+
+	// onStop 为通过 watchStops 注册的容器退出回调，收到 die/destroy 事件时调用
+	onStop atomic.Pointer[func(containerID string)]
+}
+
+type watchEventsFunc func(ctx context.Context) (<-chan events.Message, <-chan error)
+
+// errEventsUnsupported 表示 daemon/代理不支持或不允许订阅事件，此时改为轮询
+var errEventsUnsupported = errors.New("docker events API unavailable")
+
+// defaultEventsPollInterval 为事件接口不可用时的默认轮询间隔
+const defaultEventsPollInterval = 10 * time.Second
+
+type followLogsFunc func(ctx context.Context, containerID string, since time.Time) (io.ReadCloser, error)
+type inspectContainerFunc func(ctx context.Context, containerID string) (containerInspectInfo, error)
+
+// logTailer 为单个容器的日志跟随任务。
+type logTailer struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	name   string
+	// maxLineBytes 为该 tailer 启动时使用的单行上限，配置变化时需重启才能生效
+	maxLineBytes int
+	// last 为已读取的最后一行日志时间（UnixNano），重启时从此处续读，避免丢失或重复
+	last atomic.Int64
 }
 
 func NewLogCollector(store *storage.Storage) (*LogCollector, error) {
@@ -42,15 +90,61 @@ func NewLogCollector(store *storage.Storage) (*LogCollector, error) {
 	return &LogCollector{store: store}, nil
 }
 
+func (c *LogCollector) WithFollower(fn followLogsFunc) *LogCollector {
+	c.follow = fn
+	return c
+}
+
+func (c *LogCollector) WithInspector(fn inspectContainerFunc) *LogCollector {
+	c.inspect = fn
+	return c
+}
+
+// WithEventSource 替换订阅容器事件的函数
+func (c *LogCollector) WithEventSource(fn watchEventsFunc) *LogCollector {
+	c.watchEvents = fn
+	return c
+}
+
+// WithRunningLister 替换列出运行中容器的函数（启动时与轮询时使用）
+func (c *LogCollector) WithRunningLister(fn listContainersFunc) *LogCollector {
+	c.listRunning = fn
+	return c
+}
+
+// prepare 初始化运行期状态，Run 与测试共用
+func (c *LogCollector) prepare(ctx context.Context) {
+	c.cfg = c.cfg.withDefaults()
+	c.tailersMu.Lock()
+	c.logCh = make(chan storage.ContainerLog, c.cfg.QueueSize)
+	c.tailers = make(map[string]*logTailer)
+	c.tailersMu.Unlock()
+	c.runCtx = ctx
+	c.startedAt = time.Now()
+	if c.follow == nil {
+		c.follow = c.defaultFollow
+	}
+	if c.inspect == nil {
+		c.inspect = c.inspectContainer
+	}
+	if c.watchEvents == nil {
+		c.watchEvents = defaultWatchEvents
+	}
+	if c.listRunning == nil {
+		c.listRunning = defaultListRunning
+	}
+	if c.pollInterval <= 0 {
+		c.pollInterval = defaultEventsPollInterval
+	}
+}
+
 func (c *LogCollector) Run(ctx context.Context) error {
 	if c == nil || c.store == nil {
 		return errors.New("log collector not initialized")
 	}
-	c.cfg = c.cfg.withDefaults()
-	c.logCh = make(chan storage.ContainerLog, c.cfg.QueueSize)
-	c.tailers = make(map[string]context.CancelFunc)
+	c.prepare(ctx)
 
-	startedAt := time.Now()
+	startedAt := c.startedAt
 	if !c.cfg.SinceFromStart {
 		startedAt = time.Time{}
 	}
@@ -64,7 +158,26 @@ func (c *LogCollector) Run(ctx context.Context) error {
 		c.cfg.OnError(err)
 	}
 
-	eventsErr := c.eventsLoop(ctx, startedAt)
+	// 周期性对账，补上事件丢失时漏掉的容器
+	reconcileCtx, stopReconcile := context.WithCancel(ctx)
+	reconcileDone := make(chan struct{})
+	go func() {
+		defer close(reconcileDone)
+		if c.cfg.ReconcileInterval > 0 {
+			_ = c.reconcileLoop(reconcileCtx, c.cfg.ReconcileInterval)
+		}
+	}()
+
+	eventsErr := c.eventsLoop(ctx)
+	if errors.Is(eventsErr, errEventsUnsupported) {
+		// 只记录一次降级，之后按较短的固定间隔轮询运行中的容器（取代周期对账），不再反复重连事件流
+		stopReconcile()
+		<-reconcileDone
+		c.cfg.OnError(fmt.Errorf("%w, falling back to polling running containers every %s", eventsErr, c.pollInterval))
+		eventsErr = c.reconcileLoop(ctx, c.pollInterval)
+	}
+	stopReconcile()
+	<-reconcileDone
 	c.stopAllTailers()
 
 	writerErr := <-writerErrCh
@@ -79,26 +192,123 @@ func (c *LogCollector) Run(ctx context.Context) error {
 }
 
 func (c *LogCollector) reconcileRunning(ctx context.Context, since time.Time) error {
-	items, err := docker.ListContainerDetail(ctx, docker.ListContainersOptions{All: false, Status: "running"})
+	items, err := c.listRunning(ctx)
 	if err != nil {
 		return err
 	}
 	for _, it := range items {
-		c.startTailer(ctx, it.ID, it.Names, since)
+		c.startTailer(ctx, it.ID, it.Name, since)
 	}
 	return nil
 }
 
-func (c *LogCollector) eventsLoop(ctx context.Context, startedAt time.Time) error {
+func defaultListRunning(ctx context.Context) ([]containerMeta, error) {
+	items, err := docker.ListContainerDetail(ctx, docker.ListContainersOptions{All: false, Status: "running"})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]containerMeta, 0, len(items))
+	for _, it := range items {
+		out = append(out, containerMeta{ID: it.ID, Name: it.Names})
+	}
+	return out, nil
+}
+
+// reconcileLoop 按 interval 周期性列出运行中的容器并为缺失的容器启动 tailer（已有 tailer 的容器跳过）；
+// 容器停止后 tailer 随日志流结束自行退出。新 tailer 从上一轮之前开始读取，覆盖两轮之间启动的容器，
+// 重叠部分由写入端去重。事件可用时用于自愈，事件不可用时作为轮询。
+func (c *LogCollector) reconcileLoop(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	prev := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			now := time.Now()
+			if err := c.reconcileRunning(ctx, prev.Add(-time.Second)); err != nil && ctx.Err() == nil {
+				c.cfg.OnError(fmt.Errorf("reconcile running containers: %w", err))
+			}
+			prev = now
+		}
+	}
+}
+
+// Reload 更新 tailer 相关配置（MaxLineBytes、SinceFromStart、TailerLimit）。
+// MaxLineBytes 变化时逐个重启正在运行的 tailer，并从各自读到的最后一行之后续读，不丢失日志；
+// SinceFromStart 只影响之后新启动的 tailer。队列与写入参数（QueueSize/BatchSize 等）需重启进程生效。
+func (c *LogCollector) Reload(cfg LogConfig) {
+	if c == nil {
+		return
+	}
+	cfg = cfg.withDefaults()
+
+	c.cfgMu.Lock()
+	changed := c.cfg.MaxLineBytes != cfg.MaxLineBytes
+	c.cfg.MaxLineBytes = cfg.MaxLineBytes
+	c.cfg.SinceFromStart = cfg.SinceFromStart
+	c.cfg.TailerLimit = cfg.TailerLimit
+	c.cfgMu.Unlock()
+
+	if !changed || c.runCtx == nil {
+		return
+	}
+
+	c.tailersMu.Lock()
+	ids := make([]string, 0, len(c.tailers))
+	for id := range c.tailers {
+		ids = append(ids, id)
+	}
+	c.tailersMu.Unlock()
+
+	for _, id := range ids {
+		c.restartTailer(id)
+	}
+}
+
+// restartTailer 停止容器当前的 tailer，等待其退出后以新配置从最后读取位置重新跟随
+func (c *LogCollector) restartTailer(containerID string) {
+	c.tailersMu.Lock()
+	t, ok := c.tailers[containerID]
+	if ok {
+		delete(c.tailers, containerID)
+	}
+	c.tailersMu.Unlock()
+	if !ok {
+		return
+	}
+	t.cancel()
+	<-t.done
+
+	if c.runCtx.Err() != nil {
+		return
+	}
+	since := time.Time{}
+	if last := t.last.Load(); last > 0 {
+		since = time.Unix(0, last).Add(time.Nanosecond)
+	} else if c.tailerConfig().SinceFromStart {
+		since = c.startedAt
+	}
+	c.startTailer(c.runCtx, containerID, t.name, since)
+}
+
+// tailerConfig 返回当前 tailer 配置的快照
+func (c *LogCollector) tailerConfig() LogConfig {
+	c.cfgMu.RLock()
+	defer c.cfgMu.RUnlock()
+	return c.cfg
+}
+
+func (c *LogCollector) eventsLoop(ctx context.Context) error {
 	backoff := c.cfg.ReconnectDelay
 	for {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
 
-		args := filters.NewArgs()
-		args.Add("type", "container")
-		msgCh, errCh := docker.Events(ctx, events.ListOptions{Filters: args})
+		msgCh, errCh := c.watchEvents(ctx)
 
 		for {
 			select {
@@ -109,6 +319,9 @@ func (c *LogCollector) eventsLoop(ctx context.Context, startedAt time.Time) erro
 					time.Sleep(withJitter(backoff, c.cfg.ReconnectJitter))
 					goto reconnect
 				}
+				if docker.IsUnsupported(err) {
+					return fmt.Errorf("%w: %w", errEventsUnsupported, err)
+				}
 				if err != nil && !errors.Is(err, context.Canceled) {
 					c.cfg.OnError(fmt.Errorf("events stream error: %w", err))
 				}
@@ -119,7 +332,7 @@ func (c *LogCollector) eventsLoop(ctx context.Context, startedAt time.Time) erro
 					time.Sleep(withJitter(backoff, c.cfg.ReconnectJitter))
 					goto reconnect
 				}
-				c.handleEvent(ctx, msg, startedAt)
+				c.handleEvent(ctx, msg)
 			}
 		}
 
@@ -128,7 +341,22 @@ func (c *LogCollector) eventsLoop(ctx context.Context, startedAt time.Time) erro
 	}
 }
 
-func (c *LogCollector) handleEvent(ctx context.Context, msg events.Message, startedAt time.Time) {
+// watchStops 实现 watchStopsFunc：复用本采集器的事件订阅，将 die/destroy 事件转交给 onStop，
+// 使 stats 流式采样无需另开一条 Docker 事件订阅
+func (c *LogCollector) watchStops(ctx context.Context, onStop func(containerID string)) error {
+	c.onStop.Store(&onStop)
+	defer c.onStop.Store(nil)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func defaultWatchEvents(ctx context.Context) (<-chan events.Message, <-chan error) {
+	args := filters.NewArgs()
+	args.Add("type", "container")
+	return docker.Events(ctx, events.ListOptions{Filters: args})
+}
+
+func (c *LogCollector) handleEvent(ctx context.Context, msg events.Message) {
 	if msg.Type != "container" {
 		return
 	}
@@ -138,11 +366,24 @@ func (c *LogCollector) handleEvent(ctx context.Context, msg events.Message, star
 	}
 
 	action := msg.Action
+	switch action {
+	case "oom", "die", "start", "restart":
+		c.recordEvent(ctx, msg)
+	}
+	c.alert.ObserveEvent(msg)
+
+	switch action {
+	case "die", "destroy":
+		if onStop := c.onStop.Load(); onStop != nil {
+			(*onStop)(containerID)
+		}
+	}
+
 	switch action {
 	case "start":
-		since := startedAt
-		if !since.IsZero() {
-			since = since.Add(-500 * time.Millisecond)
+		var since time.Time
+		if c.tailerConfig().SinceFromStart {
+			since = c.startedAt.Add(-500 * time.Millisecond)
 		}
 		c.startTailer(ctx, containerID, "", since)
 	case "die", "stop", "destroy":
@@ -150,63 +391,89 @@ func (c *LogCollector) handleEvent(ctx context.Context, msg events.Message, star
 	}
 }
 
+// recordEvent 将 oom/die/start/restart 事件写入 events 表，供排查退出原因与定位最近一次重启；
+// 写入失败只上报，不影响日志采集
+func (c *LogCollector) recordEvent(ctx context.Context, msg events.Message) {
+	if err := c.store.InsertDockerEvent(ctx, storage.NewDockerEvent(msg)); err != nil && ctx.Err() == nil {
+		c.cfg.OnError(fmt.Errorf("record %s event for container %s: %w", msg.Action, msg.Actor.ID, err))
+	}
+}
+
 func (c *LogCollector) startTailer(ctx context.Context, containerID string, name string, since time.Time) {
+	cfg := c.tailerConfig()
 	c.tailersMu.Lock()
 	if _, ok := c.tailers[containerID]; ok {
 		c.tailersMu.Unlock()
 		return
 	}
-	if c.cfg.TailerLimit > 0 && len(c.tailers) >= c.cfg.TailerLimit {
+	if cfg.TailerLimit > 0 && len(c.tailers) >= cfg.TailerLimit {
 		c.tailersMu.Unlock()
-		c.cfg.OnError(fmt.Errorf("tailer limit reached: %d", c.cfg.TailerLimit))
+		cfg.OnError(fmt.Errorf("tailer limit reached: %d", cfg.TailerLimit))
 		return
 	}
 	tailerCtx, cancel := context.WithCancel(ctx)
-	c.tailers[containerID] = cancel
+	t := &logTailer{
+		cancel:       cancel,
+		done:         make(chan struct{}),
+		name:         name,
+		maxLineBytes: cfg.MaxLineBytes,
+	}
+	c.tailers[containerID] = t
 	c.tailersMu.Unlock()
 
 	go func() {
-		defer c.stopTailer(containerID)
+		defer close(t.done)
+		defer c.removeTailer(containerID, t)
 
-		info, err := c.inspectContainer(tailerCtx, containerID)
+		info, err := c.inspect(tailerCtx, containerID)
 		if err != nil {
-			c.cfg.OnError(err)
+			cfg.OnError(&ContainerError{ContainerID: containerID, Op: "inspect", Err: err})
 			return
 		}
-		if name == "" {
-			name = info.name
+		if t.name == "" {
+			t.name = info.name
 		}
-		if since.IsZero() && c.cfg.SinceFromStart {
+		if since.IsZero() && cfg.SinceFromStart {
 			since = time.Now()
 		}
-		if err := c.tailContainer(tailerCtx, containerID, name, info.tty, since); err != nil && !errors.Is(err, context.Canceled) {
-			c.cfg.OnError(err)
+		if err := c.tailContainer(tailerCtx, t, containerID, info.tty, since); err != nil && !errors.Is(err, context.Canceled) {
+			cfg.OnError(&ContainerError{ContainerID: containerID, Op: "tail logs", Err: err})
 		}
 	}()
 }
 
+// removeTailer 在 tailer 退出时移除登记；仅当登记的仍是同一个 tailer 时移除，避免误删重启后的新 tailer
+func (c *LogCollector) removeTailer(containerID string, t *logTailer) {
+	c.tailersMu.Lock()
+	if cur, ok := c.tailers[containerID]; ok && cur == t {
+		delete(c.tailers, containerID)
+	}
+	c.tailersMu.Unlock()
+	t.cancel()
+}
+
 func (c *LogCollector) stopTailer(containerID string) {
 	c.tailersMu.Lock()
-	cancel, ok := c.tailers[containerID]
+	t, ok := c.tailers[containerID]
 	if ok {
 		delete(c.tailers, containerID)
 	}
 	c.tailersMu.Unlock()
 	if ok {
-		cancel()
+		t.cancel()
 	}
 }
 
 func (c *LogCollector) stopAllTailers() {
 	c.tailersMu.Lock()
-	tailers := make([]context.CancelFunc, 0, len(c.tailers))
-	for _, cancel := range c.tailers {
-		tailers = append(tailers, cancel)
+	tailers := make([]*logTailer, 0, len(c.tailers))
+	for _, t := range c.tailers {
+		tailers = append(tailers, t)
 	}
-	c.tailers = make(map[string]context.CancelFunc)
+	c.tailers = make(map[string]*logTailer)
 	c.tailersMu.Unlock()
-	for _, cancel := range tailers {
-		cancel()
+	for _, t := range tailers {
+		t.cancel()
 	}
 }
 
@@ -231,7 +498,7 @@ func (c *LogCollector) inspectContainer(ctx context.Context, containerID string)
 	return containerInspectInfo{name: info.Name, tty: tty}, nil
 }
 
-func (c *LogCollector) tailContainer(ctx context.Context, containerID, containerName string, tty bool, since time.Time) error {
+func (c *LogCollector) defaultFollow(ctx context.Context, containerID string, since time.Time) (io.ReadCloser, error) {
 	sinceStr := ""
 	if !since.IsZero() {
 		sinceStr = since.UTC().Format(time.RFC3339Nano)
@@ -246,7 +513,15 @@ func (c *LogCollector) tailContainer(ctx context.Context, containerID, container
 		Since:      sinceStr,
 	})
 	if err != nil {
-		return fmt.Errorf("container logs follow %s: %w", containerID, err)
+		return nil, fmt.Errorf("container logs follow %s: %w", containerID, err)
+	}
+	return r, nil
+}
+
+func (c *LogCollector) tailContainer(ctx context.Context, t *logTailer, containerID string, tty bool, since time.Time) error {
+	r, err := c.follow(ctx, containerID, since)
+	if err != nil {
+		return err
 	}
 	defer r.Close()
 	go func() {
@@ -255,7 +530,7 @@ func (c *LogCollector) tailContainer(ctx context.Context, containerID, container
 	}()
 
 	if tty {
-		return c.scanLines(ctx, "stdout", containerID, containerName, r)
+		return c.scanLines(ctx, t, "stdout", containerID, r)
 	}
 
 	stdoutR, stdoutW := io.Pipe()
@@ -277,11 +552,11 @@ func (c *LogCollector) tailContainer(ctx context.Context, containerID, container
 	scanWG.Add(2)
 	go func() {
 		defer scanWG.Done()
-		_ = c.scanLines(ctx, "stdout", containerID, containerName, stdoutR)
+		_ = c.scanLines(ctx, t, "stdout", containerID, stdoutR)
 	}()
 	go func() {
 		defer scanWG.Done()
-		_ = c.scanLines(ctx, "stderr", containerID, containerName, stderrR)
+		_ = c.scanLines(ctx, t, "stderr", containerID, stderrR)
 	}()
 
 	select {
@@ -293,20 +568,23 @@ func (c *LogCollector) tailContainer(ctx context.Context, containerID, container
 	}
 }
 
-func (c *LogCollector) scanLines(ctx context.Context, source, containerID, containerName string, r io.Reader) error {
+func (c *LogCollector) scanLines(ctx context.Context, t *logTailer, source, containerID string, r io.Reader) error {
 	scanner := bufio.NewScanner(r)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, c.cfg.MaxLineBytes)
+	buf := make([]byte, 0, min(64*1024, t.maxLineBytes))
+	scanner.Buffer(buf, t.maxLineBytes)
 
 	for scanner.Scan() {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
+		if err := c.paused.wait(ctx); err != nil {
+			return err
+		}
 
 		ts, msg := parseDockerTimestampedLine(scanner.Text())
 		rec := storage.ContainerLog{
 			ContainerID:   containerID,
-			ContainerName: containerName,
+			ContainerName: t.name,
 			Source:        source,
 			Level:         inferLogLevel(msg),
 			Message:       msg,
@@ -314,6 +592,14 @@ func (c *LogCollector) scanLines(ctx context.Context, source, containerID, conta
 			Raw:           scanner.Text(),
 		}
 
+		// stdout/stderr 两个扫描协程并发更新，只前进不后退
+		for n := ts.UnixNano(); ; {
+			cur := t.last.Load()
+			if n <= cur || t.last.CompareAndSwap(cur, n) {
+				break
+			}
+		}
+
 		select {
 		case c.logCh <- rec:
 		default:
@@ -327,26 +613,57 @@ func (c *LogCollector) scanLines(ctx context.Context, source, containerID, conta
 	return nil
 }
 
+// queueStatus 返回日志队列深度与正在跟随的容器数
+func (c *LogCollector) queueStatus() (QueueStatus, int) {
+	c.tailersMu.Lock()
+	defer c.tailersMu.Unlock()
+	return QueueStatus{Len: len(c.logCh), Cap: cap(c.logCh)}, len(c.tailers)
+}
+
 func (c *LogCollector) writeLoop(ctx context.Context) error {
 	flushTicker := time.NewTicker(c.cfg.FlushInterval)
 	defer flushTicker.Stop()
 
 	buf := make([]storage.ContainerLog, 0, c.cfg.BatchSize)
-	flush := func() error {
+	// 日志轮转/截断或 tailer 重连时 follow 流可能重放已读过的行，入队前按窗口去重
+	dedup := newLogDedup(logDedupWindow)
+	flushTo := func(ctx context.Context) error {
 		if len(buf) == 0 {
 			return nil
 		}
-		err := c.store.InsertContainerLogs(ctx, buf)
+		var err error
+		if c.cfg.Transactional {
+			err = c.store.InsertLogsTx(ctx, buf)
+		} else {
+			err = c.store.InsertContainerLogs(ctx, buf)
+		}
 		buf = buf[:0]
 		return err
 	}
+	flush := func() error { return flushTo(ctx) }
 
 	for {
 		select {
 		case <-ctx.Done():
-			_ = flush()
+			// 停止时取出队列中已有的记录，并以不随 ctx 取消的 context 落库最后一批
+			for drained := false; !drained; {
+				select {
+				case rec := <-c.logCh:
+					if !dedup.duplicate(rec, time.Now()) {
+						buf = append(buf, rec)
+					}
+				default:
+					drained = true
+				}
+			}
+			if err := flushTo(context.WithoutCancel(ctx)); err != nil {
+				c.cfg.OnError(fmt.Errorf("final logs flush: %w", err))
+			}
 			return ctx.Err()
 		case rec := <-c.logCh:
+			if dedup.duplicate(rec, time.Now()) {
+				continue
+			}
 			buf = append(buf, rec)
 			if len(buf) >= c.cfg.BatchSize {
 				if err := flush(); err != nil {
@@ -354,6 +671,7 @@ func (c *LogCollector) writeLoop(ctx context.Context) error {
 				}
 			}
 		case <-flushTicker.C:
+			dedup.prune(time.Now())
 			if err := flush(); err != nil {
 				return err
 			}
@@ -3,6 +3,7 @@ package monitor
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 )
@@ -13,9 +14,16 @@ type Manager struct {
 	stats *StatsCollector
 	logs  *LogCollector
 	ret   *RetentionCollector
+	alert *AlertCollector
+	host  *HostStatsCollector
 
 	started atomic.Bool
 
+	// pause 为各采集器共享的暂停开关，states 记录各采集器协程状态，供 Status 查询
+	pause    *pauser
+	statesMu sync.Mutex
+	states   map[string]collectorState
+
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 
@@ -27,11 +35,14 @@ func NewManager(cfg Config) (*Manager, error) {
 	cfg.Stats = cfg.Stats.withDefaults()
 	cfg.Logs = cfg.Logs.withDefaults()
 	cfg.Retention = cfg.Retention.withDefaults()
+	cfg.Alert = cfg.Alert.withDefaults()
+	cfg.Host = cfg.Host.withDefaults()
 	return &Manager{
 		cfg:   cfg,
 		stats: nil,
 		logs:  nil,
 		ret:   nil,
+		pause: newPauser(),
 	}, nil
 }
 func (m *Manager) WithStats(stats *StatsCollector) *Manager {
@@ -41,6 +52,26 @@ func (m *Manager) WithStats(stats *StatsCollector) *Manager {
 	m.stats = stats
 	if m.stats != nil {
 		m.stats.cfg = m.cfg.Stats
+		m.stats.alert = m.alert
+		m.stats.paused = m.pause
+	}
+	return m
+}
+
+// WithAlerts 挂载告警采集器；资源告警基于 stats 采样，需同时启用 stats；OOM 告警基于日志流水线订阅的容器事件。
+func (m *Manager) WithAlerts(alert *AlertCollector) *Manager {
+	if m == nil {
+		return nil
+	}
+	m.alert = alert
+	if m.alert != nil {
+		m.alert.cfg = m.cfg.Alert
+	}
+	if m.stats != nil {
+		m.stats.alert = m.alert
+	}
+	if m.logs != nil {
+		m.logs.alert = m.alert
 	}
 	return m
 }
@@ -52,6 +83,8 @@ func (m *Manager) WithLogs(logs *LogCollector) *Manager {
 	m.logs = logs
 	if m.logs != nil {
 		m.logs.cfg = m.cfg.Logs
+		m.logs.paused = m.pause
+		m.logs.alert = m.alert
 	}
 	return m
 }
@@ -67,6 +100,27 @@ func (m *Manager) WithRetention(ret *RetentionCollector) *Manager {
 	return m
 }
 
+// WithHost 挂载宿主机采样器。
+func (m *Manager) WithHost(host *HostStatsCollector) *Manager {
+	if m == nil {
+		return nil
+	}
+	m.host = host
+	if m.host != nil {
+		m.host.cfg = m.cfg.Host
+		m.host.paused = m.pause
+	}
+	return m
+}
+
+// ReloadLogs 热更新日志采集的 tailer 配置，详见 LogCollector.Reload。
+func (m *Manager) ReloadLogs(cfg LogConfig) {
+	if m == nil || m.logs == nil {
+		return
+	}
+	m.logs.Reload(cfg)
+}
+
 func (m *Manager) Start(ctx context.Context) error {
 	if m == nil {
 		return errors.New("manager is nil")
@@ -78,23 +132,21 @@ func (m *Manager) Start(ctx context.Context) error {
 	runCtx, cancel := context.WithCancel(ctx)
 	m.cancel = cancel
 
+	if m.cfg.Alert.Enabled && m.alert == nil {
+		m.cancel()
+		return errors.New("alert collector is required when alert enabled")
+	}
+
 	if m.cfg.Stats.Enabled {
 		if m.stats == nil {
 			m.cancel()
 			return errors.New("stats collector is required when stats enabled")
 		}
-		m.wg.Add(1)
-		go func() {
-			defer m.wg.Done()
-			if err := m.stats.Run(runCtx); err != nil && !errors.Is(err, context.Canceled) {
-				m.runErrMu.Lock()
-				if m.runErr == nil {
-					m.runErr = err
-				}
-				m.runErrMu.Unlock()
-				m.cancel()
-			}
-		}()
+		// 日志采集开启时，流式采样从日志采集器的事件订阅中获知容器退出，避免重复订阅
+		if m.cfg.Logs.Enabled && m.logs != nil && m.stats.watchStops == nil {
+			m.stats.watchStops = m.logs.watchStops
+		}
+		m.goRun(runCtx, "stats", m.stats.Run)
 	}
 
 	if m.cfg.Logs.Enabled {
@@ -102,18 +154,7 @@ func (m *Manager) Start(ctx context.Context) error {
 			m.cancel()
 			return errors.New("logs collector is required when logs enabled")
 		}
-		m.wg.Add(1)
-		go func() {
-			defer m.wg.Done()
-			if err := m.logs.Run(runCtx); err != nil && !errors.Is(err, context.Canceled) {
-				m.runErrMu.Lock()
-				if m.runErr == nil {
-					m.runErr = err
-				}
-				m.runErrMu.Unlock()
-				m.cancel()
-			}
-		}()
+		m.goRun(runCtx, "logs", m.logs.Run)
 	}
 
 	if m.cfg.Retention.Enabled {
@@ -121,23 +162,42 @@ func (m *Manager) Start(ctx context.Context) error {
 			m.cancel()
 			return errors.New("retention collector is required when retention enabled")
 		}
-		m.wg.Add(1)
-		go func() {
-			defer m.wg.Done()
-			if err := m.ret.Run(runCtx); err != nil && !errors.Is(err, context.Canceled) {
-				m.runErrMu.Lock()
-				if m.runErr == nil {
-					m.runErr = err
-				}
-				m.runErrMu.Unlock()
-				m.cancel()
-			}
-		}()
+		m.goRun(runCtx, "retention", m.ret.Run)
+	}
+
+	if m.cfg.Host.Enabled {
+		if m.host == nil {
+			m.cancel()
+			return errors.New("host stats collector is required when host stats enabled")
+		}
+		m.goRun(runCtx, "host", m.host.Run)
 	}
 
 	return nil
 }
 
+// goRun 在独立协程中运行采集器；异常退出时记录首个错误并停止其余采集器
+func (m *Manager) goRun(ctx context.Context, name string, run func(context.Context) error) {
+	m.setState(name, true, nil)
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		err := run(ctx)
+		if errors.Is(err, context.Canceled) {
+			err = nil
+		}
+		m.setState(name, false, err)
+		if err != nil {
+			m.runErrMu.Lock()
+			if m.runErr == nil {
+				m.runErr = err
+			}
+			m.runErrMu.Unlock()
+			m.cancel()
+		}
+	}()
+}
+
 func (m *Manager) Stop() {
 	if m == nil || m.cancel == nil {
 		return
@@ -145,6 +205,24 @@ func (m *Manager) Stop() {
 	m.cancel()
 }
 
+// Shutdown 停止所有采集器，并在 ctx 到期前等待其退出（包括最后一批缓冲数据的落库）。
+// ctx 到期时返回超时错误，此时仍在写入的数据可能丢失；正常退出时返回与 Wait 相同的运行错误。
+func (m *Manager) Shutdown(ctx context.Context) error {
+	if m == nil {
+		return nil
+	}
+	m.Stop()
+
+	done := make(chan error, 1)
+	go func() { done <- m.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("monitor shutdown did not finish: %w", ctx.Err())
+	}
+}
+
 func (m *Manager) Wait() error {
 	if m == nil {
 		return nil
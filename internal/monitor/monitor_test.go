@@ -1,20 +1,29 @@
 package monitor
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/containerd/containerd/errdefs"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 
 	"github.com/wwwzy/CentAgent/internal/docker"
+	"github.com/wwwzy/CentAgent/internal/logging"
 	"github.com/wwwzy/CentAgent/internal/storage"
 )
 
@@ -30,7 +39,7 @@ func openTestStorage(t *testing.T, ctx context.Context) *storage.Storage {
 	return store
 }
 
-func requireDocker(t *testing.T) {
+func requireDocker(t testing.TB) {
 	t.Helper()
 
 	cli, err := docker.GetClient()
@@ -46,7 +55,7 @@ func requireDocker(t *testing.T) {
 	}
 }
 
-func ensureAnyRunningContainer(t *testing.T, ctx context.Context) string {
+func ensureAnyRunningContainer(t testing.TB, ctx context.Context) string {
 	t.Helper()
 
 	containers, err := docker.ListContainers(ctx, docker.ListContainersOptions{All: false, Status: "running"})
@@ -391,3 +400,1314 @@ func TestRetentionCollector_RunOnce_PrunesByPolicy(t *testing.T) {
 		t.Fatalf("unexpected remaining rows: stats=%d logs=%d", len(remainStats), len(remainLogs))
 	}
 }
+
+func TestRetentionCollector_RunOnce_TrimsToMaxTotalRows(t *testing.T) {
+	ctx := context.Background()
+	store := openTestStorage(t, ctx)
+
+	// 全部在 keep_all 窗口内，按策略不会删除任何行
+	now := time.Now().UTC()
+	var stats []storage.ContainerStat
+	var logs []storage.ContainerLog
+	for i := 0; i < 25; i++ {
+		at := now.Add(time.Duration(i-25) * time.Minute)
+		stats = append(stats, storage.ContainerStat{ContainerID: "cid-a", ContainerName: "a", CPUPercent: float64(i), CollectedAt: at})
+		logs = append(logs, storage.ContainerLog{ContainerID: "cid-a", ContainerName: "a", Source: "stdout", Level: "INFO", Message: fmt.Sprintf("line %d", i), Timestamp: at})
+	}
+	if err := store.InsertContainerStats(ctx, stats); err != nil {
+		t.Fatalf("insert stats: %v", err)
+	}
+	if err := store.InsertContainerLogs(ctx, logs); err != nil {
+		t.Fatalf("insert logs: %v", err)
+	}
+
+	ret, err := NewRetentionCollector(store)
+	if err != nil {
+		t.Fatalf("new retention collector: %v", err)
+	}
+	cfg := DefaultConfig().Retention
+	cfg.BatchRows = 4
+	cfg.IdleSleep = 0
+	cfg.Stats.MaxTotalRows = 10
+	cfg.Logs.MaxTotalRows = 7
+	ret.cfg = cfg.withDefaults()
+	if err := ret.runOnce(ctx, now); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+
+	if n, err := store.CountContainerStats(ctx); err != nil || n != 10 {
+		t.Fatalf("expected stats trimmed to 10, got %d (%v)", n, err)
+	}
+	if n, err := store.CountContainerLogs(ctx); err != nil || n != 7 {
+		t.Fatalf("expected logs trimmed to 7, got %d (%v)", n, err)
+	}
+	// 保留的是最新的行
+	remain, err := store.QueryContainerLogs(ctx, storage.LogQuery{ContainerID: "cid-a", Limit: 50})
+	if err != nil {
+		t.Fatalf("query remaining logs: %v", err)
+	}
+	if len(remain) != 7 || remain[0].Message != "line 18" || remain[6].Message != "line 24" {
+		t.Fatalf("unexpected remaining logs: %+v", remain)
+	}
+
+	// 未超出上限时不删除
+	if err := ret.runOnce(ctx, now); err != nil {
+		t.Fatalf("run once again: %v", err)
+	}
+	if n, _ := store.CountContainerStats(ctx); n != 10 {
+		t.Fatalf("expected stats to stay at 10, got %d", n)
+	}
+}
+
+func TestAlertCollector_FiresOnceWhenSustained(t *testing.T) {
+	var alerts []Alert
+	a := NewAlertCollector()
+	a.cfg = AlertConfig{
+		Enabled:      true,
+		CPUHigh:      80,
+		MemHigh:      90,
+		SustainedFor: time.Minute,
+	}.withDefaults()
+	a.cfg.OnAlert = func(al Alert) { alerts = append(alerts, al) }
+
+	base := time.Now().UTC()
+	sample := func(offset time.Duration, cpu float64) storage.ContainerStat {
+		return storage.ContainerStat{ContainerID: "cid", ContainerName: "/web", CPUPercent: cpu, CollectedAt: base.Add(offset)}
+	}
+
+	a.Observe(sample(0, 95))
+	a.Observe(sample(30*time.Second, 95))
+	if len(alerts) != 0 {
+		t.Fatalf("expected no alert before sustained_for, got %d", len(alerts))
+	}
+	a.Observe(sample(time.Minute, 95))
+	a.Observe(sample(90*time.Second, 95))
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert, got %d", len(alerts))
+	}
+	if alerts[0].Metric != "cpu" || !alerts[0].Since.Equal(base) {
+		t.Fatalf("unexpected alert: %+v", alerts[0])
+	}
+
+	// 回落后重新计时
+	a.Observe(sample(2*time.Minute, 10))
+	a.Observe(sample(3*time.Minute, 95))
+	if len(alerts) != 1 {
+		t.Fatalf("expected timer reset after recovery, got %d alerts", len(alerts))
+	}
+	a.Observe(sample(4*time.Minute, 95))
+	if len(alerts) != 2 {
+		t.Fatalf("expected second alert, got %d", len(alerts))
+	}
+}
+
+func TestAlertCollector_CooldownAndResolve(t *testing.T) {
+	var alerts, resolved []Alert
+	a := NewAlertCollector()
+	a.cfg = AlertConfig{
+		Enabled:      true,
+		CPUHigh:      80,
+		SustainedFor: time.Minute,
+		Cooldown:     time.Hour,
+		ResolveAfter: 2 * time.Minute,
+	}.withDefaults()
+	a.cfg.OnAlert = func(al Alert) { alerts = append(alerts, al) }
+	a.cfg.OnResolve = func(al Alert) { resolved = append(resolved, al) }
+
+	base := time.Now().UTC()
+	sample := func(offset time.Duration, cpu float64) storage.ContainerStat {
+		return storage.ContainerStat{ContainerID: "cid", ContainerName: "/web", CPUPercent: cpu, CollectedAt: base.Add(offset)}
+	}
+
+	// 持续 30 分钟超限，每 30s 一次采样
+	for off := time.Duration(0); off <= 30*time.Minute; off += 30 * time.Second {
+		a.Observe(sample(off, 95))
+	}
+	// 短暂回落不足 ResolveAfter，随后再次超限，不应恢复也不应重新告警
+	a.Observe(sample(31*time.Minute, 10))
+	a.Observe(sample(32*time.Minute, 95))
+	if len(alerts) != 1 || len(resolved) != 0 {
+		t.Fatalf("expected 1 alert and no resolve, got %d/%d", len(alerts), len(resolved))
+	}
+
+	// 持续回落达到 ResolveAfter 后恢复
+	a.Observe(sample(33*time.Minute, 10))
+	a.Observe(sample(34*time.Minute, 10))
+	a.Observe(sample(35*time.Minute, 10))
+	if len(alerts) != 1 || len(resolved) != 1 {
+		t.Fatalf("expected 1 alert and 1 resolve, got %d/%d", len(alerts), len(resolved))
+	}
+	if !resolved[0].Since.Equal(base) || !resolved[0].At.Equal(base.Add(35*time.Minute)) {
+		t.Fatalf("unexpected resolve: %+v", resolved[0])
+	}
+
+	// 冷却期内再次持续超限：不告警，也就没有对应的恢复
+	a.Observe(sample(40*time.Minute, 95))
+	a.Observe(sample(42*time.Minute, 95))
+	a.Observe(sample(45*time.Minute, 10))
+	a.Observe(sample(47*time.Minute, 10))
+	if len(alerts) != 1 || len(resolved) != 1 {
+		t.Fatalf("expected cooldown to suppress re-fire, got %d/%d", len(alerts), len(resolved))
+	}
+
+	// 冷却结束后可以再次告警
+	a.Observe(sample(70*time.Minute, 95))
+	a.Observe(sample(72*time.Minute, 95))
+	if len(alerts) != 2 {
+		t.Fatalf("expected alert after cooldown, got %d", len(alerts))
+	}
+}
+
+func TestLogCollector_OOMKillRaisesAlertAndRecordsEvent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := openTestStorage(t, ctx)
+
+	var alerts []Alert
+	mgr, err := NewManager(Config{Alert: AlertConfig{
+		Enabled:  true,
+		OOM:      true,
+		Cooldown: time.Hour,
+		OnAlert:  func(al Alert) { alerts = append(alerts, al) },
+	}})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	c, err := NewLogCollector(store)
+	if err != nil {
+		t.Fatalf("new log collector: %v", err)
+	}
+	mgr.WithLogs(c).WithAlerts(NewAlertCollector())
+	c.prepare(ctx)
+
+	base := time.Now().UTC().Truncate(time.Second)
+	event := func(action events.Action, offset time.Duration, exitCode string) events.Message {
+		attrs := map[string]string{"name": "web"}
+		if exitCode != "" {
+			attrs["exitCode"] = exitCode
+		}
+		return events.Message{
+			Type:     events.ContainerEventType,
+			Action:   action,
+			Actor:    events.Actor{ID: "cid-web", Attributes: attrs},
+			TimeNano: base.Add(offset).UnixNano(),
+		}
+	}
+
+	// 正常退出不告警
+	c.handleEvent(ctx, event("die", 0, "0"))
+	// OOM kill：oom 事件后以 137 退出
+	c.handleEvent(ctx, event("oom", time.Second, ""))
+	c.handleEvent(ctx, event("die", 2*time.Second, "137"))
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 oom alert, got %d", len(alerts))
+	}
+	al := alerts[0]
+	if al.Metric != MetricOOM || al.ContainerID != "cid-web" || al.ContainerName != "web" || al.ExitCode != 137 ||
+		!al.Since.Equal(base.Add(time.Second)) || !al.At.Equal(base.Add(2*time.Second)) {
+		t.Fatalf("unexpected oom alert: %+v", al)
+	}
+
+	// 没有 oom 事件的非零退出、以及冷却期内的再次 OOM kill 都不告警
+	c.handleEvent(ctx, event("die", time.Minute, "1"))
+	c.handleEvent(ctx, event("oom", 2*time.Minute, ""))
+	c.handleEvent(ctx, event("die", 2*time.Minute+time.Second, "137"))
+	if len(alerts) != 1 {
+		t.Fatalf("expected no further alerts, got %d", len(alerts))
+	}
+
+	ooms, err := store.QueryDockerEvents(ctx, storage.EventQuery{Action: "oom", ActorID: "cid-web"})
+	if err != nil {
+		t.Fatalf("query events: %v", err)
+	}
+	if len(ooms) != 2 || ooms[0].ActorName != "web" || !ooms[0].Timestamp.Equal(base.Add(time.Second)) {
+		t.Fatalf("expected oom events to be recorded, got %+v", ooms)
+	}
+	dies, err := store.CountDockerEvents(ctx, storage.EventQuery{Action: "die"})
+	if err != nil {
+		t.Fatalf("count events: %v", err)
+	}
+	if dies != 4 {
+		t.Fatalf("expected 4 die events, got %d", dies)
+	}
+}
+
+func TestLogCollector_RecordsStartEvent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := openTestStorage(t, ctx)
+
+	apiID := strings.Repeat("a", 64)
+	restartAt := time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+
+	c, err := NewLogCollector(store)
+	if err != nil {
+		t.Fatalf("new log collector: %v", err)
+	}
+	c.cfg = LogConfig{FlushInterval: 10 * time.Millisecond, OnError: func(err error) {}}
+	c.WithEventSource(func(ctx context.Context) (<-chan events.Message, <-chan error) {
+		msgs := make(chan events.Message, 1)
+		msgs <- events.Message{
+			Type:     events.ContainerEventType,
+			Action:   "start",
+			Actor:    events.Actor{ID: apiID, Attributes: map[string]string{"name": "api"}},
+			TimeNano: restartAt.UnixNano(),
+		}
+		return msgs, make(chan error)
+	}).WithRunningLister(func(ctx context.Context) ([]containerMeta, error) {
+		return nil, nil
+	}).WithInspector(func(ctx context.Context, id string) (containerInspectInfo, error) {
+		return containerInspectInfo{name: "/api", tty: true}, nil
+	}).WithFollower(func(ctx context.Context, id string, since time.Time) (io.ReadCloser, error) {
+		pr, pw := io.Pipe()
+		go func() {
+			<-ctx.Done()
+			_ = pw.Close()
+		}()
+		return pr, nil
+	})
+
+	runDone := make(chan error, 1)
+	go func() { runDone <- c.Run(ctx) }()
+
+	// logs_since_last_restart 依赖 LastContainerStart 找到这条 start 事件
+	deadline := time.Now().Add(3 * time.Second)
+	var ev *storage.DockerEvent
+	for {
+		ev, err = store.LastContainerStart(ctx, apiID[:12], []string{"api"})
+		if err != nil {
+			t.Fatalf("last container start: %v", err)
+		}
+		if ev != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("start event was never recorded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if ev.ActorID != apiID || ev.ActorName != "api" || !ev.Timestamp.Equal(restartAt) {
+		t.Fatalf("unexpected recorded start event: %+v", ev)
+	}
+
+	cancel()
+	if err := <-runDone; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
+
+func TestDefaultOnErrorLogsContainerFields(t *testing.T) {
+	var buf bytes.Buffer
+	prev := logging.SetDefault(logging.New(&buf, slog.LevelInfo))
+	defer logging.SetDefault(prev)
+
+	cfg := StatsConfig{}.withDefaults()
+	cfg.OnError(&ContainerError{ContainerID: "abc123", Op: "fetch stats", Err: errors.New("boom")})
+
+	out := buf.String()
+	for _, want := range []string{"level=WARN", "component=stats", "container_id=abc123", `op="fetch stats"`, "err=boom"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in log output %q", want, out)
+		}
+	}
+
+	// 日志级别高于 warn 时被过滤
+	buf.Reset()
+	logging.SetDefault(logging.New(&buf, slog.LevelError))
+	cfg.OnError(errors.New("quiet"))
+	if buf.Len() != 0 {
+		t.Fatalf("expected no output at error level, got %q", buf.String())
+	}
+}
+
+func TestManager_ShutdownFlushesBufferedStats(t *testing.T) {
+	ctx := context.Background()
+	store := openTestStorage(t, ctx)
+
+	buffered := make(chan struct{}, 1)
+	cfg := DefaultConfig()
+	cfg.Stats = StatsConfig{
+		Enabled: true,
+		// 间隔与批量都足够大，保证采样只会停留在缓冲中，只能由停止时的最后一次 flush 落库
+		Interval:      time.Hour,
+		Workers:       1,
+		BatchSize:     1000,
+		FlushInterval: time.Hour,
+	}
+	cfg.Logs.Enabled = false
+	cfg.Retention.Enabled = false
+	cfg.Alert = AlertConfig{
+		Enabled: true,
+		CPUHigh: 50,
+		// 告警在采样进入写入缓冲时触发，用于确认采样已被缓冲
+		OnAlert: func(Alert) { buffered <- struct{}{} },
+	}
+	mgr, err := NewManager(cfg)
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+
+	stats, err := NewStatsCollector(store)
+	if err != nil {
+		t.Fatalf("new stats collector: %v", err)
+	}
+	stats.WithLister(func(ctx context.Context) ([]containerMeta, error) {
+		return []containerMeta{{ID: "buffered", Name: "/buffered"}}, nil
+	}).WithFetcher(func(ctx context.Context, meta containerMeta) (storage.ContainerStat, error) {
+		return storage.ContainerStat{ContainerID: meta.ID, ContainerName: meta.Name, CPUPercent: 99, CollectedAt: time.Now().UTC()}, nil
+	})
+	mgr.WithStats(stats).WithAlerts(NewAlertCollector())
+
+	if err := mgr.Start(ctx); err != nil {
+		t.Fatalf("start manager: %v", err)
+	}
+	select {
+	case <-buffered:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("stat was never buffered")
+	}
+
+	rows, err := store.QueryContainerStats(ctx, storage.StatsQuery{ContainerID: "buffered"})
+	if err != nil {
+		t.Fatalf("query stats: %v", err)
+	}
+	if len(rows) != 0 {
+		t.Fatalf("expected stat to still be buffered before shutdown, got %d rows", len(rows))
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := mgr.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	rows, err = store.QueryContainerStats(ctx, storage.StatsQuery{ContainerID: "buffered"})
+	if err != nil {
+		t.Fatalf("query stats: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected buffered stat to be flushed on shutdown, got %d rows", len(rows))
+	}
+}
+
+func TestStatsTargetsSkipsPaused(t *testing.T) {
+	got := statsTargets([]docker.ContainerSummary{
+		{ID: "run1", Names: "/web", State: "running"},
+		{ID: "pause1", Names: "/frozen", State: "paused"},
+		{ID: "run2", Names: "/db", State: "running", Labels: map[string]string{"tier": "data"}},
+	})
+	if len(got) != 2 {
+		t.Fatalf("expected paused container to be skipped, got %+v", got)
+	}
+	if got[0].ID != "run1" || got[1].ID != "run2" || got[1].Labels["tier"] != "data" {
+		t.Fatalf("unexpected targets: %+v", got)
+	}
+}
+
+func TestStatsCollector_FetchTimeoutSkipsSlowContainer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := openTestStorage(t, ctx)
+
+	errCh := make(chan error, 8)
+	c, err := NewStatsCollector(store)
+	if err != nil {
+		t.Fatalf("new stats collector: %v", err)
+	}
+	c.cfg = StatsConfig{
+		Interval:      time.Hour,
+		Workers:       1,
+		FlushInterval: 20 * time.Millisecond,
+		FetchTimeout:  100 * time.Millisecond,
+		OnError:       func(err error) { errCh <- err },
+	}
+	c.WithLister(func(ctx context.Context) ([]containerMeta, error) {
+		return []containerMeta{{ID: "slow", Name: "/slow"}, {ID: "fast", Name: "/fast"}}, nil
+	}).WithFetcher(func(ctx context.Context, meta containerMeta) (storage.ContainerStat, error) {
+		if meta.ID == "slow" {
+			select {
+			case <-ctx.Done():
+				return storage.ContainerStat{}, ctx.Err()
+			case <-time.After(10 * time.Second):
+			}
+		}
+		return storage.ContainerStat{ContainerID: meta.ID, ContainerName: meta.Name, CollectedAt: time.Now().UTC()}, nil
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx) }()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded for slow container, got %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("slow container was not timed out")
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		got, err := store.QueryContainerStats(ctx, storage.StatsQuery{ContainerID: "fast"})
+		if err != nil {
+			t.Fatalf("query stats: %v", err)
+		}
+		if len(got) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("worker did not move on to the next container")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
+
+func TestManager_HostStatsPipeline_WritesSamples(t *testing.T) {
+	ctx := context.Background()
+	store := openTestStorage(t, ctx)
+
+	cfg := DefaultConfig()
+	cfg.Stats.Enabled = false
+	cfg.Logs.Enabled = false
+	cfg.Retention.Enabled = false
+	cfg.Host = HostStatsConfig{Enabled: true, Interval: 20 * time.Millisecond}
+	mgr, err := NewManager(cfg)
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+
+	host, err := NewHostStatsCollector(store)
+	if err != nil {
+		t.Fatalf("new host stats collector: %v", err)
+	}
+	host.WithSampler(func(ctx context.Context) (storage.HostStat, error) {
+		return storage.HostStat{
+			CPUPercent:        42,
+			MemTotalBytes:     8 << 30,
+			MemUsedBytes:      2 << 30,
+			MemPercent:        25,
+			NCPU:              4,
+			ContainersRunning: 3,
+			ContainersStopped: 1,
+		}, nil
+	})
+	mgr.WithHost(host)
+
+	if err := mgr.Start(ctx); err != nil {
+		t.Fatalf("start manager: %v", err)
+	}
+	defer mgr.Stop()
+
+	deadline := time.Now().Add(3 * time.Second)
+	var rows []storage.HostStat
+	for {
+		rows, err = store.QueryHostStats(ctx, storage.HostStatsQuery{})
+		if err != nil {
+			t.Fatalf("query host stats: %v", err)
+		}
+		if len(rows) >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected host samples to be written, got %d", len(rows))
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	got := rows[0]
+	if got.CPUPercent != 42 || got.ContainersRunning != 3 || got.ContainersStopped != 1 || got.NCPU != 4 || got.CollectedAt.IsZero() {
+		t.Fatalf("unexpected host sample: %+v", got)
+	}
+
+	mgr.Stop()
+	if err := mgr.Wait(); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+}
+
+func TestManager_HostStatsRequiresCollector(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Stats.Enabled = false
+	cfg.Retention.Enabled = false
+	cfg.Host.Enabled = true
+	mgr, err := NewManager(cfg)
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if err := mgr.Start(context.Background()); err == nil {
+		t.Fatalf("expected error when host stats enabled without collector")
+	}
+}
+
+func TestHostCPUAndMemParsing(t *testing.T) {
+	prev, err := parseCPUTimes(strings.Fields("100 0 100 800 0 0 0 0 0 0"))
+	if err != nil {
+		t.Fatalf("parse prev: %v", err)
+	}
+	cur, err := parseCPUTimes(strings.Fields("150 0 150 900 0 0 0 0 0 0"))
+	if err != nil {
+		t.Fatalf("parse cur: %v", err)
+	}
+	if got := cpuPercent(prev, cur); got != 50 {
+		t.Fatalf("expected 50%% cpu, got %v", got)
+	}
+	if got := cpuPercent(cpuTimes{}, cur); got != 0 {
+		t.Fatalf("expected 0 without baseline, got %v", got)
+	}
+
+	total, used, err := parseMemInfo("MemTotal:       8000 kB\nMemFree:         1000 kB\nMemAvailable:    6000 kB\n")
+	if err != nil {
+		t.Fatalf("parse meminfo: %v", err)
+	}
+	if total != 8000*1024 || used != 2000*1024 {
+		t.Fatalf("unexpected meminfo: total=%d used=%d", total, used)
+	}
+	if _, _, err := parseMemInfo("MemTotal: 8000 kB\n"); err == nil {
+		t.Fatalf("expected error when MemAvailable is missing")
+	}
+}
+
+func TestStatsCollector_CollectSkipsDisabledFields(t *testing.T) {
+	resp := container.StatsResponse{}
+	resp.Read = time.Now().UTC()
+	resp.CPUStats.CPUUsage.TotalUsage = 2000
+	resp.CPUStats.SystemUsage = 20000
+	resp.CPUStats.OnlineCPUs = 1
+	resp.PreCPUStats.CPUUsage.TotalUsage = 1000
+	resp.PreCPUStats.SystemUsage = 10000
+	resp.MemoryStats.Usage = 50
+	resp.MemoryStats.Limit = 100
+	resp.Networks = map[string]container.NetworkStats{"eth0": {RxBytes: 10, TxBytes: 20}}
+	resp.BlkioStats.IoServiceBytesRecursive = []container.BlkioStatEntry{{Op: "Read", Value: 30}, {Op: "Write", Value: 40}}
+	resp.PidsStats.Current = 7
+	meta := containerMeta{ID: "c1", Name: "/web"}
+
+	all := &StatsCollector{cfg: StatsConfig{StoreRawJSON: true}.withDefaults()}
+	got := all.statFromResponse(meta, resp)
+	if got.CPUPercent != 10 || got.MemPercent != 50 || got.NetRxBytes != 10 || got.BlockWriteBytes != 40 || got.Pids != 7 || got.RawJSON == "" {
+		t.Fatalf("expected all fields collected by default, got %+v", got)
+	}
+
+	c := &StatsCollector{cfg: StatsConfig{Collect: []string{"cpu", "MEM"}, StoreRawJSON: true}.withDefaults()}
+	got = c.statFromResponse(meta, resp)
+	if got.CPUPercent != 10 || got.MemUsageBytes != 50 || got.MemPercent != 50 {
+		t.Fatalf("expected cpu/mem to be collected, got %+v", got)
+	}
+	if got.NetRxBytes != 0 || got.NetTxBytes != 0 || got.BlockReadBytes != 0 || got.BlockWriteBytes != 0 || got.Pids != 0 {
+		t.Fatalf("expected disabled fields to be zero, got %+v", got)
+	}
+	if got.RawJSON != "" {
+		t.Fatalf("expected RawJSON to be empty when raw_json not collected, got %q", got.RawJSON)
+	}
+	if got.ContainerID != "c1" || !got.CollectedAt.Equal(resp.Read) {
+		t.Fatalf("unexpected identity fields: %+v", got)
+	}
+}
+
+func TestStatsCollector_StoreRawJSONDisabled(t *testing.T) {
+	resp := container.StatsResponse{}
+	resp.Read = time.Now().UTC()
+	resp.MemoryStats.Usage = 50
+	resp.MemoryStats.Limit = 100
+	meta := containerMeta{ID: "c1", Name: "/web"}
+
+	c := &StatsCollector{cfg: StatsConfig{StoreRawJSON: false}.withDefaults()}
+	got := c.statFromResponse(meta, resp)
+	if got.RawJSON != "" {
+		t.Fatalf("expected RawJSON to be empty when store_raw_json is false, got %q", got.RawJSON)
+	}
+	if got.MemPercent != 50 {
+		t.Fatalf("expected other fields to be collected, got %+v", got)
+	}
+
+	// 即使 collect 中显式列出 raw_json，关闭开关后仍不保存
+	c = &StatsCollector{cfg: StatsConfig{Collect: []string{"raw_json"}}.withDefaults()}
+	if got := c.statFromResponse(meta, resp); got.RawJSON != "" {
+		t.Fatalf("expected store_raw_json=false to override collect, got %q", got.RawJSON)
+	}
+
+	if !DefaultConfig().Stats.StoreRawJSON {
+		t.Fatalf("expected RawJSON to be stored by default")
+	}
+}
+
+func TestValidateStatsCollect(t *testing.T) {
+	if err := ValidateStatsCollect([]string{"cpu", " Mem ", "raw_json"}); err != nil {
+		t.Fatalf("expected valid fields, got %v", err)
+	}
+	if err := ValidateStatsCollect([]string{"cpu", "disk"}); err == nil {
+		t.Fatalf("expected error for unknown field")
+	}
+}
+
+// fakeStatsStream 模拟 docker 的流式 stats：每隔 period 写入一帧，直到读端被关闭
+type fakeStatsStream struct {
+	closed chan struct{}
+}
+
+func startFakeStatsStream(ctx context.Context, period time.Duration) (io.ReadCloser, *fakeStatsStream) {
+	pr, pw := io.Pipe()
+	fs := &fakeStatsStream{closed: make(chan struct{})}
+	go func() {
+		defer close(fs.closed)
+		enc := json.NewEncoder(pw)
+		var total uint64
+		for {
+			total += 1000
+			frame := container.StatsResponse{}
+			frame.Read = time.Now().UTC()
+			frame.CPUStats.CPUUsage.TotalUsage = total
+			frame.CPUStats.SystemUsage = total * 10
+			frame.CPUStats.OnlineCPUs = 1
+			frame.PreCPUStats.CPUUsage.TotalUsage = total - 1000
+			frame.PreCPUStats.SystemUsage = (total - 1000) * 10
+			if err := enc.Encode(frame); err != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				_ = pw.Close()
+				return
+			case <-time.After(period):
+			}
+		}
+	}()
+	return pr, fs
+}
+
+func TestStatsCollector_StreamModeWritesSamplesAndCleansUp(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := openTestStorage(t, ctx)
+
+	var (
+		mu      sync.Mutex
+		running = []containerMeta{{ID: "aaa111", Name: "/a"}, {ID: "bbb222", Name: "/b"}}
+		opened  = map[string]int{}
+		fakes   = map[string]*fakeStatsStream{}
+	)
+	stopCh := make(chan string, 1)
+
+	c, err := NewStatsCollector(store)
+	if err != nil {
+		t.Fatalf("new stats collector: %v", err)
+	}
+	c.cfg = StatsConfig{
+		Mode:          StatsModeStream,
+		Interval:      30 * time.Millisecond,
+		Workers:       2,
+		FlushInterval: 10 * time.Millisecond,
+		FetchTimeout:  time.Second,
+		OnError:       func(err error) { t.Logf("stats error: %v", err) },
+	}
+	c.WithLister(func(ctx context.Context) ([]containerMeta, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]containerMeta(nil), running...), nil
+	}).WithStreamOpener(func(ctx context.Context, id string) (io.ReadCloser, error) {
+		body, fs := startFakeStatsStream(ctx, 5*time.Millisecond)
+		mu.Lock()
+		opened[id]++
+		fakes[id] = fs
+		mu.Unlock()
+		return body, nil
+	}).WithStopWatcher(func(ctx context.Context, onStop func(string)) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case id := <-stopCh:
+				onStop(id)
+			}
+		}
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx) }()
+
+	waitFor := func(what string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(3 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	countRows := func(id string) int {
+		rows, err := store.QueryContainerStats(ctx, storage.StatsQuery{ContainerID: id})
+		if err != nil {
+			t.Fatalf("query stats: %v", err)
+		}
+		return len(rows)
+	}
+
+	// 多个周期的采样均落库，且每个容器只建立一条连接
+	waitFor("samples from both streams", func() bool { return countRows("aaa111") >= 3 && countRows("bbb222") >= 3 })
+	rows, _ := store.QueryContainerStats(ctx, storage.StatsQuery{ContainerID: "aaa111"})
+	if rows[0].CPUPercent != 10 {
+		t.Fatalf("expected cpu computed from stream frame, got %v", rows[0].CPUPercent)
+	}
+	mu.Lock()
+	if opened["aaa111"] != 1 || opened["bbb222"] != 1 {
+		t.Fatalf("expected one stream per container, got %v", opened)
+	}
+	fakeA, fakeB := fakes["aaa111"], fakes["bbb222"]
+	mu.Unlock()
+
+	// 容器退出事件（完整 ID）关闭对应的流
+	mu.Lock()
+	running = running[1:]
+	mu.Unlock()
+	stopCh <- "aaa111ffffffffffffffff"
+	select {
+	case <-fakeA.closed:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("stream of stopped container was not closed")
+	}
+
+	// 容器从列表中消失（事件丢失）时也会关闭
+	mu.Lock()
+	running = nil
+	mu.Unlock()
+	select {
+	case <-fakeB.closed:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("stream of vanished container was not closed")
+	}
+	waitFor("streams to be released", func() bool { return c.streams.count() == 0 })
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
+
+func TestStatsStreamer_WatchStopsGivesUpWhenEventsUnsupported(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var reported atomic.Int32
+	s := newStatsStreamer(ctx, func(ctx context.Context, id string) (io.ReadCloser, error) {
+		return nil, errors.New("unused")
+	}, func(err error) {
+		if errors.Is(err, errEventsUnsupported) {
+			reported.Add(1)
+		}
+	})
+	defer s.closeAll()
+
+	var calls atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.watchStopsLoop(ctx, func(ctx context.Context, onStop func(string)) error {
+			calls.Add(1)
+			return fmt.Errorf("%w: %w", errEventsUnsupported, errdefs.ErrNotImplemented)
+		}, time.Millisecond)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("watch loop kept retrying after events were reported unsupported")
+	}
+	if calls.Load() != 1 || reported.Load() != 1 {
+		t.Fatalf("expected one attempt and one report, got %d attempts and %d reports", calls.Load(), reported.Load())
+	}
+}
+
+func TestLogCollector_WatchStopsForwardsStopEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := openTestStorage(t, ctx)
+
+	c, err := NewLogCollector(store)
+	if err != nil {
+		t.Fatalf("new log collector: %v", err)
+	}
+	c.cfg = LogConfig{OnError: func(err error) {}}
+	c.prepare(ctx)
+
+	stopped := make(chan string, 4)
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.watchStops(watchCtx, func(id string) { stopped <- id })
+	}()
+	deadline := time.Now().Add(3 * time.Second)
+	for c.onStop.Load() == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("stop watcher was never registered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	event := func(action events.Action) events.Message {
+		return events.Message{Type: events.ContainerEventType, Action: action, Actor: events.Actor{ID: "cid-" + string(action)}}
+	}
+	c.handleEvent(ctx, event("stop"))
+	c.handleEvent(ctx, event("die"))
+	c.handleEvent(ctx, event("destroy"))
+	for _, want := range []string{"cid-die", "cid-destroy"} {
+		select {
+		case got := <-stopped:
+			if got != want {
+				t.Fatalf("expected %s, got %s", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s was not forwarded", want)
+		}
+	}
+
+	stopWatch()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected watcher to end with the context, got %v", err)
+	}
+	if c.onStop.Load() != nil {
+		t.Fatalf("expected stop watcher to be unregistered")
+	}
+	c.handleEvent(ctx, event("die"))
+	if len(stopped) != 0 {
+		t.Fatalf("unexpected forwarded event after unregister")
+	}
+}
+
+func TestStatsStreamer_ReopensAfterStreamEnds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opens := 0
+	s := newStatsStreamer(ctx, func(ctx context.Context, id string) (io.ReadCloser, error) {
+		opens++
+		return io.NopCloser(strings.NewReader(`{"read":"2024-01-02T15:04:05Z"}`)), nil
+	}, func(error) {})
+	defer s.closeAll()
+
+	for i := 0; i < 2; i++ {
+		frame, err := s.latest(ctx, "c1")
+		if err != nil {
+			t.Fatalf("latest: %v", err)
+		}
+		if frame.Read.IsZero() {
+			t.Fatalf("expected frame to be decoded")
+		}
+		deadline := time.Now().Add(time.Second)
+		for s.count() != 0 {
+			if time.Now().After(deadline) {
+				t.Fatalf("ended stream was not removed")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	if opens != 2 {
+		t.Fatalf("expected stream to be reopened after EOF, got %d opens", opens)
+	}
+}
+
+// BenchmarkStatsFetch 比较 oneshot 与 stream 两种方式下单次采样的开销（需要本地 docker）：
+// go test -run '^$' -bench StatsFetch ./internal/monitor
+func BenchmarkStatsFetch(b *testing.B) {
+	requireDocker(b)
+	ctx := context.Background()
+	id := ensureAnyRunningContainer(b, ctx)
+	meta := containerMeta{ID: id}
+
+	b.Run("oneshot", func(b *testing.B) {
+		c := &StatsCollector{cfg: StatsConfig{}.withDefaults()}
+		for i := 0; i < b.N; i++ {
+			if _, err := c.defaultFetchStats(ctx, meta); err != nil {
+				b.Fatalf("fetch: %v", err)
+			}
+		}
+	})
+
+	b.Run("stream", func(b *testing.B) {
+		streamCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		c := &StatsCollector{cfg: StatsConfig{Mode: StatsModeStream}.withDefaults()}
+		c.streams = newStatsStreamer(streamCtx, defaultOpenStatsStream, func(err error) { b.Log(err) })
+		defer c.streams.closeAll()
+		for i := 0; i < b.N; i++ {
+			if _, err := c.streamFetchStats(ctx, meta); err != nil {
+				b.Fatalf("fetch: %v", err)
+			}
+		}
+	})
+}
+
+func TestLogCollector_ReloadRestartsTailersWithNewMaxLineBytes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := openTestStorage(t, ctx)
+
+	type follow struct {
+		since time.Time
+		w     *io.PipeWriter
+	}
+	follows := make(chan follow, 4)
+
+	c, err := NewLogCollector(store)
+	if err != nil {
+		t.Fatalf("new log collector: %v", err)
+	}
+	c.cfg = LogConfig{
+		FlushInterval: 10 * time.Millisecond,
+		MaxLineBytes:  64,
+		OnError:       func(err error) { t.Logf("logs error: %v", err) },
+	}
+	c.WithInspector(func(ctx context.Context, id string) (containerInspectInfo, error) {
+		return containerInspectInfo{name: "/app", tty: true}, nil
+	}).WithFollower(func(ctx context.Context, id string, since time.Time) (io.ReadCloser, error) {
+		pr, pw := io.Pipe()
+		go func() {
+			<-ctx.Done()
+			_ = pw.CloseWithError(ctx.Err())
+		}()
+		follows <- follow{since: since, w: pw}
+		return pr, nil
+	})
+	c.prepare(ctx)
+	writerDone := make(chan error, 1)
+	go func() { writerDone <- c.writeLoop(ctx) }()
+
+	waitLogs := func(contains string) {
+		t.Helper()
+		deadline := time.Now().Add(3 * time.Second)
+		for {
+			got, err := store.QueryContainerLogs(ctx, storage.LogQuery{ContainerID: "app1", Contains: contains})
+			if err != nil {
+				t.Fatalf("query logs: %v", err)
+			}
+			if len(got) > 0 {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("log %q was not collected", contains)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	c.startTailer(ctx, "app1", "", time.Time{})
+	first := <-follows
+	ts := time.Date(2024, 1, 2, 15, 4, 5, 123456789, time.UTC)
+	if _, err := fmt.Fprintf(first.w, "%s short line\n", ts.Format(time.RFC3339Nano)); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+	waitLogs("short line")
+
+	c.Reload(LogConfig{MaxLineBytes: 4096})
+
+	// 重启后的 tailer 从最后一行之后续读，并使用新的单行上限
+	var second follow
+	select {
+	case second = <-follows:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("tailer was not restarted after MaxLineBytes changed")
+	}
+	if want := ts.Add(time.Nanosecond); !second.since.Equal(want) {
+		t.Fatalf("expected restart to resume from %s, got %s", want, second.since)
+	}
+	c.tailersMu.Lock()
+	restarted := c.tailers["app1"]
+	c.tailersMu.Unlock()
+	if restarted == nil || restarted.maxLineBytes != 4096 || restarted.name != "/app" {
+		t.Fatalf("expected restarted tailer with new max line bytes, got %+v", restarted)
+	}
+
+	long := strings.Repeat("x", 200)
+	if _, err := fmt.Fprintf(second.w, "%s %s\n", ts.Add(time.Second).Format(time.RFC3339Nano), long); err != nil {
+		t.Fatalf("write long log: %v", err)
+	}
+	waitLogs(long)
+
+	// 只修改与 tailer 无关或未变化的配置时不重启
+	c.Reload(LogConfig{MaxLineBytes: 4096, SinceFromStart: true})
+	select {
+	case f := <-follows:
+		t.Fatalf("unexpected tailer restart (since %s)", f.since)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if !c.tailerConfig().SinceFromStart {
+		t.Fatalf("expected SinceFromStart to be updated")
+	}
+
+	// 等待 tailer 退出后再结束测试，避免退出时的错误回调晚于测试结束
+	cancel()
+	<-restarted.done
+	<-writerDone
+}
+
+func TestLogCollector_WriteLoopDropsDuplicateLines(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := openTestStorage(t, ctx)
+
+	c, err := NewLogCollector(store)
+	if err != nil {
+		t.Fatalf("new log collector: %v", err)
+	}
+	c.cfg = LogConfig{FlushInterval: 10 * time.Millisecond}
+	c.WithFollower(func(ctx context.Context, id string, since time.Time) (io.ReadCloser, error) {
+		return nil, errors.New("not used")
+	})
+	c.prepare(ctx)
+	writerDone := make(chan error, 1)
+	go func() { writerDone <- c.writeLoop(ctx) }()
+
+	// 模拟日志轮转后 follow 流重放：同一容器、同一时间戳、同一内容的行重复出现
+	ts := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	lines := []storage.ContainerLog{
+		{ContainerID: "c1", Source: "stdout", Message: "rotated", Timestamp: ts},
+		{ContainerID: "c1", Source: "stdout", Message: "rotated", Timestamp: ts},
+		{ContainerID: "c1", Source: "stdout", Message: "other", Timestamp: ts},
+		{ContainerID: "c2", Source: "stdout", Message: "rotated", Timestamp: ts},
+		{ContainerID: "c1", Source: "stdout", Message: "rotated", Timestamp: ts},
+	}
+	for _, l := range lines {
+		c.logCh <- l
+	}
+	cancel()
+	<-writerDone
+
+	got, err := store.QueryContainerLogs(context.Background(), storage.LogQuery{})
+	if err != nil {
+		t.Fatalf("query logs: %v", err)
+	}
+	count := map[string]int{}
+	for _, l := range got {
+		count[l.ContainerID+"/"+l.Message]++
+	}
+	want := map[string]int{"c1/rotated": 1, "c1/other": 1, "c2/rotated": 1}
+	if len(got) != 3 || count["c1/rotated"] != 1 || count["c1/other"] != 1 || count["c2/rotated"] != 1 {
+		t.Fatalf("expected %v, got %v", want, count)
+	}
+}
+
+func TestLogDedup_Window(t *testing.T) {
+	d := newLogDedup(time.Second)
+	now := time.Now()
+	rec := storage.ContainerLog{ContainerID: "c1", Message: "m", Timestamp: now}
+	if d.duplicate(rec, now) {
+		t.Fatalf("first line reported as duplicate")
+	}
+	if !d.duplicate(rec, now.Add(500*time.Millisecond)) {
+		t.Fatalf("expected duplicate within window")
+	}
+	if d.duplicate(rec, now.Add(2*time.Second)) {
+		t.Fatalf("expected line to be accepted again after window")
+	}
+	d.prune(now.Add(10 * time.Second))
+	if len(d.seen) != 0 {
+		t.Fatalf("expected prune to drop expired entries, got %d", len(d.seen))
+	}
+}
+
+func TestManager_PauseSkipsStatsSampling(t *testing.T) {
+	ctx := context.Background()
+	store := openTestStorage(t, ctx)
+
+	cfg := Config{}
+	cfg.Stats.Enabled = true
+	cfg.Stats.Interval = 20 * time.Millisecond
+	cfg.Stats.FlushInterval = 10 * time.Millisecond
+	mgr, err := NewManager(cfg)
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+
+	var fetched atomic.Int64
+	stats, err := NewStatsCollector(store)
+	if err != nil {
+		t.Fatalf("new stats collector: %v", err)
+	}
+	stats.WithLister(func(ctx context.Context) ([]containerMeta, error) {
+		return []containerMeta{{ID: "c1", Name: "/c1"}}, nil
+	}).WithFetcher(func(ctx context.Context, meta containerMeta) (storage.ContainerStat, error) {
+		fetched.Add(1)
+		return storage.ContainerStat{ContainerID: meta.ID, CollectedAt: time.Now().UTC()}, nil
+	})
+	mgr.WithStats(stats)
+
+	if !mgr.Pause() || mgr.Pause() {
+		t.Fatalf("expected only the first Pause to change state")
+	}
+	if err := mgr.Start(ctx); err != nil {
+		t.Fatalf("start manager: %v", err)
+	}
+	defer mgr.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+	if n := fetched.Load(); n != 0 {
+		t.Fatalf("expected no samples while paused, got %d", n)
+	}
+	st := mgr.Status()
+	if !st.Paused || len(st.Collectors) == 0 || st.Collectors[0].Name != "stats" || !st.Collectors[0].Running {
+		t.Fatalf("unexpected status while paused: %+v", st)
+	}
+
+	if !mgr.Resume() {
+		t.Fatalf("expected Resume to change state")
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for fetched.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("sampling did not resume")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLogCollector_FallsBackToPollingWhenEventsUnsupported(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := openTestStorage(t, ctx)
+
+	var watchCalls, degraded atomic.Int64
+	var started atomic.Bool
+	followed := make(chan string, 4)
+
+	c, err := NewLogCollector(store)
+	if err != nil {
+		t.Fatalf("new log collector: %v", err)
+	}
+	c.cfg = LogConfig{
+		FlushInterval:  10 * time.Millisecond,
+		ReconnectDelay: time.Millisecond,
+		OnError: func(err error) {
+			if errors.Is(err, errEventsUnsupported) {
+				degraded.Add(1)
+			}
+		},
+	}
+	c.pollInterval = 20 * time.Millisecond
+	c.WithEventSource(func(ctx context.Context) (<-chan events.Message, <-chan error) {
+		watchCalls.Add(1)
+		errCh := make(chan error, 1)
+		errCh <- fmt.Errorf("proxy rejected request: %w", errdefs.ErrNotImplemented)
+		return make(chan events.Message), errCh
+	}).WithRunningLister(func(ctx context.Context) ([]containerMeta, error) {
+		// 容器在事件不可用期间启动，只能通过轮询发现
+		if !started.Load() {
+			return nil, nil
+		}
+		return []containerMeta{{ID: "late1", Name: "/late"}}, nil
+	}).WithInspector(func(ctx context.Context, id string) (containerInspectInfo, error) {
+		return containerInspectInfo{name: "/late", tty: true}, nil
+	}).WithFollower(func(ctx context.Context, id string, since time.Time) (io.ReadCloser, error) {
+		followed <- id
+		pr, pw := io.Pipe()
+		go func() {
+			<-ctx.Done()
+			_ = pw.Close()
+		}()
+		return pr, nil
+	})
+
+	runDone := make(chan error, 1)
+	go func() { runDone <- c.Run(ctx) }()
+
+	time.Sleep(60 * time.Millisecond)
+	started.Store(true)
+
+	select {
+	case id := <-followed:
+		if id != "late1" {
+			t.Fatalf("unexpected tailed container %q", id)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("container started while events were unavailable was never tailed")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if n := watchCalls.Load(); n != 1 {
+		t.Fatalf("expected events to be requested once and not retried, got %d calls", n)
+	}
+	if n := degraded.Load(); n != 1 {
+		t.Fatalf("expected degradation to be reported once, got %d", n)
+	}
+
+	cancel()
+	if err := <-runDone; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
+
+func TestLogCollector_ReconcileTailsContainersMissedByEvents(t *testing.T) {
+	run := func(t *testing.T, interval time.Duration) bool {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		store := openTestStorage(t, ctx)
+
+		var started atomic.Bool
+		followed := make(chan string, 4)
+
+		c, err := NewLogCollector(store)
+		if err != nil {
+			t.Fatalf("new log collector: %v", err)
+		}
+		c.cfg = LogConfig{
+			FlushInterval:     10 * time.Millisecond,
+			ReconcileInterval: interval,
+			OnError:           func(err error) {},
+		}
+		// 事件流已连接但不再投递任何事件（例如 daemon 重启后事件丢失）
+		c.WithEventSource(func(ctx context.Context) (<-chan events.Message, <-chan error) {
+			return make(chan events.Message), make(chan error)
+		}).WithRunningLister(func(ctx context.Context) ([]containerMeta, error) {
+			if !started.Load() {
+				return nil, nil
+			}
+			return []containerMeta{{ID: "missed1", Name: "/missed"}}, nil
+		}).WithInspector(func(ctx context.Context, id string) (containerInspectInfo, error) {
+			return containerInspectInfo{name: "/missed", tty: true}, nil
+		}).WithFollower(func(ctx context.Context, id string, since time.Time) (io.ReadCloser, error) {
+			followed <- id
+			pr, pw := io.Pipe()
+			go func() {
+				<-ctx.Done()
+				_ = pw.Close()
+			}()
+			return pr, nil
+		})
+
+		runDone := make(chan error, 1)
+		go func() { runDone <- c.Run(ctx) }()
+		defer func() {
+			cancel()
+			if err := <-runDone; err != nil {
+				t.Fatalf("run: %v", err)
+			}
+		}()
+
+		time.Sleep(30 * time.Millisecond)
+		started.Store(true)
+
+		select {
+		case id := <-followed:
+			if id != "missed1" {
+				t.Fatalf("unexpected tailed container %q", id)
+			}
+			return true
+		case <-time.After(300 * time.Millisecond):
+			return false
+		}
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		if !run(t, 20*time.Millisecond) {
+			t.Fatalf("container started while events were down was not tailed after reconcile")
+		}
+	})
+	t.Run("disabled", func(t *testing.T) {
+		if run(t, -1) {
+			t.Fatalf("expected no reconcile when reconcile_interval is negative")
+		}
+	})
+}
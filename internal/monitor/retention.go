@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/wwwzy/CentAgent/internal/storage"
@@ -13,6 +14,13 @@ type RetentionCollector struct {
 	cfg RetentionConfig
 
 	store *storage.Storage
+
+	// deleted 为本轮清理已删除的行数，用于决定是否执行 WAL 检查点
+	deleted atomic.Int64
+
+	// runMu 使周期清理与 RunNow 串行执行，lastRun 为最近一次成功清理的时间（UnixNano）
+	runMu   sync.Mutex
+	lastRun atomic.Int64
 }
 
 func NewRetentionCollector(store *storage.Storage) (*RetentionCollector, error) {
@@ -26,9 +34,11 @@ func (c *RetentionCollector) Run(ctx context.Context) error {
 	if c == nil || c.store == nil {
 		return errors.New("retention collector not initialized")
 	}
+	c.runMu.Lock()
 	c.cfg = c.cfg.withDefaults()
+	c.runMu.Unlock()
 
-	if err := c.runOnce(ctx, time.Now().UTC()); err != nil && !errors.Is(err, context.Canceled) {
+	if err := c.runLocked(ctx); err != nil && !errors.Is(err, context.Canceled) {
 		return err
 	}
 
@@ -40,13 +50,44 @@ func (c *RetentionCollector) Run(ctx context.Context) error {
 		case <-ctx.Done():
 			return nil
 		case <-ticker.C:
-			if err := c.runOnce(ctx, time.Now().UTC()); err != nil && !errors.Is(err, context.Canceled) {
+			if err := c.runLocked(ctx); err != nil && !errors.Is(err, context.Canceled) {
 				return err
 			}
 		}
 	}
 }
 
+// RunNow 立即执行一次清理；与周期清理串行，正在清理时等待其完成后再执行
+func (c *RetentionCollector) RunNow(ctx context.Context) error {
+	if c == nil || c.store == nil {
+		return errors.New("retention collector not initialized")
+	}
+	c.runMu.Lock()
+	c.cfg = c.cfg.withDefaults()
+	c.runMu.Unlock()
+	return c.runLocked(ctx)
+}
+
+func (c *RetentionCollector) runLocked(ctx context.Context) error {
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+	now := time.Now().UTC()
+	if err := c.runOnce(ctx, now); err != nil {
+		return err
+	}
+	c.lastRun.Store(now.UnixNano())
+	return nil
+}
+
+// lastRunAt 返回最近一次成功清理的时间，尚未清理时返回零值
+func (c *RetentionCollector) lastRunAt() time.Time {
+	n := c.lastRun.Load()
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n).UTC()
+}
+
 // Prune 执行一次性全量清理，用于 CLI 调用
 func Prune(ctx context.Context, store *storage.Storage, cfg RetentionConfig) error {
 	rc, err := NewRetentionCollector(store)
@@ -63,6 +104,8 @@ func (c *RetentionCollector) runOnce(ctx context.Context, now time.Time) error {
 		return errors.New("retention collector not initialized")
 	}
 
+	c.deleted.Store(0)
+
 	var tasks []func(context.Context) error
 
 	statsCutAll := now.Add(-c.cfg.Stats.KeepAll)
@@ -73,6 +116,10 @@ func (c *RetentionCollector) runOnce(ctx context.Context, now time.Time) error {
 	tasks = append(tasks, func(ctx context.Context) error {
 		return c.deleteStatsNonAnomalyInRange(ctx, statsCutAnomaly, statsCutAll)
 	})
+	// 宿主机采样行数少，不做异常分层，按 stats 的最长保留窗口整体清理
+	tasks = append(tasks, func(ctx context.Context) error {
+		return c.deleteHostStatsBefore(ctx, statsCutAnomaly)
+	})
 
 	logsCutAll := now.Add(-c.cfg.Logs.KeepAll)
 	logsCutImportant := now.Add(-c.cfg.Logs.KeepImportantUntil)
@@ -83,6 +130,11 @@ func (c *RetentionCollector) runOnce(ctx context.Context, now time.Time) error {
 		return c.deleteLogsUnimportantInRange(ctx, logsCutImportant, logsCutAll)
 	})
 
+	sessionsCut := now.Add(-c.cfg.Sessions.KeepFor)
+	tasks = append(tasks, func(ctx context.Context) error {
+		return c.deleteChatSessionsBefore(ctx, sessionsCut)
+	})
+
 	workers := c.cfg.Workers
 	if workers > len(tasks) {
 		workers = len(tasks)
@@ -127,6 +179,23 @@ func (c *RetentionCollector) runOnce(ctx context.Context, now time.Time) error {
 			return err
 		}
 	}
+
+	// 最后按总行数上限兜底：按策略清理后仍超出的部分不论策略删除最旧的行
+	for _, trim := range []func(context.Context) error{c.trimStatsToMax, c.trimLogsToMax} {
+		if err := trim(ctx); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				c.cfg.OnError(err)
+			}
+			return err
+		}
+	}
+
+	// 大批量删除后 WAL 可能已增长到很大，截断以回收磁盘空间；检查点失败不影响下一轮清理
+	if n := c.cfg.CheckpointAfterRows; n > 0 && c.deleted.Load() >= int64(n) {
+		if _, err := c.store.Checkpoint(ctx, "TRUNCATE"); err != nil && !errors.Is(err, context.Canceled) {
+			c.cfg.OnError(err)
+		}
+	}
 	return nil
 }
 
@@ -139,6 +208,7 @@ func (c *RetentionCollector) deleteStatsBefore(ctx context.Context, before time.
 		if err != nil {
 			return err
 		}
+		c.deleted.Add(affected)
 		if affected == 0 {
 			return nil
 		}
@@ -160,6 +230,45 @@ func (c *RetentionCollector) deleteStatsNonAnomalyInRange(ctx context.Context, f
 		if err != nil {
 			return err
 		}
+		c.deleted.Add(affected)
+		if affected == 0 {
+			return nil
+		}
+		if err := c.sleepIdle(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *RetentionCollector) deleteHostStatsBefore(ctx context.Context, before time.Time) error {
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		affected, err := c.store.DeleteHostStatsBeforeLimited(ctx, before, c.cfg.BatchRows)
+		if err != nil {
+			return err
+		}
+		c.deleted.Add(affected)
+		if affected == 0 {
+			return nil
+		}
+		if err := c.sleepIdle(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *RetentionCollector) deleteChatSessionsBefore(ctx context.Context, before time.Time) error {
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		affected, err := c.store.DeleteChatSessionsBeforeLimited(ctx, before, c.cfg.BatchRows)
+		if err != nil {
+			return err
+		}
+		c.deleted.Add(affected)
 		if affected == 0 {
 			return nil
 		}
@@ -178,6 +287,7 @@ func (c *RetentionCollector) deleteLogsBefore(ctx context.Context, before time.T
 		if err != nil {
 			return err
 		}
+		c.deleted.Add(affected)
 		if affected == 0 {
 			return nil
 		}
@@ -199,6 +309,47 @@ func (c *RetentionCollector) deleteLogsUnimportantInRange(ctx context.Context, f
 		if err != nil {
 			return err
 		}
+		c.deleted.Add(affected)
+		if affected == 0 {
+			return nil
+		}
+		if err := c.sleepIdle(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *RetentionCollector) trimStatsToMax(ctx context.Context) error {
+	return c.trimToMax(ctx, c.cfg.Stats.MaxTotalRows, c.store.CountContainerStats, c.store.DeleteOldestContainerStatsLimited)
+}
+
+func (c *RetentionCollector) trimLogsToMax(ctx context.Context) error {
+	return c.trimToMax(ctx, c.cfg.Logs.MaxTotalRows, c.store.CountContainerLogs, c.store.DeleteOldestContainerLogsLimited)
+}
+
+// trimToMax 在总行数超过 maxRows 时分批删除最旧的行，直到不超过上限；maxRows<=0 表示不限制
+func (c *RetentionCollector) trimToMax(ctx context.Context, maxRows int64,
+	count func(context.Context) (int64, error), deleteOldest func(context.Context, int) (int64, error)) error {
+	if maxRows <= 0 {
+		return nil
+	}
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		total, err := count(ctx)
+		if err != nil {
+			return err
+		}
+		excess := total - maxRows
+		if excess <= 0 {
+			return nil
+		}
+		affected, err := deleteOldest(ctx, int(min(excess, int64(c.cfg.BatchRows))))
+		if err != nil {
+			return err
+		}
+		c.deleted.Add(affected)
 		if affected == 0 {
 			return nil
 		}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"strings"
+	"fmt"
 	"sync"
 	"time"
 
@@ -15,8 +15,9 @@ import (
 )
 
 type containerMeta struct {
-	ID   string
-	Name string
+	ID     string
+	Name   string
+	Labels map[string]string
 }
 
 type listContainersFunc func(ctx context.Context) ([]containerMeta, error)
@@ -26,9 +27,19 @@ type StatsCollector struct {
 	cfg StatsConfig
 
 	store *storage.Storage
+	alert *AlertCollector
 
 	list  listContainersFunc
 	fetch fetchStatsFunc
+
+	// paused 为 Manager 注入的暂停开关，暂停期间跳过采样周期
+	paused *pauser
+
+	// stream 模式下的常驻连接管理与可替换的连接/事件函数；streamsMu 保护 streams 的赋值，供 Status 并发读取
+	streamsMu  sync.Mutex
+	streams    *statsStreamer
+	openStream openStatsStreamFunc
+	watchStops watchStopsFunc
 }
 
 func NewStatsCollector(store *storage.Storage) (*StatsCollector, error) {
@@ -50,6 +61,18 @@ func (c *StatsCollector) WithFetcher(fn fetchStatsFunc) *StatsCollector {
 	return c
 }
 
+// WithStreamOpener 替换 stream 模式下建立 stats 流的函数
+func (c *StatsCollector) WithStreamOpener(fn openStatsStreamFunc) *StatsCollector {
+	c.openStream = fn
+	return c
+}
+
+// WithStopWatcher 替换 stream 模式下监听容器退出事件的函数
+func (c *StatsCollector) WithStopWatcher(fn watchStopsFunc) *StatsCollector {
+	c.watchStops = fn
+	return c
+}
+
 func (c *StatsCollector) Run(ctx context.Context) error {
 	if c == nil || c.store == nil {
 		return errors.New("stats collector not initialized")
@@ -63,6 +86,35 @@ func (c *StatsCollector) Run(ctx context.Context) error {
 	fetchFn := c.fetch
 	if fetchFn == nil {
 		fetchFn = c.defaultFetchStats
+		if c.cfg.Mode == StatsModeStream {
+			fetchFn = c.streamFetchStats
+		}
+	}
+
+	if c.cfg.Mode == StatsModeStream {
+		openFn := c.openStream
+		if openFn == nil {
+			openFn = defaultOpenStatsStream
+		}
+		watchFn := c.watchStops
+		if watchFn == nil {
+			watchFn = defaultWatchStops
+		}
+		c.streamsMu.Lock()
+		c.streams = newStatsStreamer(ctx, openFn, c.cfg.OnError)
+		c.streamsMu.Unlock()
+		defer c.streams.closeAll()
+
+		watchCtx, stopWatch := context.WithCancel(ctx)
+		watchDone := make(chan struct{})
+		go func() {
+			defer close(watchDone)
+			c.streams.watchStopsLoop(watchCtx, watchFn, c.cfg.Interval)
+		}()
+		defer func() {
+			stopWatch()
+			<-watchDone
+		}()
 	}
 
 	jobs := make(chan containerMeta, c.cfg.QueueSize)
@@ -81,9 +133,9 @@ func (c *StatsCollector) Run(ctx context.Context) error {
 					if !ok {
 						return
 					}
-					stat, err := fetchFn(ctx, job)
+					stat, err := c.fetchWithTimeout(ctx, fetchFn, job)
 					if err != nil {
-						c.cfg.OnError(err)
+						c.cfg.OnError(&ContainerError{ContainerID: job.ID, Op: "fetch stats", Err: err})
 						continue
 					}
 					select {
@@ -106,7 +158,9 @@ func (c *StatsCollector) Run(ctx context.Context) error {
 	ticker := time.NewTicker(c.cfg.Interval)
 	defer ticker.Stop()
 
-	c.enqueueOnce(ctx, listFn, jobs)
+	if !c.paused.isPaused() {
+		c.enqueueOnce(ctx, listFn, jobs)
+	}
 
 	for {
 		select {
@@ -120,17 +174,47 @@ func (c *StatsCollector) Run(ctx context.Context) error {
 			}
 			return writerErr
 		case <-ticker.C:
+			if c.paused.isPaused() {
+				continue
+			}
 			c.enqueueOnce(ctx, listFn, jobs)
 		}
 	}
 }
 
+// streamCount 返回 stream 模式下打开的连接数
+func (c *StatsCollector) streamCount() int {
+	c.streamsMu.Lock()
+	defer c.streamsMu.Unlock()
+	if c.streams == nil {
+		return 0
+	}
+	return c.streams.count()
+}
+
+// fetchWithTimeout 为单个容器的采样加上 FetchTimeout，避免某个容器的 stats 调用卡住 worker 导致队列积压
+func (c *StatsCollector) fetchWithTimeout(ctx context.Context, fetchFn fetchStatsFunc, meta containerMeta) (storage.ContainerStat, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, c.cfg.FetchTimeout)
+	defer cancel()
+
+	stat, err := fetchFn(fetchCtx, meta)
+	if err != nil && ctx.Err() == nil && errors.Is(fetchCtx.Err(), context.DeadlineExceeded) {
+		return stat, fmt.Errorf("fetch stats for %s timed out after %s: %w", meta.ID, c.cfg.FetchTimeout, err)
+	}
+	return stat, err
+}
+
 func (c *StatsCollector) enqueueOnce(ctx context.Context, listFn listContainersFunc, jobs chan<- containerMeta) {
-	containers, err := listFn(ctx)
+	listCtx, cancel := context.WithTimeout(ctx, c.cfg.FetchTimeout)
+	containers, err := listFn(listCtx)
+	cancel()
 	if err != nil {
 		c.cfg.OnError(err)
 		return
 	}
+	if c.streams != nil {
+		c.streams.retain(containers)
+	}
 
 	for _, meta := range containers {
 		select {
@@ -146,24 +230,39 @@ func (c *StatsCollector) writeLoop(ctx context.Context, results <-chan storage.C
 	defer flushTicker.Stop()
 
 	buf := make([]storage.ContainerStat, 0, c.cfg.BatchSize)
-	flush := func() error {
+	flushTo := func(ctx context.Context) error {
 		if len(buf) == 0 {
 			return nil
 		}
-		err := c.store.InsertContainerStats(ctx, buf)
+		var err error
+		if c.cfg.Transactional {
+			err = c.store.InsertStatsTx(ctx, buf)
+		} else {
+			err = c.store.InsertContainerStats(ctx, buf)
+		}
 		buf = buf[:0]
 		return err
 	}
+	flush := func() error { return flushTo(ctx) }
 
 	for {
 		select {
 		case <-ctx.Done():
-			_ = flush()
+			// 停止时收完 worker 已产出的结果（Run 在 worker 退出后关闭 results），
+			// 并以不随 ctx 取消的 context 落库最后一批，避免丢失缓冲中的数据
+			for stat := range results {
+				c.alert.Observe(stat)
+				buf = append(buf, stat)
+			}
+			if err := flushTo(context.WithoutCancel(ctx)); err != nil {
+				c.cfg.OnError(fmt.Errorf("final stats flush: %w", err))
+			}
 			return ctx.Err()
 		case stat, ok := <-results:
 			if !ok {
 				return flush()
 			}
+			c.alert.Observe(stat)
 			buf = append(buf, stat)
 			if len(buf) >= c.cfg.BatchSize {
 				if err := flush(); err != nil {
@@ -179,19 +278,28 @@ func (c *StatsCollector) writeLoop(ctx context.Context, results <-chan storage.C
 }
 
 func (c *StatsCollector) defaultListContainers(ctx context.Context) ([]containerMeta, error) {
-	containers, err := docker.ListContainers(ctx, docker.ListContainersOptions{All: false})
+	containers, err := docker.ListContainers(ctx, docker.ListContainersOptions{All: false, Labels: c.cfg.CaptureLabels})
 	if err != nil {
 		return nil, err
 	}
+	return statsTargets(containers), nil
+}
 
+// statsTargets 将容器列表转换为采样目标；跳过已暂停的容器，
+// 其 stats 为全零或报错，会拉低聚合结果（All:false 的列表仍包含 paused 容器）。
+func statsTargets(containers []docker.ContainerSummary) []containerMeta {
 	out := make([]containerMeta, 0, len(containers))
 	for _, item := range containers {
+		if item.State == "paused" {
+			continue
+		}
 		out = append(out, containerMeta{
-			ID:   item.ID,
-			Name: item.Names,
+			ID:     item.ID,
+			Name:   item.Names,
+			Labels: item.Labels,
 		})
 	}
-	return out, nil
+	return out
 }
 
 func (c *StatsCollector) defaultFetchStats(ctx context.Context, meta containerMeta) (storage.ContainerStat, error) {
@@ -206,71 +314,61 @@ func (c *StatsCollector) defaultFetchStats(ctx context.Context, meta containerMe
 	if err := dec.Decode(&stats); err != nil {
 		return storage.ContainerStat{}, err
 	}
+	return c.statFromResponse(meta, stats), nil
+}
 
-	rawJSON, _ := json.Marshal(stats)
-	if c.cfg.MaxRawJSONBytes > 0 && len(rawJSON) > c.cfg.MaxRawJSONBytes {
-		rawJSON = []byte(`{"_truncated":true}`)
+// streamFetchStats 从容器的常驻 stats 流中取最新一帧，首次采样时建立连接
+func (c *StatsCollector) streamFetchStats(ctx context.Context, meta containerMeta) (storage.ContainerStat, error) {
+	stats, err := c.streams.latest(ctx, meta.ID)
+	if err != nil {
+		return storage.ContainerStat{}, err
 	}
+	return c.statFromResponse(meta, stats), nil
+}
 
-	cpuPercent := calculateCPUPercent(stats)
-	memUsage := uint64(stats.MemoryStats.Usage)
-	memLimit := uint64(stats.MemoryStats.Limit)
-	memPercent := 0.0
-	if memLimit > 0 {
-		memPercent = (float64(memUsage) / float64(memLimit)) * 100.0
+// statFromResponse 按 Collect 只计算需要的指标，未采集的字段保持零值
+func (c *StatsCollector) statFromResponse(meta containerMeta, stats container.StatsResponse) storage.ContainerStat {
+	out := storage.ContainerStat{
+		ContainerID:   meta.ID,
+		ContainerName: meta.Name,
+		CollectedAt:   time.Now(),
 	}
-
-	var netRx, netTx uint64
-	for _, nw := range stats.Networks {
-		netRx += uint64(nw.RxBytes)
-		netTx += uint64(nw.TxBytes)
+	if !stats.Read.IsZero() {
+		out.CollectedAt = stats.Read
 	}
 
-	var blkRead, blkWrite uint64
-	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
-		switch strings.ToLower(entry.Op) {
-		case "read":
-			blkRead += uint64(entry.Value)
-		case "write":
-			blkWrite += uint64(entry.Value)
+	if c.cfg.collects(StatsFieldCPU) {
+		out.CPUPercent = docker.CalculateCPUPercent(stats)
+	}
+	if c.cfg.collects(StatsFieldMem) {
+		out.MemUsageBytes = stats.MemoryStats.Usage
+		out.MemLimitBytes = stats.MemoryStats.Limit
+		if out.MemLimitBytes > 0 {
+			out.MemPercent = (float64(out.MemUsageBytes) / float64(out.MemLimitBytes)) * 100.0
 		}
 	}
-
-	collectedAt := time.Now()
-	if !stats.Read.IsZero() {
-		collectedAt = stats.Read
+	if c.cfg.collects(StatsFieldNet) {
+		out.NetRxBytes, out.NetTxBytes = docker.NetworkIO(stats)
+	}
+	if c.cfg.collects(StatsFieldBlock) {
+		out.BlockReadBytes, out.BlockWriteBytes = docker.BlockIO(stats)
+	}
+	if c.cfg.collects(StatsFieldPids) {
+		out.Pids = stats.PidsStats.Current
 	}
 
-	return storage.ContainerStat{
-		ContainerID:     meta.ID,
-		ContainerName:   meta.Name,
-		CPUPercent:      cpuPercent,
-		MemUsageBytes:   memUsage,
-		MemLimitBytes:   memLimit,
-		MemPercent:      memPercent,
-		NetRxBytes:      netRx,
-		NetTxBytes:      netTx,
-		BlockReadBytes:  blkRead,
-		BlockWriteBytes: blkWrite,
-		Pids:            uint64(stats.PidsStats.Current),
-		RawJSON:         string(rawJSON),
-		CollectedAt:     collectedAt,
-	}, nil
-}
-
-func calculateCPUPercent(stats container.StatsResponse) float64 {
-	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
-	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
-	if cpuDelta <= 0 || systemDelta <= 0 {
-		return 0
+	if c.cfg.StoreRawJSON && c.cfg.collects(StatsFieldRawJSON) {
+		rawJSON, _ := json.Marshal(stats)
+		if c.cfg.MaxRawJSONBytes > 0 && len(rawJSON) > c.cfg.MaxRawJSONBytes {
+			rawJSON = []byte(`{"_truncated":true}`)
+		}
+		out.RawJSON = string(rawJSON)
 	}
-	onlineCPUs := float64(stats.CPUStats.OnlineCPUs)
-	if onlineCPUs <= 0 {
-		if n := len(stats.CPUStats.CPUUsage.PercpuUsage); n > 0 {
-			onlineCPUs = float64(n)
-		} else {
-			onlineCPUs = 1
+
+	if c.cfg.CaptureLabels && len(meta.Labels) > 0 {
+		if b, err := json.Marshal(meta.Labels); err == nil {
+			out.Labels = string(b)
 		}
 	}
-	return (cpuDelta / systemDelta) * onlineCPUs * 100.0
+	return out
 }
@@ -0,0 +1,273 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/wwwzy/CentAgent/internal/docker"
+)
+
+type openStatsStreamFunc func(ctx context.Context, containerID string) (io.ReadCloser, error)
+
+// watchStopsFunc 监听容器退出事件，对每个退出的容器调用 onStop；返回表示监听中断，由调用方重连
+type watchStopsFunc func(ctx context.Context, onStop func(containerID string)) error
+
+// statsStream 为单个容器的常驻 stats 流；读取协程持续解码并只保留最新一帧，
+// 避免未读取的帧在连接中积压导致读到过期数据。
+type statsStream struct {
+	cancel context.CancelFunc
+	// ready 在收到第一帧后关闭，done 在读取协程退出后关闭
+	ready chan struct{}
+	done  chan struct{}
+
+	mu     sync.Mutex
+	latest container.StatsResponse
+	err    error
+}
+
+// statsStreamer 管理 stream 模式下每个容器一条的 stats 连接：按需建立、流结束后移除（下次采样时重连），
+// 容器退出或不再出现在列表中时关闭。
+type statsStreamer struct {
+	ctx     context.Context
+	open    openStatsStreamFunc
+	onError ErrorHandler
+
+	mu      sync.Mutex
+	streams map[string]*statsStream
+	wg      sync.WaitGroup
+}
+
+func newStatsStreamer(ctx context.Context, open openStatsStreamFunc, onError ErrorHandler) *statsStreamer {
+	return &statsStreamer{
+		ctx:     ctx,
+		open:    open,
+		onError: onError,
+		streams: make(map[string]*statsStream),
+	}
+}
+
+// latest 返回容器最新的一帧；连接不存在时建立连接并等待第一帧，等待时长由 ctx 控制
+func (s *statsStreamer) latest(ctx context.Context, containerID string) (container.StatsResponse, error) {
+	st, err := s.get(containerID)
+	if err != nil {
+		return container.StatsResponse{}, err
+	}
+
+	select {
+	case <-st.ready:
+	case <-st.done:
+	case <-ctx.Done():
+		return container.StatsResponse{}, fmt.Errorf("wait for first stats frame: %w", ctx.Err())
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	select {
+	case <-st.ready:
+		return st.latest, nil
+	default:
+	}
+	if st.err != nil {
+		return container.StatsResponse{}, st.err
+	}
+	return container.StatsResponse{}, errors.New("stats stream closed before first frame")
+}
+
+func (s *statsStreamer) get(containerID string) (*statsStream, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.streams[containerID]; ok {
+		return st, nil
+	}
+	if s.ctx.Err() != nil {
+		return nil, s.ctx.Err()
+	}
+
+	streamCtx, cancel := context.WithCancel(s.ctx)
+	body, err := s.open(streamCtx, containerID)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("open stats stream: %w", err)
+	}
+	st := &statsStream{
+		cancel: cancel,
+		ready:  make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	s.streams[containerID] = st
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer close(st.done)
+		defer body.Close()
+		err := st.read(body)
+		s.remove(containerID, st)
+		if err != nil && streamCtx.Err() == nil {
+			s.onError(&ContainerError{ContainerID: containerID, Op: "stats stream", Err: err})
+		}
+	}()
+	return st, nil
+}
+
+func (st *statsStream) read(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	first := true
+	for {
+		var frame container.StatsResponse
+		if err := dec.Decode(&frame); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			st.mu.Lock()
+			st.err = fmt.Errorf("decode stats frame: %w", err)
+			st.mu.Unlock()
+			return st.err
+		}
+		st.mu.Lock()
+		st.latest = frame
+		st.mu.Unlock()
+		if first {
+			close(st.ready)
+			first = false
+		}
+	}
+}
+
+// remove 从表中移除已结束的流；仅当表中仍是同一条流时移除，避免误删重连后的新流
+func (s *statsStreamer) remove(containerID string, st *statsStream) {
+	s.mu.Lock()
+	if cur, ok := s.streams[containerID]; ok && cur == st {
+		delete(s.streams, containerID)
+	}
+	s.mu.Unlock()
+}
+
+// close 关闭容器的 stats 流（容器退出时调用）；列表中的 ID 可能被截断，事件中为完整 ID，按前缀匹配
+func (s *statsStreamer) close(containerID string) {
+	if containerID == "" {
+		return
+	}
+	s.mu.Lock()
+	var closed []*statsStream
+	for id, st := range s.streams {
+		if strings.HasPrefix(containerID, id) || strings.HasPrefix(id, containerID) {
+			delete(s.streams, id)
+			closed = append(closed, st)
+		}
+	}
+	s.mu.Unlock()
+	for _, st := range closed {
+		st.cancel()
+	}
+}
+
+// retain 关闭不在 targets 中的流，作为事件丢失时的兜底
+func (s *statsStreamer) retain(targets []containerMeta) {
+	keep := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		keep[t.ID] = true
+	}
+	s.mu.Lock()
+	var stale []string
+	for id := range s.streams {
+		if !keep[id] {
+			stale = append(stale, id)
+		}
+	}
+	s.mu.Unlock()
+	for _, id := range stale {
+		s.close(id)
+	}
+}
+
+// closeAll 关闭全部流并等待读取协程退出
+func (s *statsStreamer) closeAll() {
+	s.mu.Lock()
+	streams := s.streams
+	s.streams = make(map[string]*statsStream)
+	s.mu.Unlock()
+	for _, st := range streams {
+		st.cancel()
+	}
+	s.wg.Wait()
+}
+
+// count 返回当前打开的流数量
+func (s *statsStreamer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.streams)
+}
+
+// watchStopsLoop 持续监听容器退出事件并关闭对应的流，监听中断时按 retryDelay 重连；
+// 事件接口不可用时上报一次后不再重试，已退出容器的流改由每轮的容器列表对账关闭
+func (s *statsStreamer) watchStopsLoop(ctx context.Context, watch watchStopsFunc, retryDelay time.Duration) {
+	for ctx.Err() == nil {
+		err := watch(ctx, s.close)
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, errEventsUnsupported) {
+			s.onError(fmt.Errorf("stats stream events: %w", err))
+			return
+		}
+		if err != nil {
+			s.onError(fmt.Errorf("stats stream events: %w", err))
+		}
+		timer := time.NewTimer(retryDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func defaultOpenStatsStream(ctx context.Context, containerID string) (io.ReadCloser, error) {
+	resp, err := docker.GetContainerStats(ctx, containerID, true)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func defaultWatchStops(ctx context.Context, onStop func(containerID string)) error {
+	args := filters.NewArgs()
+	args.Add("type", "container")
+	args.Add("event", "die")
+	args.Add("event", "destroy")
+	msgCh, errCh := docker.Events(ctx, events.ListOptions{Filters: args})
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err, ok := <-errCh:
+			if !ok {
+				return errors.New("events stream closed")
+			}
+			if docker.IsUnsupported(err) {
+				return fmt.Errorf("%w: %w", errEventsUnsupported, err)
+			}
+			return err
+		case msg, ok := <-msgCh:
+			if !ok {
+				return errors.New("events stream closed")
+			}
+			if msg.Actor.ID != "" {
+				onStop(msg.Actor.ID)
+			}
+		}
+	}
+}
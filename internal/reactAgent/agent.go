@@ -2,9 +2,14 @@ package reactAgent
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/flow/agent/react"
+	"github.com/cloudwego/eino/schema"
+	"github.com/wwwzy/CentAgent/internal/agent"
 	"github.com/wwwzy/CentAgent/internal/storage"
 )
 
@@ -14,34 +19,64 @@ type ArkConfig struct {
 	BaseURL string `mapstructure:"base_url"`
 }
 
-func BuildAgent(ctx context.Context, arkConfig ArkConfig, store *storage.Storage) (*react.Agent, error) {
+// defaultMaxStep 为未配置 MaxStep 时的最大步数
+const defaultMaxStep = 20
+
+// Options 控制 ReAct Agent 的工具授权与执行步数
+type Options struct {
+	// AllowMutating 为 false 时，变更类工具（启停容器、删除镜像等）不会执行，而是返回拒绝说明；只读工具照常执行
+	AllowMutating bool
+	// MaxStep 为最大步数，<=0 时使用 defaultMaxStep；过小会截断正常的多步工具调用
+	MaxStep int
+	// Tools 为工具的 allow/deny 配置，与交互式 Agent 共用 agent.tools
+	Tools agent.ToolsConfig
+	// SystemPrompt 为系统提示词（见 agent.LoadSystemPrompt），为空时使用内置提示词
+	SystemPrompt string
+}
+
+func BuildAgent(ctx context.Context, arkConfig ArkConfig, store *storage.Storage, opts Options) (*react.Agent, error) {
 	chatModel, err := NewChatModel(ctx, arkConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	toolsInfo, err := GetToolsInfo(ctx, store)
-	if err != nil {
-		return nil, err
+	tools := buildTools(store, opts)
+	toolsInfo := make([]*schema.ToolInfo, 0, len(tools))
+	for _, t := range tools {
+		info, err := t.Info(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tool info: %w", err)
+		}
+		toolsInfo = append(toolsInfo, info)
 	}
 	toolCallingModel, err := chatModel.WithTools(toolsInfo)
 	if err != nil {
 		return nil, err
 	}
 
-	tools := compose.ToolsNodeConfig{
-		Tools: GetTools(store),
+	ra, err := react.NewAgent(ctx, newAgentConfig(toolCallingModel, compose.ToolsNodeConfig{Tools: tools}, opts))
+	if err != nil {
+		return nil, err
 	}
+	return ra, nil
+}
+
+// buildTools 返回 ReAct Agent 可用的工具：与交互式 Agent 共用同一套工具、包装（校验/dry-run/审计）与 allow/deny 过滤，
+// 未授权时变更类工具替换为拒绝执行的包装
+func buildTools(store *storage.Storage, opts Options) []tool.BaseTool {
+	return guardTools(agent.GetTools(store, opts.Tools), opts.AllowMutating)
+}
 
-	agent, err := react.NewAgent(ctx, &react.AgentConfig{
+func newAgentConfig(toolCallingModel model.ToolCallingChatModel, tools compose.ToolsNodeConfig, opts Options) *react.AgentConfig {
+	maxStep := opts.MaxStep
+	if maxStep <= 0 {
+		maxStep = defaultMaxStep
+	}
+	return &react.AgentConfig{
 		ToolCallingModel: toolCallingModel,
 		ToolsConfig:      tools,
-		MessageModifier:  MessageModify,
+		MessageModifier:  NewMessageModifier(opts.SystemPrompt),
 		//MessageRewriter: MessageRewrite,
-		MaxStep: 20,
-	})
-	if err != nil {
-		return nil, err
+		MaxStep: maxStep,
 	}
-	return agent, nil
 }
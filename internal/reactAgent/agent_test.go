@@ -0,0 +1,84 @@
+package reactAgent
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/require"
+	"github.com/wwwzy/CentAgent/internal/agent"
+)
+
+func TestNewAgentConfig_MaxStep(t *testing.T) {
+	cfg := newAgentConfig(nil, compose.ToolsNodeConfig{}, Options{MaxStep: 35})
+	require.Equal(t, 35, cfg.MaxStep)
+
+	cfg = newAgentConfig(nil, compose.ToolsNodeConfig{}, Options{})
+	require.Equal(t, defaultMaxStep, cfg.MaxStep)
+}
+
+// namedTool 为只提供名称的测试工具
+type namedTool struct{ name string }
+
+func (t *namedTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: t.name}, nil
+}
+
+func (t *namedTool) InvokableRun(_ context.Context, _ string, _ ...tool.Option) (string, error) {
+	return "executed", nil
+}
+
+func TestGuardTools_RefusedToolReturnsFailedResult(t *testing.T) {
+	ctx := context.Background()
+	tools := guardTools([]tool.BaseTool{&namedTool{name: "stop_container"}}, false)
+
+	out, err := tools[0].(tool.InvokableTool).InvokableRun(ctx, `{"container_id":"web"}`)
+	require.NoError(t, err)
+	res, err := agent.ParseToolResult(out)
+	require.NoError(t, err)
+	require.False(t, res.OK)
+	require.Contains(t, res.Message, "stop_container")
+}
+
+func TestBuildTools_AppliesDenyList(t *testing.T) {
+	ctx := context.Background()
+	tools := buildTools(nil, Options{Tools: agent.ToolsConfig{Deny: []string{"remove_image"}}})
+
+	names := make(map[string]bool, len(tools))
+	for _, bt := range tools {
+		info, err := bt.Info(ctx)
+		require.NoError(t, err)
+		names[info.Name] = true
+	}
+	require.False(t, names["remove_image"], "denied tool must not be offered to the ask agent")
+	require.True(t, names["list_containers"])
+	require.True(t, names["container_health"])
+
+	// 即使允许变更类工具，deny 列表依然生效
+	tools = buildTools(nil, Options{AllowMutating: true, Tools: agent.ToolsConfig{Deny: []string{"remove_image"}}})
+	for _, bt := range tools {
+		info, err := bt.Info(ctx)
+		require.NoError(t, err)
+		require.NotEqual(t, "remove_image", info.Name)
+	}
+}
+
+func TestNewMessageModifier_UsesCustomPrompt(t *testing.T) {
+	ctx := context.Background()
+	input := []*schema.Message{schema.UserMessage("hi")}
+
+	out := NewMessageModifier("你是运维助手，运行在 {os}")(ctx, input)
+	require.Len(t, out, 2)
+	require.Equal(t, schema.System, out[0].Role)
+	require.Equal(t, "你是运维助手，运行在 "+runtime.GOOS, out[0].Content)
+
+	// 已有 system message 时不重复添加
+	require.Len(t, NewMessageModifier("custom")(ctx, out), 2)
+
+	// 未指定时使用内置提示词
+	out = NewMessageModifier("")(ctx, input)
+	require.Equal(t, MessageModify(ctx, input)[0].Content[:20], out[0].Content[:20])
+}
@@ -7,29 +7,29 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cloudwego/eino/flow/agent/react"
 	"github.com/cloudwego/eino/schema"
+	"github.com/wwwzy/CentAgent/internal/agent"
 )
 
-const systemPromptTemplate = `你是一名专业的 Docker 智能助手 CentAgent。
-你的目标是帮助用户管理、监控和诊断 Docker 容器问题。
-
-当前运行环境:
-- 操作系统: {os}
-- 架构: {arch}
-- 系统时间: {time}
-
-你需要遵循以下原则:
-1. 在执行删除、停止等高风险操作前，必须明确告知用户风险。
-2. 如果用户询问日志，优先查看最近的异常日志。
-3. 回答要简洁明了，命令输出如果过长，请进行摘要。
-4. 如果遇到无法解决的问题，建议用户查阅官方文档。
+// MessageModify 为使用内置系统提示词的 MessageModifier，见 NewMessageModifier
+func MessageModify(ctx context.Context, input []*schema.Message) []*schema.Message {
+	return modifyMessages(input, agent.SystemPromptTemplate)
+}
 
-你可以使用的工具包括 Docker 容器管理、镜像管理、网络管理等。
-请根据用户的输入，选择合适的工具或直接回答。`
+// NewMessageModifier 返回 MessageModifier：会在每次把所有历史消息传递给 ChatModel 之前执行，
+// 修正不合法的工具调用参数，并在没有 system message 时以 systemPrompt 添加前置的 system message；
+// systemPrompt 为空时使用内置提示词（见 agent.LoadSystemPrompt）
+func NewMessageModifier(systemPrompt string) react.MessageModifier {
+	if strings.TrimSpace(systemPrompt) == "" {
+		systemPrompt = agent.SystemPromptTemplate
+	}
+	return func(_ context.Context, input []*schema.Message) []*schema.Message {
+		return modifyMessages(input, systemPrompt)
+	}
+}
 
-// MessageModifier 会在每次把所有历史消息传递给 ChatModel 之前执行
-// 常用于添加前置的 system message
-func MessageModify(ctx context.Context, input []*schema.Message) []*schema.Message {
+func modifyMessages(input []*schema.Message, systemPrompt string) []*schema.Message {
 	sanitized := input
 	changed := false
 	for i, m := range input {
@@ -75,7 +75,8 @@ func MessageModify(ctx context.Context, input []*schema.Message) []*schema.Messa
 		"{os}", runtime.GOOS,
 		"{arch}", runtime.GOARCH,
 		"{time}", time.Now().Format(time.RFC3339),
-	).Replace(systemPromptTemplate)
+		"{current_container}", "无",
+	).Replace(systemPrompt)
 
 	sys := schema.SystemMessage(content)
 	out := make([]*schema.Message, 0, len(sanitized)+1)
@@ -74,7 +74,7 @@ func TestReActAgent_RealModel_PrintAllMessages(t *testing.T) {
 		APIKey:  apiKey,
 		ModelID: modelID,
 		BaseURL: base_url,
-	}, nil)
+	}, nil, Options{AllowMutating: true})
 	require.NoError(t, err)
 
 	var (
@@ -0,0 +1,50 @@
+package reactAgent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"github.com/wwwzy/CentAgent/internal/agent"
+)
+
+// refusedTool 包装变更类工具：不执行，直接返回拒绝说明，便于模型向用户解释
+type refusedTool struct {
+	impl tool.BaseTool
+	name string
+}
+
+// guardTools 在未授权时将变更类工具替换为拒绝执行的包装
+func guardTools(tools []tool.BaseTool, allowMutating bool) []tool.BaseTool {
+	if allowMutating {
+		return tools
+	}
+	out := make([]tool.BaseTool, len(tools))
+	for i, t := range tools {
+		out[i] = t
+		info, err := t.Info(context.Background())
+		if err != nil || info == nil || !agent.IsMutatingTool(info.Name) {
+			continue
+		}
+		out[i] = &refusedTool{impl: t, name: info.Name}
+	}
+	return out
+}
+
+func (t *refusedTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return t.impl.Info(ctx)
+}
+
+// InvokableRun 返回 ok=false 的工具结果信封，与其他工具的输出格式一致
+func (t *refusedTool) InvokableRun(_ context.Context, _ string, _ ...tool.Option) (string, error) {
+	out, err := json.Marshal(agent.ToolResult{
+		OK:      false,
+		Message: fmt.Sprintf("refused: %s 会修改 Docker 状态，当前未授权执行（非交互模式下需使用 --yes）。请告知用户需要执行的操作。", t.name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return string(out), nil
+}
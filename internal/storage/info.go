@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TableInfo 为单张表的行数、磁盘占用与数据时间范围。
+type TableInfo struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+	// TableBytes/IndexBytes 为表与其索引占用的字节数（来自 dbstat）；dbstat 不可用时为 -1。
+	TableBytes int64 `json:"table_bytes"`
+	IndexBytes int64 `json:"index_bytes"`
+	// Oldest/Newest 为表中最早/最新一条记录的时间，空表时为 nil。
+	Oldest *time.Time `json:"oldest,omitempty"`
+	Newest *time.Time `json:"newest,omitempty"`
+}
+
+// DatabaseInfo 为数据库整体的页统计与各表概况。
+type DatabaseInfo struct {
+	PageSize  int64 `json:"page_size"`
+	PageCount int64 `json:"page_count"`
+	FreePages int64 `json:"free_pages"`
+	// DBStat 表示 SQLite 是否编译了 dbstat 虚拟表（决定能否统计每张表的大小）。
+	DBStat bool        `json:"dbstat"`
+	Tables []TableInfo `json:"tables"`
+}
+
+// infoTables 为需要统计的表及其时间列。
+var infoTables = []struct {
+	model      any
+	table      string
+	timeColumn string
+}{
+	{&ContainerStat{}, "container_stats", "collected_at"},
+	{&ContainerLog{}, "container_logs", "timestamp"},
+	{&AuditRecord{}, "audit_records", "created_at"},
+	{&DockerEvent{}, "docker_events", "timestamp"},
+	{&HostStat{}, "host_stats", "collected_at"},
+	{&ChatSession{}, "chat_sessions", "updated_at"},
+}
+
+// Info 统计数据库页信息与各表的行数、大小和时间范围；dbstat 不可用时仅跳过大小统计。
+func (s *Storage) Info(ctx context.Context) (*DatabaseInfo, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
+	}
+	db := s.db.WithContext(ctx)
+
+	out := &DatabaseInfo{}
+	if err := db.Raw("PRAGMA page_size").Scan(&out.PageSize).Error; err != nil {
+		return nil, fmt.Errorf("read page_size: %w", err)
+	}
+	if err := db.Raw("PRAGMA page_count").Scan(&out.PageCount).Error; err != nil {
+		return nil, fmt.Errorf("read page_count: %w", err)
+	}
+	if err := db.Raw("PRAGMA freelist_count").Scan(&out.FreePages).Error; err != nil {
+		return nil, fmt.Errorf("read freelist_count: %w", err)
+	}
+
+	var probe int64
+	out.DBStat = db.Raw("SELECT COUNT(*) FROM dbstat WHERE name = 'sqlite_master'").Scan(&probe).Error == nil
+
+	for _, t := range infoTables {
+		ti := TableInfo{Name: t.table, TableBytes: -1, IndexBytes: -1}
+		if err := db.Model(t.model).Count(&ti.Rows).Error; err != nil {
+			return nil, fmt.Errorf("count %s: %w", t.table, err)
+		}
+
+		if out.DBStat {
+			var sizes struct {
+				TableBytes int64
+				IndexBytes int64
+			}
+			err := db.Raw(`SELECT
+				COALESCE(SUM(CASE WHEN d.name = ? THEN d.pgsize END), 0) AS table_bytes,
+				COALESCE(SUM(CASE WHEN d.name <> ? THEN d.pgsize END), 0) AS index_bytes
+				FROM dbstat d JOIN sqlite_master m ON m.name = d.name
+				WHERE m.tbl_name = ?`, t.table, t.table, t.table).Scan(&sizes).Error
+			if err != nil {
+				return nil, fmt.Errorf("read %s size: %w", t.table, err)
+			}
+			ti.TableBytes, ti.IndexBytes = sizes.TableBytes, sizes.IndexBytes
+		}
+
+		if ti.Rows > 0 {
+			oldest, err := s.boundaryTime(ctx, t.model, t.timeColumn, "ASC")
+			if err != nil {
+				return nil, err
+			}
+			newest, err := s.boundaryTime(ctx, t.model, t.timeColumn, "DESC")
+			if err != nil {
+				return nil, err
+			}
+			ti.Oldest, ti.Newest = oldest, newest
+		}
+		out.Tables = append(out.Tables, ti)
+	}
+	return out, nil
+}
+
+// boundaryTime 返回时间列的最早/最新值；按列排序取一条，保留列类型以便驱动解析为 time.Time。
+func (s *Storage) boundaryTime(ctx context.Context, model any, column string, dir string) (*time.Time, error) {
+	var ts []time.Time
+	err := s.db.WithContext(ctx).Model(model).
+		Order(column+" "+dir).
+		Limit(1).
+		Pluck(column, &ts).Error
+	if err != nil {
+		return nil, fmt.Errorf("read %s boundary: %w", column, err)
+	}
+	if len(ts) == 0 {
+		return nil, nil
+	}
+	t := ts[0]
+	return &t, nil
+}
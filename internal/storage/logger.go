@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"strings"
+
+	"gorm.io/gorm/logger"
+)
+
+// LogLevel 将配置中的 log_level 映射为 GORM 日志级别。
+// 仅 debug 输出全部 SQL；info 及以上只输出慢查询与错误，避免正常运行时刷屏。
+func LogLevel(level string) logger.LogLevel {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return logger.Info
+	case "error":
+		return logger.Error
+	default:
+		return logger.Warn
+	}
+}
+
+// NewLogger 按 log_level 创建 GORM logger，用于 Config.Logger。
+func NewLogger(level string) logger.Interface {
+	return logger.Default.LogMode(LogLevel(level))
+}
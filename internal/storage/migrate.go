@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration 为一个带版本号的迁移步骤，用于 AutoMigrate 无法完成的变更（删列、改索引、数据修正等）。
+// 迁移在 AutoMigrate 之前按版本号依次执行，每个版本只执行一次；依赖新表/新列时需在 Up 中自行 AutoMigrate。
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, tx *gorm.DB) error
+}
+
+// SchemaMigration 记录已执行的迁移版本。
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:128;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// MigrationStatus 为单个迁移的执行状态。
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// migrations 为全部已知迁移，版本号必须递增且不可修改已发布的条目。
+var migrations = []Migration{
+	{Version: 1, Name: "drop_legacy_audit_actor", Up: dropLegacyAuditActor},
+	{Version: 2, Name: "container_stats_unique_index", Up: migrateStatsUniqueIndex},
+}
+
+// Migrate 执行未应用的迁移并同步表结构。
+func (s *Storage) Migrate(ctx context.Context) error {
+	_, err := s.ApplyMigrations(ctx)
+	return err
+}
+
+// ApplyMigrations 依次执行未应用的迁移，再通过 AutoMigrate 同步表结构，返回本次执行的迁移。
+func (s *Storage) ApplyMigrations(ctx context.Context) ([]MigrationStatus, error) {
+	return s.applyMigrations(ctx, migrations)
+}
+
+func (s *Storage) applyMigrations(ctx context.Context, list []Migration) ([]MigrationStatus, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
+	}
+	db := s.db.WithContext(ctx)
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	done, err := s.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []MigrationStatus
+	for _, m := range list {
+		if _, ok := done[m.Version]; ok {
+			continue
+		}
+		rec := SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now().UTC()}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(ctx, tx); err != nil {
+				return err
+			}
+			return tx.Create(&rec).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		applied = append(applied, MigrationStatus{Version: m.Version, Name: m.Name, Applied: true, AppliedAt: &rec.AppliedAt})
+	}
+
+	if err := db.AutoMigrate(
+		&ContainerStat{},
+		&ContainerLog{},
+		&AuditRecord{},
+		&DockerEvent{},
+		&HostStat{},
+		&ChatSession{},
+	); err != nil {
+		return applied, fmt.Errorf("auto migrate: %w", err)
+	}
+	return applied, nil
+}
+
+// Migrations 返回全部已知迁移及其执行状态，不执行任何迁移。
+func (s *Storage) Migrations(ctx context.Context) ([]MigrationStatus, error) {
+	return s.migrationStatus(ctx, migrations)
+}
+
+func (s *Storage) migrationStatus(ctx context.Context, list []Migration) ([]MigrationStatus, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
+	}
+	done := map[int]SchemaMigration{}
+	if s.db.WithContext(ctx).Migrator().HasTable(&SchemaMigration{}) {
+		var err error
+		if done, err = s.appliedMigrations(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]MigrationStatus, 0, len(list))
+	for _, m := range list {
+		st := MigrationStatus{Version: m.Version, Name: m.Name}
+		if rec, ok := done[m.Version]; ok {
+			at := rec.AppliedAt
+			st.Applied, st.AppliedAt = true, &at
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+func (s *Storage) appliedMigrations(ctx context.Context) (map[int]SchemaMigration, error) {
+	var rows []SchemaMigration
+	if err := s.db.WithContext(ctx).Order("version ASC").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	out := make(map[int]SchemaMigration, len(rows))
+	for _, r := range rows {
+		out[r.Version] = r
+	}
+	return out, nil
+}
+
+// dropLegacyAuditActor 修复旧版本遗留的 actor 列：AuditRecord 移除了 Actor 字段，
+// 但 SQLite 的 AutoMigrate 不会删除旧列/约束，导致 NOT NULL constraint failed，因此重建该表。
+func dropLegacyAuditActor(ctx context.Context, tx *gorm.DB) error {
+	m := tx.Migrator()
+	if !m.HasTable(&AuditRecord{}) || !m.HasColumn(&AuditRecord{}, "actor") {
+		return nil
+	}
+	if err := m.DropTable(&AuditRecord{}); err != nil {
+		return fmt.Errorf("drop old audit_records table: %w", err)
+	}
+	return nil
+}
+
+// legacyStatsIndex 为旧版本 (container_id, collected_at) 上的非唯一索引
+const legacyStatsIndex = "idx_container_stats_container_time"
+
+// migrateStatsUniqueIndex 将旧的非唯一索引替换为唯一索引：先删除重复采样（保留 ID 最小的一条），
+// 再删除旧索引，由 AutoMigrate 创建新的唯一索引。
+func migrateStatsUniqueIndex(ctx context.Context, tx *gorm.DB) error {
+	m := tx.Migrator()
+	if !m.HasTable(&ContainerStat{}) || !m.HasIndex(&ContainerStat{}, legacyStatsIndex) {
+		return nil
+	}
+	err := tx.Exec(
+		"DELETE FROM container_stats WHERE id NOT IN (SELECT MIN(id) FROM container_stats GROUP BY container_id, collected_at)",
+	).Error
+	if err != nil {
+		return fmt.Errorf("dedupe container stats: %w", err)
+	}
+	if err := m.DropIndex(&ContainerStat{}, legacyStatsIndex); err != nil {
+		return fmt.Errorf("drop legacy container stats index: %w", err)
+	}
+	return nil
+}
@@ -6,7 +6,7 @@ type ContainerStat struct {
 	// ID 为自增主键（内部使用）。
 	ID uint64 `gorm:"primaryKey"`
 	// ContainerID 为容器唯一标识（Docker ID），用于跨重启/重命名保持稳定关联。
-	ContainerID string `gorm:"size:128;not null;index:idx_container_stats_container_time,priority:1"`
+	ContainerID string `gorm:"size:128;not null;uniqueIndex:idx_container_stats_container_time_uniq,priority:1"`
 	// ContainerName 为采样时刻的容器名称（可变），便于展示与按名称检索。
 	ContainerName string `gorm:"size:255;index"`
 	// CPUPercent 为 CPU 使用率百分比（0~100+，取决于核数与计算方式），用于趋势分析与告警。
@@ -26,8 +26,10 @@ type ContainerStat struct {
 	Pids uint64 `gorm:"not null"`
 	// RawJSON 可选：存放采样的原始 JSON，便于未来字段扩展或离线重算。
 	RawJSON string `gorm:"type:text"`
-	// CollectedAt 为采样发生时间（推荐用 UTC），用于时序查询与聚合；与 ContainerID 组成联合索引。
-	CollectedAt time.Time `gorm:"not null;index:idx_container_stats_container_time,priority:2"`
+	// Labels 可选：采样时容器标签的 JSON 对象（仅在开启 capture_labels 时写入），用于按标签分组统计。
+	Labels string `gorm:"type:text"`
+	// CollectedAt 为采样发生时间（推荐用 UTC），用于时序查询与聚合；与 ContainerID 组成唯一索引，重复采样写入时被忽略。
+	CollectedAt time.Time `gorm:"not null;uniqueIndex:idx_container_stats_container_time_uniq,priority:2"`
 	// CreatedAt 为写入数据库时间（与 CollectedAt 含义不同），默认自动填充。
 	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
 }
@@ -83,3 +85,64 @@ type AuditRecord struct {
 	// CreatedAt 为记录写入数据库的时间（与 StartedAt 含义不同），默认自动填充。
 	CreatedAt time.Time `gorm:"not null;autoCreateTime;index"`
 }
+
+// DockerEvent 记录一条 Docker 事件（容器/镜像/网络/卷的动作），用于回溯“何时发生了什么变更”。
+type DockerEvent struct {
+	// ID 为自增主键（内部使用）。
+	ID uint64 `gorm:"primaryKey"`
+	// Type 为事件对象类型（container/image/network/volume 等）。
+	Type string `gorm:"size:32;not null;index"`
+	// Action 为事件动作（start/die/pull/destroy 等）。
+	Action string `gorm:"size:64;not null;index"`
+	// ActorID 为事件对象 ID（容器 ID、镜像 ID 等）。
+	ActorID string `gorm:"size:128;index"`
+	// ActorName 为事件对象名称（来自 Actor.Attributes["name"]，可能为空）。
+	ActorName string `gorm:"size:255"`
+	// AttributesJSON 为事件属性（JSON 字符串），例如镜像名、退出码等。
+	AttributesJSON string `gorm:"type:text"`
+	// Timestamp 为事件发生时间。
+	Timestamp time.Time `gorm:"not null;index"`
+	// CreatedAt 为写入数据库时间，默认自动填充。
+	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
+}
+
+// HostStat 记录一次宿主机整体采样（CPU/内存与 docker 容器数量），用于判断宿主机本身是否存在资源压力。
+type HostStat struct {
+	// ID 为自增主键（内部使用）。
+	ID uint64 `gorm:"primaryKey"`
+	// CPUPercent 为宿主机整体 CPU 使用率百分比（0~100，所有核平均）；无法读取时为 0。
+	CPUPercent float64 `gorm:"not null"`
+	// MemTotalBytes/MemUsedBytes 为宿主机内存总量/已用量（字节），已用量不含可回收的页缓存。
+	MemTotalBytes uint64 `gorm:"not null"`
+	MemUsedBytes  uint64 `gorm:"not null"`
+	// MemPercent 为内存使用率百分比（0~100）。
+	MemPercent float64 `gorm:"not null"`
+	// NCPU 为宿主机 CPU 核数（来自 docker info）。
+	NCPU int `gorm:"not null"`
+	// ContainersRunning/Paused/Stopped 为采样时刻各状态的容器数量（来自 docker info）。
+	ContainersRunning int `gorm:"not null"`
+	ContainersPaused  int `gorm:"not null"`
+	ContainersStopped int `gorm:"not null"`
+	// Images 为本地镜像数量。
+	Images int `gorm:"not null"`
+	// CollectedAt 为采样发生时间（推荐用 UTC），用于时序查询与清理。
+	CollectedAt time.Time `gorm:"not null;index"`
+	// CreatedAt 为写入数据库时间，默认自动填充。
+	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
+}
+
+// ChatSession 持久化一次对话会话的消息历史，用于 chat --resume 恢复长时间的排障会话。
+type ChatSession struct {
+	// ID 为会话 ID（由调用方生成，如 UUID），作为主键。
+	ID string `gorm:"primaryKey;size:64"`
+	// Title 为会话标题（取首条用户消息的摘要），便于列出会话时辨认。
+	Title string `gorm:"size:255"`
+	// MessagesJSON 为会话消息列表（含工具调用与工具结果）的 JSON 数组。
+	MessagesJSON string `gorm:"type:text;not null"`
+	// MessageCount 为消息条数，便于列表展示而无需解析 MessagesJSON。
+	MessageCount int `gorm:"not null"`
+	// CreatedAt 为会话首次保存时间。
+	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
+	// UpdatedAt 为最近一次保存时间；保留策略按该时间清理不再活跃的会话。
+	UpdatedAt time.Time `gorm:"not null;index"`
+}
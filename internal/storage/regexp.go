@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"sync"
+
+	sqlite "github.com/glebarez/go-sqlite"
+)
+
+// maxCachedRegexps 为缓存的已编译正则数量上限；超过后清空重建，避免任意模式导致内存增长
+const maxCachedRegexps = 64
+
+var registerRegexpOnce sync.Once
+var registerRegexpErr error
+
+// regexpCache 缓存已编译的正则；REGEXP 对每一行都会调用一次，避免逐行重复编译
+var regexpCache = struct {
+	sync.Mutex
+	m map[string]*regexp.Regexp
+}{m: make(map[string]*regexp.Regexp)}
+
+func cachedRegexp(pattern string) (*regexp.Regexp, error) {
+	regexpCache.Lock()
+	defer regexpCache.Unlock()
+	if re, ok := regexpCache.m[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(regexpCache.m) >= maxCachedRegexps {
+		regexpCache.m = make(map[string]*regexp.Regexp)
+	}
+	regexpCache.m[pattern] = re
+	return re, nil
+}
+
+// registerRegexp 注册 SQLite 的 regexp(pattern, value) 函数，使 `value REGEXP pattern` 可用。
+// 函数为进程级注册，对之后打开的所有连接生效。
+func registerRegexp() error {
+	registerRegexpOnce.Do(func() {
+		registerRegexpErr = sqlite.RegisterDeterministicScalarFunction("regexp", 2, sqliteRegexp)
+	})
+	return registerRegexpErr
+}
+
+func sqliteRegexp(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	pattern, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("regexp: pattern must be text")
+	}
+	var value string
+	switch v := args[1].(type) {
+	case nil:
+		return false, nil
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		value = fmt.Sprint(v)
+	}
+	re, err := cachedRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString(value), nil
+}
@@ -2,9 +2,15 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 const (
@@ -22,12 +28,47 @@ type StatsQuery struct {
 	// From/To 过滤 CollectedAt 区间：[From, To]（两端包含）。
 	From *time.Time
 	To   *time.Time
+	// Label 按容器标签过滤：key=value 为精确匹配，仅 key 表示存在该标签；只能匹配写入了 Labels 的采样。
+	Label string
 	// Limit 限制返回条数；<=0 使用默认值。
 	Limit int
 	// Desc 按 CollectedAt 倒序返回（优先返回最新采样点）。
 	Desc bool
 }
 
+// where 应用 StatsQuery 中的容器、标签与时间条件（不含排序与 Limit）
+func (q StatsQuery) where(db *gorm.DB) *gorm.DB {
+	if q.ContainerID != "" {
+		db = db.Where("container_id = ?", q.ContainerID)
+	}
+	if q.ContainerName != "" {
+		db = db.Where("container_name = ?", q.ContainerName)
+	}
+	if key, value, hasValue := strings.Cut(q.Label, "="); strings.TrimSpace(key) != "" {
+		// 未采集标签的样本 labels 为空串，不是合法 JSON，需先转为 NULL
+		path := `$."` + strings.TrimSpace(key) + `"`
+		if hasValue {
+			db = db.Where("json_extract(NULLIF(labels, ''), ?) = ?", path, strings.TrimSpace(value))
+		} else {
+			db = db.Where("json_type(NULLIF(labels, ''), ?) IS NOT NULL", path)
+		}
+	}
+	if q.From != nil {
+		db = db.Where("collected_at >= ?", *q.From)
+	}
+	if q.To != nil {
+		db = db.Where("collected_at <= ?", *q.To)
+	}
+	return db
+}
+
+// ignoreDuplicateStats 使同一容器在同一时间点的重复采样（如定时采集与手动采样重叠）被静默忽略；
+// 只针对 (container_id, collected_at) 唯一索引，主键冲突等其他错误仍会返回。
+var ignoreDuplicateStats = clause.OnConflict{
+	Columns:   []clause.Column{{Name: "container_id"}, {Name: "collected_at"}},
+	DoNothing: true,
+}
+
 func (s *Storage) InsertContainerStat(ctx context.Context, stat *ContainerStat) error {
 	if s == nil || s.db == nil {
 		return errors.New("storage not initialized")
@@ -42,7 +83,9 @@ func (s *Storage) InsertContainerStat(ctx context.Context, stat *ContainerStat)
 	if stat.CreatedAt.IsZero() {
 		stat.CreatedAt = now
 	}
-	if err := s.db.WithContext(ctx).Create(stat).Error; err != nil {
+	if err := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Clauses(ignoreDuplicateStats).Create(stat)
+	}).Error; err != nil {
 		return fmt.Errorf("insert container stat: %w", err)
 	}
 	return nil
@@ -64,31 +107,50 @@ func (s *Storage) InsertContainerStats(ctx context.Context, stats []ContainerSta
 			stats[i].CreatedAt = now
 		}
 	}
-	if err := s.db.WithContext(ctx).CreateInBatches(stats, 200).Error; err != nil {
+	if err := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Clauses(ignoreDuplicateStats).CreateInBatches(stats, 200)
+	}).Error; err != nil {
 		return fmt.Errorf("insert container stats: %w", err)
 	}
 	return nil
 }
 
-func (s *Storage) QueryContainerStats(ctx context.Context, q StatsQuery) ([]ContainerStat, error) {
+// InsertStatsTx 在一个显式事务中写入整批 stats：任意一行失败时整批回滚，
+// 同时避免每个子批次单独提交带来的 fsync 开销。
+func (s *Storage) InsertStatsTx(ctx context.Context, stats []ContainerStat) error {
 	if s == nil || s.db == nil {
-		return nil, errors.New("storage not initialized")
+		return errors.New("storage not initialized")
 	}
-
-	limit := normalizeLimit(q.Limit)
-	db := s.db.WithContext(ctx).Model(&ContainerStat{})
-	if q.ContainerID != "" {
-		db = db.Where("container_id = ?", q.ContainerID)
+	if len(stats) == 0 {
+		return nil
 	}
-	if q.ContainerName != "" {
-		db = db.Where("container_name = ?", q.ContainerName)
+	now := time.Now().UTC()
+	for i := range stats {
+		if stats[i].CollectedAt.IsZero() {
+			stats[i].CollectedAt = now
+		}
+		if stats[i].CreatedAt.IsZero() {
+			stats[i].CreatedAt = now
+		}
 	}
-	if q.From != nil {
-		db = db.Where("collected_at >= ?", *q.From)
+	err := retryOnBusyErr(ctx, func() error {
+		return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return tx.Clauses(ignoreDuplicateStats).CreateInBatches(stats, 200).Error
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("insert container stats tx: %w", err)
 	}
-	if q.To != nil {
-		db = db.Where("collected_at <= ?", *q.To)
+	return nil
+}
+
+func (s *Storage) QueryContainerStats(ctx context.Context, q StatsQuery) ([]ContainerStat, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
 	}
+
+	limit := normalizeLimit(q.Limit)
+	db := q.where(s.db.WithContext(ctx).Model(&ContainerStat{}))
 	if q.Desc {
 		db = db.Order("collected_at DESC")
 	} else {
@@ -103,6 +165,136 @@ func (s *Storage) QueryContainerStats(ctx context.Context, q StatsQuery) ([]Cont
 	return out, nil
 }
 
+// LatestStatPerContainer 返回每个容器最新的一条采样记录，按容器名称排序。
+func (s *Storage) LatestStatPerContainer(ctx context.Context) ([]ContainerStat, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
+	}
+
+	latest := s.db.WithContext(ctx).Model(&ContainerStat{}).
+		Select("container_id, MAX(collected_at) AS max_collected_at").
+		Group("container_id")
+
+	var out []ContainerStat
+	err := s.db.WithContext(ctx).Model(&ContainerStat{}).
+		Joins("JOIN (?) AS latest ON latest.container_id = container_stats.container_id AND latest.max_collected_at = container_stats.collected_at", latest).
+		Order("container_stats.container_name ASC").
+		Find(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("query latest container stats: %w", err)
+	}
+	return dedupeLatestStats(out), nil
+}
+
+// StatsAggregate 为一段时间内 stats 采样的聚合结果。
+type StatsAggregate struct {
+	ContainerID   string  `json:"container_id"`
+	ContainerName string  `json:"container_name"`
+	Samples       int64   `json:"samples"`
+	AvgCPUPercent float64 `json:"avg_cpu_percent"`
+	MaxCPUPercent float64 `json:"max_cpu_percent"`
+	AvgMemPercent float64 `json:"avg_mem_percent"`
+	MaxMemPercent float64 `json:"max_mem_percent"`
+	// MaxMemUsageBytes 为窗口内的内存峰值（字节）。
+	MaxMemUsageBytes uint64 `json:"max_mem_usage_bytes"`
+}
+
+// AggregateStats 按 StatsQuery 的容器、标签与时间条件聚合 stats（忽略 Limit/Desc）；无采样时 Samples 为 0。
+func (s *Storage) AggregateStats(ctx context.Context, q StatsQuery) (*StatsAggregate, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
+	}
+
+	db := q.where(s.db.WithContext(ctx).Model(&ContainerStat{}))
+
+	var out StatsAggregate
+	err := db.Select(`COALESCE(MAX(container_id), '') AS container_id,
+		COALESCE(MAX(container_name), '') AS container_name,
+		COUNT(*) AS samples,
+		COALESCE(AVG(cpu_percent), 0) AS avg_cpu_percent,
+		COALESCE(MAX(cpu_percent), 0) AS max_cpu_percent,
+		COALESCE(AVG(mem_percent), 0) AS avg_mem_percent,
+		COALESCE(MAX(mem_percent), 0) AS max_mem_percent,
+		COALESCE(MAX(mem_usage_bytes), 0) AS max_mem_usage_bytes`).
+		Scan(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("aggregate container stats: %w", err)
+	}
+	return &out, nil
+}
+
+// StatsBucket 为单个容器在一个时间桶内的平均资源占用。
+type StatsBucket struct {
+	ContainerID   string `json:"container_id"`
+	ContainerName string `json:"container_name"`
+	// Start 为桶的起始时间（从 From 起按桶宽对齐）。
+	Start         time.Time `json:"start"`
+	Samples       int       `json:"samples"`
+	AvgCPUPercent float64   `json:"avg_cpu_percent"`
+	AvgMemPercent float64   `json:"avg_mem_percent"`
+}
+
+// QueryStatsBucketed 将 StatsQuery 条件内的 stats 按 bucket 宽度分桶（忽略 Limit/Desc），
+// 返回每个容器每个非空桶的平均 CPU/内存，按容器 ID、桶起始时间升序；From 与 To 必须设置。
+func (s *Storage) QueryStatsBucketed(ctx context.Context, q StatsQuery, bucket time.Duration) ([]StatsBucket, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
+	}
+	if q.From == nil || q.To == nil {
+		return nil, errors.New("bucketed stats query requires from and to")
+	}
+	if bucket <= 0 {
+		return nil, fmt.Errorf("bucket must be positive, got %s", bucket)
+	}
+
+	var rows []ContainerStat
+	err := q.where(s.db.WithContext(ctx).Model(&ContainerStat{})).
+		Select("container_id", "container_name", "cpu_percent", "mem_percent", "collected_at").
+		Order("container_id ASC, collected_at ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("query bucketed container stats: %w", err)
+	}
+
+	from := q.From.UTC()
+	var out []StatsBucket
+	for _, r := range rows {
+		start := from.Add(r.CollectedAt.UTC().Sub(from) / bucket * bucket)
+		n := len(out)
+		if n == 0 || out[n-1].ContainerID != r.ContainerID || !out[n-1].Start.Equal(start) {
+			out = append(out, StatsBucket{ContainerID: r.ContainerID, Start: start})
+			n++
+		}
+		b := &out[n-1]
+		b.ContainerName = r.ContainerName
+		b.Samples++
+		b.AvgCPUPercent += r.CPUPercent
+		b.AvgMemPercent += r.MemPercent
+	}
+	for i := range out {
+		out[i].AvgCPUPercent /= float64(out[i].Samples)
+		out[i].AvgMemPercent /= float64(out[i].Samples)
+	}
+	return out, nil
+}
+
+// dedupeLatestStats 去掉同一容器在同一时间点的重复采样，保留 ID 最大的一条
+func dedupeLatestStats(stats []ContainerStat) []ContainerStat {
+	idx := make(map[string]int, len(stats))
+	out := stats[:0]
+	for _, st := range stats {
+		if i, ok := idx[st.ContainerID]; ok {
+			if st.ID > out[i].ID {
+				out[i] = st
+			}
+			continue
+		}
+		idx[st.ContainerID] = len(out)
+		out = append(out, st)
+	}
+	return out
+}
+
 func (s *Storage) CountContainerStats(ctx context.Context) (int64, error) {
 	if s == nil || s.db == nil {
 		return 0, errors.New("storage not initialized")
@@ -118,7 +310,9 @@ func (s *Storage) DeleteContainerStatsBefore(ctx context.Context, before time.Ti
 	if s == nil || s.db == nil {
 		return 0, errors.New("storage not initialized")
 	}
-	res := s.db.WithContext(ctx).Where("collected_at < ?", before).Delete(&ContainerStat{})
+	res := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Where("collected_at < ?", before).Delete(&ContainerStat{})
+	})
 	if res.Error != nil {
 		return 0, fmt.Errorf("delete container stats: %w", res.Error)
 	}
@@ -145,13 +339,79 @@ func (s *Storage) DeleteContainerStatsBeforeLimited(ctx context.Context, before
 		return 0, nil
 	}
 
-	res := s.db.WithContext(ctx).Where("id IN ?", ids).Delete(&ContainerStat{})
+	res := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Where("id IN ?", ids).Delete(&ContainerStat{})
+	})
 	if res.Error != nil {
 		return 0, fmt.Errorf("delete container stats: %w", res.Error)
 	}
 	return res.RowsAffected, nil
 }
 
+// anomalyCondition 返回判定异常采样的 SQL 条件：CPU 或内存使用率达到阈值（<=0 的阈值不参与判断）。
+// 保留策略与 RecentAnomalies 共用该条件，保证“异常”的口径一致；两个阈值都未设置时返回空串。
+func anomalyCondition(cpuHigh float64, memHigh float64) (string, []any) {
+	var conds []string
+	var args []any
+	if cpuHigh > 0 {
+		conds = append(conds, "cpu_percent >= ?")
+		args = append(args, cpuHigh)
+	}
+	if memHigh > 0 {
+		conds = append(conds, "mem_percent >= ?")
+		args = append(args, memHigh)
+	}
+	return strings.Join(conds, " OR "), args
+}
+
+// RecentAnomalies 返回所有容器中 CPU 或内存使用率达到阈值的最近 limit 条采样，按采集时间倒序。
+// 阈值都 <=0 时没有采样被视为异常，返回空结果。
+func (s *Storage) RecentAnomalies(ctx context.Context, cpuHigh float64, memHigh float64, limit int) ([]ContainerStat, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
+	}
+	cond, args := anomalyCondition(cpuHigh, memHigh)
+	if cond == "" {
+		return nil, nil
+	}
+
+	var out []ContainerStat
+	err := s.db.WithContext(ctx).
+		Where(cond, args...).
+		Order("collected_at DESC, id DESC").
+		Limit(normalizeLimit(limit)).
+		Find(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("query recent anomalies: %w", err)
+	}
+	return out, nil
+}
+
+// DeleteOldestContainerStatsLimited 按写入顺序（ID 升序）删除最旧的至多 limit 条 stats，不区分保留策略；用于总行数上限兜底。
+func (s *Storage) DeleteOldestContainerStatsLimited(ctx context.Context, limit int) (int64, error) {
+	if s == nil || s.db == nil {
+		return 0, errors.New("storage not initialized")
+	}
+
+	limit = normalizeDeleteLimit(limit)
+
+	var ids []uint64
+	if err := s.db.WithContext(ctx).Model(&ContainerStat{}).Select("id").Order("id ASC").Limit(limit).Find(&ids).Error; err != nil {
+		return 0, fmt.Errorf("select oldest container stats ids: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	res := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Where("id IN ?", ids).Delete(&ContainerStat{})
+	})
+	if res.Error != nil {
+		return 0, fmt.Errorf("delete oldest container stats: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
 func (s *Storage) DeleteContainerStatsNonAnomalyInRangeLimited(ctx context.Context, from time.Time, to time.Time, cpuHigh float64, memHigh float64, limit int) (int64, error) {
 	if s == nil || s.db == nil {
 		return 0, errors.New("storage not initialized")
@@ -165,11 +425,8 @@ func (s *Storage) DeleteContainerStatsNonAnomalyInRangeLimited(ctx context.Conte
 	db := s.db.WithContext(ctx).Model(&ContainerStat{}).
 		Select("id").
 		Where("collected_at >= ? AND collected_at < ?", from, to)
-	if cpuHigh > 0 {
-		db = db.Where("cpu_percent < ?", cpuHigh)
-	}
-	if memHigh > 0 {
-		db = db.Where("mem_percent < ?", memHigh)
+	if cond, args := anomalyCondition(cpuHigh, memHigh); cond != "" {
+		db = db.Where("NOT ("+cond+")", args...)
 	}
 
 	var ids []uint64
@@ -180,7 +437,9 @@ func (s *Storage) DeleteContainerStatsNonAnomalyInRangeLimited(ctx context.Conte
 		return 0, nil
 	}
 
-	res := s.db.WithContext(ctx).Where("id IN ?", ids).Delete(&ContainerStat{})
+	res := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Where("id IN ?", ids).Delete(&ContainerStat{})
+	})
 	if res.Error != nil {
 		return 0, fmt.Errorf("delete container stats: %w", res.Error)
 	}
@@ -199,6 +458,8 @@ type LogQuery struct {
 	Source string
 	// Contains 对 Message 做子串匹配（SQL LIKE），用于关键字检索。
 	Contains string
+	// Regexp 对 Message 做正则匹配（Go regexp 语法，SQL REGEXP）。
+	Regexp string
 	// Limit 限制返回条数；<=0 使用默认值。
 	Limit int
 	// Desc 按 Timestamp 倒序返回（优先返回最新日志）。
@@ -219,7 +480,9 @@ func (s *Storage) InsertContainerLog(ctx context.Context, log *ContainerLog) err
 	if log.CreatedAt.IsZero() {
 		log.CreatedAt = now
 	}
-	if err := s.db.WithContext(ctx).Create(log).Error; err != nil {
+	if err := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Create(log)
+	}).Error; err != nil {
 		return fmt.Errorf("insert container log: %w", err)
 	}
 	return nil
@@ -241,12 +504,42 @@ func (s *Storage) InsertContainerLogs(ctx context.Context, logs []ContainerLog)
 			logs[i].CreatedAt = now
 		}
 	}
-	if err := s.db.WithContext(ctx).CreateInBatches(logs, 200).Error; err != nil {
+	if err := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).CreateInBatches(logs, 200)
+	}).Error; err != nil {
 		return fmt.Errorf("insert container logs: %w", err)
 	}
 	return nil
 }
 
+// InsertLogsTx 在一个显式事务中写入整批日志：任意一行失败时整批回滚。
+func (s *Storage) InsertLogsTx(ctx context.Context, logs []ContainerLog) error {
+	if s == nil || s.db == nil {
+		return errors.New("storage not initialized")
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	for i := range logs {
+		if logs[i].Timestamp.IsZero() {
+			logs[i].Timestamp = now
+		}
+		if logs[i].CreatedAt.IsZero() {
+			logs[i].CreatedAt = now
+		}
+	}
+	err := retryOnBusyErr(ctx, func() error {
+		return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return tx.CreateInBatches(logs, 200).Error
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("insert container logs tx: %w", err)
+	}
+	return nil
+}
+
 func (s *Storage) QueryContainerLogs(ctx context.Context, q LogQuery) ([]ContainerLog, error) {
 	if s == nil || s.db == nil {
 		return nil, errors.New("storage not initialized")
@@ -275,6 +568,13 @@ func (s *Storage) QueryContainerLogs(ctx context.Context, q LogQuery) ([]Contain
 	if q.Contains != "" {
 		db = db.Where("message LIKE ?", "%"+q.Contains+"%")
 	}
+	if q.Regexp != "" {
+		// 先在 Go 侧校验，避免错误在逐行执行时才暴露
+		if _, err := cachedRegexp(q.Regexp); err != nil {
+			return nil, fmt.Errorf("invalid regexp %q: %w", q.Regexp, err)
+		}
+		db = db.Where("message REGEXP ?", q.Regexp)
+	}
 	if q.Desc {
 		db = db.Order("timestamp DESC")
 	} else {
@@ -289,6 +589,83 @@ func (s *Storage) QueryContainerLogs(ctx context.Context, q LogQuery) ([]Contain
 	return out, nil
 }
 
+// LogLevelCounts 按 LogQuery 的容器/来源/时间条件统计各日志级别的条数（忽略 Contains/Regexp/Limit/Desc）；
+// 未识别级别的日志计入空字符串。
+func (s *Storage) LogLevelCounts(ctx context.Context, q LogQuery) (map[string]int64, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
+	}
+
+	db := s.db.WithContext(ctx).Model(&ContainerLog{})
+	if q.ContainerID != "" {
+		db = db.Where("container_id = ?", q.ContainerID)
+	}
+	if q.ContainerName != "" {
+		db = db.Where("container_name = ?", q.ContainerName)
+	}
+	if q.Source != "" {
+		db = db.Where("source = ?", q.Source)
+	}
+	if q.From != nil {
+		db = db.Where("timestamp >= ?", *q.From)
+	}
+	if q.To != nil {
+		db = db.Where("timestamp <= ?", *q.To)
+	}
+
+	var rows []struct {
+		Level string
+		Count int64
+	}
+	if err := db.Select("level, COUNT(*) AS count").Group("level").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("count log levels: %w", err)
+	}
+	out := make(map[string]int64, len(rows))
+	for _, r := range rows {
+		out[r.Level] += r.Count
+	}
+	return out, nil
+}
+
+// ContainerErrorRate 为单个容器在时间窗口内的错误日志数与日志总数。
+type ContainerErrorRate struct {
+	ContainerID   string `json:"container_id"`
+	ContainerName string `json:"container_name"`
+	// Errors 为 ERROR/FATAL 级别的日志条数；Total 为全部日志条数。
+	Errors int64 `json:"errors"`
+	Total  int64 `json:"total"`
+	// ErrorRate 为 Errors/Total（0~1）。
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// ErrorRatePerContainer 统计 [from, to] 内每个容器的 ERROR/FATAL 日志数与日志总数，
+// 按错误数倒序（相同时按总数倒序）返回；窗口内没有日志的容器不出现在结果中。
+func (s *Storage) ErrorRatePerContainer(ctx context.Context, from, to time.Time) ([]ContainerErrorRate, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
+	}
+
+	var out []ContainerErrorRate
+	err := s.db.WithContext(ctx).Model(&ContainerLog{}).
+		Select(`container_id,
+			MAX(container_name) AS container_name,
+			SUM(CASE WHEN level IN ('ERROR', 'FATAL') THEN 1 ELSE 0 END) AS errors,
+			COUNT(*) AS total`).
+		Where("timestamp >= ? AND timestamp <= ?", from, to).
+		Group("container_id").
+		Order("errors DESC, total DESC, container_id ASC").
+		Scan(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("count container errors: %w", err)
+	}
+	for i := range out {
+		if out[i].Total > 0 {
+			out[i].ErrorRate = float64(out[i].Errors) / float64(out[i].Total)
+		}
+	}
+	return out, nil
+}
+
 func (s *Storage) CountContainerLogs(ctx context.Context) (int64, error) {
 	if s == nil || s.db == nil {
 		return 0, errors.New("storage not initialized")
@@ -304,7 +681,9 @@ func (s *Storage) DeleteContainerLogsBefore(ctx context.Context, before time.Tim
 	if s == nil || s.db == nil {
 		return 0, errors.New("storage not initialized")
 	}
-	res := s.db.WithContext(ctx).Where("timestamp < ?", before).Delete(&ContainerLog{})
+	res := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Where("timestamp < ?", before).Delete(&ContainerLog{})
+	})
 	if res.Error != nil {
 		return 0, fmt.Errorf("delete container logs: %w", res.Error)
 	}
@@ -331,13 +710,40 @@ func (s *Storage) DeleteContainerLogsBeforeLimited(ctx context.Context, before t
 		return 0, nil
 	}
 
-	res := s.db.WithContext(ctx).Where("id IN ?", ids).Delete(&ContainerLog{})
+	res := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Where("id IN ?", ids).Delete(&ContainerLog{})
+	})
 	if res.Error != nil {
 		return 0, fmt.Errorf("delete container logs: %w", res.Error)
 	}
 	return res.RowsAffected, nil
 }
 
+// DeleteOldestContainerLogsLimited 按写入顺序（ID 升序）删除最旧的至多 limit 条日志，不区分保留策略；用于总行数上限兜底。
+func (s *Storage) DeleteOldestContainerLogsLimited(ctx context.Context, limit int) (int64, error) {
+	if s == nil || s.db == nil {
+		return 0, errors.New("storage not initialized")
+	}
+
+	limit = normalizeDeleteLimit(limit)
+
+	var ids []uint64
+	if err := s.db.WithContext(ctx).Model(&ContainerLog{}).Select("id").Order("id ASC").Limit(limit).Find(&ids).Error; err != nil {
+		return 0, fmt.Errorf("select oldest container logs ids: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	res := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Where("id IN ?", ids).Delete(&ContainerLog{})
+	})
+	if res.Error != nil {
+		return 0, fmt.Errorf("delete oldest container logs: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
 func (s *Storage) DeleteContainerLogsUnimportantInRangeLimited(ctx context.Context, from time.Time, to time.Time, keepLevels []string, keepSources []string, limit int) (int64, error) {
 	if s == nil || s.db == nil {
 		return 0, errors.New("storage not initialized")
@@ -366,7 +772,9 @@ func (s *Storage) DeleteContainerLogsUnimportantInRangeLimited(ctx context.Conte
 		return 0, nil
 	}
 
-	res := s.db.WithContext(ctx).Where("id IN ?", ids).Delete(&ContainerLog{})
+	res := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Where("id IN ?", ids).Delete(&ContainerLog{})
+	})
 	if res.Error != nil {
 		return 0, fmt.Errorf("delete container logs: %w", res.Error)
 	}
@@ -405,7 +813,9 @@ func (s *Storage) InsertAuditRecord(ctx context.Context, rec *AuditRecord) error
 	if rec.CreatedAt.IsZero() {
 		rec.CreatedAt = now
 	}
-	if err := s.db.WithContext(ctx).Create(rec).Error; err != nil {
+	if err := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Create(rec)
+	}).Error; err != nil {
 		return fmt.Errorf("insert audit record: %w", err)
 	}
 	return nil
@@ -447,6 +857,22 @@ func (s *Storage) QueryAuditRecords(ctx context.Context, q AuditQuery) ([]AuditR
 	return out, nil
 }
 
+// GetAuditRecord 按 ID 读取单条审计记录；不存在时返回 notFoundError。
+func (s *Storage) GetAuditRecord(ctx context.Context, id uint64) (*AuditRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
+	}
+
+	var out []AuditRecord
+	if err := s.db.WithContext(ctx).Where("id = ?", id).Limit(1).Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("get audit record: %w", err)
+	}
+	if len(out) == 0 {
+		return nil, gormNotFoundError("audit record", id)
+	}
+	return &out[0], nil
+}
+
 func (s *Storage) CountAuditRecords(ctx context.Context) (int64, error) {
 	if s == nil || s.db == nil {
 		return 0, errors.New("storage not initialized")
@@ -488,7 +914,9 @@ func (s *Storage) UpdateAuditRecord(ctx context.Context, id uint64, up AuditUpda
 		return nil
 	}
 
-	res := s.db.WithContext(ctx).Model(&AuditRecord{}).Where("id = ?", id).Updates(updates)
+	res := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Model(&AuditRecord{}).Where("id = ?", id).Updates(updates)
+	})
 	if res.Error != nil {
 		return fmt.Errorf("update audit record: %w", res.Error)
 	}
@@ -532,7 +960,9 @@ func (s *Storage) DeleteAuditRecordsKeepLatest(ctx context.Context, keepCount in
 	// 为了安全起见，我们还是用 ID 列表或者“不在前 N 个 ID 集合中”的方式。
 	// DELETE FROM audit_records WHERE id NOT IN (SELECT id FROM audit_records ORDER BY created_at DESC LIMIT ?)
 
-	res := s.db.WithContext(ctx).Where("id <= ?", boundaryID).Delete(&AuditRecord{})
+	res := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Where("id <= ?", boundaryID).Delete(&AuditRecord{})
+	})
 	if res.Error != nil {
 		return 0, fmt.Errorf("delete audit records: %w", res.Error)
 	}
@@ -544,13 +974,346 @@ func (s *Storage) DeleteAuditRecordsBefore(ctx context.Context, before time.Time
 	if s == nil || s.db == nil {
 		return 0, errors.New("storage not initialized")
 	}
-	res := s.db.WithContext(ctx).Where("created_at < ?", before).Delete(&AuditRecord{})
+	res := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Where("created_at < ?", before).Delete(&AuditRecord{})
+	})
 	if res.Error != nil {
 		return 0, fmt.Errorf("delete audit records: %w", res.Error)
 	}
 	return res.RowsAffected, nil
 }
 
+// EventQuery 用于查询 Docker 事件的过滤条件，零值字段不参与过滤。
+type EventQuery struct {
+	// Type/Action/ActorID/ActorName 均为精确匹配。
+	Type      string
+	Action    string
+	ActorID   string
+	ActorName string
+	// From/To 过滤 Timestamp 区间：[From, To]（两端包含）。
+	From *time.Time
+	To   *time.Time
+	// Limit 限制返回条数；<=0 使用默认值。
+	Limit int
+	// Desc 按 Timestamp 倒序返回。
+	Desc bool
+}
+
+// NewDockerEvent 将 Docker 事件流中的消息转换为待写入的 DockerEvent
+func NewDockerEvent(msg events.Message) *DockerEvent {
+	attrs, _ := json.Marshal(msg.Actor.Attributes)
+	return &DockerEvent{
+		Type:           string(msg.Type),
+		Action:         string(msg.Action),
+		ActorID:        msg.Actor.ID,
+		ActorName:      msg.Actor.Attributes["name"],
+		AttributesJSON: string(attrs),
+		Timestamp:      EventTime(msg),
+	}
+}
+
+// EventTime 返回事件发生时间（UTC），优先使用纳秒精度的 TimeNano
+func EventTime(msg events.Message) time.Time {
+	if msg.TimeNano > 0 {
+		return time.Unix(0, msg.TimeNano).UTC()
+	}
+	return time.Unix(msg.Time, 0).UTC()
+}
+
+func (s *Storage) InsertDockerEvent(ctx context.Context, ev *DockerEvent) error {
+	if s == nil || s.db == nil {
+		return errors.New("storage not initialized")
+	}
+	if ev == nil {
+		return errors.New("event is nil")
+	}
+	now := time.Now().UTC()
+	if ev.Timestamp.IsZero() {
+		ev.Timestamp = now
+	}
+	if ev.CreatedAt.IsZero() {
+		ev.CreatedAt = now
+	}
+	if err := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Create(ev)
+	}).Error; err != nil {
+		return fmt.Errorf("insert docker event: %w", err)
+	}
+	return nil
+}
+
+func (s *Storage) QueryDockerEvents(ctx context.Context, q EventQuery) ([]DockerEvent, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
+	}
+
+	limit := normalizeLimit(q.Limit)
+	db := s.eventsFiltered(ctx, q)
+	if q.Desc {
+		db = db.Order("timestamp DESC")
+	} else {
+		db = db.Order("timestamp ASC")
+	}
+	db = db.Limit(limit)
+
+	var out []DockerEvent
+	if err := db.Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("query docker events: %w", err)
+	}
+	return out, nil
+}
+
+// CountDockerEvents 统计满足条件的事件数量（忽略 Limit/Desc）。
+func (s *Storage) CountDockerEvents(ctx context.Context, q EventQuery) (int64, error) {
+	if s == nil || s.db == nil {
+		return 0, errors.New("storage not initialized")
+	}
+	var count int64
+	if err := s.eventsFiltered(ctx, q).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("count docker events: %w", err)
+	}
+	return count, nil
+}
+
+// LastContainerStart 返回容器最近一次 start/restart 事件；containerID 按前缀匹配（可为短 ID），
+// names 为候选容器名（事件中不带前导 /），两者任一命中即可。没有记录时返回 nil, nil。
+func (s *Storage) LastContainerStart(ctx context.Context, containerID string, names []string) (*DockerEvent, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
+	}
+	containerID = strings.TrimSpace(containerID)
+	if containerID == "" && len(names) == 0 {
+		return nil, errors.New("container id or name is required")
+	}
+
+	match := s.db.WithContext(ctx)
+	switch {
+	case containerID != "" && len(names) > 0:
+		match = match.Where("substr(actor_id, 1, ?) = ?", len(containerID), containerID).Or("actor_name IN ?", names)
+	case containerID != "":
+		match = match.Where("substr(actor_id, 1, ?) = ?", len(containerID), containerID)
+	default:
+		match = match.Where("actor_name IN ?", names)
+	}
+
+	var out []DockerEvent
+	err := s.db.WithContext(ctx).Model(&DockerEvent{}).
+		Where("type = ? AND action IN ?", "container", []string{"start", "restart"}).
+		Where(match).
+		Order("timestamp DESC").
+		Limit(1).
+		Find(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("query last container start: %w", err)
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return &out[0], nil
+}
+
+func (s *Storage) eventsFiltered(ctx context.Context, q EventQuery) *gorm.DB {
+	db := s.db.WithContext(ctx).Model(&DockerEvent{})
+	if q.Type != "" {
+		db = db.Where("type = ?", q.Type)
+	}
+	if q.Action != "" {
+		db = db.Where("action = ?", q.Action)
+	}
+	if q.ActorID != "" {
+		db = db.Where("actor_id = ?", q.ActorID)
+	}
+	if q.ActorName != "" {
+		db = db.Where("actor_name = ?", q.ActorName)
+	}
+	if q.From != nil {
+		db = db.Where("timestamp >= ?", *q.From)
+	}
+	if q.To != nil {
+		db = db.Where("timestamp <= ?", *q.To)
+	}
+	return db
+}
+
+// HostStatsQuery 为宿主机采样的查询条件。
+type HostStatsQuery struct {
+	// From/To 过滤 CollectedAt 区间：[From, To]（两端包含）。
+	From *time.Time
+	To   *time.Time
+	// Limit 限制返回条数；<=0 使用默认值。
+	Limit int
+	// Desc 按 CollectedAt 倒序返回。
+	Desc bool
+}
+
+func (s *Storage) InsertHostStat(ctx context.Context, stat *HostStat) error {
+	if s == nil || s.db == nil {
+		return errors.New("storage not initialized")
+	}
+	if stat == nil {
+		return errors.New("host stat is nil")
+	}
+	now := time.Now().UTC()
+	if stat.CollectedAt.IsZero() {
+		stat.CollectedAt = now
+	}
+	if stat.CreatedAt.IsZero() {
+		stat.CreatedAt = now
+	}
+	if err := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Create(stat)
+	}).Error; err != nil {
+		return fmt.Errorf("insert host stat: %w", err)
+	}
+	return nil
+}
+
+func (s *Storage) QueryHostStats(ctx context.Context, q HostStatsQuery) ([]HostStat, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
+	}
+
+	db := s.db.WithContext(ctx).Model(&HostStat{})
+	if q.From != nil {
+		db = db.Where("collected_at >= ?", *q.From)
+	}
+	if q.To != nil {
+		db = db.Where("collected_at <= ?", *q.To)
+	}
+	if q.Desc {
+		db = db.Order("collected_at DESC")
+	} else {
+		db = db.Order("collected_at ASC")
+	}
+	db = db.Limit(normalizeLimit(q.Limit))
+
+	var out []HostStat
+	if err := db.Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("query host stats: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Storage) DeleteHostStatsBeforeLimited(ctx context.Context, before time.Time, limit int) (int64, error) {
+	if s == nil || s.db == nil {
+		return 0, errors.New("storage not initialized")
+	}
+
+	limit = normalizeDeleteLimit(limit)
+
+	var ids []uint64
+	db := s.db.WithContext(ctx).Model(&HostStat{}).
+		Select("id").
+		Where("collected_at < ?", before).
+		Order("id ASC").
+		Limit(limit)
+	if err := db.Find(&ids).Error; err != nil {
+		return 0, fmt.Errorf("select host stats ids: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	res := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Where("id IN ?", ids).Delete(&HostStat{})
+	})
+	if res.Error != nil {
+		return 0, fmt.Errorf("delete host stats: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// SaveChatSession 写入或覆盖一个对话会话（按 ID upsert），保留首次保存的 CreatedAt。
+func (s *Storage) SaveChatSession(ctx context.Context, sess *ChatSession) error {
+	if s == nil || s.db == nil {
+		return errors.New("storage not initialized")
+	}
+	if sess == nil {
+		return errors.New("chat session is nil")
+	}
+	if strings.TrimSpace(sess.ID) == "" {
+		return errors.New("chat session id is required")
+	}
+	now := time.Now().UTC()
+	if sess.CreatedAt.IsZero() {
+		sess.CreatedAt = now
+	}
+	sess.UpdatedAt = now
+	if err := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"title", "messages_json", "message_count", "updated_at"}),
+		}).Create(sess)
+	}).Error; err != nil {
+		return fmt.Errorf("save chat session: %w", err)
+	}
+	return nil
+}
+
+// GetChatSession 按 ID 读取对话会话；不存在时返回 notFoundError。
+func (s *Storage) GetChatSession(ctx context.Context, id string) (*ChatSession, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
+	}
+
+	var out []ChatSession
+	if err := s.db.WithContext(ctx).Where("id = ?", id).Limit(1).Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("get chat session: %w", err)
+	}
+	if len(out) == 0 {
+		return nil, gormNotFoundError("chat session", id)
+	}
+	return &out[0], nil
+}
+
+// ListChatSessions 按最近保存时间倒序列出会话（不含消息内容）。
+func (s *Storage) ListChatSessions(ctx context.Context, limit int) ([]ChatSession, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("storage not initialized")
+	}
+
+	var out []ChatSession
+	err := s.db.WithContext(ctx).Model(&ChatSession{}).
+		Select("id", "title", "message_count", "created_at", "updated_at").
+		Order("updated_at DESC").
+		Limit(normalizeLimit(limit)).
+		Find(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("list chat sessions: %w", err)
+	}
+	return out, nil
+}
+
+// DeleteChatSessionsBeforeLimited 删除 UpdatedAt 早于 before 的会话，单次最多 limit 条。
+func (s *Storage) DeleteChatSessionsBeforeLimited(ctx context.Context, before time.Time, limit int) (int64, error) {
+	if s == nil || s.db == nil {
+		return 0, errors.New("storage not initialized")
+	}
+
+	limit = normalizeDeleteLimit(limit)
+
+	var ids []string
+	db := s.db.WithContext(ctx).Model(&ChatSession{}).
+		Select("id").
+		Where("updated_at < ?", before).
+		Order("updated_at ASC").
+		Limit(limit)
+	if err := db.Find(&ids).Error; err != nil {
+		return 0, fmt.Errorf("select chat session ids: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	res := retryOnBusy(ctx, func() *gorm.DB {
+		return s.db.WithContext(ctx).Where("id IN ?", ids).Delete(&ChatSession{})
+	})
+	if res.Error != nil {
+		return 0, fmt.Errorf("delete chat sessions: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
 func normalizeLimit(v int) int {
 	if v <= 0 {
 		return defaultLimit
@@ -573,13 +1336,14 @@ func normalizeDeleteLimit(v int) int {
 
 type notFoundError struct {
 	Entity string
-	ID     uint64
+	ID     string
 }
 
 func (e notFoundError) Error() string {
-	return fmt.Sprintf("%s not found: %d", e.Entity, e.ID)
+	return fmt.Sprintf("%s not found: %s", e.Entity, e.ID)
 }
 
-func gormNotFoundError(entity string, id uint64) error {
-	return notFoundError{Entity: entity, ID: id}
+// gormNotFoundError 构造 notFoundError；id 可为数字主键或字符串 ID
+func gormNotFoundError(entity string, id any) error {
+	return notFoundError{Entity: entity, ID: fmt.Sprint(id)}
 }
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// busyRetryAttempts 为写操作遇到 SQLITE_BUSY 时的最大尝试次数（含首次）。
+	busyRetryAttempts = 5
+	// busyRetryBaseDelay 为首次重试前的等待时间，之后每次翻倍。
+	busyRetryBaseDelay = 25 * time.Millisecond
+)
+
+// retryOnBusy 执行写操作，遇到 SQLITE_BUSY/database is locked 时按指数退避重试。
+// busy_timeout 只覆盖单条语句的等待，清理与采集并发写入时仍可能超时，这里再兜底几次。
+func retryOnBusy(ctx context.Context, op func() *gorm.DB) *gorm.DB {
+	var res *gorm.DB
+	_ = retryOnBusyErr(ctx, func() error {
+		res = op()
+		return res.Error
+	})
+	return res
+}
+
+// retryOnBusyErr 与 retryOnBusy 相同，用于事务等只返回 error 的写操作。
+func retryOnBusyErr(ctx context.Context, op func() error) error {
+	delay := busyRetryBaseDelay
+	err := op()
+	for attempt := 1; attempt < busyRetryAttempts && isBusyError(err); attempt++ {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		delay *= 2
+		err = op()
+	}
+	return err
+}
+
+// isBusyError 判断错误是否为 SQLite 锁冲突（SQLITE_BUSY/SQLITE_LOCKED）。
+func isBusyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "sqlite_busy") ||
+		strings.Contains(msg, "database is locked") ||
+		strings.Contains(msg, "database table is locked")
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/glebarez/sqlite"
@@ -20,6 +21,7 @@ type Config struct {
 	MaxOpenConns    int              `mapstructure:"max_open_conns"`
 	MaxIdleConns    int              `mapstructure:"max_idle_conns"`
 	ConnMaxLifetime time.Duration    `mapstructure:"conn_max_lifetime"`
+	SkipMigrate     bool             `mapstructure:"skip_migrate"`
 	Logger          logger.Interface `mapstructure:"-"`
 }
 
@@ -28,6 +30,9 @@ type Storage struct {
 	sqlDB *sql.DB
 }
 
+// memoryDBSeq 为每个内存数据库生成独立的名称，同一进程内多次 Open 互不干扰
+var memoryDBSeq atomic.Int64
+
 func Open(ctx context.Context, cfg Config) (*Storage, error) {
 	if cfg.BusyTimeout <= 0 {
 		cfg.BusyTimeout = 5 * time.Second
@@ -38,6 +43,10 @@ func Open(ctx context.Context, cfg Config) (*Storage, error) {
 		return nil, err
 	}
 
+	if err := registerRegexp(); err != nil {
+		return nil, fmt.Errorf("register regexp function: %w", err)
+	}
+
 	gormCfg := &gorm.Config{}
 	if cfg.Logger != nil {
 		gormCfg.Logger = cfg.Logger
@@ -59,7 +68,8 @@ func Open(ctx context.Context, cfg Config) (*Storage, error) {
 	if cfg.MaxIdleConns > 0 {
 		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
 	}
-	if cfg.ConnMaxLifetime > 0 {
+	// 共享缓存的内存库在最后一条连接关闭时即被销毁，因此内存模式下不按生存时间回收连接
+	if cfg.ConnMaxLifetime > 0 && !cfg.InMemory {
 		sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 	}
 
@@ -77,9 +87,12 @@ func Open(ctx context.Context, cfg Config) (*Storage, error) {
 		return nil, fmt.Errorf("enable foreign keys: %w", err)
 	}
 
-	if err := s.Migrate(ctx); err != nil {
-		_ = s.Close()
-		return nil, err
+	// SkipMigrate 时由 storage migrate 显式升级，便于受控的版本升级
+	if !cfg.SkipMigrate {
+		if err := s.Migrate(ctx); err != nil {
+			_ = s.Close()
+			return nil, err
+		}
 	}
 
 	if err := s.Ping(ctx); err != nil {
@@ -104,30 +117,13 @@ func (s *Storage) Ping(ctx context.Context) error {
 	return s.sqlDB.PingContext(ctx)
 }
 
-func (s *Storage) Migrate(ctx context.Context) error {
-	if s == nil || s.db == nil {
-		return errors.New("storage not initialized")
-	}
-
-	// 临时修复：由于 AuditRecord 移除了 Actor 字段，但 SQLite 的 AutoMigrate 不会删除旧列/约束
-	// 导致 NOT NULL constraint failed。这里检查如果表存在且有 actor 列，则重建表。
-	if s.db.Migrator().HasTable(&AuditRecord{}) {
-		if s.db.Migrator().HasColumn(&AuditRecord{}, "actor") {
-			// 发现旧列，重建表
-			if err := s.db.Migrator().DropTable(&AuditRecord{}); err != nil {
-				return fmt.Errorf("drop old audit_records table: %w", err)
-			}
-		}
-	}
-
-	if err := s.db.WithContext(ctx).AutoMigrate(
-		&ContainerStat{},
-		&ContainerLog{},
-		&AuditRecord{},
-	); err != nil {
-		return fmt.Errorf("auto migrate: %w", err)
+// PoolStats 返回底层连接池的统计信息（打开/使用中/空闲连接数、等待次数与时长等），
+// 用于判断 MaxOpenConns 是否设置过小。
+func (s *Storage) PoolStats() sql.DBStats {
+	if s == nil || s.sqlDB == nil {
+		return sql.DBStats{}
 	}
-	return nil
+	return s.sqlDB.Stats()
 }
 
 func (s *Storage) DB() *gorm.DB {
@@ -144,7 +140,8 @@ func dsnFromConfig(cfg Config) (string, error) {
 	}
 
 	if cfg.InMemory {
-		return fmt.Sprintf("file:centagent?mode=memory&cache=shared&_busy_timeout=%d", timeoutMS), nil
+		name := fmt.Sprintf("centagent-%d", memoryDBSeq.Add(1))
+		return fmt.Sprintf("file:%s?mode=memory&cache=shared&_busy_timeout=%d", name, timeoutMS), nil
 	}
 
 	if cfg.Path == "" {
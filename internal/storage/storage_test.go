@@ -2,9 +2,17 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 func openTestStorage(t *testing.T) *Storage {
@@ -147,6 +155,31 @@ func TestContainerLogsQuery(t *testing.T) {
 	}
 }
 
+func TestContainerLogsQueryRegexp(t *testing.T) {
+	s := openTestStorage(t)
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Minute).UTC()
+	for i, msg := range []string{"GET /a 200", "GET /b 502", "POST /c 404"} {
+		l := ContainerLog{ContainerID: "cid-a", ContainerName: "web", Source: "stdout", Message: msg, Timestamp: base.Add(time.Duration(i) * time.Second)}
+		if err := s.InsertContainerLog(ctx, &l); err != nil {
+			t.Fatalf("insert log: %v", err)
+		}
+	}
+
+	got, err := s.QueryContainerLogs(ctx, LogQuery{Regexp: `^(GET|POST) /[a-z] [45]\d\d$`})
+	if err != nil {
+		t.Fatalf("query logs: %v", err)
+	}
+	if len(got) != 2 || got[0].Message != "GET /b 502" || got[1].Message != "POST /c 404" {
+		t.Fatalf("unexpected regexp result: %+v", got)
+	}
+
+	if _, err := s.QueryContainerLogs(ctx, LogQuery{Regexp: "("}); err == nil || !strings.Contains(err.Error(), "invalid regexp") {
+		t.Fatalf("expected invalid regexp error, got %v", err)
+	}
+}
+
 func TestRetentionPruneStatsAndLogs(t *testing.T) {
 	s := openTestStorage(t)
 	ctx := context.Background()
@@ -305,6 +338,17 @@ func TestAuditInsertQueryUpdate(t *testing.T) {
 	if got2[0].Status != "success" || got2[0].ResultJSON != result {
 		t.Fatalf("unexpected updated record: status=%s result=%s", got2[0].Status, got2[0].ResultJSON)
 	}
+
+	one, err := s.GetAuditRecord(ctx, rec.ID)
+	if err != nil {
+		t.Fatalf("get audit: %v", err)
+	}
+	if one.Action != "docker.ps" || one.ResultJSON != result {
+		t.Fatalf("unexpected audit record: %+v", one)
+	}
+	if _, err := s.GetAuditRecord(ctx, rec.ID+100); err == nil {
+		t.Fatalf("expected not found error")
+	}
 }
 
 func TestAuditDelete(t *testing.T) {
@@ -371,3 +415,769 @@ func TestAuditDelete(t *testing.T) {
 		t.Fatalf("expected remaining record to be t5, got %s", recs[0].TraceID)
 	}
 }
+
+func TestLatestStatPerContainer(t *testing.T) {
+	s := openTestStorage(t)
+	ctx := context.Background()
+
+	base := time.Now().Add(-10 * time.Minute).UTC()
+	stats := []ContainerStat{
+		{ContainerID: "cid-b", ContainerName: "redis-b", CPUPercent: 1, CollectedAt: base},
+		{ContainerID: "cid-a", ContainerName: "nginx-a", CPUPercent: 2, CollectedAt: base},
+		{ContainerID: "cid-a", ContainerName: "nginx-a", CPUPercent: 3, CollectedAt: base.Add(time.Minute)},
+		{ContainerID: "cid-b", ContainerName: "redis-b", CPUPercent: 4, CollectedAt: base.Add(2 * time.Minute)},
+	}
+	if err := s.InsertContainerStats(ctx, stats); err != nil {
+		t.Fatalf("insert stats: %v", err)
+	}
+
+	got, err := s.LatestStatPerContainer(ctx)
+	if err != nil {
+		t.Fatalf("latest stats: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 stats, got %d", len(got))
+	}
+	if got[0].ContainerID != "cid-a" || got[0].CPUPercent != 3 {
+		t.Fatalf("unexpected latest for cid-a: %+v", got[0])
+	}
+	if got[1].ContainerID != "cid-b" || got[1].CPUPercent != 4 {
+		t.Fatalf("unexpected latest for cid-b: %+v", got[1])
+	}
+}
+
+func TestQueryStatsBucketed(t *testing.T) {
+	s := openTestStorage(t)
+	ctx := context.Background()
+
+	from := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	to := from.Add(30 * time.Minute)
+	stats := []ContainerStat{
+		{ContainerID: "cid-a", ContainerName: "nginx-a", CPUPercent: 10, MemPercent: 20, CollectedAt: from.Add(time.Minute)},
+		{ContainerID: "cid-a", ContainerName: "nginx-a", CPUPercent: 30, MemPercent: 40, CollectedAt: from.Add(4 * time.Minute)},
+		{ContainerID: "cid-a", ContainerName: "nginx-a", CPUPercent: 50, MemPercent: 60, CollectedAt: from.Add(21 * time.Minute)},
+		{ContainerID: "cid-b", ContainerName: "redis-b", CPUPercent: 5, MemPercent: 5, CollectedAt: from.Add(12 * time.Minute)},
+		// 窗口外的采样不参与分桶
+		{ContainerID: "cid-a", ContainerName: "nginx-a", CPUPercent: 99, MemPercent: 99, CollectedAt: from.Add(-time.Minute)},
+	}
+	if err := s.InsertContainerStats(ctx, stats); err != nil {
+		t.Fatalf("insert stats: %v", err)
+	}
+
+	got, err := s.QueryStatsBucketed(ctx, StatsQuery{From: &from, To: &to}, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("bucketed stats: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 buckets, got %+v", got)
+	}
+	if b := got[0]; b.ContainerID != "cid-a" || !b.Start.Equal(from) || b.Samples != 2 || b.AvgCPUPercent != 20 || b.AvgMemPercent != 30 {
+		t.Fatalf("unexpected first bucket: %+v", b)
+	}
+	if b := got[1]; b.ContainerID != "cid-a" || !b.Start.Equal(from.Add(20*time.Minute)) || b.Samples != 1 || b.AvgCPUPercent != 50 {
+		t.Fatalf("unexpected second bucket: %+v", b)
+	}
+	if b := got[2]; b.ContainerID != "cid-b" || b.ContainerName != "redis-b" || !b.Start.Equal(from.Add(10*time.Minute)) {
+		t.Fatalf("unexpected third bucket: %+v", b)
+	}
+
+	if _, err := s.QueryStatsBucketed(ctx, StatsQuery{From: &from}, time.Minute); err == nil {
+		t.Fatalf("expected error without to")
+	}
+	if _, err := s.QueryStatsBucketed(ctx, StatsQuery{From: &from, To: &to}, 0); err == nil {
+		t.Fatalf("expected error for zero bucket")
+	}
+}
+
+func TestNewDockerEvent(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	msg := events.Message{
+		Type:     events.ContainerEventType,
+		Action:   "die",
+		Actor:    events.Actor{ID: "cid-a", Attributes: map[string]string{"name": "nginx", "exitCode": "137"}},
+		TimeNano: at.UnixNano(),
+	}
+	ev := NewDockerEvent(msg)
+	if ev.Type != "container" || ev.Action != "die" || ev.ActorID != "cid-a" || ev.ActorName != "nginx" ||
+		!strings.Contains(ev.AttributesJSON, `"exitCode":"137"`) || !ev.Timestamp.Equal(at) || ev.Timestamp.Location() != time.UTC {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+
+	// 没有 TimeNano 时退回秒级 Time
+	msg.TimeNano = 0
+	msg.Time = at.Unix()
+	if got := EventTime(msg); !got.Equal(at.Truncate(time.Second)) || got.Location() != time.UTC {
+		t.Fatalf("unexpected event time: %v", got)
+	}
+}
+
+func TestDockerEventsInsertQuery(t *testing.T) {
+	s := openTestStorage(t)
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Minute).UTC()
+	events := []DockerEvent{
+		{Type: "container", Action: "start", ActorID: "cid-a", ActorName: "nginx", Timestamp: base},
+		{Type: "image", Action: "pull", ActorID: "nginx:alpine", Timestamp: base.Add(time.Second)},
+		{Type: "container", Action: "die", ActorID: "cid-a", ActorName: "nginx", Timestamp: base.Add(2 * time.Second)},
+	}
+	for i := range events {
+		if err := s.InsertDockerEvent(ctx, &events[i]); err != nil {
+			t.Fatalf("insert event %d: %v", i, err)
+		}
+	}
+
+	got, err := s.QueryDockerEvents(ctx, EventQuery{Type: "container", Desc: true})
+	if err != nil {
+		t.Fatalf("query events: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 container events, got %d", len(got))
+	}
+	if got[0].Action != "die" || got[1].Action != "start" {
+		t.Fatalf("unexpected order: %s then %s", got[0].Action, got[1].Action)
+	}
+}
+
+func TestStorageInfo(t *testing.T) {
+	s := openTestStorage(t)
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	stats := []ContainerStat{
+		{ContainerID: "cid-a", ContainerName: "a", CollectedAt: base},
+		{ContainerID: "cid-a", ContainerName: "a", CollectedAt: base.Add(30 * time.Minute)},
+	}
+	if err := s.InsertContainerStats(ctx, stats); err != nil {
+		t.Fatalf("insert stats: %v", err)
+	}
+
+	info, err := s.Info(ctx)
+	if err != nil {
+		t.Fatalf("storage info: %v", err)
+	}
+	if info.PageSize <= 0 || info.PageCount <= 0 {
+		t.Fatalf("unexpected page stats: %+v", info)
+	}
+
+	var statsInfo *TableInfo
+	for i := range info.Tables {
+		if info.Tables[i].Name == "container_stats" {
+			statsInfo = &info.Tables[i]
+		}
+	}
+	if statsInfo == nil {
+		t.Fatalf("container_stats missing from info")
+	}
+	if statsInfo.Rows != 2 {
+		t.Fatalf("expected 2 rows, got %d", statsInfo.Rows)
+	}
+	if statsInfo.Oldest == nil || !statsInfo.Oldest.Equal(base) {
+		t.Fatalf("unexpected oldest: %v", statsInfo.Oldest)
+	}
+	if statsInfo.Newest == nil || !statsInfo.Newest.Equal(base.Add(30*time.Minute)) {
+		t.Fatalf("unexpected newest: %v", statsInfo.Newest)
+	}
+	if info.DBStat && statsInfo.TableBytes <= 0 {
+		t.Fatalf("expected table size with dbstat, got %d", statsInfo.TableBytes)
+	}
+	if !info.DBStat && statsInfo.TableBytes != -1 {
+		t.Fatalf("expected -1 table size without dbstat, got %d", statsInfo.TableBytes)
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+	cases := map[string]logger.LogLevel{
+		"debug": logger.Info,
+		"DEBUG": logger.Info,
+		"info":  logger.Warn,
+		"warn":  logger.Warn,
+		"error": logger.Error,
+		"":      logger.Warn,
+	}
+	for in, want := range cases {
+		if got := LogLevel(in); got != want {
+			t.Fatalf("LogLevel(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestSQLiteRegexpFunction(t *testing.T) {
+	s := openTestStorage(t)
+	ctx := context.Background()
+
+	var matched bool
+	if err := s.db.WithContext(ctx).Raw(`SELECT 'order id=12345 failed' REGEXP ?`, `id=\d{5}\b`).Scan(&matched).Error; err != nil {
+		t.Fatalf("regexp query: %v", err)
+	}
+	if !matched {
+		t.Fatalf("expected numeric pattern to match")
+	}
+	if err := s.db.WithContext(ctx).Raw(`SELECT 'order id=123 failed' REGEXP ?`, `id=\d{5}\b`).Scan(&matched).Error; err != nil {
+		t.Fatalf("regexp query: %v", err)
+	}
+	if matched {
+		t.Fatalf("expected short id not to match")
+	}
+
+	regexpCache.Lock()
+	_, cached := regexpCache.m[`id=\d{5}\b`]
+	regexpCache.Unlock()
+	if !cached {
+		t.Fatalf("expected compiled pattern to be cached")
+	}
+}
+
+func TestAggregateStatsAndCountEvents(t *testing.T) {
+	s := openTestStorage(t)
+	ctx := context.Background()
+
+	base := time.Now().Add(-10 * time.Minute).UTC()
+	stats := []ContainerStat{
+		{ContainerID: "cid-a", ContainerName: "/a", CPUPercent: 10, MemPercent: 20, MemUsageBytes: 100, CollectedAt: base},
+		{ContainerID: "cid-a", ContainerName: "/a", CPUPercent: 30, MemPercent: 40, MemUsageBytes: 300, CollectedAt: base.Add(time.Minute)},
+		{ContainerID: "cid-a", ContainerName: "/a", CPUPercent: 99, MemPercent: 99, MemUsageBytes: 900, CollectedAt: base.Add(-time.Hour)},
+		{ContainerID: "cid-b", ContainerName: "/b", CPUPercent: 50, MemPercent: 50, MemUsageBytes: 500, CollectedAt: base},
+	}
+	if err := s.InsertContainerStats(ctx, stats); err != nil {
+		t.Fatalf("insert stats: %v", err)
+	}
+
+	from := base.Add(-time.Second)
+	agg, err := s.AggregateStats(ctx, StatsQuery{ContainerID: "cid-a", From: &from})
+	if err != nil {
+		t.Fatalf("aggregate stats: %v", err)
+	}
+	if agg.Samples != 2 || agg.ContainerName != "/a" {
+		t.Fatalf("unexpected aggregate: %+v", agg)
+	}
+	if agg.AvgCPUPercent != 20 || agg.MaxCPUPercent != 30 || agg.AvgMemPercent != 30 || agg.MaxMemUsageBytes != 300 {
+		t.Fatalf("unexpected aggregate values: %+v", agg)
+	}
+
+	empty, err := s.AggregateStats(ctx, StatsQuery{ContainerID: "missing"})
+	if err != nil {
+		t.Fatalf("aggregate missing: %v", err)
+	}
+	if empty.Samples != 0 {
+		t.Fatalf("expected no samples, got %+v", empty)
+	}
+
+	for _, action := range []string{"restart", "restart", "die"} {
+		ev := &DockerEvent{Type: "container", Action: action, ActorID: "cid-a-full", ActorName: "a", Timestamp: base}
+		if err := s.InsertDockerEvent(ctx, ev); err != nil {
+			t.Fatalf("insert event: %v", err)
+		}
+	}
+	n, err := s.CountDockerEvents(ctx, EventQuery{ActorName: "a", Action: "restart"})
+	if err != nil {
+		t.Fatalf("count events: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 restart events, got %d", n)
+	}
+}
+
+func TestLogLevelCounts(t *testing.T) {
+	s := openTestStorage(t)
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Minute).UTC()
+	logs := []ContainerLog{
+		{ContainerID: "cid-a", Level: "ERROR", Message: "e1", Timestamp: base},
+		{ContainerID: "cid-a", Level: "ERROR", Message: "e2", Timestamp: base},
+		{ContainerID: "cid-a", Level: "INFO", Message: "i1", Timestamp: base},
+		{ContainerID: "cid-a", Message: "plain", Timestamp: base},
+		{ContainerID: "cid-b", Level: "ERROR", Message: "other", Timestamp: base},
+	}
+	if err := s.InsertContainerLogs(ctx, logs); err != nil {
+		t.Fatalf("insert logs: %v", err)
+	}
+
+	counts, err := s.LogLevelCounts(ctx, LogQuery{ContainerID: "cid-a"})
+	if err != nil {
+		t.Fatalf("log level counts: %v", err)
+	}
+	if counts["ERROR"] != 2 || counts["INFO"] != 1 || counts[""] != 1 {
+		t.Fatalf("unexpected counts: %+v", counts)
+	}
+}
+
+func TestCheckpointTruncatesWAL(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "centagent.db")
+	s, err := Open(ctx, Config{Path: dbPath, EnableWAL: true})
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	base := time.Now().Add(-time.Hour).UTC()
+	stats := make([]ContainerStat, 0, 500)
+	for i := 0; i < 500; i++ {
+		stats = append(stats, ContainerStat{
+			ContainerID:   "cid-a",
+			ContainerName: "a",
+			RawJSON:       strings.Repeat("x", 512),
+			CollectedAt:   base.Add(time.Duration(i) * time.Second),
+		})
+	}
+	if err := s.InsertContainerStats(ctx, stats); err != nil {
+		t.Fatalf("insert stats: %v", err)
+	}
+
+	walPath := dbPath + "-wal"
+	before, err := os.Stat(walPath)
+	if err != nil {
+		t.Fatalf("stat wal: %v", err)
+	}
+	if before.Size() == 0 {
+		t.Fatalf("expected wal to grow after writes")
+	}
+
+	res, err := s.Checkpoint(ctx, "")
+	if err != nil {
+		t.Fatalf("checkpoint: %v", err)
+	}
+	if res.Busy != 0 {
+		t.Fatalf("checkpoint blocked: %+v", res)
+	}
+	after, err := os.Stat(walPath)
+	if err != nil {
+		t.Fatalf("stat wal: %v", err)
+	}
+	if after.Size() >= before.Size() {
+		t.Fatalf("expected wal to shrink, before=%d after=%d", before.Size(), after.Size())
+	}
+
+	if _, err := s.Checkpoint(ctx, "bogus"); err == nil {
+		t.Fatalf("expected error for invalid mode")
+	}
+}
+
+func TestInsertRetriesOnBusy(t *testing.T) {
+	ctx := context.Background()
+	s, err := Open(ctx, Config{
+		Path:        filepath.Join(t.TempDir(), "centagent.db"),
+		EnableWAL:   true,
+		BusyTimeout: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	// 用独立连接持有写锁，模拟清理任务与采集写入并发
+	conn, err := s.sqlDB.Conn(ctx)
+	if err != nil {
+		t.Fatalf("get conn: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		t.Fatalf("begin immediate: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var insertErr, commitErr error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		time.Sleep(100 * time.Millisecond)
+		_, commitErr = conn.ExecContext(ctx, "COMMIT")
+	}()
+	go func() {
+		defer wg.Done()
+		insertErr = s.InsertContainerStat(ctx, &ContainerStat{ContainerID: "cid-a", ContainerName: "a"})
+	}()
+	wg.Wait()
+
+	if commitErr != nil {
+		t.Fatalf("commit: %v", commitErr)
+	}
+	if insertErr != nil {
+		t.Fatalf("expected insert to succeed after retry, got %v", insertErr)
+	}
+	n, err := s.CountContainerStats(ctx)
+	if err != nil {
+		t.Fatalf("count stats: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 stat, got %d", n)
+	}
+}
+
+func TestInsertStatsTxAllOrNothing(t *testing.T) {
+	s := openTestStorage(t)
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Hour).UTC()
+	newBatch := func(n int) []ContainerStat {
+		out := make([]ContainerStat, 0, n)
+		for i := 0; i < n; i++ {
+			out = append(out, ContainerStat{
+				ID:            uint64(i + 1),
+				ContainerID:   "cid-a",
+				ContainerName: "a",
+				CollectedAt:   base.Add(time.Duration(i) * time.Second),
+			})
+		}
+		return out
+	}
+
+	// 第二个子批次中的行与第一行主键冲突，整批应回滚
+	bad := newBatch(500)
+	bad[350].ID = bad[0].ID
+	if err := s.InsertStatsTx(ctx, bad); err == nil {
+		t.Fatalf("expected insert to fail on duplicate primary key")
+	}
+	n, err := s.CountContainerStats(ctx)
+	if err != nil {
+		t.Fatalf("count stats: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("expected rollback to leave 0 rows, got %d", n)
+	}
+
+	if err := s.InsertStatsTx(ctx, newBatch(500)); err != nil {
+		t.Fatalf("insert stats tx: %v", err)
+	}
+	n, err = s.CountContainerStats(ctx)
+	if err != nil {
+		t.Fatalf("count stats: %v", err)
+	}
+	if n != 500 {
+		t.Fatalf("expected 500 rows, got %d", n)
+	}
+}
+
+func TestInsertContainerStatsIgnoresDuplicates(t *testing.T) {
+	s := openTestStorage(t)
+	ctx := context.Background()
+
+	at := time.Now().Add(-time.Minute).UTC()
+	sample := ContainerStat{ContainerID: "cid-a", ContainerName: "a", CPUPercent: 1, CollectedAt: at}
+	first, second := sample, sample
+	if err := s.InsertContainerStat(ctx, &first); err != nil {
+		t.Fatalf("insert stat: %v", err)
+	}
+	if err := s.InsertContainerStat(ctx, &second); err != nil {
+		t.Fatalf("insert duplicate stat: %v", err)
+	}
+	if err := s.InsertContainerStats(ctx, []ContainerStat{sample, sample}); err != nil {
+		t.Fatalf("insert duplicate batch: %v", err)
+	}
+
+	n, err := s.CountContainerStats(ctx)
+	if err != nil {
+		t.Fatalf("count stats: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected duplicates to be ignored, got %d rows", n)
+	}
+}
+
+func TestPoolStats(t *testing.T) {
+	ctx := context.Background()
+	s, err := Open(ctx, Config{
+		Path:         filepath.Join(t.TempDir(), "centagent.db"),
+		MaxOpenConns: 4,
+	})
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.CountContainerStats(ctx); err != nil {
+			t.Fatalf("count stats: %v", err)
+		}
+	}
+
+	st := s.PoolStats()
+	if st.MaxOpenConnections != 4 {
+		t.Fatalf("expected max open connections 4, got %d", st.MaxOpenConnections)
+	}
+	if st.OpenConnections < 1 || st.OpenConnections > 4 {
+		t.Fatalf("unexpected open connections: %d", st.OpenConnections)
+	}
+	if st.InUse != 0 || st.Idle != st.OpenConnections {
+		t.Fatalf("expected all connections idle after queries, got %+v", st)
+	}
+
+	var nilStore *Storage
+	if got := nilStore.PoolStats(); got.OpenConnections != 0 {
+		t.Fatalf("expected zero stats for nil storage, got %+v", got)
+	}
+}
+
+func TestContainerStatsLabelFilter(t *testing.T) {
+	s := openTestStorage(t)
+	ctx := context.Background()
+
+	base := time.Now().Add(-10 * time.Minute).UTC()
+	stats := []ContainerStat{
+		{ContainerID: "cid-a", ContainerName: "web-1", CPUPercent: 10, Labels: `{"app":"web","tier":"front"}`, CollectedAt: base},
+		{ContainerID: "cid-b", ContainerName: "web-2", CPUPercent: 30, Labels: `{"app":"web"}`, CollectedAt: base},
+		{ContainerID: "cid-c", ContainerName: "db", CPUPercent: 90, Labels: `{"app":"db"}`, CollectedAt: base},
+		{ContainerID: "cid-d", ContainerName: "plain", CPUPercent: 50, CollectedAt: base},
+	}
+	if err := s.InsertContainerStats(ctx, stats); err != nil {
+		t.Fatalf("insert stats: %v", err)
+	}
+
+	got, err := s.QueryContainerStats(ctx, StatsQuery{ContainerID: "cid-a"})
+	if err != nil {
+		t.Fatalf("query stats: %v", err)
+	}
+	if len(got) != 1 || got[0].Labels != `{"app":"web","tier":"front"}` {
+		t.Fatalf("labels did not round-trip: %+v", got)
+	}
+
+	web, err := s.QueryContainerStats(ctx, StatsQuery{Label: "app=web"})
+	if err != nil {
+		t.Fatalf("query by label: %v", err)
+	}
+	if len(web) != 2 {
+		t.Fatalf("expected 2 samples labeled app=web, got %d", len(web))
+	}
+
+	tier, err := s.QueryContainerStats(ctx, StatsQuery{Label: "tier"})
+	if err != nil {
+		t.Fatalf("query by label key: %v", err)
+	}
+	if len(tier) != 1 || tier[0].ContainerID != "cid-a" {
+		t.Fatalf("expected only cid-a to have tier label, got %+v", tier)
+	}
+
+	agg, err := s.AggregateStats(ctx, StatsQuery{Label: "app=web"})
+	if err != nil {
+		t.Fatalf("aggregate by label: %v", err)
+	}
+	if agg.Samples != 2 || agg.AvgCPUPercent != 20 {
+		t.Fatalf("unexpected aggregate for app=web: %+v", agg)
+	}
+}
+
+func TestErrorRatePerContainer(t *testing.T) {
+	s := openTestStorage(t)
+	ctx := context.Background()
+
+	base := time.Now().Add(-30 * time.Minute).UTC()
+	var logs []ContainerLog
+	add := func(id, name, level string, n int, at time.Time) {
+		for i := 0; i < n; i++ {
+			logs = append(logs, ContainerLog{
+				ContainerID:   id,
+				ContainerName: name,
+				Source:        "stdout",
+				Level:         level,
+				Message:       level + " line",
+				Timestamp:     at.Add(time.Duration(i) * time.Second),
+			})
+		}
+	}
+	add("cid-a", "api", "ERROR", 5, base)
+	add("cid-a", "api", "INFO", 5, base)
+	add("cid-b", "worker", "ERROR", 1, base)
+	add("cid-b", "worker", "FATAL", 1, base)
+	add("cid-b", "worker", "WARN", 2, base)
+	add("cid-c", "quiet", "INFO", 3, base)
+	// 窗口之外的错误不计入
+	add("cid-c", "quiet", "ERROR", 10, base.Add(-2*time.Hour))
+	if err := s.InsertContainerLogs(ctx, logs); err != nil {
+		t.Fatalf("insert logs: %v", err)
+	}
+
+	got, err := s.ErrorRatePerContainer(ctx, base.Add(-time.Minute), time.Now().UTC())
+	if err != nil {
+		t.Fatalf("error rate: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 containers, got %+v", got)
+	}
+	if got[0].ContainerID != "cid-a" || got[0].Errors != 5 || got[0].Total != 10 || got[0].ErrorRate != 0.5 {
+		t.Fatalf("unexpected first hotspot: %+v", got[0])
+	}
+	if got[1].ContainerID != "cid-b" || got[1].Errors != 2 || got[1].Total != 4 {
+		t.Fatalf("unexpected second hotspot: %+v", got[1])
+	}
+	if got[2].ContainerID != "cid-c" || got[2].Errors != 0 || got[2].Total != 3 || got[2].ContainerName != "quiet" {
+		t.Fatalf("unexpected third entry: %+v", got[2])
+	}
+}
+
+func TestRecentAnomalies(t *testing.T) {
+	s := openTestStorage(t)
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Hour).UTC()
+	stats := []ContainerStat{
+		{ContainerID: "a", ContainerName: "api", CPUPercent: 10, MemPercent: 20, CollectedAt: base},
+		{ContainerID: "a", ContainerName: "api", CPUPercent: 95, MemPercent: 20, CollectedAt: base.Add(time.Minute)},
+		{ContainerID: "b", ContainerName: "db", CPUPercent: 5, MemPercent: 92, CollectedAt: base.Add(2 * time.Minute)},
+		{ContainerID: "b", ContainerName: "db", CPUPercent: 79.9, MemPercent: 79.9, CollectedAt: base.Add(3 * time.Minute)},
+		{ContainerID: "c", ContainerName: "web", CPUPercent: 80, MemPercent: 1, CollectedAt: base.Add(4 * time.Minute)},
+	}
+	if err := s.InsertContainerStats(ctx, stats); err != nil {
+		t.Fatalf("insert stats: %v", err)
+	}
+
+	got, err := s.RecentAnomalies(ctx, 80, 80, 10)
+	if err != nil {
+		t.Fatalf("recent anomalies: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 anomalies, got %+v", got)
+	}
+	wantNames := []string{"web", "db", "api"}
+	for i, st := range got {
+		if st.ContainerName != wantNames[i] {
+			t.Fatalf("unexpected order at %d: %+v", i, got)
+		}
+	}
+
+	got, err = s.RecentAnomalies(ctx, 80, 80, 1)
+	if err != nil || len(got) != 1 || got[0].ContainerName != "web" {
+		t.Fatalf("expected limit to keep the newest anomaly, got %+v (err=%v)", got, err)
+	}
+
+	// 仅检查内存
+	got, err = s.RecentAnomalies(ctx, 0, 80, 10)
+	if err != nil || len(got) != 1 || got[0].ContainerName != "db" {
+		t.Fatalf("expected only the memory anomaly, got %+v (err=%v)", got, err)
+	}
+
+	// 保留策略删除非异常采样后，剩余的正是 RecentAnomalies 返回的行
+	if _, err := s.DeleteContainerStatsNonAnomalyInRangeLimited(ctx, base.Add(-time.Minute), time.Now(), 80, 80, 100); err != nil {
+		t.Fatalf("delete non-anomaly: %v", err)
+	}
+	remain, err := s.QueryContainerStats(ctx, StatsQuery{})
+	if err != nil {
+		t.Fatalf("query stats: %v", err)
+	}
+	if len(remain) != 3 {
+		t.Fatalf("expected retention to keep exactly the 3 anomalies, got %+v", remain)
+	}
+}
+
+func TestOpenInMemory(t *testing.T) {
+	ctx := context.Background()
+	s, err := Open(ctx, Config{InMemory: true, MaxOpenConns: 1, MaxIdleConns: 1})
+	if err != nil {
+		t.Fatalf("open in-memory storage: %v", err)
+	}
+	defer s.Close()
+
+	// 迁移应已在内存库上执行
+	if !s.DB().Migrator().HasTable(&ContainerStat{}) {
+		t.Fatalf("expected container_stats table after migration")
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := s.InsertContainerStats(ctx, []ContainerStat{{ContainerID: "cid-mem", ContainerName: "mem", CPUPercent: 12.5, CollectedAt: now}}); err != nil {
+		t.Fatalf("insert stats: %v", err)
+	}
+	got, err := s.QueryContainerStats(ctx, StatsQuery{ContainerID: "cid-mem"})
+	if err != nil {
+		t.Fatalf("query stats: %v", err)
+	}
+	if len(got) != 1 || got[0].CPUPercent != 12.5 {
+		t.Fatalf("unexpected stats from in-memory store: %+v", got)
+	}
+
+	// 另一个内存库与之隔离
+	other, err := Open(ctx, Config{InMemory: true})
+	if err != nil {
+		t.Fatalf("open second in-memory storage: %v", err)
+	}
+	defer other.Close()
+	if rows, err := other.QueryContainerStats(ctx, StatsQuery{ContainerID: "cid-mem"}); err != nil || len(rows) != 0 {
+		t.Fatalf("expected isolated in-memory stores, got %d rows (err=%v)", len(rows), err)
+	}
+}
+
+func TestApplyMigrations(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "centagent.db")
+
+	s, err := Open(ctx, Config{Path: dbPath, SkipMigrate: true})
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	defer s.Close()
+
+	status, err := s.Migrations(ctx)
+	if err != nil {
+		t.Fatalf("migration status: %v", err)
+	}
+	for _, m := range status {
+		if m.Applied {
+			t.Fatalf("expected no migrations applied with SkipMigrate, got %+v", m)
+		}
+	}
+
+	applied, err := s.ApplyMigrations(ctx)
+	if err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Fatalf("expected %d migrations applied, got %+v", len(migrations), applied)
+	}
+	if !s.db.Migrator().HasTable(&ContainerStat{}) {
+		t.Fatalf("expected schema to be created after migrate")
+	}
+
+	// 新增一个待执行的迁移：只执行新版本，已执行的不重复
+	extra := append(append([]Migration(nil), migrations...), Migration{
+		Version: 1000,
+		Name:    "test_add_marker",
+		Up: func(ctx context.Context, tx *gorm.DB) error {
+			return tx.Exec("CREATE TABLE migration_marker (id INTEGER PRIMARY KEY)").Error
+		},
+	})
+	applied, err = s.applyMigrations(ctx, extra)
+	if err != nil {
+		t.Fatalf("apply pending migration: %v", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 1000 || applied[0].AppliedAt == nil {
+		t.Fatalf("expected only the pending migration to run, got %+v", applied)
+	}
+	if !s.db.Migrator().HasTable("migration_marker") {
+		t.Fatalf("expected pending migration to run")
+	}
+
+	status, err = s.migrationStatus(ctx, extra)
+	if err != nil {
+		t.Fatalf("migration status: %v", err)
+	}
+	if len(status) != len(extra) || !status[len(status)-1].Applied {
+		t.Fatalf("expected status to reflect applied migration, got %+v", status)
+	}
+
+	applied, err = s.applyMigrations(ctx, extra)
+	if err != nil || len(applied) != 0 {
+		t.Fatalf("expected rerun to be a no-op, got %+v, %v", applied, err)
+	}
+
+	// 失败的迁移整体回滚，且不记录版本
+	failing := append(extra, Migration{
+		Version: 1001,
+		Name:    "test_failing",
+		Up: func(ctx context.Context, tx *gorm.DB) error {
+			if err := tx.Exec("CREATE TABLE half_done (id INTEGER)").Error; err != nil {
+				return err
+			}
+			return errors.New("boom")
+		},
+	})
+	if _, err := s.applyMigrations(ctx, failing); err == nil || !strings.Contains(err.Error(), "migration 1001") {
+		t.Fatalf("expected failing migration error, got %v", err)
+	}
+	if s.db.Migrator().HasTable("half_done") {
+		t.Fatalf("expected failed migration to be rolled back")
+	}
+	status, _ = s.migrationStatus(ctx, failing)
+	if status[len(status)-1].Applied {
+		t.Fatalf("failed migration must not be recorded as applied")
+	}
+}
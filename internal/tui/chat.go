@@ -1,15 +1,18 @@
 package tui
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
-	"os"
+	"math"
+	"sort"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/spinner"
-	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
@@ -17,28 +20,60 @@ import (
 	"github.com/cloudwego/eino/schema"
 	"github.com/google/uuid"
 	"github.com/wwwzy/CentAgent/internal/agent"
+	"github.com/wwwzy/CentAgent/internal/logging"
 	"github.com/wwwzy/CentAgent/internal/ui"
 )
 
 type ChatUI struct{}
 
 func (u *ChatUI) Run(ctx context.Context, backend ui.ChatBackend, initial agent.AgentState, opts ui.ChatOptions) error {
+	// 全屏界面运行期间写入终端的日志会破坏画面，暂时丢弃内部日志
+	prevLogger := logging.SetDefault(nil)
+	defer logging.SetDefault(prevLogger)
+
 	m := newChatModel(ctx, backend, initial, opts)
 	p := tea.NewProgram(m, tea.WithAltScreen())
-	_, err := p.Run()
-	return err
+	final, err := p.Run()
+	if err != nil {
+		return err
+	}
+
+	if opts.TranscriptPath != "" {
+		if fm, ok := final.(chatModel); ok {
+			if err := ui.SaveTranscript(opts.TranscriptPath, fm.state.Messages); err != nil {
+				return fmt.Errorf("保存会话记录失败: %w", err)
+			}
+		}
+	}
+	return nil
 }
 
 type backendResultMsg struct {
 	state     agent.AgentState
 	err       error
 	prevCount int
+	seq       int
 }
 
 type streamTickMsg struct{}
 type cancelMsg struct{}
 
-var stdioMu sync.Mutex
+// toolProgressMsg 为调用进行中工具上报的进度；seq 用于丢弃已结束调用的进度
+type toolProgressMsg struct {
+	progress agent.ToolProgress
+	seq      int
+}
+
+const (
+	// inputHistoryLimit 为输入历史最多保留的条数
+	inputHistoryLimit = 100
+	// inputLines 为输入框可见的行数
+	inputLines = 3
+	// toolCollapseLines 为工具输出超过该行数时默认折叠
+	toolCollapseLines = 8
+	// progressBarWidth 为底栏工具进度条的宽度
+	progressBarWidth = 20
+)
 
 type chatModel struct {
 	ctx     context.Context
@@ -51,13 +86,14 @@ type chatModel struct {
 	height int
 
 	viewport   viewport.Model
-	input      textinput.Model
+	input      textarea.Model
 	spinner    spinner.Model
 	thinking   bool
 	followTail bool
 
 	confirmVisible bool
 	confirmTitle   string
+	confirmCalls   []schema.ToolCall
 	confirmIndex   int
 
 	overrideContent map[int]string
@@ -68,7 +104,38 @@ type chatModel struct {
 
 	renderer *glamour.TermRenderer
 
+	search searchState
+
 	lastInvokePrevCount int
+
+	// cancelInvoke 取消当前进行中的 backend.Invoke；invokeSeq 用于丢弃已取消调用的迟到结果
+	cancelInvoke context.CancelFunc
+	invokeSeq    int
+
+	// progressCh 接收当前调用中工具上报的进度，progressDone 在调用结束时关闭；progress 为最近一次进度
+	progressCh   chan agent.ToolProgress
+	progressDone <-chan struct{}
+	progress     *agent.ToolProgress
+
+	// thinkingSince 为本次调用开始时间；durations 记录每次调用最终回复（按 Messages 下标）的耗时
+	thinkingSince time.Time
+	durations     map[int]time.Duration
+
+	// history 为已发送消息的环形缓冲；historyIdx 为 -1 表示未在浏览历史
+	// historyDraft 保存开始浏览前输入框中尚未发送的内容
+	history      []string
+	historyIdx   int
+	historyDraft string
+
+	// expandedTools 记录已展开的工具消息（按 Messages 下标）；selectedTool 为当前选中的工具消息，-1 表示未选中
+	expandedTools map[int]bool
+	selectedTool  int
+
+	// showHelp 为 true 时在对话末尾显示 /help 帮助
+	showHelp bool
+
+	// notice 为显示在底栏右侧的一次性提示（如复制结果），下一次按键时清除
+	notice string
 }
 
 func newChatModel(ctx context.Context, backend ui.ChatBackend, initial agent.AgentState, opts ui.ChatOptions) chatModel {
@@ -80,9 +147,13 @@ func newChatModel(ctx context.Context, backend ui.ChatBackend, initial agent.Age
 	s := spinner.New()
 	s.Spinner = spinner.MiniDot
 
-	ti := textinput.New()
-	ti.Placeholder = "输入消息，回车发送"
+	ti := textarea.New()
+	ti.Placeholder = "输入消息，回车发送，Alt+Enter 换行"
 	ti.Prompt = ""
+	ti.ShowLineNumbers = false
+	ti.SetHeight(inputLines)
+	// Enter 用于发送，换行改为 Alt+Enter / Ctrl+J
+	ti.KeyMap.InsertNewline.SetKeys("alt+enter", "ctrl+j")
 	ti.Focus()
 
 	vp := viewport.New(0, 0)
@@ -98,11 +169,16 @@ func newChatModel(ctx context.Context, backend ui.ChatBackend, initial agent.Age
 		spinner:         s,
 		followTail:      true,
 		overrideContent: map[int]string{},
+		historyIdx:      -1,
+		search:          searchState{input: newSearchInput()},
+		expandedTools:   map[int]bool{},
+		durations:       map[int]time.Duration{},
+		selectedTool:    -1,
 	}
 }
 
 func (m chatModel) Init() tea.Cmd {
-	return tea.Batch(textinput.Blink, m.spinner.Tick, waitCancel(m.ctx))
+	return tea.Batch(textarea.Blink, m.spinner.Tick, waitCancel(m.ctx))
 }
 
 func waitCancel(ctx context.Context) tea.Cmd {
@@ -121,7 +197,8 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 
-		inputHeight := 3
+		// 输入框行数 + 上下边框
+		inputHeight := inputLines + 2
 		footerHeight := 1
 		chatHeight := m.height - inputHeight - footerHeight
 		if chatHeight < 1 {
@@ -131,7 +208,7 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.viewport.Width = m.width
 		m.viewport.Height = chatHeight
 
-		m.input.Width = max(10, m.width-4)
+		m.input.SetWidth(max(10, m.width-4))
 
 		m.resetMarkdownRenderer()
 		m.updateViewportContent(m.renderChat())
@@ -146,12 +223,23 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case backendResultMsg:
+		if msg.seq != m.invokeSeq {
+			// 已取消的调用返回的结果，直接丢弃
+			return m, nil
+		}
+		if m.cancelInvoke != nil {
+			m.cancelInvoke()
+			m.cancelInvoke = nil
+		}
 		m.thinking = false
+		m.progress = nil
+		elapsed := time.Since(m.thinkingSince)
 		if msg.err != nil {
 			m.state.Messages = append(m.state.Messages, &schema.Message{
 				Role:    schema.Assistant,
 				Content: fmt.Sprintf("发生错误：%v", msg.err),
 			})
+			m.durations[len(m.state.Messages)-1] = elapsed
 			m.followTail = true
 			m.updateViewportContent(m.renderChat())
 			return m, nil
@@ -162,6 +250,10 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.state.Context = map[string]interface{}{}
 		}
 		m.state.Context[agent.ConfirmEnabledContextKey] = m.opts.ConfirmTools
+		m.state.Context[agent.DryRunEnabledContextKey] = m.opts.DryRun
+		if idx := lastAssistantIndex(m.state.Messages, msg.prevCount); idx >= 0 {
+			m.durations[idx] = elapsed
+		}
 
 		m.updateViewportContent(m.renderChat())
 
@@ -177,6 +269,19 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case toolProgressMsg:
+		if msg.seq != m.invokeSeq || !m.thinking {
+			return m, nil
+		}
+		p := msg.progress
+		m.progress = &p
+		return m, waitToolProgress(m.progressCh, m.progressDone, m.invokeSeq)
+
+	case containersResultMsg:
+		m.notice = ""
+		m.appendLocalAssistant(msg.content)
+		return m, nil
+
 	case streamTickMsg:
 		if !m.streaming {
 			return m, nil
@@ -193,9 +298,24 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		m.notice = ""
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
+		case "ctrl+y":
+			m.copyLastAssistant()
+			return m, nil
+		case "ctrl+t":
+			m.selectPrevTool()
+			return m, nil
+		}
+
+		// 对话记录内搜索：Ctrl+F 进入，n/N 跳转，Esc 退出
+		if m.search.active {
+			return m.updateSearch(msg)
+		}
+		if msg.String() == "ctrl+f" && !m.confirmVisible {
+			return m, m.startSearch()
 		}
 
 		if m.confirmVisible {
@@ -211,11 +331,9 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state.Context[agent.ConfirmGrantedContextKey] = false
 				m.state.UserQuery = "我拒绝执行工具操作，请给出替代方案。"
 				m.state.Context[agent.ConfirmEnabledContextKey] = m.opts.ConfirmTools
+				m.state.Context[agent.DryRunEnabledContextKey] = m.opts.DryRun
 
-				m.thinking = true
-				prev := len(m.state.Messages)
-				m.lastInvokePrevCount = prev
-				return m, invokeBackend(m.ctx, m.backend, m.state, prev)
+				return m, m.startInvoke()
 			case "enter":
 				granted := m.confirmIndex == 0
 				m.confirmVisible = false
@@ -226,17 +344,35 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.state.UserQuery = "我拒绝执行工具操作，请给出替代方案。"
 				}
 				m.state.Context[agent.ConfirmEnabledContextKey] = m.opts.ConfirmTools
+				m.state.Context[agent.DryRunEnabledContextKey] = m.opts.DryRun
 
-				m.thinking = true
 				m.followTail = true
-				prev := len(m.state.Messages)
-				m.lastInvokePrevCount = prev
-				return m, invokeBackend(m.ctx, m.backend, m.state, prev)
+				return m, m.startInvoke()
 			default:
 				return m, nil
 			}
 		}
 
+		if m.thinking && msg.String() == "esc" {
+			m.cancelCurrentInvoke()
+			return m, nil
+		}
+
+		if m.selectedTool >= 0 {
+			switch msg.String() {
+			case "esc":
+				m.selectedTool = -1
+				m.updateViewportContent(m.renderChat())
+				return m, nil
+			case "enter":
+				if strings.TrimSpace(m.input.Value()) == "" {
+					m.expandedTools[m.selectedTool] = !m.expandedTools[m.selectedTool]
+					m.updateViewportContent(m.renderChat())
+					return m, nil
+				}
+			}
+		}
+
 		switch msg.String() {
 		case "pgup", "pageup":
 			m.viewport.PageUp()
@@ -248,6 +384,17 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.followTail = true
 			}
 			return m, nil
+		case "up":
+			// 仅在光标位于首行时浏览历史，否则在多行输入内移动光标
+			if m.input.Line() == 0 {
+				m.browseHistory(-1)
+				return m, nil
+			}
+		case "down":
+			if m.input.Line() >= m.input.LineCount()-1 {
+				m.browseHistory(1)
+				return m, nil
+			}
 		}
 
 		var cmd tea.Cmd
@@ -262,23 +409,29 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "exit", "quit":
 				return m, tea.Quit
 			}
+			// 以 / 开头的输入作为本地命令处理，不发送给模型
+			if sc, ok := parseSlashCommand(text); ok {
+				m.input.SetValue("")
+				return m, tea.Batch(cmd, m.runSlashCommand(sc))
+			}
+			m.showHelp = false
 
 			m.state.Context[agent.ConfirmEnabledContextKey] = m.opts.ConfirmTools
+			m.state.Context[agent.DryRunEnabledContextKey] = m.opts.DryRun
+
 			m.state.UserQuery = text
 			m.state.Messages = append(m.state.Messages, schema.UserMessage(text))
 			m.followTail = true
 			m.updateViewportContent(m.renderChat())
 
 			m.input.SetValue("")
-			m.thinking = true
+			m.pushHistory(text)
 
 			// 每次新用户查询生成一个 TraceID 并注入 Context
 			traceID := uuid.New().String()
 			m.ctx = agent.WithTraceID(m.ctx, traceID)
 
-			prev := len(m.state.Messages)
-			m.lastInvokePrevCount = prev
-			return m, tea.Batch(cmd, invokeBackend(m.ctx, m.backend, m.state, prev))
+			return m, tea.Batch(cmd, m.startInvoke())
 		}
 
 		return m, cmd
@@ -297,6 +450,8 @@ func (m chatModel) View() string {
 	var inputLine string
 	if m.confirmVisible {
 		inputLine = m.confirmView()
+	} else if m.search.active {
+		inputLine = m.searchView()
 	} else {
 		inputLine = m.inputView()
 	}
@@ -307,12 +462,21 @@ func (m chatModel) View() string {
 }
 
 func (m chatModel) footerView() string {
-	left := "Enter 发送 | PgUp/PgDn 滚动 | Ctrl+C 退出"
+	left := "Enter 发送 | Alt+Enter 换行 | ↑/↓ 历史 | Ctrl+F 搜索 | Ctrl+T 选择工具输出 | Ctrl+Y 复制回复 | PgUp/PgDn 滚动 | /help 帮助 | Ctrl+C 退出"
+	if m.selectedTool >= 0 {
+		left = "Enter 展开/折叠工具输出 | Ctrl+T 上一个 | Esc 取消选择"
+	}
 	right := ""
 	if m.confirmVisible {
 		right = "Tab/←/→ 切换  Enter 确认  Esc 取消"
+	} else if m.search.active {
+		right = m.searchStatus()
+	} else if m.thinking && m.progress != nil {
+		right = fmt.Sprintf("%s %s  %ds  Esc 取消", m.spinner.View(), formatToolProgress(*m.progress), int(time.Since(m.thinkingSince).Seconds()))
 	} else if m.thinking {
-		right = m.spinner.View() + " Thinking..."
+		right = fmt.Sprintf("%s Thinking... %ds  Esc 取消", m.spinner.View(), int(time.Since(m.thinkingSince).Seconds()))
+	} else if m.notice != "" {
+		right = m.notice
 	}
 	style := lipgloss.NewStyle().Width(m.width).Padding(0, 1)
 	return style.Render(lipgloss.JoinHorizontal(lipgloss.Left, left, lipgloss.NewStyle().Width(max(0, m.width-lipgloss.Width(left)-lipgloss.Width(right)-2)).Render(""), right))
@@ -322,7 +486,7 @@ func (m chatModel) inputView() string {
 	box := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		Padding(0, 1).
-		Width(max(1, m.input.Width+2)).
+		Width(max(1, m.input.Width()+2)).
 		Render(m.input.View())
 	return box
 }
@@ -340,11 +504,62 @@ func (m *chatModel) startConfirmPrompt() {
 		title = strings.TrimSpace(title[:idx])
 	}
 
+	// 待确认的工具调用由 ChatModelNode 暂存在 Context 中（NextStepToolCalls 此时已被清空）
+	m.confirmCalls = nil
+	if pending, ok := m.state.Context[agent.ConfirmPendingContextKey].([]schema.ToolCall); ok && len(pending) > 0 {
+		m.confirmCalls = pending
+		title = "允许执行以下工具操作？"
+	}
+
 	m.confirmTitle = title
 	m.confirmVisible = true
 	m.confirmIndex = 0
 }
 
+// formatToolCall 将工具调用渲染为可读文本
+// 参数均为标量时渲染为 name(k=v, ...)，否则在名称下方输出缩进后的 JSON
+func formatToolCall(tc schema.ToolCall) string {
+	name := strings.TrimSpace(tc.Function.Name)
+	if name == "" {
+		name = "未知工具"
+	}
+	raw := strings.TrimSpace(tc.Function.Arguments)
+	if raw == "" || raw == "{}" || raw == "null" {
+		return name + "()"
+	}
+
+	var args map[string]any
+	if err := json.Unmarshal([]byte(raw), &args); err != nil {
+		return name + " " + raw
+	}
+	if len(args) == 0 {
+		return name + "()"
+	}
+
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		switch v := args[k].(type) {
+		case string:
+			parts = append(parts, fmt.Sprintf("%s=%s", k, v))
+		case bool, float64, nil:
+			parts = append(parts, fmt.Sprintf("%s=%v", k, v))
+		default:
+			pretty, err := json.MarshalIndent(args, "  ", "  ")
+			if err != nil {
+				return name + " " + raw
+			}
+			return name + "\n  " + string(pretty)
+		}
+	}
+	return name + "(" + strings.Join(parts, ", ") + ")"
+}
+
 func (m chatModel) confirmView() string {
 	title := m.confirmTitle
 	if strings.TrimSpace(title) == "" {
@@ -369,6 +584,14 @@ func (m chatModel) confirmView() string {
 		rightBtn = active.Render("取消")
 	}
 
+	if len(m.confirmCalls) > 0 {
+		lines := make([]string, 0, len(m.confirmCalls))
+		for _, tc := range m.confirmCalls {
+			lines = append(lines, "将执行 "+formatToolCall(tc))
+		}
+		title += "\n\n" + strings.Join(lines, "\n")
+	}
+
 	buttons := lipgloss.JoinHorizontal(lipgloss.Left, leftBtn, " ", rightBtn)
 	box := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -379,7 +602,7 @@ func (m chatModel) confirmView() string {
 
 func (m *chatModel) updateViewportContent(content string) {
 	oldYOffset := m.viewport.YOffset
-	m.viewport.SetContent(content)
+	m.viewport.SetContent(m.highlightSearch(content))
 	if m.followTail {
 		m.viewport.GotoBottom()
 		return
@@ -387,35 +610,162 @@ func (m *chatModel) updateViewportContent(content string) {
 	m.viewport.SetYOffset(oldYOffset)
 }
 
-func invokeBackend(ctx context.Context, backend ui.ChatBackend, state agent.AgentState, prevCount int) tea.Cmd {
-	return func() tea.Msg {
-		next, err := invokeBackendDiscardingStdIO(ctx, backend, state)
-		return backendResultMsg{state: next, err: err, prevCount: prevCount}
+// copyLastAssistant 将最近一条助手回复的原始 Markdown 复制到系统剪贴板
+func (m *chatModel) copyLastAssistant() {
+	content := lastAssistantContent(m.state.Messages)
+	if content == "" {
+		m.notice = "没有可复制的回复"
+		return
+	}
+	if clipboard.Unsupported {
+		m.notice = "剪贴板不可用"
+		return
 	}
+	if err := clipboard.WriteAll(content); err != nil {
+		m.notice = "剪贴板不可用"
+		return
+	}
+	m.notice = "已复制最近一条回复"
 }
 
-func invokeBackendDiscardingStdIO(ctx context.Context, backend ui.ChatBackend, state agent.AgentState) (agent.AgentState, error) {
-	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
-	if err != nil {
-		return backend.Invoke(ctx, state)
+// lastAssistantContent 返回最近一条有文本内容的助手消息（仅含工具调用的消息会被跳过）
+func lastAssistantContent(msgs []*schema.Message) string {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		msg := msgs[i]
+		if msg == nil || msg.Role != schema.Assistant {
+			continue
+		}
+		if strings.TrimSpace(msg.Content) != "" {
+			return msg.Content
+		}
+	}
+	return ""
+}
+
+// pushHistory 记录一条已发送的输入，并重置浏览状态
+func (m *chatModel) pushHistory(text string) {
+	if n := len(m.history); n == 0 || m.history[n-1] != text {
+		m.history = append(m.history, text)
+		if len(m.history) > inputHistoryLimit {
+			m.history = m.history[len(m.history)-inputHistoryLimit:]
+		}
+	}
+	m.historyIdx = -1
+	m.historyDraft = ""
+}
+
+// browseHistory 在输入历史中移动：delta<0 向更早，delta>0 向更新
+// 回到“当前”时恢复浏览前未发送的输入
+func (m *chatModel) browseHistory(delta int) {
+	if len(m.history) == 0 {
+		return
+	}
+
+	idx := m.historyIdx
+	if idx == -1 {
+		if delta > 0 {
+			return
+		}
+		m.historyDraft = m.input.Value()
+		idx = len(m.history)
+	}
+	idx += delta
+
+	switch {
+	case idx < 0:
+		idx = 0
+	case idx >= len(m.history):
+		m.historyIdx = -1
+		m.input.SetValue(m.historyDraft)
+		return
 	}
-	defer devNull.Close()
 
-	stdioMu.Lock()
-	oldStdout := os.Stdout
-	oldStderr := os.Stderr
-	os.Stdout = devNull
-	os.Stderr = devNull
-	stdioMu.Unlock()
+	m.historyIdx = idx
+	m.input.SetValue(m.history[idx])
+}
+
+// startInvoke 以可取消的子 context 发起一次 backend.Invoke
+// 取消会沿 context 传递到 Graph 内的模型调用与 Docker 调用
+func (m *chatModel) startInvoke() tea.Cmd {
+	if m.cancelInvoke != nil {
+		m.cancelInvoke()
+	}
+	invokeCtx, cancel := context.WithCancel(m.ctx)
+	m.cancelInvoke = cancel
+	m.invokeSeq++
+	m.thinking = true
+	m.thinkingSince = time.Now()
+
+	// 工具进度经由 context 回调写入 channel；channel 满时丢弃，避免阻塞工具执行
+	ch := make(chan agent.ToolProgress, 16)
+	m.progressCh = ch
+	m.progressDone = invokeCtx.Done()
+	m.progress = nil
+	invokeCtx = agent.WithToolProgress(invokeCtx, func(p agent.ToolProgress) {
+		select {
+		case ch <- p:
+		default:
+		}
+	})
+
+	prev := len(m.state.Messages)
+	m.lastInvokePrevCount = prev
+	// spinner 在空闲时停止 tick，这里重新启动以刷新动画与耗时
+	return tea.Batch(
+		invokeBackend(invokeCtx, m.backend, m.state, prev, m.invokeSeq),
+		waitToolProgress(m.progressCh, m.progressDone, m.invokeSeq),
+		m.spinner.Tick,
+	)
+}
+
+// waitToolProgress 等待下一条工具进度；调用结束（done 关闭）后返回 nil
+func waitToolProgress(ch <-chan agent.ToolProgress, done <-chan struct{}, seq int) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case p := <-ch:
+			return toolProgressMsg{progress: p, seq: seq}
+		case <-done:
+			return nil
+		}
+	}
+}
 
-	next, invokeErr := backend.Invoke(ctx, state)
+// formatToolProgress 渲染底栏的工具进度；Percent 未知时只显示描述（由 spinner 表示进行中）
+func formatToolProgress(p agent.ToolProgress) string {
+	label := strings.TrimSpace(p.Tool + " " + p.Message)
+	if p.Percent < 0 {
+		return label
+	}
+	pct := math.Min(100, p.Percent)
+	filled := int(pct / 100 * progressBarWidth)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+	return fmt.Sprintf("%s %s %3.0f%%", label, bar, pct)
+}
 
-	stdioMu.Lock()
-	os.Stdout = oldStdout
-	os.Stderr = oldStderr
-	stdioMu.Unlock()
+// cancelCurrentInvoke 取消进行中的调用，并追加一条“已取消”的提示
+func (m *chatModel) cancelCurrentInvoke() {
+	if m.cancelInvoke != nil {
+		m.cancelInvoke()
+		m.cancelInvoke = nil
+	}
+	// 使迟到的结果失效
+	m.invokeSeq++
+	m.thinking = false
+	m.progress = nil
+
+	m.state.Messages = append(m.state.Messages, &schema.Message{
+		Role:    schema.Assistant,
+		Content: "已取消本次请求。",
+	})
+	m.followTail = true
+	m.updateViewportContent(m.renderChat())
+}
 
-	return next, invokeErr
+func invokeBackend(ctx context.Context, backend ui.ChatBackend, state agent.AgentState, prevCount int, seq int) tea.Cmd {
+	return func() tea.Msg {
+		next, err := backend.Invoke(ctx, state)
+		return backendResultMsg{state: next, err: err, prevCount: prevCount, seq: seq}
+	}
 }
 
 func streamTick() tea.Cmd {
@@ -493,13 +843,16 @@ func (m chatModel) renderChat() string {
 			continue
 		}
 
-		line := m.renderOneMessage(msg.Role, content)
+		line := m.renderOneMessage(i, msg.Role, content)
 		if line == "" {
 			continue
 		}
 		b.WriteString(line)
 		b.WriteString("\n\n")
 	}
+	if m.showHelp {
+		b.WriteString(m.renderAssistant(-1, helpText))
+	}
 	return strings.TrimRight(b.String(), "\n")
 }
 
@@ -546,26 +899,29 @@ func maxLineWidth(s string) int {
 	return maxW
 }
 
-func (m chatModel) renderOneMessage(role schema.RoleType, content string) string {
+func (m chatModel) renderOneMessage(idx int, role schema.RoleType, content string) string {
 	switch role {
 	case schema.User:
 		return m.renderUser(content)
 	case schema.Assistant:
-		return m.renderAssistant(content)
+		return m.renderAssistant(idx, content)
 	case schema.Tool:
-		return m.renderTool(content)
+		return m.renderTool(idx, content)
 	default:
-		return m.renderTool(content)
+		return m.renderTool(idx, content)
 	}
 }
 
-func (m chatModel) renderAssistant(content string) string {
+func (m chatModel) renderAssistant(idx int, content string) string {
 	md := content
 	if m.renderer != nil && strings.TrimSpace(md) != "" {
 		if rendered, err := m.renderer.Render(md); err == nil {
 			md = strings.TrimRight(rendered, "\n")
 		}
 	}
+	if d, ok := m.durations[idx]; ok {
+		md += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(fmt.Sprintf("耗时 %.1fs", d.Seconds()))
+	}
 	md = m.wrapToWidth(md, m.desiredContentWidth(md))
 	bubble := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -587,16 +943,37 @@ func (m chatModel) renderUser(content string) string {
 	return lipgloss.NewStyle().Width(m.width).Align(lipgloss.Right).Render(bubble)
 }
 
-func (m chatModel) renderTool(content string) string {
+func (m chatModel) renderTool(idx int, content string) string {
 	label := "TOOL"
 	body := content
 	if strings.TrimSpace(body) == "" {
 		body = "(无输出)"
 	}
+	if res, err := agent.ParseToolResult(body); err == nil {
+		body = toolResultBody(res)
+	} else if pretty, ok := prettyJSON(body); ok {
+		body = pretty
+	}
+
+	// 输出较长时默认折叠为一行摘要，选中后 Enter 展开
+	if lines := strings.Count(body, "\n") + 1; lines > toolCollapseLines {
+		if m.expandedTools[idx] {
+			label += fmt.Sprintf(" ▾ (%d 行)", lines)
+		} else {
+			label += fmt.Sprintf(" ▸ (%d 行，已折叠)", lines)
+			body = toolSummary(body, max(10, m.bubbleMaxContentWidth()-4))
+		}
+	}
+
+	borderColor := lipgloss.Color("240")
+	if idx == m.selectedTool {
+		borderColor = lipgloss.Color("214")
+	}
+
 	body = m.wrapToWidth(body, m.desiredContentWidth(body))
 	bubble := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("240")).
+		BorderForeground(borderColor).
 		Foreground(lipgloss.Color("245")).
 		Padding(0, 1).
 		MaxWidth(max(20, m.width-4)).
@@ -604,6 +981,84 @@ func (m chatModel) renderTool(content string) string {
 	return bubble
 }
 
+// lastAssistantIndex 返回 from 之后最后一条有文本内容的助手消息下标，没有则返回 -1
+func lastAssistantIndex(msgs []*schema.Message, from int) int {
+	for i := len(msgs) - 1; i >= from && i >= 0; i-- {
+		if msg := msgs[i]; msg != nil && msg.Role == schema.Assistant && strings.TrimSpace(msg.Content) != "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// selectPrevTool 从当前选中位置向前选择上一条工具消息，到头后取消选择
+func (m *chatModel) selectPrevTool() {
+	start := len(m.state.Messages) - 1
+	if m.selectedTool >= 0 {
+		start = m.selectedTool - 1
+	}
+	m.selectedTool = -1
+	for i := start; i >= 0; i-- {
+		if msg := m.state.Messages[i]; msg != nil && msg.Role == schema.Tool {
+			m.selectedTool = i
+			break
+		}
+	}
+	m.followTail = m.selectedTool < 0
+	m.updateViewportContent(m.renderChat())
+}
+
+// prettyJSON 检测 JSON 对象/数组并缩进格式化；非 JSON 时返回 false
+func prettyJSON(s string) (string, bool) {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return s, false
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(trimmed), "", "  "); err != nil {
+		return s, false
+	}
+	return buf.String(), true
+}
+
+// toolResultBody 将工具结果信封渲染为“说明 + 格式化数据”，失败结果加上标记
+func toolResultBody(res agent.ToolResult) string {
+	parts := make([]string, 0, 2)
+	if msg := strings.TrimSpace(res.Message); msg != "" {
+		if !res.OK {
+			msg = "✗ " + msg
+		}
+		parts = append(parts, msg)
+	} else if !res.OK {
+		parts = append(parts, "✗ 失败")
+	}
+	if data := strings.TrimSpace(string(res.Data)); data != "" && data != "null" {
+		if pretty, ok := prettyJSON(data); ok {
+			data = pretty
+		}
+		parts = append(parts, data)
+	}
+	if len(parts) == 0 {
+		return "(无输出)"
+	}
+	return strings.Join(parts, "\n")
+}
+
+// toolSummary 取首个非空行作为折叠后的摘要
+func toolSummary(body string, width int) string {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "{" || line == "[" {
+			continue
+		}
+		if r := []rune(line); len(r) > width {
+			line = string(r[:width]) + "…"
+		}
+		return line + " …"
+	}
+	return "…"
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
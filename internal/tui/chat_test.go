@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/wwwzy/CentAgent/internal/agent"
+)
+
+func TestLastAssistantContent(t *testing.T) {
+	msgs := []*schema.Message{
+		schema.UserMessage("列出容器"),
+		{Role: schema.Assistant, Content: "好的，先看看 `docker ps`"},
+		{Role: schema.Assistant, ToolCalls: []schema.ToolCall{{ID: "call-1"}}},
+		schema.ToolMessage(`[{"id":"abc"}]`, "call-1"),
+		{Role: schema.Assistant, Content: "运行中的容器：\n\n- web"},
+		schema.UserMessage("谢谢"),
+	}
+	if got := lastAssistantContent(msgs); got != "运行中的容器：\n\n- web" {
+		t.Fatalf("unexpected content: %q", got)
+	}
+
+	// 仅含工具调用的助手消息应被跳过
+	msgs = msgs[:4]
+	if got := lastAssistantContent(msgs); got != "好的，先看看 `docker ps`" {
+		t.Fatalf("unexpected content: %q", got)
+	}
+
+	if got := lastAssistantContent([]*schema.Message{schema.UserMessage("hi")}); got != "" {
+		t.Fatalf("expected empty content, got %q", got)
+	}
+}
+
+func TestPrettyJSON(t *testing.T) {
+	got, ok := prettyJSON(` {"name":"/web","ports":[80,443]} `)
+	if !ok {
+		t.Fatalf("expected JSON to be detected")
+	}
+	want := "{\n  \"name\": \"/web\",\n  \"ports\": [\n    80,\n    443\n  ]\n}"
+	if got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+
+	for _, in := range []string{"Container web started successfully", "{not json", "", "[1, 2"} {
+		if out, ok := prettyJSON(in); ok || out != in {
+			t.Fatalf("expected %q to be left untouched, got %q", in, out)
+		}
+	}
+}
+
+func TestToolResultBody(t *testing.T) {
+	res := agent.ToolResult{OK: true, Message: "Container web started successfully", Data: []byte(`{"container_id":"web"}`)}
+	want := "Container web started successfully\n{\n  \"container_id\": \"web\"\n}"
+	if got := toolResultBody(res); got != want {
+		t.Fatalf("unexpected body:\n%s", got)
+	}
+
+	// 失败结果加上标记，且没有 data 时只显示说明
+	res = agent.ToolResult{OK: false, Message: "invalid argument: field container_id is required"}
+	if got := toolResultBody(res); got != "✗ invalid argument: field container_id is required" {
+		t.Fatalf("unexpected failure body: %q", got)
+	}
+}
+
+func TestFormatToolProgress(t *testing.T) {
+	got := formatToolProgress(agent.ToolProgress{Tool: "pull_image", Message: "1/2 layers", Percent: 50})
+	want := "pull_image 1/2 layers " + strings.Repeat("█", 10) + strings.Repeat("░", 10) + "  50%"
+	if got != want {
+		t.Fatalf("unexpected progress:\n got %q\nwant %q", got, want)
+	}
+
+	// 无法估算百分比时只显示描述
+	if got := formatToolProgress(agent.ToolProgress{Tool: "pull_image", Message: "Waiting", Percent: -1}); got != "pull_image Waiting" {
+		t.Fatalf("unexpected progress without percent: %q", got)
+	}
+}
@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/cloudwego/eino/schema"
+	"github.com/wwwzy/CentAgent/internal/agent"
+	"github.com/wwwzy/CentAgent/internal/docker"
+	"github.com/wwwzy/CentAgent/internal/ui"
+)
+
+const helpText = `**快捷键**
+
+- Enter 发送，Alt+Enter / Ctrl+J 换行
+- ↑/↓ 浏览输入历史，PgUp/PgDn 滚动
+- Ctrl+F 搜索对话记录，n/N 跳转
+- Ctrl+T 选择工具输出，Enter 展开/折叠
+- Ctrl+Y 复制最近一条回复
+- Esc 取消进行中的请求，Ctrl+C 退出
+
+**命令**
+
+- /clear 清空当前会话
+- /containers 列出所有容器（不经过模型）
+- /confirm on|off 开启/关闭工具调用确认
+- /save <path> 保存会话记录 (.json 或 Markdown)
+- /help 显示本帮助`
+
+// slashCommand 为以 / 开头的本地命令
+type slashCommand struct {
+	name string
+	arg  string
+}
+
+// parseSlashCommand 解析以 / 开头的输入；非命令输入返回 false
+func parseSlashCommand(text string) (slashCommand, bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return slashCommand{}, false
+	}
+	name, arg, _ := strings.Cut(strings.TrimPrefix(text, "/"), " ")
+	return slashCommand{name: strings.ToLower(name), arg: strings.TrimSpace(arg)}, true
+}
+
+// containersResultMsg 为 /containers 命令的执行结果
+type containersResultMsg struct {
+	content string
+}
+
+// runSlashCommand 执行本地命令，不经过模型
+func (m *chatModel) runSlashCommand(sc slashCommand) tea.Cmd {
+	m.showHelp = false
+	switch sc.name {
+	case "clear":
+		m.state.Messages = nil
+		m.state.UserQuery = ""
+		delete(m.state.Context, agent.ConfirmPendingContextKey)
+		delete(m.state.Context, agent.ConfirmAwaitingContextKey)
+		delete(m.state.Context, agent.ConfirmGrantedContextKey)
+		m.overrideContent = map[int]string{}
+		m.expandedTools = map[int]bool{}
+		m.durations = map[int]time.Duration{}
+		m.selectedTool = -1
+		m.notice = "会话已清空"
+	case "help":
+		m.showHelp = true
+	case "containers":
+		m.notice = "正在获取容器列表..."
+		return listContainersCmd(m.ctx)
+	case "confirm":
+		switch strings.ToLower(sc.arg) {
+		case "on":
+			m.opts.ConfirmTools = true
+		case "off":
+			m.opts.ConfirmTools = false
+		default:
+			m.notice = "用法: /confirm on|off"
+			return nil
+		}
+		m.state.Context[agent.ConfirmEnabledContextKey] = m.opts.ConfirmTools
+		if m.opts.ConfirmTools {
+			m.notice = "已开启工具调用确认"
+		} else {
+			m.notice = "已关闭工具调用确认"
+		}
+	case "save":
+		path := sc.arg
+		if path == "" {
+			m.notice = "用法: /save <path>"
+		} else if err := ui.SaveTranscript(path, m.state.Messages); err != nil {
+			m.notice = fmt.Sprintf("保存失败: %v", err)
+		} else {
+			m.notice = "会话记录已保存到 " + path
+		}
+	default:
+		m.notice = fmt.Sprintf("未知命令 /%s，输入 /help 查看帮助", sc.name)
+	}
+	m.updateViewportContent(m.renderChat())
+	return nil
+}
+
+// listContainersCmd 直接调用 Docker 列出容器，并渲染为 Markdown 表格
+func listContainersCmd(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		containers, err := docker.ListContainers(ctx, docker.ListContainersOptions{All: true})
+		if err != nil {
+			return containersResultMsg{content: fmt.Sprintf("获取容器列表失败：%v", err)}
+		}
+		if len(containers) == 0 {
+			return containersResultMsg{content: "当前没有容器。"}
+		}
+		var b strings.Builder
+		b.WriteString("| ID | 名称 | 镜像 | 状态 |\n|---|---|---|---|\n")
+		for _, c := range containers {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", c.ID, strings.TrimPrefix(c.Names, "/"), c.Image, c.Status)
+		}
+		return containersResultMsg{content: b.String()}
+	}
+}
+
+// appendLocalAssistant 追加一条本地生成的助手消息
+func (m *chatModel) appendLocalAssistant(content string) {
+	msg := &schema.Message{Role: schema.Assistant, Content: content}
+	m.state.Messages = append(m.state.Messages, msg)
+	m.followTail = true
+	m.updateViewportContent(m.renderChat())
+}
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/wwwzy/CentAgent/internal/agent"
+	"github.com/wwwzy/CentAgent/internal/ui"
+)
+
+func TestParseSlashCommand(t *testing.T) {
+	cases := []struct {
+		in   string
+		ok   bool
+		name string
+		arg  string
+	}{
+		{in: "/clear", ok: true, name: "clear"},
+		{in: " /HELP ", ok: true, name: "help"},
+		{in: "/confirm  off", ok: true, name: "confirm", arg: "off"},
+		{in: "/save out.md", ok: true, name: "save", arg: "out.md"},
+		{in: "列出容器", ok: false},
+	}
+	for _, c := range cases {
+		sc, ok := parseSlashCommand(c.in)
+		if ok != c.ok || sc.name != c.name || sc.arg != c.arg {
+			t.Fatalf("parse %q: got (%+v, %v)", c.in, sc, ok)
+		}
+	}
+}
+
+func newTestChatModel() chatModel {
+	state := ui.DefaultInitialState()
+	state.Messages = []*schema.Message{
+		schema.UserMessage("hi"),
+		{Role: schema.Assistant, Content: "hello"},
+	}
+	return newChatModel(context.Background(), nil, state, ui.ChatOptions{ConfirmTools: true})
+}
+
+func TestRunSlashCommand_Clear(t *testing.T) {
+	m := newTestChatModel()
+	m.state.Context[agent.ConfirmAwaitingContextKey] = true
+
+	if cmd := m.runSlashCommand(slashCommand{name: "clear"}); cmd != nil {
+		t.Fatalf("clear should not return a command")
+	}
+	if len(m.state.Messages) != 0 {
+		t.Fatalf("expected messages to be cleared, got %d", len(m.state.Messages))
+	}
+	if _, ok := m.state.Context[agent.ConfirmAwaitingContextKey]; ok {
+		t.Fatalf("expected pending confirm state to be cleared")
+	}
+}
+
+func TestRunSlashCommand_Help(t *testing.T) {
+	m := newTestChatModel()
+	m.runSlashCommand(slashCommand{name: "help"})
+	if !m.showHelp {
+		t.Fatalf("expected help to be shown")
+	}
+	if len(m.state.Messages) != 2 {
+		t.Fatalf("help should not modify the conversation")
+	}
+}
+
+func TestRunSlashCommand_Containers(t *testing.T) {
+	m := newTestChatModel()
+	// 只验证分发到了容器列表命令，不实际访问 Docker
+	if cmd := m.runSlashCommand(slashCommand{name: "containers"}); cmd == nil {
+		t.Fatalf("expected containers command to return a command")
+	}
+}
+
+func TestRunSlashCommand_Confirm(t *testing.T) {
+	m := newTestChatModel()
+
+	m.runSlashCommand(slashCommand{name: "confirm", arg: "off"})
+	if m.opts.ConfirmTools {
+		t.Fatalf("expected confirm to be disabled")
+	}
+	if enabled, _ := m.state.Context[agent.ConfirmEnabledContextKey].(bool); enabled {
+		t.Fatalf("expected confirm context to be disabled")
+	}
+
+	m.runSlashCommand(slashCommand{name: "confirm", arg: "on"})
+	if !m.opts.ConfirmTools {
+		t.Fatalf("expected confirm to be enabled")
+	}
+
+	m.runSlashCommand(slashCommand{name: "confirm", arg: "maybe"})
+	if !m.opts.ConfirmTools || m.notice == "" {
+		t.Fatalf("invalid argument should keep setting and show usage")
+	}
+}
+
+func TestRunSlashCommand_Unknown(t *testing.T) {
+	m := newTestChatModel()
+	m.runSlashCommand(slashCommand{name: "nope"})
+	if m.notice == "" {
+		t.Fatalf("expected notice for unknown command")
+	}
+}
@@ -0,0 +1,429 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/wwwzy/CentAgent/internal/docker"
+	"github.com/wwwzy/CentAgent/internal/logging"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+// DashboardOptions 为监控面板的配置
+type DashboardOptions struct {
+	// Interval 为刷新周期
+	Interval time.Duration
+	// Window 为趋势图与错误计数覆盖的时间窗口
+	Window time.Duration
+	// Points 为趋势图的点数，窗口按点数等分为时间桶
+	Points int
+	// LogLines 为查看容器日志时显示的最近日志条数
+	LogLines int
+}
+
+func (o DashboardOptions) withDefaults() DashboardOptions {
+	if o.Interval <= 0 {
+		o.Interval = 5 * time.Second
+	}
+	if o.Window <= 0 {
+		o.Window = 30 * time.Minute
+	}
+	if o.Points <= 0 {
+		o.Points = 30
+	}
+	if o.LogLines <= 0 {
+		o.LogLines = 100
+	}
+	return o
+}
+
+// dashboardDockerTimeout 为每次刷新查询 Docker 容器状态的超时；Docker 不可用时面板仍显示存储中的数据
+const dashboardDockerTimeout = 2 * time.Second
+
+// DashboardUI 为监控容器的实时面板：基于已采集的 stats/logs 展示资源趋势、错误数与健康状态
+type DashboardUI struct {
+	Store *storage.Storage
+}
+
+func (u *DashboardUI) Run(ctx context.Context, opts DashboardOptions) error {
+	if u == nil || u.Store == nil {
+		return fmt.Errorf("dashboard: storage is required")
+	}
+	// 全屏界面运行期间写入终端的日志会破坏画面，暂时丢弃内部日志
+	prevLogger := logging.SetDefault(nil)
+	defer logging.SetDefault(prevLogger)
+
+	m := newDashboardModel(ctx, u.Store, opts.withDefaults())
+	_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
+
+// dashboardRow 为面板中的一个容器
+type dashboardRow struct {
+	ID     string
+	Name   string
+	Health string
+	// CPU/Mem 为最新一次采样的使用率；CPUTrend/MemTrend 为窗口内各时间桶的平均值，无采样的桶为 -1
+	CPU         float64
+	Mem         float64
+	CPUTrend    []float64
+	MemTrend    []float64
+	Errors      int64
+	CollectedAt time.Time
+}
+
+type dashboardDataMsg struct {
+	rows []dashboardRow
+	err  error
+	at   time.Time
+}
+
+type dashboardTickMsg struct{}
+
+type dashboardLogsMsg struct {
+	id   string
+	logs []storage.ContainerLog
+	err  error
+}
+
+type dashboardModel struct {
+	ctx   context.Context
+	store *storage.Storage
+	opts  DashboardOptions
+
+	width  int
+	height int
+
+	rows      []dashboardRow
+	selected  int
+	updatedAt time.Time
+	err       error
+
+	// logsFor 非空时显示该容器的最近日志
+	logsFor  *dashboardRow
+	logsErr  error
+	viewport viewport.Model
+}
+
+func newDashboardModel(ctx context.Context, store *storage.Storage, opts DashboardOptions) dashboardModel {
+	return dashboardModel{
+		ctx:      ctx,
+		store:    store,
+		opts:     opts,
+		viewport: viewport.New(0, 0),
+	}
+}
+
+func (m dashboardModel) Init() tea.Cmd {
+	return tea.Batch(m.load(), waitCancel(m.ctx))
+}
+
+func (m dashboardModel) load() tea.Cmd {
+	return func() tea.Msg {
+		now := time.Now().UTC()
+		rows, err := loadDashboard(m.ctx, m.store, m.opts, now)
+		return dashboardDataMsg{rows: rows, err: err, at: now}
+	}
+}
+
+func (m dashboardModel) loadLogs(id string) tea.Cmd {
+	return func() tea.Msg {
+		logs, err := m.store.QueryContainerLogs(m.ctx, storage.LogQuery{ContainerID: id, Limit: m.opts.LogLines, Desc: true})
+		// 倒序查询最新的 N 条，再按时间正序显示
+		for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
+			logs[i], logs[j] = logs[j], logs[i]
+		}
+		return dashboardLogsMsg{id: id, logs: logs, err: err}
+	}
+}
+
+func (m dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case cancelMsg:
+		return m, tea.Quit
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.viewport.Width = msg.Width
+		m.viewport.Height = max(1, msg.Height-2)
+		return m, nil
+
+	case dashboardDataMsg:
+		m.err = msg.err
+		if msg.err == nil {
+			m.selected = reselect(m.rows, msg.rows, m.selected)
+			m.rows = msg.rows
+			m.updatedAt = msg.at
+		}
+		return m, tea.Tick(m.opts.Interval, func(time.Time) tea.Msg { return dashboardTickMsg{} })
+
+	case dashboardTickMsg:
+		return m, m.load()
+
+	case dashboardLogsMsg:
+		if m.logsFor == nil || m.logsFor.ID != msg.id {
+			return m, nil
+		}
+		m.logsErr = msg.err
+		m.viewport.SetContent(renderDashboardLogs(msg.logs))
+		m.viewport.GotoBottom()
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if m.logsFor != nil {
+			switch msg.String() {
+			case "esc", "q", "backspace":
+				m.logsFor = nil
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.viewport, cmd = m.viewport.Update(msg)
+			return m, cmd
+		}
+		switch msg.String() {
+		case "q", "esc":
+			return m, tea.Quit
+		case "up", "k":
+			if m.selected > 0 {
+				m.selected--
+			}
+		case "down", "j":
+			if m.selected < len(m.rows)-1 {
+				m.selected++
+			}
+		case "r":
+			return m, m.load()
+		case "enter":
+			if m.selected < len(m.rows) {
+				row := m.rows[m.selected]
+				m.logsFor = &row
+				m.logsErr = nil
+				m.viewport.SetContent("加载中...")
+				return m, m.loadLogs(row.ID)
+			}
+		}
+	}
+	return m, nil
+}
+
+// reselect 刷新后按容器 ID 保持选中项；原容器已消失时保持下标不越界
+func reselect(prev, next []dashboardRow, selected int) int {
+	if selected < len(prev) {
+		for i, r := range next {
+			if r.ID == prev[selected].ID {
+				return i
+			}
+		}
+	}
+	return max(0, min(selected, len(next)-1))
+}
+
+var (
+	dashboardHeaderStyle   = lipgloss.NewStyle().Bold(true)
+	dashboardSelectedStyle = lipgloss.NewStyle().Reverse(true)
+	dashboardMutedStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	dashboardErrorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+)
+
+func (m dashboardModel) View() string {
+	if m.logsFor != nil {
+		title := dashboardHeaderStyle.Render("日志: " + m.logsFor.Name)
+		footer := dashboardMutedStyle.Render("↑/↓ PgUp/PgDn 滚动 | Esc 返回 | Ctrl+C 退出")
+		if m.logsErr != nil {
+			footer = dashboardErrorStyle.Render("读取日志失败: " + m.logsErr.Error())
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, title, m.viewport.View(), footer)
+	}
+
+	var b strings.Builder
+	header := fmt.Sprintf("CentAgent Dashboard  窗口 %s  每 %s 刷新", m.opts.Window, m.opts.Interval)
+	if !m.updatedAt.IsZero() {
+		header += "  更新于 " + m.updatedAt.Local().Format("15:04:05")
+	}
+	b.WriteString(dashboardHeaderStyle.Render(header))
+	b.WriteString("\n\n")
+
+	spark := m.opts.Points
+	b.WriteString(dashboardHeaderStyle.Render(fmt.Sprintf("%-24s %-10s %7s %-*s %7s %-*s %6s",
+		"NAME", "HEALTH", "CPU%", spark, "CPU TREND", "MEM%", spark, "MEM TREND", "ERRORS")))
+	b.WriteString("\n")
+	if len(m.rows) == 0 {
+		b.WriteString(dashboardMutedStyle.Render("暂无采集数据（请先运行 centagent start 开启 stats 采集）"))
+		b.WriteString("\n")
+	}
+	for i, r := range m.rows {
+		line := fmt.Sprintf("%-24s %-10s %7.1f %s %7.1f %s %6d",
+			truncateRunes(r.Name, 24), truncateRunes(r.Health, 10), r.CPU, sparkline(r.CPUTrend), r.Mem, sparkline(r.MemTrend), r.Errors)
+		if i == m.selected {
+			line = dashboardSelectedStyle.Render(line)
+		} else if r.Errors > 0 || r.Health == "unhealthy" {
+			line = dashboardErrorStyle.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if m.err != nil {
+		b.WriteString(dashboardErrorStyle.Render("刷新失败: " + m.err.Error()))
+	} else {
+		b.WriteString(dashboardMutedStyle.Render("↑/↓ 选择 | Enter 查看日志 | r 刷新 | q 退出"))
+	}
+	return b.String()
+}
+
+// loadDashboard 汇总面板数据：每个容器最新采样、窗口内的分桶趋势与错误日志数，以及 Docker 中的健康状态
+func loadDashboard(ctx context.Context, store *storage.Storage, opts DashboardOptions, now time.Time) ([]dashboardRow, error) {
+	latest, err := store.LatestStatPerContainer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	from := now.Add(-opts.Window)
+	bucket := opts.Window / time.Duration(opts.Points)
+	buckets, err := store.QueryStatsBucketed(ctx, storage.StatsQuery{From: &from, To: &now}, bucket)
+	if err != nil {
+		return nil, err
+	}
+	errorRates, err := store.ErrorRatePerContainer(ctx, from, now)
+	if err != nil {
+		return nil, err
+	}
+
+	dctx, cancel := context.WithTimeout(ctx, dashboardDockerTimeout)
+	defer cancel()
+	containers, dockerErr := docker.ListContainers(dctx, docker.ListContainersOptions{All: true, FullID: true})
+
+	return buildDashboardRows(latest, buckets, errorRates, containers, dockerErr == nil, from, bucket, opts.Points), nil
+}
+
+// buildDashboardRows 将各数据源按容器 ID 合并为面板行，按容器名排序
+func buildDashboardRows(latest []storage.ContainerStat, buckets []storage.StatsBucket, errorRates []storage.ContainerErrorRate,
+	containers []docker.ContainerSummary, dockerOK bool, from time.Time, bucket time.Duration, points int) []dashboardRow {
+	rows := make([]dashboardRow, 0, len(latest))
+	index := make(map[string]int, len(latest))
+	for _, st := range latest {
+		index[st.ContainerID] = len(rows)
+		rows = append(rows, dashboardRow{
+			ID:          st.ContainerID,
+			Name:        strings.TrimPrefix(st.ContainerName, "/"),
+			Health:      "-",
+			CPU:         st.CPUPercent,
+			Mem:         st.MemPercent,
+			CPUTrend:    emptyTrend(points),
+			MemTrend:    emptyTrend(points),
+			CollectedAt: st.CollectedAt,
+		})
+	}
+
+	for _, b := range buckets {
+		i, ok := index[b.ContainerID]
+		if !ok {
+			continue
+		}
+		slot := int(b.Start.Sub(from) / bucket)
+		if slot < 0 || slot >= points {
+			continue
+		}
+		rows[i].CPUTrend[slot] = b.AvgCPUPercent
+		rows[i].MemTrend[slot] = b.AvgMemPercent
+	}
+	for _, e := range errorRates {
+		if i, ok := index[e.ContainerID]; ok {
+			rows[i].Errors = e.Errors
+		}
+	}
+	if dockerOK {
+		health := make(map[string]string, len(containers))
+		for _, c := range containers {
+			health[c.ID] = containerHealth(c.State, c.Status)
+		}
+		for i := range rows {
+			if h, ok := health[rows[i].ID]; ok {
+				rows[i].Health = h
+			} else {
+				rows[i].Health = "removed"
+			}
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+	return rows
+}
+
+// containerHealth 从容器状态与 docker ps 的状态描述中提取健康状态；无健康检查时返回运行状态
+func containerHealth(state, status string) string {
+	switch {
+	case strings.Contains(status, "(unhealthy)"):
+		return "unhealthy"
+	case strings.Contains(status, "(healthy)"):
+		return "healthy"
+	case strings.Contains(status, "(health: starting)"):
+		return "starting"
+	}
+	if state == "" {
+		return "-"
+	}
+	return state
+}
+
+func emptyTrend(points int) []float64 {
+	out := make([]float64, points)
+	for i := range out {
+		out[i] = -1
+	}
+	return out
+}
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline 将趋势渲染为迷你柱状图，按 max(100, 最大值) 缩放；无数据的点显示为空格
+func sparkline(values []float64) string {
+	top := 100.0
+	for _, v := range values {
+		top = math.Max(top, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		if v < 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		idx := int(v / top * float64(len(sparkBlocks)-1))
+		b.WriteRune(sparkBlocks[max(0, min(idx, len(sparkBlocks)-1))])
+	}
+	return b.String()
+}
+
+func renderDashboardLogs(logs []storage.ContainerLog) string {
+	if len(logs) == 0 {
+		return dashboardMutedStyle.Render("(暂无日志，日志采集需开启 monitor.logs.enabled)")
+	}
+	var b strings.Builder
+	for _, l := range logs {
+		line := fmt.Sprintf("%s [%s] %s", l.Timestamp.Local().Format("01-02 15:04:05"), l.Source, strings.TrimRight(l.Message, "\n"))
+		if l.Level == "ERROR" || l.Level == "FATAL" {
+			line = dashboardErrorStyle.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
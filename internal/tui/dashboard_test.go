@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wwwzy/CentAgent/internal/docker"
+	"github.com/wwwzy/CentAgent/internal/storage"
+)
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]float64{0, 50, 100, -1}); got != "▁▄█ " {
+		t.Fatalf("unexpected sparkline: %q", got)
+	}
+	// 超过 100% 时按最大值缩放
+	if got := sparkline([]float64{100, 200}); got != "▄█" {
+		t.Fatalf("unexpected scaled sparkline: %q", got)
+	}
+}
+
+func TestContainerHealth(t *testing.T) {
+	cases := []struct{ state, status, want string }{
+		{"running", "Up 5 minutes (healthy)", "healthy"},
+		{"running", "Up 5 minutes (unhealthy)", "unhealthy"},
+		{"running", "Up 3 seconds (health: starting)", "starting"},
+		{"exited", "Exited (137) 2 minutes ago", "exited"},
+		{"", "", "-"},
+	}
+	for _, c := range cases {
+		if got := containerHealth(c.state, c.status); got != c.want {
+			t.Fatalf("containerHealth(%q, %q) = %q, want %q", c.state, c.status, got, c.want)
+		}
+	}
+}
+
+func TestBuildDashboardRows(t *testing.T) {
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	latest := []storage.ContainerStat{
+		{ContainerID: "cid-web", ContainerName: "/web", CPUPercent: 40, MemPercent: 30},
+		{ContainerID: "cid-api", ContainerName: "/api", CPUPercent: 10, MemPercent: 20},
+		{ContainerID: "cid-old", ContainerName: "/old"},
+	}
+	buckets := []storage.StatsBucket{
+		{ContainerID: "cid-web", Start: from, AvgCPUPercent: 20, AvgMemPercent: 25},
+		{ContainerID: "cid-web", Start: from.Add(2 * time.Minute), AvgCPUPercent: 40, AvgMemPercent: 30},
+		// 超出点数的桶被忽略
+		{ContainerID: "cid-web", Start: from.Add(10 * time.Minute), AvgCPUPercent: 90},
+	}
+	errorRates := []storage.ContainerErrorRate{{ContainerID: "cid-api", Errors: 3, Total: 10}}
+	containers := []docker.ContainerSummary{
+		{ID: "cid-web", State: "running", Status: "Up 1 hour (healthy)"},
+		{ID: "cid-api", State: "running", Status: "Up 1 hour"},
+	}
+
+	rows := buildDashboardRows(latest, buckets, errorRates, containers, true, from, time.Minute, 3)
+	if len(rows) != 3 || rows[0].Name != "api" || rows[1].Name != "old" || rows[2].Name != "web" {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+	if rows[0].Errors != 3 || rows[0].Health != "running" {
+		t.Fatalf("unexpected api row: %+v", rows[0])
+	}
+	if rows[1].Health != "removed" {
+		t.Fatalf("expected removed container, got %+v", rows[1])
+	}
+	web := rows[2]
+	if web.Health != "healthy" || web.CPU != 40 {
+		t.Fatalf("unexpected web row: %+v", web)
+	}
+	if want := []float64{20, -1, 40}; !equalFloats(web.CPUTrend, want) {
+		t.Fatalf("unexpected cpu trend: %v", web.CPUTrend)
+	}
+
+	// Docker 不可用时健康状态显示为 "-"
+	rows = buildDashboardRows(latest, nil, nil, nil, false, from, time.Minute, 3)
+	for _, r := range rows {
+		if r.Health != "-" {
+			t.Fatalf("expected unknown health without docker, got %+v", r)
+		}
+	}
+}
+
+func TestReselect(t *testing.T) {
+	prev := []dashboardRow{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	if got := reselect(prev, []dashboardRow{{ID: "b"}, {ID: "c"}}, 2); got != 1 {
+		t.Fatalf("expected selection to follow container, got %d", got)
+	}
+	if got := reselect(prev, []dashboardRow{{ID: "a"}}, 2); got != 0 {
+		t.Fatalf("expected selection clamped, got %d", got)
+	}
+}
+
+func equalFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,198 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;?]*[ -/]*[@-~]")
+
+var searchHighlightStyle = lipgloss.NewStyle().Reverse(true)
+
+// searchState 记录对话记录内搜索的状态
+type searchState struct {
+	// active 为 true 时处于搜索模式；editing 为 true 时正在输入关键字
+	active  bool
+	editing bool
+
+	input   textinput.Model
+	query   string
+	matches []int // 命中的行号（基于渲染后的内容）
+	current int
+
+	// prevFollowTail 为进入搜索前的 followTail，退出时恢复
+	prevFollowTail bool
+}
+
+func newSearchInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "搜索: "
+	ti.Placeholder = "输入关键字，Enter 确认"
+	return ti
+}
+
+// startSearch 进入搜索模式，搜索期间关闭 followTail
+func (m *chatModel) startSearch() tea.Cmd {
+	m.search.active = true
+	m.search.editing = true
+	m.search.prevFollowTail = m.followTail
+	m.followTail = false
+	m.search.input.SetValue(m.search.query)
+	m.search.input.CursorEnd()
+	m.input.Blur()
+	return m.search.input.Focus()
+}
+
+// stopSearch 退出搜索模式并恢复 followTail
+func (m *chatModel) stopSearch() tea.Cmd {
+	m.search.active = false
+	m.search.editing = false
+	m.search.matches = nil
+	m.search.input.Blur()
+	m.followTail = m.search.prevFollowTail
+	m.updateViewportContent(m.renderChat())
+	return m.input.Focus()
+}
+
+// updateSearch 处理搜索模式下的按键：输入阶段实时匹配，确认后用 n/N 在命中之间跳转
+func (m chatModel) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return m, m.stopSearch()
+	}
+
+	if m.search.editing {
+		if msg.String() == "enter" {
+			m.search.editing = false
+			m.search.input.Blur()
+			if len(m.search.matches) == 0 {
+				return m, m.stopSearch()
+			}
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.search.input, cmd = m.search.input.Update(msg)
+		m.search.query = m.search.input.Value()
+		m.refreshSearch()
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "n":
+		m.jumpToMatch(1)
+	case "N":
+		m.jumpToMatch(-1)
+	case "ctrl+f", "/":
+		return m, m.startSearch()
+	case "pgup", "pageup":
+		m.viewport.PageUp()
+	case "pgdown", "pagedown":
+		m.viewport.PageDown()
+	}
+	return m, nil
+}
+
+// refreshSearch 重新计算命中并定位到第一个命中
+func (m *chatModel) refreshSearch() {
+	m.search.matches = findMatchLines(m.renderChat(), m.search.query)
+	m.search.current = 0
+	m.updateViewportContent(m.renderChat())
+	m.scrollToMatch()
+}
+
+func (m *chatModel) jumpToMatch(delta int) {
+	n := len(m.search.matches)
+	if n == 0 {
+		return
+	}
+	m.search.current = ((m.search.current+delta)%n + n) % n
+	m.updateViewportContent(m.renderChat())
+	m.scrollToMatch()
+}
+
+func (m *chatModel) scrollToMatch() {
+	if len(m.search.matches) == 0 {
+		return
+	}
+	line := m.search.matches[m.search.current]
+	// 命中行上方保留少量上下文
+	m.viewport.SetYOffset(max(0, line-2))
+}
+
+// highlightSearch 高亮渲染内容中的命中；命中行会去掉原有样式后按纯文本高亮
+func (m chatModel) highlightSearch(content string) string {
+	if !m.search.active || m.search.query == "" {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	for _, idx := range m.search.matches {
+		if idx < 0 || idx >= len(lines) {
+			continue
+		}
+		lines[idx] = highlightLine(ansiPattern.ReplaceAllString(lines[idx], ""), m.search.query)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func highlightLine(plain, query string) string {
+	lowerLine := strings.ToLower(plain)
+	lowerQuery := strings.ToLower(query)
+	if len(lowerLine) != len(plain) || len(lowerQuery) != len(query) {
+		// 大小写转换改变了字节长度时退化为区分大小写匹配，避免下标错位
+		lowerLine, lowerQuery = plain, query
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(lowerLine, lowerQuery)
+		if i < 0 {
+			b.WriteString(plain)
+			return b.String()
+		}
+		b.WriteString(plain[:i])
+		b.WriteString(searchHighlightStyle.Render(plain[i : i+len(query)]))
+		plain = plain[i+len(query):]
+		lowerLine = lowerLine[i+len(query):]
+	}
+}
+
+// findMatchLines 返回渲染内容中包含 query（忽略大小写与 ANSI 样式）的行号
+func findMatchLines(content, query string) []int {
+	query = strings.ToLower(query)
+	if query == "" {
+		return nil
+	}
+	var out []int
+	for i, line := range strings.Split(content, "\n") {
+		if strings.Contains(strings.ToLower(ansiPattern.ReplaceAllString(line, "")), query) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// searchView 渲染搜索输入框，高度与输入区一致以保持布局不变
+func (m chatModel) searchView() string {
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("214")).
+		Padding(0, 1).
+		Width(max(1, m.input.Width()+2)).
+		Height(inputLines).
+		Render(m.search.input.View())
+}
+
+func (m chatModel) searchStatus() string {
+	if len(m.search.matches) == 0 {
+		if m.search.query == "" {
+			return "Enter 确认  Esc 退出搜索"
+		}
+		return "无匹配  Esc 退出搜索"
+	}
+	return fmt.Sprintf("%d/%d  n/N 跳转  Esc 退出搜索", m.search.current+1, len(m.search.matches))
+}
@@ -17,6 +17,10 @@ type ChatUI interface {
 
 type ChatOptions struct {
 	ConfirmTools bool
+	// DryRun 为 true 时变更类工具不真正执行，只返回将要调用的 Docker API
+	DryRun bool
+	// TranscriptPath 非空时，退出对话后将会话记录写入该文件（.json 为 JSON，否则为 Markdown）
+	TranscriptPath string
 }
 
 func DefaultInitialState() agent.AgentState {
@@ -33,7 +33,15 @@ func (u *ConsoleChatUI) Run(ctx context.Context, backend ChatBackend, initial ag
 		state.Context = map[string]interface{}{}
 	}
 
-	fmt.Fprintln(out, "进入 CentAgent 对话模式。输入 exit/quit 退出。")
+	if opts.TranscriptPath != "" {
+		defer func() {
+			if err := SaveTranscript(opts.TranscriptPath, state.Messages); err != nil {
+				fmt.Fprintf(out, "保存会话记录失败: %v\n", err)
+			}
+		}()
+	}
+
+	fmt.Fprintln(out, "进入 CentAgent 对话模式。输入 exit/quit 退出，/save <path> 保存会话记录。")
 	for {
 		select {
 		case <-ctx.Done():
@@ -43,6 +51,7 @@ func (u *ConsoleChatUI) Run(ctx context.Context, backend ChatBackend, initial ag
 		}
 
 		state.Context[agent.ConfirmEnabledContextKey] = opts.ConfirmTools
+		state.Context[agent.DryRunEnabledContextKey] = opts.DryRun
 
 		if awaiting, ok := state.Context[agent.ConfirmAwaitingContextKey].(bool); ok && awaiting {
 			fmt.Fprint(out, "确认执行工具？(y/N): ")
@@ -79,10 +88,21 @@ func (u *ConsoleChatUI) Run(ctx context.Context, backend ChatBackend, initial ag
 				fmt.Fprintln(out, "已退出。")
 				return nil
 			}
+			if path, ok := ParseSaveCommand(line); ok {
+				if path == "" {
+					fmt.Fprintln(out, "用法: /save <path>")
+				} else if err := SaveTranscript(path, state.Messages); err != nil {
+					fmt.Fprintf(out, "保存会话记录失败: %v\n", err)
+				} else {
+					fmt.Fprintf(out, "会话记录已保存到 %s\n", path)
+				}
+				continue
+			}
 			state.UserQuery = line
 
 			// 每次新用户查询生成一个 TraceID
 			state.Context[agent.ConfirmEnabledContextKey] = opts.ConfirmTools
+			state.Context[agent.DryRunEnabledContextKey] = opts.DryRun
 			traceID := uuid.New().String()
 			// 将 TraceID 注入 context
 			ctx = agent.WithTraceID(ctx, traceID)
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/wwwzy/CentAgent/internal/agent"
+)
+
+// TranscriptEntry 为导出会话记录中的一条消息
+type TranscriptEntry struct {
+	Time       *time.Time        `json:"time,omitempty"`
+	Role       string            `json:"role"`
+	Content    string            `json:"content,omitempty"`
+	ToolCalls  []schema.ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string            `json:"tool_call_id,omitempty"`
+}
+
+// SaveTranscript 将会话消息写入文件：.json 后缀导出 JSON，其余导出 Markdown
+func SaveTranscript(path string, messages []*schema.Message) error {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return fmt.Errorf("transcript path is required")
+	}
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		b, err := json.MarshalIndent(TranscriptEntries(messages), "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal transcript: %w", err)
+		}
+		data = append(b, '\n')
+	} else {
+		data = []byte(RenderTranscriptMarkdown(messages))
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write transcript: %w", err)
+	}
+	return nil
+}
+
+// TranscriptEntries 将消息转换为导出记录，跳过 System 消息
+func TranscriptEntries(messages []*schema.Message) []TranscriptEntry {
+	entries := make([]TranscriptEntry, 0, len(messages))
+	for _, msg := range messages {
+		if msg == nil || msg.Role == schema.System {
+			continue
+		}
+		e := TranscriptEntry{
+			Role:       string(msg.Role),
+			Content:    msg.Content,
+			ToolCalls:  msg.ToolCalls,
+			ToolCallID: msg.ToolCallID,
+		}
+		if t := agent.MessageTime(msg); !t.IsZero() {
+			e.Time = &t
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// RenderTranscriptMarkdown 将会话渲染为 Markdown
+func RenderTranscriptMarkdown(messages []*schema.Message) string {
+	var b strings.Builder
+	b.WriteString("# CentAgent 会话记录\n")
+
+	for _, e := range TranscriptEntries(messages) {
+		title := transcriptRoleTitle(e.Role)
+		if e.Time != nil {
+			title += " · " + e.Time.Format(time.RFC3339)
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+
+		if e.Content != "" {
+			if e.Role == string(schema.Tool) {
+				fmt.Fprintf(&b, "```\n%s\n```\n", strings.TrimRight(e.Content, "\n"))
+			} else {
+				fmt.Fprintf(&b, "%s\n", strings.TrimRight(e.Content, "\n"))
+			}
+		}
+		if e.Content != "" && len(e.ToolCalls) > 0 {
+			b.WriteString("\n")
+		}
+		for _, tc := range e.ToolCalls {
+			fmt.Fprintf(&b, "- 调用工具 `%s` 参数: `%s`\n", tc.Function.Name, tc.Function.Arguments)
+		}
+	}
+	return b.String()
+}
+
+func transcriptRoleTitle(role string) string {
+	switch schema.RoleType(role) {
+	case schema.User:
+		return "用户"
+	case schema.Assistant:
+		return "助手"
+	case schema.Tool:
+		return "工具"
+	}
+	return role
+}
+
+// ParseSaveCommand 解析 "/save <path>" 命令；ok 表示输入为 /save 命令（path 可能为空）
+func ParseSaveCommand(text string) (path string, ok bool) {
+	text = strings.TrimSpace(text)
+	if text != "/save" && !strings.HasPrefix(text, "/save ") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(text, "/save")), true
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/wwwzy/CentAgent/internal/agent"
+)
+
+func transcriptFixture() []*schema.Message {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	msgs := []*schema.Message{
+		schema.UserMessage("查看 web 容器"),
+		{
+			Role: schema.Assistant,
+			ToolCalls: []schema.ToolCall{{
+				ID:       "call-1",
+				Function: schema.FunctionCall{Name: "inspect_container", Arguments: `{"container_id":"web"}`},
+			}},
+		},
+		schema.ToolMessage(`{"name":"/web","status":"running"}`, "call-1"),
+		{Role: schema.Assistant, Content: "web 容器正在运行。"},
+	}
+	for _, msg := range msgs {
+		agent.StampMessage(msg, at)
+	}
+	return msgs
+}
+
+func TestSaveTranscript_Markdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.md")
+	if err := SaveTranscript(path, transcriptFixture()); err != nil {
+		t.Fatalf("save transcript: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read transcript: %v", err)
+	}
+	want := "# CentAgent 会话记录\n" +
+		"\n## 用户 · 2025-01-02T03:04:05Z\n\n查看 web 容器\n" +
+		"\n## 助手 · 2025-01-02T03:04:05Z\n\n- 调用工具 `inspect_container` 参数: `{\"container_id\":\"web\"}`\n" +
+		"\n## 工具 · 2025-01-02T03:04:05Z\n\n```\n{\"name\":\"/web\",\"status\":\"running\"}\n```\n" +
+		"\n## 助手 · 2025-01-02T03:04:05Z\n\nweb 容器正在运行。\n"
+	if string(data) != want {
+		t.Fatalf("unexpected transcript:\n%s", data)
+	}
+}
+
+func TestSaveTranscript_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := SaveTranscript(path, transcriptFixture()); err != nil {
+		t.Fatalf("save transcript: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read transcript: %v", err)
+	}
+	var entries []TranscriptEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("decode transcript: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(entries))
+	}
+	if entries[2].Role != "tool" || entries[2].ToolCallID != "call-1" {
+		t.Fatalf("unexpected tool entry: %+v", entries[2])
+	}
+	if entries[0].Time == nil || !entries[0].Time.Equal(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Fatalf("unexpected time: %v", entries[0].Time)
+	}
+	if !strings.Contains(entries[3].Content, "正在运行") {
+		t.Fatalf("unexpected assistant content: %s", entries[3].Content)
+	}
+}
+
+func TestParseSaveCommand(t *testing.T) {
+	if path, ok := ParseSaveCommand("/save out.md"); !ok || path != "out.md" {
+		t.Fatalf("unexpected parse: %q %v", path, ok)
+	}
+	if _, ok := ParseSaveCommand("/saved"); ok {
+		t.Fatalf("/saved should not be treated as /save")
+	}
+}
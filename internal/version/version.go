@@ -0,0 +1,47 @@
+// Package version 保存构建时通过 -ldflags 注入的版本信息。
+//
+// 构建示例：
+//
+//	go build -ldflags "-X github.com/wwwzy/CentAgent/internal/version.Version=v0.1.0 \
+//	  -X github.com/wwwzy/CentAgent/internal/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/wwwzy/CentAgent/internal/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/centagent
+package version
+
+import (
+	"fmt"
+	"runtime"
+)
+
+var (
+	// Version 为发布版本号，未注入时为 dev
+	Version = "dev"
+	// Commit 为构建时的 git commit
+	Commit = "unknown"
+	// BuildDate 为构建时间（UTC，RFC3339）
+	BuildDate = "unknown"
+)
+
+// Info 为当前二进制的构建信息
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get 返回当前二进制的构建信息
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+	}
+}
+
+// String 返回单行的版本描述
+func (i Info) String() string {
+	return fmt.Sprintf("centagent %s (commit %s, built %s, %s %s)", i.Version, i.Commit, i.BuildDate, i.GoVersion, i.Platform)
+}